@@ -2,9 +2,11 @@ package main
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
@@ -39,6 +41,7 @@ func (s *server) openACPConversationConnection(c echo.Context) error {
 	upgrader := websocket.Upgrader{
 		CheckOrigin:  s.acp.allowOrigin,
 		Subprotocols: subprotocols,
+		Error:        websocketUpgradeError,
 	}
 	browserConn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
 	if err != nil {
@@ -50,7 +53,9 @@ func (s *server) openACPConversationConnection(c echo.Context) error {
 
 	instanceConn, _, err := websocket.DefaultDialer.DialContext(c.Request().Context(), s.acpInstanceURL(spritz.Namespace, spritz.Name), nil)
 	if err != nil {
-		return err
+		log.Printf("spritz acp: instance dial failed name=%s namespace=%s err=%v", spritz.Name, spritz.Namespace, err)
+		_ = browserConn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "instance unavailable"), time.Now().Add(500*time.Millisecond))
+		return nil
 	}
 	defer func() {
 		_ = instanceConn.Close()
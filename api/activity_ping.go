@@ -0,0 +1,73 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+type activityPingResponse struct {
+	LastActivityAt string `json:"lastActivityAt"`
+}
+
+// newActivityPingLimiter lets each spritz bump its activity at most once per
+// SPRITZ_ACTIVITY_MIN_INTERVAL. Web workloads and ingress sidecars ping on
+// every request, and one status write per interval is enough to keep the idle
+// TTL moving. It returns nil, which allows every ping, when the interval is
+// zero.
+func newActivityPingLimiter() *keyedLimiter {
+	interval := parseDurationEnv("SPRITZ_ACTIVITY_MIN_INTERVAL", 30*time.Second)
+	if interval <= 0 {
+		return nil
+	}
+	return newKeyedLimiter(rate.Every(interval), 1, interval, interval, 0)
+}
+
+// recordActivityPing handles POST /spritzes/:name/activity, which bumps
+// status.lastActivityAt for traffic the API does not see itself, such as
+// requests served straight from the workload's own web port.
+func (s *server) recordActivityPing(c echo.Context) error {
+	principal, ok := principalFromContext(c)
+	if s.auth.enabled() && (!ok || principal.ID == "") {
+		return writeError(c, http.StatusUnauthorized, "unauthenticated")
+	}
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		return writeError(c, http.StatusBadRequest, "spritz name required")
+	}
+	namespace := s.namespace
+	if namespace == "" {
+		namespace = c.QueryParam("namespace")
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	spritz := &spritzv1.Spritz{}
+	if err := s.client.Get(c.Request().Context(), clientKey(namespace, name), spritz); err != nil {
+		return writeError(c, http.StatusNotFound, "spritz not found")
+	}
+	if err := authorizeHumanOwnedAccess(principal, spritz.Spec.Owner.ID, s.auth.enabled()); err != nil {
+		return writeForbidden(c)
+	}
+	allowed, retryAfter, refund := s.activityPings.Reserve(execSessionSpritzKey(namespace, name))
+	if !allowed {
+		c.Response().Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+		return writeError(c, http.StatusTooManyRequests, "rate limit exceeded")
+	}
+
+	now := time.Now().UTC()
+	if err := s.recordSpritzActivity(c.Request().Context(), namespace, name, now); err != nil {
+		log.Printf("spritz activity: failed to record activity name=%s namespace=%s user_id=%s err=%v", name, namespace, principal.ID, err)
+		// Give the slot back so the caller can retry the failed write.
+		refund()
+		return writeError(c, http.StatusInternalServerError, "failed to record activity")
+	}
+	return writeJSON(c, http.StatusOK, activityPingResponse{LastActivityAt: now.Format(time.RFC3339)})
+}
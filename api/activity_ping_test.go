@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+)
+
+func TestActivityPingRecordsActivityForOwner(t *testing.T) {
+	s := newListSpritzTestServer(t, spritzForOwner("web-otter", "user-1", nil))
+	var recorded []string
+	s.activityRecorder = func(ctx context.Context, namespace, name string, when time.Time) error {
+		recorded = append(recorded, namespace+"/"+name)
+		return nil
+	}
+	s.activityPings = newKeyedLimiter(rate.Every(time.Minute), 1, time.Minute, time.Minute, 0)
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes/:name/activity", s.recordActivityPing)
+
+	ping := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/spritzes/web-otter/activity", nil)
+		req.Header.Set("X-Spritz-User-Id", userID)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := ping("user-2"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected other user to be forbidden, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := ping("user-1"); rec.Code != http.StatusOK {
+		t.Fatalf("expected owner ping to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := ping("user-1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected second ping to be rate limited, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After header on rate limited ping")
+	}
+	if len(recorded) != 1 || recorded[0] != "spritz-test/web-otter" {
+		t.Fatalf("expected one recorded activity, got %v", recorded)
+	}
+}
+
+func TestActivityPingLimiterAllowsAfterInterval(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	t.Setenv("SPRITZ_ACTIVITY_MIN_INTERVAL", "30s")
+	limiter := newActivityPingLimiter()
+	limiter.now = func() time.Time { return now }
+
+	if ok, _ := limiter.Allow("ns/a"); !ok {
+		t.Fatal("expected first ping to be allowed")
+	}
+	if ok, wait := limiter.Allow("ns/a"); ok || wait != 30*time.Second {
+		t.Fatalf("expected repeat ping to wait 30s, got ok=%v wait=%s", ok, wait)
+	}
+	if ok, _ := limiter.Allow("ns/b"); !ok {
+		t.Fatal("expected other spritz to be limited separately")
+	}
+	now = now.Add(30 * time.Second)
+	if ok, _ := limiter.Allow("ns/a"); !ok {
+		t.Fatal("expected ping after the interval to be allowed")
+	}
+	var nilLimiter *keyedLimiter
+	if ok, _ := nilLimiter.Allow("ns/a"); !ok {
+		t.Fatal("expected nil limiter to allow pings")
+	}
+}
+
+func TestActivityPingRetriesAfterFailedWrite(t *testing.T) {
+	s := newListSpritzTestServer(t, spritzForOwner("web-otter", "user-1", nil))
+	failures := 1
+	recorded := 0
+	s.activityRecorder = func(ctx context.Context, namespace, name string, when time.Time) error {
+		if failures > 0 {
+			failures--
+			return errors.New("conflict")
+		}
+		recorded++
+		return nil
+	}
+	s.activityPings = newKeyedLimiter(rate.Every(time.Minute), 1, time.Minute, time.Minute, 0)
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes/:name/activity", s.recordActivityPing)
+
+	ping := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/spritzes/web-otter/activity", nil)
+		req.Header.Set("X-Spritz-User-Id", "user-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := ping(); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected failed write to return 500, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := ping(); rec.Code != http.StatusOK {
+		t.Fatalf("expected retry after a failed write to be allowed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if recorded != 1 {
+		t.Fatalf("expected one recorded activity, got %d", recorded)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// adminNamespaceGrant makes the listed ids and teams admins of the listed
+// namespaces only. Cluster-wide admins stay configured through
+// SPRITZ_AUTH_ADMIN_IDS and SPRITZ_AUTH_ADMIN_TEAMS.
+type adminNamespaceGrant struct {
+	ids        map[string]struct{}
+	teams      map[string]struct{}
+	namespaces []string
+}
+
+// parseAdminNamespaceGrants reads SPRITZ_AUTH_ADMIN_NAMESPACES, a JSON list
+// such as [{"teams":["platform"],"namespaces":["spritz-platform"]}].
+func parseAdminNamespaceGrants(raw string) ([]adminNamespaceGrant, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var payload []struct {
+		IDs        []string `json:"ids"`
+		Teams      []string `json:"teams"`
+		Namespaces []string `json:"namespaces"`
+	}
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		return nil, fmt.Errorf("invalid SPRITZ_AUTH_ADMIN_NAMESPACES: %w", err)
+	}
+	grants := make([]adminNamespaceGrant, 0, len(payload))
+	for index, item := range payload {
+		ids := normalizeStringSet(item.IDs)
+		teams := normalizeStringSet(item.Teams)
+		if len(ids) == 0 && len(teams) == 0 {
+			return nil, fmt.Errorf("invalid SPRITZ_AUTH_ADMIN_NAMESPACES: grants[%d] needs ids or teams", index)
+		}
+		namespaces := dedupeStrings(item.Namespaces)
+		if len(namespaces) == 0 {
+			return nil, fmt.Errorf("invalid SPRITZ_AUTH_ADMIN_NAMESPACES: grants[%d].namespaces is required", index)
+		}
+		grants = append(grants, adminNamespaceGrant{
+			ids:        ids,
+			teams:      teams,
+			namespaces: namespaces,
+		})
+	}
+	return grants, nil
+}
+
+// adminNamespacesFor returns the namespaces id, or one of its teams, may
+// administer.
+func (a *authConfig) adminNamespacesFor(id string, teams []string) []string {
+	var namespaces []string
+	for _, grant := range a.adminNamespaces {
+		if !grant.matches(id, teams) {
+			continue
+		}
+		namespaces = append(namespaces, grant.namespaces...)
+	}
+	return dedupeStrings(namespaces)
+}
+
+func (g adminNamespaceGrant) matches(id string, teams []string) bool {
+	if _, ok := g.ids[id]; ok {
+		return true
+	}
+	for _, team := range teams {
+		if _, ok := g.teams[team]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdminForNamespace reports whether p may manage every spritz in namespace.
+// It is for actions confined to that namespace, such as reading or deleting a
+// spritz. Gates that lift a cluster-wide safety check, such as hostPath
+// volumes or unconfined security profiles, must use isAdminPrincipal instead.
+func (p principal) isAdminForNamespace(namespace string) bool {
+	if p.isAdminPrincipal() {
+		return true
+	}
+	namespace = strings.TrimSpace(namespace)
+	if namespace == "" {
+		return false
+	}
+	for _, candidate := range p.AdminNamespaces {
+		if candidate == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeNamespacedOwnerAccess is authorizeHumanOwnedAccess that also lets
+// admins of the spritz namespace through.
+func authorizeNamespacedOwnerAccess(principal principal, namespace, ownerID string, enabled bool) error {
+	if enabled && principal.isAdminForNamespace(namespace) {
+		return nil
+	}
+	return authorizeHumanOwnedAccess(principal, ownerID, enabled)
+}
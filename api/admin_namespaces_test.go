@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestParseAdminNamespaceGrants(t *testing.T) {
+	grants, err := parseAdminNamespaceGrants(`[{"ids":["user-1"],"teams":["platform"],"namespaces":["team-a","team-b"]}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	auth := authConfig{adminNamespaces: grants}
+	if got := auth.adminNamespacesFor("user-2", []string{"platform"}); strings.Join(got, ",") != "team-a,team-b" {
+		t.Fatalf("expected team grant to match, got %#v", got)
+	}
+	if got := auth.adminNamespacesFor("user-3", []string{"sales"}); len(got) != 0 {
+		t.Fatalf("expected no namespaces for an unrelated principal, got %#v", got)
+	}
+
+	for _, raw := range []string{
+		`{"ids":["user-1"]}`,
+		`[{"namespaces":["team-a"]}]`,
+		`[{"ids":["user-1"],"namespaces":[" "]}]`,
+	} {
+		if _, err := parseAdminNamespaceGrants(raw); err == nil {
+			t.Fatalf("expected %s to be rejected", raw)
+		}
+	}
+}
+
+func TestNamespaceAdminScopesListGetAndDelete(t *testing.T) {
+	inTeam := spritzForOwner("tidy-otter", "user-1", nil)
+	inTeam.Namespace = "team-a"
+	elsewhere := spritzForOwner("quiet-harbor", "user-1", nil)
+	elsewhere.Namespace = "team-b"
+	s := newListSpritzTestServer(t, inTeam, elsewhere)
+	s.namespace = ""
+	grants, err := parseAdminNamespaceGrants(`[{"ids":["lead"],"namespaces":["team-a"]}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.auth.adminNamespaces = grants
+
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.GET("/api/spritzes", s.listSpritzes)
+	secured.GET("/api/spritzes/:name", s.getSpritz)
+	secured.DELETE("/api/spritzes/:name", s.deleteSpritz)
+	call := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("X-Spritz-User-Id", "lead")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := call(http.MethodGet, "/api/spritzes")
+	var payload struct {
+		Data struct {
+			Items []struct {
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+			} `json:"items"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode list: %v", err)
+	}
+	if len(payload.Data.Items) != 1 || payload.Data.Items[0].Metadata.Name != "tidy-otter" {
+		t.Fatalf("expected only the administered namespace in the list, got %s", rec.Body.String())
+	}
+
+	if rec := call(http.MethodGet, "/api/spritzes/tidy-otter?namespace=team-a"); rec.Code != http.StatusOK {
+		t.Fatalf("expected namespace admin to get spritz, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := call(http.MethodGet, "/api/spritzes/quiet-harbor?namespace=team-b"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected other namespace to be forbidden, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := call(http.MethodDelete, "/api/spritzes/quiet-harbor?namespace=team-b"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected delete in other namespace to be forbidden, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := call(http.MethodDelete, "/api/spritzes/tidy-otter?namespace=team-a"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected namespace admin to delete spritz, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
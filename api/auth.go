@@ -53,8 +53,10 @@ type authConfig struct {
 	headerDefaultType         principalType
 	adminIDs                  map[string]struct{}
 	adminTeams                map[string]struct{}
+	adminNamespaces           []adminNamespaceGrant
 	bearerIntrospectionURL    string
 	bearerIntrospectionAuth   string
+	bearerIntrospectionCache  *introspectionCache
 	bearerMethod              string
 	bearerTimeout             time.Duration
 	bearerTokenParam          string
@@ -104,6 +106,9 @@ type principal struct {
 	Issuer  string
 	Scopes  []string
 	IsAdmin bool
+	// AdminNamespaces lists namespaces where a principal that is not a
+	// cluster admin may manage every spritz.
+	AdminNamespaces []string
 }
 
 type principalType string
@@ -121,6 +126,11 @@ func newAuthConfig() authConfig {
 		bearerDefaultType = principalTypeService
 	}
 	staticPrincipals, configErr := parseStaticBearerPrincipals(os.Getenv("SPRITZ_AUTH_BEARER_STATIC_PRINCIPALS_JSON"))
+	adminNamespaces, adminNamespacesErr := parseAdminNamespaceGrants(os.Getenv("SPRITZ_AUTH_ADMIN_NAMESPACES"))
+	introspectionCache := newIntrospectionCache(
+		parseDurationEnv("SPRITZ_AUTH_BEARER_INTROSPECTION_CACHE_TTL", 0),
+		parseIntEnv("SPRITZ_AUTH_BEARER_INTROSPECTION_CACHE_MAX_ENTRIES", defaultIntrospectionCacheMaxEntries),
+	)
 	return authConfig{
 		mode:                      mode,
 		headerID:                  envOrDefault("SPRITZ_AUTH_HEADER_ID", "X-Spritz-User-Id"),
@@ -132,8 +142,10 @@ func newAuthConfig() authConfig {
 		headerDefaultType:         normalizePrincipalType(envOrDefault("SPRITZ_AUTH_HEADER_DEFAULT_TYPE", string(principalTypeHuman)), principalTypeHuman),
 		adminIDs:                  splitSet(os.Getenv("SPRITZ_AUTH_ADMIN_IDS")),
 		adminTeams:                splitSet(os.Getenv("SPRITZ_AUTH_ADMIN_TEAMS")),
+		adminNamespaces:           adminNamespaces,
 		bearerIntrospectionURL:    strings.TrimSpace(os.Getenv("SPRITZ_AUTH_BEARER_INTROSPECTION_URL")),
 		bearerIntrospectionAuth:   strings.TrimSpace(os.Getenv("SPRITZ_AUTH_BEARER_INTROSPECTION_AUTH_HEADER")),
+		bearerIntrospectionCache:  introspectionCache,
 		bearerMethod:              strings.ToUpper(envOrDefault("SPRITZ_AUTH_BEARER_METHOD", "GET")),
 		bearerTimeout:             parseDurationEnv("SPRITZ_AUTH_BEARER_TIMEOUT", 5*time.Second),
 		bearerTokenParam:          envOrDefault("SPRITZ_AUTH_BEARER_TOKEN_PARAM", "token"),
@@ -155,7 +167,7 @@ func newAuthConfig() authConfig {
 		bearerJWKSRefreshTimeout:  parseDurationEnv("SPRITZ_AUTH_BEARER_JWKS_REFRESH_TIMEOUT", 5*time.Second),
 		bearerJWKSRateLimit:       parseDurationEnv("SPRITZ_AUTH_BEARER_JWKS_RATE_LIMIT", 10*time.Second),
 		bearerStaticPrincipals:    staticPrincipals,
-		configErr:                 configErr,
+		configErr:                 errors.Join(configErr, adminNamespacesErr),
 	}
 }
 
@@ -236,6 +248,15 @@ func (p principal) hasScope(scope string) bool {
 }
 
 func (a *authConfig) principal(r *http.Request) (principal, error) {
+	resolved, err := a.resolvePrincipal(r)
+	if err != nil || resolved.IsAdmin {
+		return resolved, err
+	}
+	resolved.AdminNamespaces = a.adminNamespacesFor(resolved.ID, resolved.Teams)
+	return resolved, nil
+}
+
+func (a *authConfig) resolvePrincipal(r *http.Request) (principal, error) {
 	if !a.enabled() {
 		return principal{}, nil
 	}
@@ -324,6 +345,9 @@ func (a *authConfig) principalFromBearer(r *http.Request) (principal, error) {
 }
 
 func (a *authConfig) introspectToken(ctx context.Context, token string) (principal, error) {
+	if cached, ok := a.bearerIntrospectionCache.get(token); ok {
+		return cached, nil
+	}
 	client := &http.Client{Timeout: a.bearerTimeout}
 	endpoint := a.bearerIntrospectionURL
 	var body io.Reader
@@ -380,7 +404,7 @@ func (a *authConfig) introspectToken(ctx context.Context, token string) (princip
 
 	email := firstStringPath(payload, a.bearerEmailPaths)
 	teams := firstStringListPath(payload, a.bearerTeamsPaths)
-	return finalizePrincipal(
+	resolved := finalizePrincipal(
 		id,
 		email,
 		teams,
@@ -389,7 +413,13 @@ func (a *authConfig) introspectToken(ctx context.Context, token string) (princip
 		normalizePrincipalType(firstStringPath(payload, a.bearerTypePaths), a.bearerDefaultType),
 		firstScopeListPath(payload, a.bearerScopesPaths),
 		a.isAdmin(id, teams),
-	), nil
+	)
+	var tokenExpiry time.Time
+	if raw, ok := payload["exp"]; ok {
+		tokenExpiry, _ = parseNumericDate(raw)
+	}
+	a.bearerIntrospectionCache.put(token, resolved, tokenExpiry)
+	return resolved, nil
 }
 
 func (a *authConfig) principalFromStaticBearerToken(token string) (principal, bool) {
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultIntrospectionCacheMaxEntries = 10000
+	// maxIntrospectionCacheTTL caps how long a revoked principal can stay
+	// cached. Invalidation only reaches the replica that receives it, so the
+	// other replicas rely on entries expiring.
+	maxIntrospectionCacheTTL = 5 * time.Minute
+)
+
+// introspectionCache keeps recently introspected principals keyed by a token
+// hash so repeated requests with the same bearer token do not hit the IdP.
+// Entries can be dropped per subject when the IdP pushes a revocation.
+type introspectionCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]introspectionCacheEntry
+	now        func() time.Time
+}
+
+type introspectionCacheEntry struct {
+	principal principal
+	expiresAt time.Time
+}
+
+type invalidateAuthCacheRequest struct {
+	Subject string `json:"subject"`
+}
+
+func newIntrospectionCache(ttl time.Duration, maxEntries int) *introspectionCache {
+	if ttl <= 0 {
+		return nil
+	}
+	if ttl > maxIntrospectionCacheTTL {
+		ttl = maxIntrospectionCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = defaultIntrospectionCacheMaxEntries
+	}
+	return &introspectionCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[string]introspectionCacheEntry{},
+		now:        time.Now,
+	}
+}
+
+func introspectionCacheKey(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *introspectionCache) get(token string) (principal, bool) {
+	if c == nil {
+		return principal{}, false
+	}
+	key := introspectionCacheKey(token)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return principal{}, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return principal{}, false
+	}
+	return entry.principal, true
+}
+
+// put caches a principal until the cache TTL or the token expiry, whichever
+// comes first. A zero expiry means the token did not report one.
+func (c *introspectionCache) put(token string, resolved principal, tokenExpiry time.Time) {
+	if c == nil {
+		return
+	}
+	now := c.now()
+	expiresAt := now.Add(c.ttl)
+	if !tokenExpiry.IsZero() && tokenExpiry.Before(expiresAt) {
+		expiresAt = tokenExpiry
+	}
+	if !now.Before(expiresAt) {
+		return
+	}
+	key := introspectionCacheKey(token)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		for existingKey, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, existingKey)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+	c.entries[key] = introspectionCacheEntry{principal: resolved, expiresAt: expiresAt}
+}
+
+// invalidateSubject drops every cached principal whose id or subject matches.
+func (c *introspectionCache) invalidateSubject(subject string) int {
+	subject = strings.TrimSpace(subject)
+	if c == nil || subject == "" {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for key, entry := range c.entries {
+		if entry.principal.ID == subject || entry.principal.Subject == subject {
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (s *server) invalidateAuthCache(c echo.Context) error {
+	var body invalidateAuthCacheRequest
+	if err := c.Bind(&body); err != nil {
+		return writeBindError(c, err)
+	}
+	subject := strings.TrimSpace(body.Subject)
+	if subject == "" {
+		return writeError(c, http.StatusBadRequest, "subject is required")
+	}
+	return writeJSON(c, http.StatusOK, map[string]any{
+		"subject":     subject,
+		"invalidated": s.auth.bearerIntrospectionCache.invalidateSubject(subject),
+	})
+}
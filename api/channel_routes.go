@@ -98,7 +98,7 @@ func (s *server) resolveChannelRoute(c echo.Context) error {
 
 	var body channelRouteResolveRequest
 	if err := c.Bind(&body); err != nil {
-		return writeError(c, http.StatusBadRequest, "invalid json")
+		return writeBindError(c, err)
 	}
 	normalized, err := normalizeChannelRouteResolveRequest(body)
 	if err != nil {
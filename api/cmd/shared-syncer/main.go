@@ -16,11 +16,14 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -47,6 +50,20 @@ var (
 	sharedMountKeepAlive    = 30 * time.Second
 	sharedMountHeaderTTL    = 30 * time.Second
 	sharedMountIdleConnTTL  = 90 * time.Second
+	// shutdownFlushTimeout bounds the final publish on SIGTERM; it must fit in
+	// the pod's termination grace period.
+	shutdownFlushTimeout = 20 * time.Second
+	// Poll and apply failures back off from syncRetryBackoff up to
+	// maxSyncRetryBackoff. After syncFailureThreshold consecutive failures the
+	// /ready body marks the mount degraded.
+	syncRetryBackoff     = 2 * time.Second
+	maxSyncRetryBackoff  = 5 * time.Minute
+	syncFailureThreshold = 3
+	// Keep this small; bursty editors will naturally coalesce within a few hundred milliseconds.
+	watchDebounceDelay = 200 * time.Millisecond
+	// maxPublishInterval is the minimum spacing between watch-triggered publishes.
+	// Zero publishes after every debounce window.
+	maxPublishInterval time.Duration
 )
 
 type sharedMountClient struct {
@@ -61,6 +78,62 @@ type sharedMountState struct {
 	currentChecksum string
 	suppressUntil   time.Time
 	mu              sync.Mutex
+	// synced is set once the mount has completed its initial sync.
+	synced atomic.Bool
+	health syncHealth
+}
+
+// syncHealth tracks consecutive poll/apply failures of a mount so they show up
+// on the readiness endpoint instead of only in logs.
+type syncHealth struct {
+	mu        sync.Mutex
+	failures  int
+	lastError string
+}
+
+func (h *syncHealth) recordFailure(err error) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures++
+	h.lastError = err.Error()
+	return h.failures
+}
+
+func (h *syncHealth) recordSuccess() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures = 0
+	h.lastError = ""
+}
+
+func (h *syncHealth) snapshot() (int, string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.failures, h.lastError
+}
+
+// syncFailureBackoff doubles the retry delay per consecutive failure, capped
+// at maxSyncRetryBackoff.
+func syncFailureBackoff(failures int) time.Duration {
+	delay := syncRetryBackoff
+	for i := 1; i < failures && delay < maxSyncRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxSyncRetryBackoff {
+		delay = maxSyncRetryBackoff
+	}
+	return delay
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
 }
 
 func main() {
@@ -73,6 +146,9 @@ func main() {
 	if err != nil {
 		logger.Fatalf("config error: %v", err)
 	}
+	if err := loadPublishTiming(); err != nil {
+		logger.Fatalf("config error: %v", err)
+	}
 	if len(mounts) == 0 {
 		logger.Print("no shared mounts configured; exiting")
 		return
@@ -103,7 +179,13 @@ func main() {
 		state = append(state, &sharedMountState{spec: mount})
 	}
 
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	if strings.EqualFold(strings.TrimSpace(*mode), "sidecar") {
+		if addr := strings.TrimSpace(os.Getenv("SPRITZ_SHARED_MOUNTS_READY_ADDR")); addr != "" {
+			go serveReadiness(ctx, logger, addr, state)
+		}
+	}
 	if err := runInit(ctx, logger, client, ownerID, state); err != nil {
 		logger.Fatalf("init failed: %v", err)
 	}
@@ -153,6 +235,27 @@ func loadConfig() ([]sharedmounts.MountSpec, string, string, string, error) {
 	return mounts, apiURL, token, ownerID, nil
 }
 
+// loadPublishTiming reads the watch debounce window and the minimum spacing
+// between watch-triggered publishes, so bursty editors do not cause
+// back-to-back full bundles.
+func loadPublishTiming() error {
+	if raw := strings.TrimSpace(os.Getenv("SPRITZ_SHARED_MOUNTS_DEBOUNCE")); raw != "" {
+		value, err := time.ParseDuration(raw)
+		if err != nil || value <= 0 {
+			return fmt.Errorf("invalid SPRITZ_SHARED_MOUNTS_DEBOUNCE: %q", raw)
+		}
+		watchDebounceDelay = value
+	}
+	if raw := strings.TrimSpace(os.Getenv("SPRITZ_SHARED_MOUNTS_MAX_PUBLISH_INTERVAL")); raw != "" {
+		value, err := time.ParseDuration(raw)
+		if err != nil || value < 0 {
+			return fmt.Errorf("invalid SPRITZ_SHARED_MOUNTS_MAX_PUBLISH_INTERVAL: %q", raw)
+		}
+		maxPublishInterval = value
+	}
+	return nil
+}
+
 func runInit(ctx context.Context, logger *log.Logger, client *sharedMountClient, ownerID string, mounts []*sharedMountState) error {
 	for _, state := range mounts {
 		if err := ensureMountPath(state.spec.MountPath); err != nil {
@@ -161,11 +264,72 @@ func runInit(ctx context.Context, logger *log.Logger, client *sharedMountClient,
 		if err := runInitMount(ctx, logger, client, ownerID, state); err != nil {
 			return err
 		}
+		state.synced.Store(true)
 	}
 	logger.Print("init complete")
 	return nil
 }
 
+// serveReadiness reports on /ready whether every mount, or the one named by
+// ?mount=, has completed its initial sync, so apps and probes can wait for
+// shared mounts instead of racing them. Later sync failures only show in the
+// body, which lists each mount's consecutive failures and last error and marks
+// it degraded after syncFailureThreshold of them; failing the probe would take
+// every pod out of its Service during an API or object store outage.
+func serveReadiness(ctx context.Context, logger *log.Logger, addr string, mounts []*sharedMountState) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ready", readinessHandler(mounts))
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Printf("readiness server error: %v", err)
+	}
+}
+
+type mountReadiness struct {
+	Synced    bool   `json:"synced"`
+	Degraded  bool   `json:"degraded"`
+	Failures  int    `json:"failures"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+func readinessHandler(mounts []*sharedMountState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSpace(r.URL.Query().Get("mount"))
+		status := map[string]mountReadiness{}
+		ready := true
+		degraded := false
+		for _, state := range mounts {
+			if name != "" && state.spec.Name != name {
+				continue
+			}
+			failures, lastError := state.health.snapshot()
+			mount := mountReadiness{
+				Synced:    state.synced.Load(),
+				Degraded:  failures >= syncFailureThreshold,
+				Failures:  failures,
+				LastError: lastError,
+			}
+			status[state.spec.Name] = mount
+			ready = ready && mount.Synced
+			degraded = degraded || mount.Degraded
+		}
+		code := http.StatusOK
+		if len(status) == 0 {
+			ready = false
+			code = http.StatusNotFound
+		} else if !ready {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(map[string]any{"ready": ready, "degraded": degraded, "mounts": status})
+	}
+}
+
 func runInitMount(ctx context.Context, logger *log.Logger, client *sharedMountClient, ownerID string, state *sharedMountState) error {
 	deadline := time.Now().Add(initRetryWindow)
 	attempt := 0
@@ -202,7 +366,7 @@ func runInitMountAttempt(ctx context.Context, client *sharedMountClient, ownerID
 	applyCtx, cancelApply := context.WithTimeout(ctx, initApplyRequestTTL)
 	defer cancelApply()
 
-	if err := applyRevision(applyCtx, client, ownerID, state.spec, manifest.Revision); err != nil {
+	if err := applyRevision(applyCtx, client, ownerID, state.spec, manifest.Revision, manifest.Checksum, ""); err != nil {
 		return err
 	}
 	state.currentRevision = manifest.Revision
@@ -223,7 +387,7 @@ func isRetryableInitError(err error) bool {
 	if err == nil {
 		return false
 	}
-	if errors.Is(err, context.DeadlineExceeded) {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, errChecksumMismatch) {
 		return true
 	}
 	var netErr net.Error
@@ -252,17 +416,45 @@ func isRetryableInitError(err error) bool {
 }
 
 func runSidecar(ctx context.Context, logger *log.Logger, client *sharedMountClient, ownerID string, mounts []*sharedMountState) {
+	var publishers sync.WaitGroup
+	var grouped []*sharedMountState
 	for _, state := range mounts {
 		state := state
 		if state.spec.SyncMode == sharedmounts.SyncPoll {
-			go pollLoop(ctx, logger, client, ownerID, state)
+			if atomicGroupEnabled() {
+				grouped = append(grouped, state)
+			} else {
+				go pollLoop(ctx, logger, client, ownerID, state)
+			}
 		}
 		if state.spec.Mode == sharedmounts.ModeSnapshot {
-			go publishLoop(ctx, logger, client, ownerID, state)
+			publishers.Add(1)
+			go func() {
+				defer publishers.Done()
+				publishLoop(ctx, logger, client, ownerID, state)
+			}()
 		}
 	}
 
-	select {}
+	if len(grouped) > 0 {
+		go atomicPollLoop(ctx, logger, client, ownerID, grouped)
+	}
+
+	<-ctx.Done()
+	publishers.Wait()
+	flushMounts(logger, client, ownerID, mounts)
+}
+
+// flushMounts publishes local changes one last time when the sidecar is asked
+// to stop, so edits made just before the pod shuts down are not lost.
+func flushMounts(logger *log.Logger, client *sharedMountClient, ownerID string, mounts []*sharedMountState) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownFlushTimeout)
+	defer cancel()
+	for _, state := range mounts {
+		if state.spec.Mode == sharedmounts.ModeSnapshot {
+			publishMount(ctx, logger, client, ownerID, state, "shutdown")
+		}
+	}
 }
 
 func pollLoop(ctx context.Context, logger *log.Logger, client *sharedMountClient, ownerID string, state *sharedMountState) {
@@ -284,19 +476,20 @@ func pollLoop(ctx context.Context, logger *log.Logger, client *sharedMountClient
 
 		manifest, found, err := client.latestWait(ctx, ownerID, state.spec.Name, current, interval)
 		if err != nil {
-			logger.Printf("poll error for %s: %v", state.spec.Name, err)
-			time.Sleep(2 * time.Second)
-			continue
-		}
-		if !found {
+			failures := state.health.recordFailure(err)
+			logger.Printf("poll error for %s failures=%d: %v", state.spec.Name, failures, err)
+			if !sleepContext(ctx, syncFailureBackoff(failures)) {
+				return
+			}
 			continue
 		}
-		if manifest.Revision == current {
+		if !found || manifest.Revision == current {
+			state.health.recordSuccess()
 			continue
 		}
 		state.mu.Lock()
 		applyStartedAt := time.Now()
-		err = applyRevision(ctx, client, ownerID, state.spec, manifest.Revision)
+		err = applyRevision(ctx, client, ownerID, state.spec, manifest.Revision, manifest.Checksum, state.currentChecksum)
 		applyDuration := time.Since(applyStartedAt)
 		if err == nil {
 			state.currentRevision = manifest.Revision
@@ -305,112 +498,306 @@ func pollLoop(ctx context.Context, logger *log.Logger, client *sharedMountClient
 		}
 		state.mu.Unlock()
 		if err != nil {
-			logger.Printf("apply error for %s after %s: %v", state.spec.Name, applyDuration, err)
+			failures := state.health.recordFailure(err)
+			logger.Printf("apply error for %s after %s failures=%d: %v", state.spec.Name, applyDuration, failures, err)
+			if !sleepContext(ctx, syncFailureBackoff(failures)) {
+				return
+			}
 			continue
 		}
+		state.health.recordSuccess()
 		logger.Printf("applied %s revision=%s in %s", state.spec.Name, manifest.Revision, applyDuration)
 	}
 }
 
-func publishLoop(ctx context.Context, logger *log.Logger, client *sharedMountClient, ownerID string, state *sharedMountState) {
-	interval := state.spec.PublishSeconds
-	if interval <= 0 {
-		interval = defaultPublishSeconds
-	}
-	ticker := time.NewTicker(time.Duration(interval) * time.Second)
-	defer ticker.Stop()
+// atomicGroupEnabled makes the sidecar apply its poll mounts as one group, for
+// workspaces whose mounts must not be seen at mismatched revisions.
+func atomicGroupEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("SPRITZ_SHARED_MOUNTS_ATOMIC_GROUP")), "true")
+}
 
+// atomicPollLoop keeps poll mounts consistent with each other. A new revision
+// on any mount re-reads latest for every mount, stages each changed revision
+// into its .incoming- dir, and swaps them in only once all of them staged
+// cleanly. A failed stage discards the whole group and retries with backoff,
+// so the mounts either all move to their new revisions or none do.
+func atomicPollLoop(ctx context.Context, logger *log.Logger, client *sharedMountClient, ownerID string, mounts []*sharedMountState) {
 	trigger := make(chan struct{}, 1)
-	go watchMount(ctx, logger, state.spec.MountPath, trigger)
+	for _, state := range mounts {
+		go watchLatest(ctx, logger, client, ownerID, state, trigger)
+	}
 
+	failures := 0
 	for {
-		reason := "interval"
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			reason = "interval"
 		case <-trigger:
-			reason = "fs"
 		}
-
-		state.mu.Lock()
-		if time.Now().Before(state.suppressUntil) {
-			state.mu.Unlock()
+		applyStartedAt := time.Now()
+		applied, err := applyGroup(ctx, client, ownerID, mounts)
+		if err != nil {
+			failures++
+			logger.Printf("atomic apply error failures=%d: %v", failures, err)
+			if !sleepContext(ctx, syncFailureBackoff(failures)) {
+				return
+			}
+			select {
+			case trigger <- struct{}{}:
+			default:
+			}
 			continue
 		}
-		bundleStartedAt := time.Now()
-		checksum, bundle, err := bundleMountRoot(state.spec.MountPath)
-		state.mu.Unlock()
+		failures = 0
+		if len(applied) > 0 {
+			logger.Printf("applied %s atomically in %s", strings.Join(applied, ", "), time.Since(applyStartedAt))
+		}
+	}
+}
+
+// watchLatest long-polls latest.json for one mount of an atomic group and
+// signals trigger whenever it sees a new revision. It only tracks what it has
+// seen; atomicPollLoop decides what gets applied.
+func watchLatest(ctx context.Context, logger *log.Logger, client *sharedMountClient, ownerID string, state *sharedMountState, trigger chan<- struct{}) {
+	interval := state.spec.PollSeconds
+	if interval <= 0 {
+		interval = defaultPollSeconds
+	}
+	state.mu.Lock()
+	seen := state.currentRevision
+	state.mu.Unlock()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		manifest, found, err := client.latestWait(ctx, ownerID, state.spec.Name, seen, interval)
 		if err != nil {
-			logger.Printf("bundle error for %s: %v", state.spec.Name, err)
+			failures := state.health.recordFailure(err)
+			logger.Printf("poll error for %s failures=%d: %v", state.spec.Name, failures, err)
+			if !sleepContext(ctx, syncFailureBackoff(failures)) {
+				return
+			}
 			continue
 		}
-		bundleDuration := time.Since(bundleStartedAt)
-		bundleSize := int64(0)
-		if info, statErr := os.Stat(bundle); statErr == nil {
-			bundleSize = info.Size()
+		if !found || manifest.Revision == seen {
+			state.mu.Lock()
+			pending := seen != state.currentRevision
+			state.mu.Unlock()
+			// A revision waiting on the group keeps its apply failures visible.
+			if !pending {
+				state.health.recordSuccess()
+			}
+			continue
+		}
+		seen = manifest.Revision
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+type stagedRevision struct {
+	state    *sharedMountState
+	manifest sharedmounts.LatestManifest
+	// incoming is empty when the mount already holds the revision's content.
+	incoming string
+}
+
+// applyGroup stages every mount whose latest revision changed and installs
+// them together. It returns the names of the mounts it moved. Installing is a
+// rename per mount, so a failure there can leave earlier mounts updated; the
+// retry then stages the rest until the group converges.
+func applyGroup(ctx context.Context, client *sharedMountClient, ownerID string, mounts []*sharedMountState) ([]string, error) {
+	var staged []stagedRevision
+	discard := func() {
+		for _, item := range staged {
+			if item.incoming != "" {
+				_ = os.RemoveAll(item.incoming)
+			}
+		}
+	}
+	for _, state := range mounts {
+		manifest, found, err := client.latest(ctx, ownerID, state.spec.Name)
+		if err != nil {
+			state.health.recordFailure(err)
+			discard()
+			return nil, fmt.Errorf("%s: %w", state.spec.Name, err)
 		}
-		checksumValue := "sha256:" + checksum
 		state.mu.Lock()
-		currentChecksum := state.currentChecksum
-		expectedRevision := state.currentRevision
+		current, haveChecksum := state.currentRevision, state.currentChecksum
 		state.mu.Unlock()
-		if checksumValue == currentChecksum {
-			_ = os.Remove(bundle)
+		if !found || manifest.Revision == current {
 			continue
 		}
-		revision := time.Now().UTC().Format("2006-01-02T15-04-05Z")
-		uploadStartedAt := time.Now()
-		if err := client.uploadRevision(ctx, ownerID, state.spec.Name, revision, bundle); err != nil {
-			_ = os.Remove(bundle)
-			logger.Printf("upload error for %s: %v", state.spec.Name, err)
-			continue
+		incoming, err := stageRevision(ctx, client, ownerID, state.spec, manifest.Revision, manifest.Checksum, haveChecksum)
+		if err != nil {
+			state.health.recordFailure(err)
+			discard()
+			return nil, fmt.Errorf("%s: %w", state.spec.Name, err)
 		}
-		uploadDuration := time.Since(uploadStartedAt)
-		manifest := sharedmounts.LatestManifest{
-			Revision:  revision,
-			Checksum:  checksumValue,
-			UpdatedAt: time.Now().UTC().Format(time.RFC3339),
-		}
-		latestStartedAt := time.Now()
-		if err := client.updateLatest(ctx, ownerID, state.spec.Name, manifest, expectedRevision); err != nil {
-			if errors.Is(err, errConflict) {
-				latest, found, latestErr := client.latest(ctx, ownerID, state.spec.Name)
-				if latestErr == nil && found {
-					state.mu.Lock()
-					state.currentRevision = latest.Revision
-					state.currentChecksum = latest.Checksum
-					state.mu.Unlock()
+		staged = append(staged, stagedRevision{state: state, manifest: manifest, incoming: incoming})
+	}
+
+	// Hold every staged mount so publishes see the group before or after the swap.
+	for _, item := range staged {
+		item.state.mu.Lock()
+	}
+	defer func() {
+		for _, item := range staged {
+			item.state.mu.Unlock()
+		}
+	}()
+	applied := make([]string, 0, len(staged))
+	for i, item := range staged {
+		if item.incoming != "" {
+			if err := installRevision(item.state.spec, item.incoming); err != nil {
+				item.state.health.recordFailure(err)
+				for _, rest := range staged[i+1:] {
+					if rest.incoming != "" {
+						_ = os.RemoveAll(rest.incoming)
+					}
+				}
+				return applied, fmt.Errorf("%s: %w", item.state.spec.Name, err)
+			}
+		}
+		item.state.currentRevision = item.manifest.Revision
+		item.state.currentChecksum = item.manifest.Checksum
+		item.state.suppressUntil = time.Now().Add(publishSuppressAfterApply)
+		item.state.health.recordSuccess()
+		applied = append(applied, item.state.spec.Name)
+	}
+	return applied, nil
+}
+
+func publishLoop(ctx context.Context, logger *log.Logger, client *sharedMountClient, ownerID string, state *sharedMountState) {
+	interval := state.spec.PublishSeconds
+	if interval <= 0 {
+		interval = defaultPublishSeconds
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	trigger := make(chan struct{}, 1)
+	go watchMount(ctx, logger, state.spec.MountPath, state.spec.Excludes, trigger)
+
+	var lastPublish time.Time
+	var deferred *time.Timer
+	var deferredCh <-chan time.Time
+	for {
+		reason := "interval"
+		select {
+		case <-ctx.Done():
+			if deferred != nil {
+				deferred.Stop()
+			}
+			return
+		case <-ticker.C:
+			reason = "interval"
+		case <-trigger:
+			reason = "fs"
+			if wait := publishDelay(lastPublish, time.Now(), maxPublishInterval); wait > 0 {
+				// Hold the change until the spacing window ends instead of dropping it.
+				if deferred == nil {
+					deferred = time.NewTimer(wait)
+					deferredCh = deferred.C
 				}
-				_ = os.Remove(bundle)
 				continue
 			}
+		case <-deferredCh:
+			reason = "fs"
+		}
+		if deferred != nil {
+			deferred.Stop()
+			deferred = nil
+			deferredCh = nil
+		}
+		lastPublish = time.Now()
+
+		publishMount(ctx, logger, client, ownerID, state, reason)
+	}
+}
+
+// publishMount bundles a mount and publishes it as a new revision when its
+// checksum differs from the current one.
+func publishMount(ctx context.Context, logger *log.Logger, client *sharedMountClient, ownerID string, state *sharedMountState, reason string) {
+	state.mu.Lock()
+	if time.Now().Before(state.suppressUntil) {
+		state.mu.Unlock()
+		return
+	}
+	bundleStartedAt := time.Now()
+	checksum, bundle, err := bundleMountRoot(state.spec.MountPath, state.spec.Excludes)
+	state.mu.Unlock()
+	if err != nil {
+		logger.Printf("bundle error for %s: %v", state.spec.Name, err)
+		return
+	}
+	bundleDuration := time.Since(bundleStartedAt)
+	bundleSize := int64(0)
+	if info, statErr := os.Stat(bundle); statErr == nil {
+		bundleSize = info.Size()
+	}
+	checksumValue := "sha256:" + checksum
+	state.mu.Lock()
+	currentChecksum := state.currentChecksum
+	expectedRevision := state.currentRevision
+	state.mu.Unlock()
+	if checksumValue == currentChecksum {
+		_ = os.Remove(bundle)
+		return
+	}
+	revision := time.Now().UTC().Format("2006-01-02T15-04-05Z")
+	uploadStartedAt := time.Now()
+	if err := client.uploadRevision(ctx, ownerID, state.spec.Name, revision, bundle); err != nil {
+		_ = os.Remove(bundle)
+		logger.Printf("upload error for %s: %v", state.spec.Name, err)
+		return
+	}
+	uploadDuration := time.Since(uploadStartedAt)
+	manifest := sharedmounts.LatestManifest{
+		Revision:  revision,
+		Checksum:  checksumValue,
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	latestStartedAt := time.Now()
+	if err := client.updateLatest(ctx, ownerID, state.spec.Name, manifest, expectedRevision); err != nil {
+		if errors.Is(err, errConflict) {
+			latest, found, latestErr := client.latest(ctx, ownerID, state.spec.Name)
+			if latestErr == nil && found {
+				state.mu.Lock()
+				state.currentRevision = latest.Revision
+				state.currentChecksum = latest.Checksum
+				state.mu.Unlock()
+			}
 			_ = os.Remove(bundle)
-			logger.Printf("latest update error for %s: %v", state.spec.Name, err)
-			continue
+			return
 		}
-		latestDuration := time.Since(latestStartedAt)
 		_ = os.Remove(bundle)
-		state.mu.Lock()
-		state.currentRevision = manifest.Revision
-		state.currentChecksum = manifest.Checksum
-		state.mu.Unlock()
-
-		logger.Printf(
-			"published %s revision=%s reason=%s bundle=%s upload=%s latest=%s bytes=%d",
-			state.spec.Name,
-			revision,
-			reason,
-			bundleDuration,
-			uploadDuration,
-			latestDuration,
-			bundleSize,
-		)
+		logger.Printf("latest update error for %s: %v", state.spec.Name, err)
+		return
 	}
+	latestDuration := time.Since(latestStartedAt)
+	_ = os.Remove(bundle)
+	state.mu.Lock()
+	state.currentRevision = manifest.Revision
+	state.currentChecksum = manifest.Checksum
+	state.mu.Unlock()
+
+	logger.Printf(
+		"published %s revision=%s reason=%s bundle=%s upload=%s latest=%s bytes=%d",
+		state.spec.Name,
+		revision,
+		reason,
+		bundleDuration,
+		uploadDuration,
+		latestDuration,
+		bundleSize,
+	)
 }
 
-func watchMount(ctx context.Context, logger *log.Logger, mountPath string, trigger chan<- struct{}) {
+func watchMount(ctx context.Context, logger *log.Logger, mountPath string, excludes []string, trigger chan<- struct{}) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		logger.Printf("watch error for %s: %v", mountPath, err)
@@ -418,13 +805,12 @@ func watchMount(ctx context.Context, logger *log.Logger, mountPath string, trigg
 	}
 	defer watcher.Close()
 
-	if err := addWatchRecursive(watcher, mountPath, mountPath); err != nil {
+	if err := addWatchRecursive(watcher, mountPath, mountPath, excludes); err != nil {
 		logger.Printf("watch error for %s: %v", mountPath, err)
 		return
 	}
 
-	// Keep this small; bursty editors will naturally coalesce within a few hundred milliseconds.
-	debounceDelay := 200 * time.Millisecond
+	debounceDelay := watchDebounceDelay
 	var debounceTimer *time.Timer
 	var debounceCh <-chan time.Time
 
@@ -461,14 +847,14 @@ func watchMount(ctx context.Context, logger *log.Logger, mountPath string, trigg
 			if !shouldTriggerPublish(ev.Op) {
 				continue
 			}
-			if shouldIgnoreWatchEvent(mountPath, ev.Name) {
+			if shouldIgnoreWatchEvent(mountPath, ev.Name, excludes) {
 				continue
 			}
 			// Ensure new directories are watched.
 			if ev.Op&fsnotify.Create != 0 {
 				info, statErr := os.Stat(ev.Name)
 				if statErr == nil && info.IsDir() {
-					_ = addWatchRecursive(watcher, mountPath, ev.Name)
+					_ = addWatchRecursive(watcher, mountPath, ev.Name, excludes)
 				}
 			}
 			resetDebounce()
@@ -483,13 +869,44 @@ func watchMount(ctx context.Context, logger *log.Logger, mountPath string, trigg
 	}
 }
 
-func shouldIgnoreWatchEvent(mountRoot, path string) bool {
+// publishDelay returns how long a watch-triggered publish must wait so that
+// publishes are at least interval apart.
+func publishDelay(last, now time.Time, interval time.Duration) time.Duration {
+	if interval <= 0 || last.IsZero() {
+		return 0
+	}
+	if elapsed := now.Sub(last); elapsed < interval {
+		return interval - elapsed
+	}
+	return 0
+}
+
+func shouldIgnoreWatchEvent(mountRoot, path string, excludes []string) bool {
 	rel, err := filepath.Rel(mountRoot, path)
 	if err != nil || rel == "." || rel == "" {
 		return false
 	}
-	firstComponent := strings.Split(rel, string(os.PathSeparator))[0]
-	return strings.HasPrefix(firstComponent, ".incoming-") || strings.HasPrefix(firstComponent, ".trash-") || firstComponent == "current" || firstComponent == "live"
+	return isIgnoredEntry(strings.Split(rel, string(os.PathSeparator))[0], excludes)
+}
+
+// isIgnoredEntry reports whether a top-level entry of a mount root is left out of
+// bundles and publishes. The watcher, the tar writer (and so the checksum), and
+// revision apply all use it so they agree on what belongs to the mount.
+func isIgnoredEntry(name string, excludes []string) bool {
+	if isControlEntry(name) {
+		return true
+	}
+	for _, exclude := range excludes {
+		if name == exclude {
+			return true
+		}
+	}
+	return false
+}
+
+// isControlEntry matches the syncer's own working entries and the legacy layout.
+func isControlEntry(name string) bool {
+	return strings.HasPrefix(name, ".incoming-") || strings.HasPrefix(name, ".trash-") || name == "current" || name == "live"
 }
 
 func shouldTriggerPublish(op fsnotify.Op) bool {
@@ -498,10 +915,10 @@ func shouldTriggerPublish(op fsnotify.Op) bool {
 	return op&mask != 0
 }
 
-func addWatchRecursive(watcher *fsnotify.Watcher, mountRoot, walkRoot string) error {
+func addWatchRecursive(watcher *fsnotify.Watcher, mountRoot, walkRoot string, excludes []string) error {
 	mountRootClean := filepath.Clean(mountRoot)
 	walkRootClean := filepath.Clean(walkRoot)
-	if shouldIgnoreWatchEvent(mountRootClean, walkRootClean) {
+	if shouldIgnoreWatchEvent(mountRootClean, walkRootClean, excludes) {
 		return nil
 	}
 	return filepath.WalkDir(walkRootClean, func(path string, entry os.DirEntry, err error) error {
@@ -511,7 +928,7 @@ func addWatchRecursive(watcher *fsnotify.Watcher, mountRoot, walkRoot string) er
 		if !entry.IsDir() {
 			return nil
 		}
-		if shouldIgnoreWatchEvent(mountRootClean, path) {
+		if shouldIgnoreWatchEvent(mountRootClean, path, excludes) {
 			return filepath.SkipDir
 		}
 		if addErr := watcher.Add(path); addErr != nil {
@@ -534,9 +951,31 @@ func ensureMountPath(mountPath string) error {
 	if _, err := migrateLegacyLayout(mountPath); err != nil {
 		return err
 	}
+	if err := ensureLiveShim(mountPath); err != nil {
+		return err
+	}
 	return enforceGroupWritableTree(mountPath)
 }
 
+// ensureLiveShim keeps mountPath/live working for images built against the
+// legacy layout by pointing it at the mount root. Like the legacy entries it is
+// a control entry: never bundled, watched, or replaced by an apply.
+func ensureLiveShim(mountPath string) error {
+	livePath := filepath.Join(mountPath, "live")
+	if _, err := os.Lstat(livePath); err == nil || !os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.Symlink(".", livePath); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
+
+func isLiveShim(path string) bool {
+	target, err := os.Readlink(path)
+	return err == nil && target == "."
+}
+
 func migrateLegacyLayout(mountPath string) (bool, error) {
 	currentPath := filepath.Join(mountPath, "current")
 	currentInfo, err := os.Stat(currentPath)
@@ -589,43 +1028,74 @@ func migrateLegacyLayout(mountPath string) (bool, error) {
 	return true, nil
 }
 
-func applyRevision(ctx context.Context, client *sharedMountClient, ownerID string, spec sharedmounts.MountSpec, revision string) error {
-	if err := ensureMountPath(spec.MountPath); err != nil {
+// applyRevision downloads and installs a revision. When haveChecksum is set it is
+// sent as If-None-Match, and a 304 means the mount already holds that content.
+// When wantChecksum is set, the extracted bundle must hash to it before it
+// replaces the mount; otherwise the staging dir is discarded and the mount is
+// left untouched, so a truncated or corrupt download is retried instead of
+// applied.
+func applyRevision(ctx context.Context, client *sharedMountClient, ownerID string, spec sharedmounts.MountSpec, revision, wantChecksum, haveChecksum string) error {
+	incoming, err := stageRevision(ctx, client, ownerID, spec, revision, wantChecksum, haveChecksum)
+	if err != nil || incoming == "" {
 		return err
 	}
+	return installRevision(spec, incoming)
+}
+
+// stageRevision downloads, extracts, and verifies a revision into the mount's
+// .incoming- dir and returns its path, or "" when the mount already holds the
+// revision's content.
+func stageRevision(ctx context.Context, client *sharedMountClient, ownerID string, spec sharedmounts.MountSpec, revision, wantChecksum, haveChecksum string) (string, error) {
+	if err := ensureMountPath(spec.MountPath); err != nil {
+		return "", err
+	}
 	tempFile, err := os.CreateTemp("", "spritz-shared-*.tar.gz")
 	if err != nil {
-		return err
+		return "", err
 	}
 	tempPath := tempFile.Name()
 	defer func() {
 		_ = tempFile.Close()
 		_ = os.Remove(tempPath)
 	}()
-	if err := client.downloadRevision(ctx, ownerID, spec.Name, revision, tempFile); err != nil {
-		return err
+	if err := client.downloadRevision(ctx, ownerID, spec.Name, revision, haveChecksum, tempFile); err != nil {
+		if errors.Is(err, errNotModified) {
+			return "", nil
+		}
+		return "", err
 	}
 	if err := tempFile.Close(); err != nil {
-		return err
+		return "", err
 	}
 	incoming := filepath.Join(spec.MountPath, ".incoming-"+revision)
 	_ = os.RemoveAll(incoming)
 	if err := os.MkdirAll(incoming, sharedDirPerm); err != nil {
-		return err
+		return "", err
 	}
-	if err := extractTarGz(tempPath, incoming); err != nil {
-		return err
+	checksum, err := extractTarGz(tempPath, incoming)
+	if err != nil {
+		_ = os.RemoveAll(incoming)
+		return "", err
+	}
+	if wantChecksum != "" && checksum != wantChecksum {
+		_ = os.RemoveAll(incoming)
+		return "", fmt.Errorf("%w for %s revision %s: manifest %s, bundle %s", errChecksumMismatch, spec.Name, revision, wantChecksum, checksum)
 	}
 	if err := enforceGroupWritableTree(incoming); err != nil {
-		return err
+		_ = os.RemoveAll(incoming)
+		return "", err
 	}
-	if err := replaceMountContents(spec.MountPath, incoming); err != nil {
+	return incoming, nil
+}
+
+func installRevision(spec sharedmounts.MountSpec, incoming string) error {
+	if err := replaceMountContents(spec.MountPath, incoming, spec.Excludes); err != nil {
 		return err
 	}
 	return enforceGroupWritableTree(spec.MountPath)
 }
 
-func replaceMountContents(mountPath, incoming string) error {
+func replaceMountContents(mountPath, incoming string, excludes []string) error {
 	incomingBase := filepath.Base(incoming)
 	cleanupPaths := []string{}
 	entries, err := os.ReadDir(mountPath)
@@ -645,11 +1115,18 @@ func replaceMountContents(mountPath, incoming string) error {
 			cleanupPaths = append(cleanupPaths, filepath.Join(mountPath, name))
 			continue
 		}
+		if name == "live" && isLiveShim(filepath.Join(mountPath, name)) {
+			continue
+		}
 		// Legacy layout control entries. If they still exist, they are not data.
 		if name == "current" || name == "live" {
 			_ = os.RemoveAll(filepath.Join(mountPath, name))
 			continue
 		}
+		// Excluded entries are local-only and never part of a revision.
+		if isIgnoredEntry(name, excludes) {
+			continue
+		}
 		targetPath := filepath.Join(mountPath, name)
 		if err := os.RemoveAll(targetPath); err != nil {
 			if os.IsPermission(err) {
@@ -668,7 +1145,7 @@ func replaceMountContents(mountPath, incoming string) error {
 		return err
 	}
 	for _, entry := range incomingEntries {
-		if strings.HasPrefix(entry.Name(), ".trash-") {
+		if strings.HasPrefix(entry.Name(), ".trash-") || isIgnoredEntry(entry.Name(), excludes) {
 			continue
 		}
 		src := filepath.Join(incoming, entry.Name())
@@ -683,7 +1160,7 @@ func replaceMountContents(mountPath, incoming string) error {
 	return os.RemoveAll(incoming)
 }
 
-func bundleMountRoot(mountPath string) (string, string, error) {
+func bundleMountRoot(mountPath string, excludes []string) (string, string, error) {
 	stat, err := os.Stat(mountPath)
 	if err != nil {
 		return "", "", err
@@ -709,7 +1186,7 @@ func bundleMountRoot(mountPath string) (string, string, error) {
 		return "", "", err
 	}
 	tarWriter := tar.NewWriter(io.MultiWriter(gzipWriter, hasher))
-	if err := writeTarContents(tarWriter, mountPath); err != nil {
+	if err := writeTarContents(tarWriter, mountPath, excludes); err != nil {
 		_ = tarWriter.Close()
 		_ = gzipWriter.Close()
 		_ = file.Close()
@@ -732,7 +1209,7 @@ func bundleMountRoot(mountPath string) (string, string, error) {
 	return checksum, file.Name(), nil
 }
 
-func writeTarContents(tw *tar.Writer, root string) error {
+func writeTarContents(tw *tar.Writer, root string, excludes []string) error {
 	return filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -744,8 +1221,7 @@ func writeTarContents(tw *tar.Writer, root string) error {
 		if err != nil {
 			return err
 		}
-		firstComponent := strings.Split(rel, string(os.PathSeparator))[0]
-		if strings.HasPrefix(firstComponent, ".incoming-") || strings.HasPrefix(firstComponent, ".trash-") || firstComponent == "current" || firstComponent == "live" {
+		if isIgnoredEntry(strings.Split(rel, string(os.PathSeparator))[0], excludes) {
 			if entry.IsDir() {
 				return filepath.SkipDir
 			}
@@ -820,20 +1296,24 @@ func normalizeTarHeader(header *tar.Header, info os.FileInfo) {
 	header.Mode = int64(perm)
 }
 
-func extractTarGz(archivePath, dest string) error {
+// extractTarGz extracts archivePath into dest and returns the checksum of the
+// tar stream, in the same form bundleMountRoot publishes.
+func extractTarGz(archivePath, dest string) (string, error) {
 	cleanDest := filepath.Clean(dest)
 	file, err := os.Open(archivePath)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer file.Close()
 	gzr, err := gzip.NewReader(file)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer gzr.Close()
 
-	tr := tar.NewReader(gzr)
+	hasher := sha256.New()
+	stream := io.TeeReader(gzr, hasher)
+	tr := tar.NewReader(stream)
 	type dirTime struct {
 		path string
 		time time.Time
@@ -845,53 +1325,53 @@ func extractTarGz(archivePath, dest string) error {
 			break
 		}
 		if err != nil {
-			return err
+			return "", err
 		}
 		if header == nil {
 			continue
 		}
 		target := filepath.Join(cleanDest, filepath.Clean(header.Name))
 		if target != cleanDest && !strings.HasPrefix(target, cleanDest+string(os.PathSeparator)) {
-			return fmt.Errorf("invalid archive path: %s", header.Name)
+			return "", fmt.Errorf("invalid archive path: %s", header.Name)
 		}
 		switch header.Typeflag {
 		case tar.TypeDir:
 			if err := os.MkdirAll(target, sharedDirPerm); err != nil {
-				return err
+				return "", err
 			}
 			dirTimes = append(dirTimes, dirTime{path: target, time: header.ModTime})
 		case tar.TypeReg:
 			if err := os.MkdirAll(filepath.Dir(target), sharedDirPerm); err != nil {
-				return err
+				return "", err
 			}
 			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
 			if err != nil {
-				return err
+				return "", err
 			}
 			if _, err := io.Copy(out, tr); err != nil {
 				_ = out.Close()
-				return err
+				return "", err
 			}
 			_ = out.Close()
 			if err := os.Chmod(target, os.FileMode(header.Mode)|sharedFilePermMask); err != nil {
-				return err
+				return "", err
 			}
 			_ = os.Chtimes(target, header.ModTime, header.ModTime)
 		case tar.TypeSymlink:
 			if err := os.MkdirAll(filepath.Dir(target), sharedDirPerm); err != nil {
-				return err
+				return "", err
 			}
 			link := header.Linkname
 			if filepath.IsAbs(link) {
-				return fmt.Errorf("absolute symlink in archive: %s", header.Name)
+				return "", fmt.Errorf("absolute symlink in archive: %s", header.Name)
 			}
 			cleanLink := filepath.Clean(link)
 			if cleanLink == ".." || strings.HasPrefix(cleanLink, ".."+string(os.PathSeparator)) {
-				return fmt.Errorf("symlink escapes mount: %s", header.Name)
+				return "", fmt.Errorf("symlink escapes mount: %s", header.Name)
 			}
 			_ = os.RemoveAll(target)
 			if err := os.Symlink(link, target); err != nil {
-				return err
+				return "", err
 			}
 		default:
 			continue
@@ -907,7 +1387,11 @@ func extractTarGz(archivePath, dest string) error {
 	for _, dt := range dirTimes {
 		_ = os.Chtimes(dt.path, dt.time, dt.time)
 	}
-	return nil
+	// The tar reader can stop before the end-of-archive padding; hash it too.
+	if _, err := io.Copy(io.Discard, stream); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
 func enforceGroupWritableTree(root string) error {
@@ -959,6 +1443,10 @@ func enforceGroupWritableTree(root string) error {
 
 var errConflict = errors.New("conflict")
 
+var errNotModified = errors.New("not modified")
+
+var errChecksumMismatch = errors.New("checksum mismatch")
+
 func (c *sharedMountClient) latest(ctx context.Context, ownerID, mount string) (sharedmounts.LatestManifest, bool, error) {
 	endpoint := c.endpoint(ownerID, mount, "latest")
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
@@ -1074,18 +1562,24 @@ func parseLatestManifest(body []byte) (sharedmounts.LatestManifest, error) {
 	return manifest, nil
 }
 
-func (c *sharedMountClient) downloadRevision(ctx context.Context, ownerID, mount, revision string, dest io.Writer) error {
+func (c *sharedMountClient) downloadRevision(ctx context.Context, ownerID, mount, revision, ifNoneMatch string, dest io.Writer) error {
 	endpoint := c.endpoint(ownerID, mount, "revisions", revision)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return err
 	}
 	c.applyAuth(req)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", strconv.Quote(ifNoneMatch))
+	}
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return errNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return &remoteHTTPError{
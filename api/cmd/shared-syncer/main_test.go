@@ -6,6 +6,7 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -63,7 +65,7 @@ func TestWriteTarContentsRejectsEscapingSymlink(t *testing.T) {
 	}
 	var buf bytes.Buffer
 	tw := tar.NewWriter(&buf)
-	err := writeTarContents(tw, root)
+	err := writeTarContents(tw, root, nil)
 	_ = tw.Close()
 	if err == nil {
 		t.Fatal("expected error for escaping symlink")
@@ -84,7 +86,7 @@ func TestWriteTarContentsSkipsTrashPaths(t *testing.T) {
 
 	var buf bytes.Buffer
 	tw := tar.NewWriter(&buf)
-	if err := writeTarContents(tw, root); err != nil {
+	if err := writeTarContents(tw, root, nil); err != nil {
 		t.Fatalf("writeTarContents failed: %v", err)
 	}
 	if err := tw.Close(); err != nil {
@@ -117,6 +119,73 @@ func TestWriteTarContentsSkipsTrashPaths(t *testing.T) {
 	}
 }
 
+func TestMountExcludesSkipBundleChecksumAndWatch(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "keep.txt"), []byte("keep"), 0o644); err != nil {
+		t.Fatalf("write keep file: %v", err)
+	}
+	checksumBefore, bundleBefore, err := bundleMountRoot(root, []string{".git"})
+	if err != nil {
+		t.Fatalf("bundleMountRoot failed: %v", err)
+	}
+	_ = os.Remove(bundleBefore)
+
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir .git: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref: refs/heads/main"), 0o644); err != nil {
+		t.Fatalf("write .git/HEAD: %v", err)
+	}
+	checksumAfter, bundleAfter, err := bundleMountRoot(root, []string{".git"})
+	if err != nil {
+		t.Fatalf("bundleMountRoot failed: %v", err)
+	}
+	_ = os.Remove(bundleAfter)
+	if checksumBefore != checksumAfter {
+		t.Fatal("expected excluded entries not to change the bundle checksum")
+	}
+
+	if !shouldIgnoreWatchEvent(root, filepath.Join(root, ".git", "HEAD"), []string{".git"}) {
+		t.Fatal("expected watch events under an excluded entry to be ignored")
+	}
+	if shouldIgnoreWatchEvent(root, filepath.Join(root, "keep.txt"), []string{".git"}) {
+		t.Fatal("expected watch events outside excludes to trigger publishes")
+	}
+}
+
+func TestReplaceMountContentsKeepsExcludedEntries(t *testing.T) {
+	mountPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(mountPath, ".cache"), 0o755); err != nil {
+		t.Fatalf("mkdir .cache: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mountPath, ".cache", "local.bin"), []byte("local"), 0o644); err != nil {
+		t.Fatalf("write cache file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mountPath, "old.txt"), []byte("old"), 0o644); err != nil {
+		t.Fatalf("write old file: %v", err)
+	}
+	incoming := filepath.Join(mountPath, ".incoming-test")
+	if err := os.MkdirAll(incoming, 0o755); err != nil {
+		t.Fatalf("mkdir incoming: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(incoming, "new.txt"), []byte("new"), 0o644); err != nil {
+		t.Fatalf("write incoming file: %v", err)
+	}
+
+	if err := replaceMountContents(mountPath, incoming, []string{".cache"}); err != nil {
+		t.Fatalf("replaceMountContents failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mountPath, ".cache", "local.bin")); err != nil {
+		t.Fatalf("expected excluded entry to survive apply: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mountPath, "old.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected non-excluded entry to be replaced, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mountPath, "new.txt")); err != nil {
+		t.Fatalf("expected incoming entry to be placed: %v", err)
+	}
+}
+
 func TestParseLatestManifestWrapped(t *testing.T) {
 	body := []byte(`{"status":"success","data":{"revision":"r1","checksum":"sha256:abc","updated_at":"2026-02-11T00:00:00Z"}}`)
 	manifest, err := parseLatestManifest(body)
@@ -291,6 +360,30 @@ func TestMigrateLegacyLayoutFlattensCurrent(t *testing.T) {
 	}
 }
 
+func TestEnsureMountPathKeepsLiveShim(t *testing.T) {
+	mountPath := t.TempDir()
+	if err := ensureMountPath(mountPath); err != nil {
+		t.Fatalf("ensureMountPath failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mountPath, "live", "a.txt"), []byte("hi"), 0o644); err != nil {
+		t.Fatalf("write through live shim failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mountPath, "a.txt")); err != nil {
+		t.Fatalf("expected live shim to resolve to the mount root: %v", err)
+	}
+
+	incoming := filepath.Join(mountPath, ".incoming-test")
+	if err := os.MkdirAll(incoming, 0o755); err != nil {
+		t.Fatalf("mkdir incoming failed: %v", err)
+	}
+	if err := replaceMountContents(mountPath, incoming, nil); err != nil {
+		t.Fatalf("replaceMountContents failed: %v", err)
+	}
+	if !isLiveShim(filepath.Join(mountPath, "live")) {
+		t.Fatal("expected apply to keep the live shim")
+	}
+}
+
 func TestExtractTarGzPreservesModTime(t *testing.T) {
 	root := t.TempDir()
 	archive := filepath.Join(root, "bundle.tar.gz")
@@ -339,7 +432,7 @@ func TestExtractTarGzPreservesModTime(t *testing.T) {
 	if err := os.MkdirAll(dest, 0o755); err != nil {
 		t.Fatalf("mkdir dest: %v", err)
 	}
-	if err := extractTarGz(archive, dest); err != nil {
+	if _, err := extractTarGz(archive, dest); err != nil {
 		t.Fatalf("extractTarGz failed: %v", err)
 	}
 
@@ -367,7 +460,7 @@ func TestBundleMountRootChecksumIgnoresGroupWriteBit(t *testing.T) {
 		t.Fatalf("write file: %v", err)
 	}
 
-	checksumA, bundleA, err := bundleMountRoot(root)
+	checksumA, bundleA, err := bundleMountRoot(root, nil)
 	if err != nil {
 		t.Fatalf("bundleMountRoot first call failed: %v", err)
 	}
@@ -377,7 +470,7 @@ func TestBundleMountRootChecksumIgnoresGroupWriteBit(t *testing.T) {
 		t.Fatalf("chmod file: %v", err)
 	}
 
-	checksumB, bundleB, err := bundleMountRoot(root)
+	checksumB, bundleB, err := bundleMountRoot(root, nil)
 	if err != nil {
 		t.Fatalf("bundleMountRoot second call failed: %v", err)
 	}
@@ -390,11 +483,46 @@ func TestBundleMountRootChecksumIgnoresGroupWriteBit(t *testing.T) {
 
 func TestShouldIgnoreWatchEventSkipsTrashPaths(t *testing.T) {
 	root := t.TempDir()
-	if !shouldIgnoreWatchEvent(root, filepath.Join(root, ".trash-123", "x")) {
+	if !shouldIgnoreWatchEvent(root, filepath.Join(root, ".trash-123", "x"), nil) {
 		t.Fatal("expected .trash path to be ignored")
 	}
 }
 
+func TestPublishDelaySpacesWatchTriggeredPublishes(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	if got := publishDelay(time.Time{}, now, time.Minute); got != 0 {
+		t.Fatalf("expected first publish to run immediately, got %s", got)
+	}
+	if got := publishDelay(now.Add(-20*time.Second), now, time.Minute); got != 40*time.Second {
+		t.Fatalf("expected publish to wait out the interval, got %s", got)
+	}
+	if got := publishDelay(now.Add(-2*time.Minute), now, time.Minute); got != 0 {
+		t.Fatalf("expected publish after the interval to run immediately, got %s", got)
+	}
+	if got := publishDelay(now, now, 0); got != 0 {
+		t.Fatalf("expected zero interval to disable spacing, got %s", got)
+	}
+}
+
+func TestLoadPublishTimingReadsEnv(t *testing.T) {
+	previousDebounce, previousInterval := watchDebounceDelay, maxPublishInterval
+	t.Cleanup(func() {
+		watchDebounceDelay, maxPublishInterval = previousDebounce, previousInterval
+	})
+	t.Setenv("SPRITZ_SHARED_MOUNTS_DEBOUNCE", "2s")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_MAX_PUBLISH_INTERVAL", "30s")
+	if err := loadPublishTiming(); err != nil {
+		t.Fatalf("loadPublishTiming failed: %v", err)
+	}
+	if watchDebounceDelay != 2*time.Second || maxPublishInterval != 30*time.Second {
+		t.Fatalf("unexpected publish timing debounce=%s interval=%s", watchDebounceDelay, maxPublishInterval)
+	}
+	t.Setenv("SPRITZ_SHARED_MOUNTS_DEBOUNCE", "soon")
+	if err := loadPublishTiming(); err == nil {
+		t.Fatal("expected invalid debounce to fail")
+	}
+}
+
 func TestReplaceMountContentsPermissionFallback(t *testing.T) {
 	if runtime.GOOS == "darwin" {
 		t.Skip("darwin requires write permission on directory itself for rename; fallback is validated on linux")
@@ -421,7 +549,7 @@ func TestReplaceMountContentsPermissionFallback(t *testing.T) {
 		t.Fatalf("write incoming file failed: %v", err)
 	}
 
-	if err := replaceMountContents(mountPath, incoming); err != nil {
+	if err := replaceMountContents(mountPath, incoming, nil); err != nil {
 		t.Fatalf("replaceMountContents failed: %v", err)
 	}
 
@@ -476,7 +604,7 @@ func TestReplaceMountContentsSkipsIncomingTrashEntries(t *testing.T) {
 		t.Fatalf("write incoming data file failed: %v", err)
 	}
 
-	if err := replaceMountContents(mountPath, incoming); err != nil {
+	if err := replaceMountContents(mountPath, incoming, nil); err != nil {
 		t.Fatalf("replaceMountContents failed: %v", err)
 	}
 
@@ -487,3 +615,301 @@ func TestReplaceMountContentsSkipsIncomingTrashEntries(t *testing.T) {
 		t.Fatalf("chmod existing trash for cleanup failed: %v", err)
 	}
 }
+
+func TestApplyRevisionSkipsDownloadWhenChecksumMatches(t *testing.T) {
+	var gotIfNoneMatch string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotIfNoneMatch = r.Header.Get("If-None-Match")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer srv.Close()
+
+	client := &sharedMountClient{
+		baseURL: srv.URL,
+		token:   "token",
+		client:  srv.Client(),
+	}
+	mountPath := filepath.Join(t.TempDir(), "config")
+	if err := os.MkdirAll(mountPath, 0o755); err != nil {
+		t.Fatalf("mkdir mount: %v", err)
+	}
+	keep := filepath.Join(mountPath, "keep.txt")
+	if err := os.WriteFile(keep, []byte("local"), 0o644); err != nil {
+		t.Fatalf("write existing file: %v", err)
+	}
+	spec := sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "config", MountPath: mountPath})
+
+	if err := applyRevision(context.Background(), client, "owner", spec, "rev-2", "sha256:def", "sha256:abc"); err != nil {
+		t.Fatalf("applyRevision failed: %v", err)
+	}
+	if gotIfNoneMatch != `"sha256:abc"` {
+		t.Fatalf("expected If-None-Match with current checksum, got %q", gotIfNoneMatch)
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Fatalf("expected mount contents to be left alone on 304: %v", err)
+	}
+}
+
+func TestRunSidecarFlushesSnapshotMountsOnShutdown(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	mountPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(mountPath, "settings.json"), []byte(`{"theme":"dark"}`), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	client := &sharedMountClient{baseURL: srv.URL, token: "token", client: srv.Client()}
+	state := []*sharedMountState{{
+		spec: sharedmounts.MountSpec{
+			Name:           "config",
+			Scope:          sharedmounts.ScopeOwner,
+			MountPath:      mountPath,
+			Mode:           sharedmounts.ModeSnapshot,
+			SyncMode:       sharedmounts.SyncManual,
+			PublishSeconds: 3600,
+		},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runSidecar(ctx, log.New(io.Discard, "", 0), client, "owner", state)
+
+	mu.Lock()
+	defer mu.Unlock()
+	var uploaded, latest bool
+	for _, request := range requests {
+		if strings.HasPrefix(request, "PUT ") && strings.Contains(request, "/revisions/") {
+			uploaded = true
+		}
+		if request == "PUT "+strings.TrimPrefix(client.endpoint("owner", "config", "latest"), srv.URL) {
+			latest = true
+		}
+	}
+	if !uploaded || !latest {
+		t.Fatalf("expected shutdown flush to upload and publish latest, got %v", requests)
+	}
+	if state[0].currentChecksum == "" {
+		t.Fatal("expected flushed checksum to be recorded")
+	}
+}
+
+func TestReadinessHandlerReportsInitialSync(t *testing.T) {
+	config := &sharedMountState{spec: sharedmounts.MountSpec{Name: "config"}}
+	cache := &sharedMountState{spec: sharedmounts.MountSpec{Name: "cache"}}
+	handler := readinessHandler([]*sharedMountState{config, cache})
+	status := func(target string) int {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec.Code
+	}
+
+	if code := status("/ready"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected not ready before initial sync, got %d", code)
+	}
+	config.synced.Store(true)
+	if code := status("/ready?mount=config"); code != http.StatusOK {
+		t.Fatalf("expected synced mount to be ready, got %d", code)
+	}
+	if code := status("/ready"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected overall readiness to wait for every mount, got %d", code)
+	}
+	cache.synced.Store(true)
+	if code := status("/ready"); code != http.StatusOK {
+		t.Fatalf("expected ready after every mount synced, got %d", code)
+	}
+	if code := status("/ready?mount=missing"); code != http.StatusNotFound {
+		t.Fatalf("expected unknown mount to return 404, got %d", code)
+	}
+}
+
+func TestReadinessHandlerReportsRepeatedSyncFailures(t *testing.T) {
+	config := &sharedMountState{spec: sharedmounts.MountSpec{Name: "config"}}
+	config.synced.Store(true)
+	handler := readinessHandler([]*sharedMountState{config})
+
+	for i := 0; i < syncFailureThreshold; i++ {
+		config.health.recordFailure(errors.New("upload timed out"))
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/ready?mount=config", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected repeated failures after the initial sync to stay ready, got %d", rec.Code)
+	}
+	var body struct {
+		Degraded bool                      `json:"degraded"`
+		Mounts   map[string]mountReadiness `json:"mounts"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode readiness body: %v", err)
+	}
+	got := body.Mounts["config"]
+	if !body.Degraded || !got.Degraded || got.Failures != syncFailureThreshold || got.LastError != "upload timed out" {
+		t.Fatalf("expected degraded mount with failure count and last error, got %#v", body)
+	}
+
+	config.health.recordSuccess()
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	body.Degraded = true
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode readiness body: %v", err)
+	}
+	if rec.Code != http.StatusOK || body.Degraded {
+		t.Fatalf("expected success to clear degraded, got %d %#v", rec.Code, body)
+	}
+}
+
+func TestSyncFailureBackoffDoublesUpToCap(t *testing.T) {
+	if got := syncFailureBackoff(1); got != syncRetryBackoff {
+		t.Fatalf("expected first retry after %s, got %s", syncRetryBackoff, got)
+	}
+	if got := syncFailureBackoff(3); got != 4*syncRetryBackoff {
+		t.Fatalf("expected third retry after %s, got %s", 4*syncRetryBackoff, got)
+	}
+	if got := syncFailureBackoff(50); got != maxSyncRetryBackoff {
+		t.Fatalf("expected backoff capped at %s, got %s", maxSyncRetryBackoff, got)
+	}
+}
+
+func TestApplyRevisionRejectsBundleWithMismatchedChecksum(t *testing.T) {
+	source := t.TempDir()
+	if err := os.WriteFile(filepath.Join(source, "settings.json"), []byte(`{"theme":"dark"}`), 0o644); err != nil {
+		t.Fatalf("write source file: %v", err)
+	}
+	checksum, bundle, err := bundleMountRoot(source, nil)
+	if err != nil {
+		t.Fatalf("bundleMountRoot failed: %v", err)
+	}
+	defer os.Remove(bundle)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, bundle)
+	}))
+	defer srv.Close()
+	client := &sharedMountClient{baseURL: srv.URL, token: "token", client: srv.Client()}
+
+	mountPath := filepath.Join(t.TempDir(), "config")
+	if err := os.MkdirAll(mountPath, 0o755); err != nil {
+		t.Fatalf("mkdir mount: %v", err)
+	}
+	keep := filepath.Join(mountPath, "keep.txt")
+	if err := os.WriteFile(keep, []byte("local"), 0o644); err != nil {
+		t.Fatalf("write existing file: %v", err)
+	}
+	spec := sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "config", MountPath: mountPath})
+
+	err = applyRevision(context.Background(), client, "owner", spec, "rev-2", "sha256:"+strings.Repeat("0", 64), "")
+	if !errors.Is(err, errChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if !isRetryableInitError(err) {
+		t.Fatal("expected checksum mismatch to be retried")
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Fatalf("expected mount contents to be left alone on mismatch: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mountPath, ".incoming-rev-2")); !os.IsNotExist(err) {
+		t.Fatalf("expected staging dir to be discarded, got %v", err)
+	}
+
+	if err := applyRevision(context.Background(), client, "owner", spec, "rev-2", "sha256:"+checksum, ""); err != nil {
+		t.Fatalf("expected matching checksum to apply, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(mountPath, "settings.json")); err != nil {
+		t.Fatalf("expected verified bundle to be applied: %v", err)
+	}
+}
+
+func TestApplyGroupInstallsAllMountsOrNone(t *testing.T) {
+	type revision struct {
+		manifest sharedmounts.LatestManifest
+		bundle   string
+	}
+	revisions := map[string]*revision{}
+	for _, name := range []string{"config", "cache"} {
+		source := t.TempDir()
+		if err := os.WriteFile(filepath.Join(source, name+".txt"), []byte(name), 0o644); err != nil {
+			t.Fatalf("write source file: %v", err)
+		}
+		checksum, bundle, err := bundleMountRoot(source, nil)
+		if err != nil {
+			t.Fatalf("bundleMountRoot failed: %v", err)
+		}
+		defer os.Remove(bundle)
+		revisions[name] = &revision{
+			manifest: sharedmounts.LatestManifest{Revision: "rev-" + name, Checksum: "sha256:" + checksum},
+			bundle:   bundle,
+		}
+	}
+	goodCacheChecksum := revisions["cache"].manifest.Checksum
+	revisions["cache"].manifest.Checksum = "sha256:" + strings.Repeat("0", 64)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/internal/v1/shared-mounts/owner/owner/"), "/")
+		rev, ok := revisions[parts[0]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if parts[1] == "latest" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": rev.manifest})
+			return
+		}
+		http.ServeFile(w, r, rev.bundle)
+	}))
+	defer srv.Close()
+	client := &sharedMountClient{baseURL: srv.URL, token: "token", client: srv.Client()}
+
+	root := t.TempDir()
+	var mounts []*sharedMountState
+	for _, name := range []string{"config", "cache"} {
+		mounts = append(mounts, &sharedMountState{spec: sharedmounts.NormalizeMount(sharedmounts.MountSpec{
+			Name:      name,
+			MountPath: filepath.Join(root, name),
+		})})
+	}
+
+	if _, err := applyGroup(context.Background(), client, "owner", mounts); !errors.Is(err, errChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	for _, state := range mounts {
+		if state.currentRevision != "" {
+			t.Fatalf("expected %s to stay on its old revision, got %q", state.spec.Name, state.currentRevision)
+		}
+		entries, err := os.ReadDir(state.spec.MountPath)
+		if err != nil {
+			t.Fatalf("read %s: %v", state.spec.Name, err)
+		}
+		for _, entry := range entries {
+			if entry.Name() != "live" {
+				t.Fatalf("expected %s to be untouched, found %s", state.spec.Name, entry.Name())
+			}
+		}
+	}
+	if failures, _ := mounts[1].health.snapshot(); failures != 1 {
+		t.Fatalf("expected the failing mount to record a failure, got %d", failures)
+	}
+
+	revisions["cache"].manifest.Checksum = goodCacheChecksum
+	applied, err := applyGroup(context.Background(), client, "owner", mounts)
+	if err != nil {
+		t.Fatalf("applyGroup failed: %v", err)
+	}
+	if strings.Join(applied, ",") != "config,cache" {
+		t.Fatalf("expected both mounts to be applied, got %v", applied)
+	}
+	for _, state := range mounts {
+		if _, err := os.Stat(filepath.Join(state.spec.MountPath, state.spec.Name+".txt")); err != nil {
+			t.Fatalf("expected %s revision to be installed: %v", state.spec.Name, err)
+		}
+		if state.currentRevision != "rev-"+state.spec.Name {
+			t.Fatalf("expected %s revision to be recorded, got %q", state.spec.Name, state.currentRevision)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// newCreateRateLimiter throttles workspace creation per principal. It reads
+// SPRITZ_CREATE_RATE (sustained creates per minute) and SPRITZ_CREATE_BURST,
+// and returns nil when the rate is unset or zero.
+func newCreateRateLimiter() *keyedLimiter {
+	perMinute, err := strconv.ParseFloat(strings.TrimSpace(envOrDefault("SPRITZ_CREATE_RATE", "0")), 64)
+	if err != nil || perMinute <= 0 {
+		return nil
+	}
+	burst := parseIntEnv("SPRITZ_CREATE_BURST", int(math.Max(1, math.Ceil(perMinute))))
+	return newKeyedLimiter(
+		rate.Limit(perMinute/60),
+		burst,
+		parseDurationEnv("SPRITZ_CREATE_RATE_BUCKET_TTL", 30*time.Minute),
+		5*time.Minute,
+		defaultRateLimitMaxBuckets,
+	)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func postCreateSpritz(e *echo.Echo, userID, name string) *httptest.ResponseRecorder {
+	body := []byte(fmt.Sprintf(`{"name":%q,"spec":{"image":"example.com/spritz:latest"}}`, name))
+	req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Spritz-User-Id", userID)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreateSpritzRateLimitsPerPrincipal(t *testing.T) {
+	t.Setenv("SPRITZ_CREATE_RATE", "1")
+	t.Setenv("SPRITZ_CREATE_BURST", "1")
+	s := newCreateSpritzTestServer(t)
+	s.createLimiter = newCreateRateLimiter()
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	if rec := postCreateSpritz(e, "user-1", "tidal-ember"); rec.Code != http.StatusCreated {
+		t.Fatalf("expected first create to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := postCreateSpritz(e, "user-1", "tidal-amber")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected second create to be rate limited, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("expected Retry-After header on rate limited create")
+	}
+	if rec := postCreateSpritz(e, "user-2", "quiet-harbor"); rec.Code != http.StatusCreated {
+		t.Fatalf("expected other principal to have its own bucket, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCreateSpritzRateLimitBypassedForAdmins(t *testing.T) {
+	t.Setenv("SPRITZ_CREATE_RATE", "1")
+	t.Setenv("SPRITZ_CREATE_BURST", "1")
+	s := newCreateSpritzTestServer(t)
+	s.auth.adminIDs = map[string]struct{}{"admin-1": {}}
+	s.createLimiter = newCreateRateLimiter()
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	for _, name := range []string{"tidal-ember", "tidal-amber", "tidal-cedar"} {
+		if rec := postCreateSpritz(e, "admin-1", name); rec.Code != http.StatusCreated {
+			t.Fatalf("expected admin create %s to bypass the rate limit, got %d: %s", name, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestNewCreateRateLimiterDisabledByDefault(t *testing.T) {
+	t.Setenv("SPRITZ_CREATE_RATE", "")
+	if limiter := newCreateRateLimiter(); limiter != nil {
+		t.Fatal("expected create rate limiter to be disabled without SPRITZ_CREATE_RATE")
+	}
+}
+
+func TestCreateSpritzRateLimitSkipsInvalidRequests(t *testing.T) {
+	t.Setenv("SPRITZ_CREATE_RATE", "1")
+	t.Setenv("SPRITZ_CREATE_BURST", "1")
+	s := newCreateSpritzTestServer(t)
+	s.createLimiter = newCreateRateLimiter()
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader([]byte(`{"name":"Not A Name","spec":{}}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Spritz-User-Id", "user-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid create to fail validation, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := postCreateSpritz(e, "user-1", "tidal-ember"); rec.Code != http.StatusCreated {
+		t.Fatalf("expected invalid request not to use the create budget, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
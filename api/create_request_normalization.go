@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	corev1 "k8s.io/api/core/v1"
 
 	spritzv1 "spritz.sh/operator/api/v1"
 )
@@ -132,6 +134,27 @@ func (s *server) normalizeCreateRequest(_ context.Context, principal principal,
 		return nil, newCreateRequestError(http.StatusForbidden, err)
 	}
 	requestedNamespace := s.namespaceOverrideRequested(body.Namespace, namespace)
+	if body.Spec.SSH != nil && len(body.Spec.SSH.AdditionalPrincipals) > 0 && s.auth.enabled() && !principal.isAdminForNamespace(namespace) {
+		return nil, newCreateRequestError(http.StatusForbidden, errors.New("spec.ssh.additionalPrincipals is reserved for admins"))
+	}
+	if strings.TrimSpace(body.Annotations[imageDigestExemptAnnotationKey]) != "" && s.auth.enabled() && !principal.isAdminPrincipal() {
+		return nil, newCreateRequestError(http.StatusForbidden, errors.New(imageDigestExemptAnnotationKey+" is reserved for admins"))
+	}
+	if strings.TrimSpace(body.Annotations[pausedAnnotationKey]) != "" && s.auth.enabled() && !principal.isAdminPrincipal() {
+		return nil, newCreateRequestError(http.StatusForbidden, errors.New(pausedAnnotationKey+" is reserved for admins"))
+	}
+	if strings.TrimSpace(body.Annotations[maxLifetimeExemptAnnotationKey]) != "" && s.auth.enabled() && !principal.isAdminPrincipal() {
+		return nil, newCreateRequestError(http.StatusForbidden, errors.New(maxLifetimeExemptAnnotationKey+" is reserved for admins"))
+	}
+	if requestsUnconfinedSecurityProfile(body.Spec) && s.auth.enabled() && !principal.isAdminPrincipal() {
+		return nil, newCreateRequestError(http.StatusForbidden, errors.New("unconfined seccomp and AppArmor profiles are reserved for admins"))
+	}
+	if requestsRestrictedVolume(body.Spec) && s.auth.enabled() && !principal.isAdminPrincipal() {
+		return nil, newCreateRequestError(http.StatusForbidden, errors.New("extra volumes other than emptyDir, downwardAPI and csi are reserved for admins"))
+	}
+	if strings.TrimSpace(body.Annotations[unrestrictedVolumesAnnotationKey]) != "" && s.auth.enabled() && !principal.isAdminPrincipal() {
+		return nil, newCreateRequestError(http.StatusForbidden, errors.New(unrestrictedVolumesAnnotationKey+" is reserved for admins"))
+	}
 
 	owner, err := normalizeCreateOwnerRequest(&body, principal, s.auth.enabled())
 	if err != nil {
@@ -222,11 +245,41 @@ func validateCreateSpec(spec *spritzv1.SpritzSpec) error {
 		if err := validateRepoDir(spec.Repo.Dir); err != nil {
 			return err
 		}
+		if err := validateRepoPostClone(spec.Repo.PostClone); err != nil {
+			return err
+		}
+		if err := validateRepoWorktrees("spec.repo.worktrees", spec.Repo.Worktrees); err != nil {
+			return err
+		}
+		if err := spritzv1.ValidateImageReference("spec.repo.initImage", spec.Repo.InitImage); err != nil {
+			return err
+		}
 	}
-	for _, repo := range spec.Repos {
+	for i, repo := range spec.Repos {
 		if err := validateRepoDir(repo.Dir); err != nil {
 			return err
 		}
+		if err := validateRepoPostClone(repo.PostClone); err != nil {
+			return err
+		}
+		if err := validateRepoWorktrees(fmt.Sprintf("spec.repos[%d].worktrees", i), repo.Worktrees); err != nil {
+			return err
+		}
+		if err := spritzv1.ValidateImageReference(fmt.Sprintf("spec.repos[%d].initImage", i), repo.InitImage); err != nil {
+			return err
+		}
+	}
+	if err := spritzv1.ValidateDNS(spec.DNSPolicy, spec.DNSConfig); err != nil {
+		return err
+	}
+	if err := spritzv1.ValidateSeccompProfile(spec.SeccompProfile); err != nil {
+		return err
+	}
+	if err := spritzv1.ValidateAppArmorProfile(spec.AppArmorProfile); err != nil {
+		return err
+	}
+	if err := validateTerminalCommand(spec.Terminal); err != nil {
+		return err
 	}
 	spec.AgentRef = normalizeSpritzAgentRef(spec.AgentRef)
 	if err := validateSpritzAgentRef(spec.AgentRef); err != nil {
@@ -246,3 +299,24 @@ func validateCreateSpec(spec *spritzv1.SpritzSpec) error {
 	}
 	return nil
 }
+
+// requestsUnconfinedSecurityProfile reports whether the spec turns seccomp or
+// AppArmor off, which would bypass the operator default profiles.
+func requestsUnconfinedSecurityProfile(spec spritzv1.SpritzSpec) bool {
+	if spec.SeccompProfile != nil && spec.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		return true
+	}
+	return spec.AppArmorProfile != nil && spec.AppArmorProfile.Type == corev1.AppArmorProfileTypeUnconfined
+}
+
+// requestsRestrictedVolume reports whether spec.extraVolumes uses a source
+// outside the emptyDir, downwardAPI and csi allowlist, such as hostPath or a
+// Secret.
+func requestsRestrictedVolume(spec spritzv1.SpritzSpec) bool {
+	for _, volume := range spec.ExtraVolumes {
+		if spritzv1.RestrictedVolumeSource(volume) {
+			return true
+		}
+	}
+	return false
+}
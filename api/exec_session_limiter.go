@@ -0,0 +1,86 @@
+package main
+
+import "sync"
+
+// execSessionLimiter caps concurrent terminal, SSH, and port-forward streams.
+// Each stream holds a connection to the kube API server, so limits apply per
+// spritz and per principal.
+type execSessionLimiter struct {
+	mu           sync.Mutex
+	perSpritz    int
+	perPrincipal int
+	spritzes     map[string]int
+	principals   map[string]int
+}
+
+func newExecSessionLimiter() *execSessionLimiter {
+	perSpritz := parseIntEnvAllowZero("SPRITZ_EXEC_SESSION_LIMIT_PER_SPRITZ", 20)
+	perPrincipal := parseIntEnvAllowZero("SPRITZ_EXEC_SESSION_LIMIT_PER_PRINCIPAL", 50)
+	if perSpritz <= 0 && perPrincipal <= 0 {
+		return nil
+	}
+	return &execSessionLimiter{
+		perSpritz:    perSpritz,
+		perPrincipal: perPrincipal,
+		spritzes:     map[string]int{},
+		principals:   map[string]int{},
+	}
+}
+
+func execSessionSpritzKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// Acquire reserves a session slot and returns the func that frees it. It
+// returns false when the spritz or the principal is already at its limit. An
+// empty principalID, as with auth disabled, only counts against the spritz.
+func (l *execSessionLimiter) Acquire(spritzKey, principalID string) (func(), bool) {
+	if l == nil {
+		return func() {}, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.fullLocked(spritzKey, principalID) {
+		return nil, false
+	}
+	l.spritzes[spritzKey]++
+	if principalID != "" {
+		l.principals[principalID]++
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			decrementSessionCount(l.spritzes, spritzKey)
+			if principalID != "" {
+				decrementSessionCount(l.principals, principalID)
+			}
+		})
+	}, true
+}
+
+// Full reports whether Acquire would currently be refused.
+func (l *execSessionLimiter) Full(spritzKey, principalID string) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.fullLocked(spritzKey, principalID)
+}
+
+func (l *execSessionLimiter) fullLocked(spritzKey, principalID string) bool {
+	if l.perSpritz > 0 && l.spritzes[spritzKey] >= l.perSpritz {
+		return true
+	}
+	return principalID != "" && l.perPrincipal > 0 && l.principals[principalID] >= l.perPrincipal
+}
+
+func decrementSessionCount(counts map[string]int, key string) {
+	if counts[key] <= 1 {
+		delete(counts, key)
+		return
+	}
+	counts[key]--
+}
@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestExecSessionLimiterEnforcesSpritzAndPrincipalLimits(t *testing.T) {
+	t.Setenv("SPRITZ_EXEC_SESSION_LIMIT_PER_SPRITZ", "2")
+	t.Setenv("SPRITZ_EXEC_SESSION_LIMIT_PER_PRINCIPAL", "3")
+	limiter := newExecSessionLimiter()
+
+	releaseFirst, ok := limiter.Acquire("ns/alpha", "user-1")
+	if !ok {
+		t.Fatal("expected first session to be allowed")
+	}
+	if _, ok := limiter.Acquire("ns/alpha", "user-2"); !ok {
+		t.Fatal("expected second session on the spritz to be allowed")
+	}
+	if _, ok := limiter.Acquire("ns/alpha", "user-3"); ok {
+		t.Fatal("expected third session on the spritz to be refused")
+	}
+	if !limiter.Full("ns/alpha", "user-3") {
+		t.Fatal("expected spritz to report full")
+	}
+
+	if _, ok := limiter.Acquire("ns/beta", "user-1"); !ok {
+		t.Fatal("expected another spritz to be allowed")
+	}
+	if _, ok := limiter.Acquire("ns/gamma", "user-1"); !ok {
+		t.Fatal("expected the principal's third session to be allowed")
+	}
+	if _, ok := limiter.Acquire("ns/delta", "user-1"); ok {
+		t.Fatal("expected the principal's fourth session to be refused")
+	}
+
+	releaseFirst()
+	releaseFirst()
+	if _, ok := limiter.Acquire("ns/delta", "user-1"); !ok {
+		t.Fatal("expected a released slot to be reusable")
+	}
+	if limiter.spritzes["ns/alpha"] != 1 {
+		t.Fatalf("expected a repeated release to free one slot, got %d sessions", limiter.spritzes["ns/alpha"])
+	}
+}
+
+func TestNewExecSessionLimiterDisabledWithZeroLimits(t *testing.T) {
+	t.Setenv("SPRITZ_EXEC_SESSION_LIMIT_PER_SPRITZ", "0")
+	t.Setenv("SPRITZ_EXEC_SESSION_LIMIT_PER_PRINCIPAL", "0")
+	limiter := newExecSessionLimiter()
+	if limiter != nil {
+		t.Fatal("expected limiter to be disabled when both limits are 0")
+	}
+	release, ok := limiter.Acquire("ns/alpha", "user-1")
+	if !ok || limiter.Full("ns/alpha", "user-1") {
+		t.Fatal("expected a disabled limiter to allow every session")
+	}
+	release()
+}
@@ -95,7 +95,7 @@ func (s *server) newInstanceReverseProxy(target *url.URL, externalPrefix string)
 			stripBrowserAuthHeaders(proxyReq.Out.Header, s.auth)
 		},
 		ErrorHandler: func(rw http.ResponseWriter, req *http.Request, err error) {
-			http.Error(rw, err.Error(), http.StatusBadGateway)
+			writeHTTPError(rw, http.StatusBadGateway, err.Error())
 		},
 	}
 	if s.instanceProxyTransport != nil {
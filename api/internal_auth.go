@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/labstack/echo/v4"
+
+	"spritz.sh/operator/sharedmounts"
 )
 
 type internalAuthConfig struct {
@@ -46,13 +48,7 @@ func (s *server) internalAuthMiddlewareWithBearerFallback(allowBearerFallback bo
 	}
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			token := strings.TrimSpace(c.Request().Header.Get(internalTokenHeader))
-			if token == "" && allowBearerFallback {
-				value := c.Request().Header.Get("Authorization")
-				if strings.HasPrefix(value, "Bearer ") {
-					token = strings.TrimSpace(strings.TrimPrefix(value, "Bearer "))
-				}
-			}
+			token := internalRequestToken(c.Request(), allowBearerFallback)
 			if token == "" || token != s.internalAuth.token {
 				return writeError(c, http.StatusUnauthorized, "unauthorized")
 			}
@@ -62,6 +58,38 @@ func (s *server) internalAuthMiddlewareWithBearerFallback(allowBearerFallback bo
 	}
 }
 
+// sharedMountsAuthMiddleware guards the shared mount sync endpoints. With an
+// owner token key configured, the caller must present the token derived for the
+// :owner path segment, so one workspace's syncer cannot reach another owner's
+// mounts. Without it, the shared internal token is accepted as before.
+func (s *server) sharedMountsAuthMiddleware() echo.MiddlewareFunc {
+	key := s.sharedMounts.ownerTokenKey
+	if key == "" {
+		return s.internalAuthMiddleware()
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := internalRequestToken(c.Request(), true)
+			ownerID := strings.TrimSpace(c.Param("owner"))
+			if token == "" || !sharedmounts.VerifyOwnerToken(key, ownerID, token) {
+				return writeError(c, http.StatusUnauthorized, "unauthorized")
+			}
+			return next(c)
+		}
+	}
+}
+
+func internalRequestToken(r *http.Request, allowBearerFallback bool) string {
+	token := strings.TrimSpace(r.Header.Get(internalTokenHeader))
+	if token == "" && allowBearerFallback {
+		value := r.Header.Get("Authorization")
+		if strings.HasPrefix(value, "Bearer ") {
+			token = strings.TrimSpace(strings.TrimPrefix(value, "Bearer "))
+		}
+	}
+	return token
+}
+
 // bridgeCanonicalPrincipalHeaders lets internal callers keep using the stable
 // Spritz principal headers even when a deployment is configured to trust
 // different proxy header names at the edge.
@@ -153,7 +153,7 @@ func (s *server) upsertInternalBinding(c echo.Context) error {
 
 	var body internalBindingRequest
 	if err := c.Bind(&body); err != nil {
-		return writeError(c, http.StatusBadRequest, "invalid json")
+		return writeBindError(c, err)
 	}
 	internalPrincipal, err := body.Principal.normalize()
 	if err != nil {
@@ -194,15 +194,16 @@ func (s *server) upsertInternalBinding(c echo.Context) error {
 		return writeError(c, http.StatusInternalServerError, "failed to ensure service account")
 	}
 
-	labels := map[string]string{
+	policyLabels, policyAnnotations := s.metadataPolicy.defaults(requestBody.Spec.Owner.ID, metadataPolicyTeams(internalPrincipal, requestBody.Spec.Owner.ID))
+	labels := mergeStringMap(policyLabels, map[string]string{
 		ownerLabelKey: ownerLabelValue(requestBody.Spec.Owner.ID),
 		actorLabelKey: actorLabelValue(internalPrincipal.ID),
-	}
+	})
 	if strings.TrimSpace(requestBody.PresetID) != "" {
 		labels[presetLabelKey] = strings.TrimSpace(requestBody.PresetID)
 	}
 
-	annotations := mergeStringMap(s.defaultMetadata, requestBody.Annotations)
+	annotations := mergeStringMap(mergeStringMap(s.defaultMetadata, policyAnnotations), requestBody.Annotations)
 	if annotations == nil {
 		annotations = map[string]string{}
 	}
@@ -210,7 +211,7 @@ func (s *server) upsertInternalBinding(c echo.Context) error {
 		annotations[presetIDAnnotationKey] = strings.TrimSpace(requestBody.PresetID)
 	}
 
-	applySSHDefaults(&requestBody.Spec, s.sshDefaults, namespace)
+	spritzv1.ApplySSHDefaults(&requestBody.Spec, s.sshDefaults, namespace)
 	template := spritzv1.SpritzBindingTemplate{
 		PresetID:    strings.TrimSpace(requestBody.PresetID),
 		NamePrefix:  s.resolvedCreateNamePrefix(requestBody, normalized.requestedNamePrefix),
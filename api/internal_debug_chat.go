@@ -63,7 +63,7 @@ func (s *server) sendInternalDebugChat(c echo.Context) error {
 
 	var body internalDebugChatSendRequest
 	if err := c.Bind(&body); err != nil {
-		return writeError(c, http.StatusBadRequest, "invalid json")
+		return writeBindError(c, err)
 	}
 	principal, ok := principalFromContext(c)
 	if s.auth.enabled() && (!ok || principal.ID == "") {
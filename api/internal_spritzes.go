@@ -120,7 +120,7 @@ func summarizeInternalSpritz(spritz *spritzv1.Spritz) internalSpritzSummary {
 func (s *server) createInternalSpritz(c echo.Context) error {
 	var body internalCreateSpritzRequest
 	if err := c.Bind(&body); err != nil {
-		return writeError(c, http.StatusBadRequest, "invalid json")
+		return writeBindError(c, err)
 	}
 	internalPrincipal, err := body.Principal.normalize()
 	if err != nil {
@@ -196,7 +196,7 @@ func (s *server) replaceInternalSpritz(c echo.Context) error {
 
 	var body internalReplaceSpritzRequest
 	if err := c.Bind(&body); err != nil {
-		return writeError(c, http.StatusBadRequest, "invalid json")
+		return writeBindError(c, err)
 	}
 	body.TargetRevision = strings.TrimSpace(body.TargetRevision)
 	body.IdempotencyKey = strings.TrimSpace(body.IdempotencyKey)
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -48,14 +50,69 @@ func writeJSendFailData(c echo.Context, status int, payload any) error {
 }
 
 func writeError(c echo.Context, status int, message string) error {
+	return c.JSON(status, jsendErrorResponse(status, message))
+}
+
+func jsendErrorResponse(status int, message string) jsendResponse {
 	if status >= 500 {
-		return c.JSON(status, jsendResponse{
+		return jsendResponse{
 			Status:  "error",
 			Message: message,
 			Code:    status,
-		})
+		}
+	}
+	return jsendResponse{
+		Status:  "fail",
+		Message: message,
+		Data: map[string]string{
+			"message": message,
+		},
+	}
+}
+
+// writeHTTPError writes the JSend error envelope for handlers that only have
+// an http.ResponseWriter, such as reverse proxy and WebSocket upgrade hooks.
+func writeHTTPError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(jsendErrorResponse(status, message))
+}
+
+// websocketUpgradeError is the websocket.Upgrader Error hook, so a failed
+// handshake answers with JSend instead of gorilla's plain-text body.
+func websocketUpgradeError(w http.ResponseWriter, _ *http.Request, status int, reason error) {
+	writeHTTPError(w, status, reason.Error())
+}
+
+// jsendHTTPErrorHandler replaces echo's default `{"message":...}` body for
+// errors returned by handlers and the router (404, 405, bind errors).
+// Responses that were already written, including hijacked WebSockets, are
+// left alone.
+func jsendHTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	status := http.StatusInternalServerError
+	message := "internal server error"
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		status = httpErr.Code
+		if text, ok := httpErr.Message.(string); ok && text != "" {
+			message = text
+		} else if text := http.StatusText(status); text != "" {
+			message = text
+		}
+	} else {
+		c.Logger().Error(err)
+	}
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = writeError(c, status, message)
+	}
+	if err != nil {
+		c.Logger().Error(err)
 	}
-	return writeJSendFail(c, status, message)
 }
 
 func jsendErrorMessage(payload any, status int) string {
@@ -2,10 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 )
 
@@ -115,3 +117,58 @@ func TestWriteJSendFailDataUsesErrorForServerErrors(t *testing.T) {
 		t.Fatalf("expected code %d, got %d", http.StatusServiceUnavailable, resp.Code)
 	}
 }
+
+func TestJSendHTTPErrorHandlerWrapsEchoErrors(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = jsendHTTPErrorHandler
+	e.GET("/exists", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusConflict, "already taken")
+	})
+	e.GET("/raw", func(c echo.Context) error {
+		return errors.New("dial tcp 10.0.0.1:443: connection refused")
+	})
+
+	cases := []struct {
+		method string
+		path   string
+		status int
+		want   string
+	}{
+		{method: http.MethodGet, path: "/missing", status: http.StatusNotFound, want: "Not Found"},
+		{method: http.MethodPost, path: "/exists", status: http.StatusMethodNotAllowed, want: "Method Not Allowed"},
+		{method: http.MethodGet, path: "/exists", status: http.StatusConflict, want: "already taken"},
+		{method: http.MethodGet, path: "/raw", status: http.StatusInternalServerError, want: "internal server error"},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.status {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.status, rec.Code)
+		}
+		var resp jsendResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s %s: expected JSend body, got %q", tc.method, tc.path, rec.Body.String())
+		}
+		if resp.Status == "success" || resp.Message != tc.want {
+			t.Fatalf("%s %s: unexpected envelope %#v", tc.method, tc.path, resp)
+		}
+	}
+}
+
+func TestWebSocketUpgradeErrorUsesJSend(t *testing.T) {
+	upgrader := websocket.Upgrader{Error: websocketUpgradeError}
+	rec := httptest.NewRecorder()
+	if _, err := upgrader.Upgrade(rec, httptest.NewRequest(http.MethodGet, "/terminal", nil), nil); err == nil {
+		t.Fatal("expected plain GET to fail the handshake")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	var resp jsendResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("expected JSend body, got %q", rec.Body.String())
+	}
+	if resp.Status != "fail" || resp.Message == "" {
+		t.Fatalf("unexpected envelope %#v", resp)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	spritzv1 "spritz.sh/operator/api/v1"
+	"spritz.sh/operator/tracing"
 )
 
 type server struct {
@@ -40,14 +41,22 @@ type server struct {
 	controlNamespace            string
 	auth                        authConfig
 	internalAuth                internalAuthConfig
-	ingressDefaults             ingressDefaults
+	ingressDefaults             spritzv1.IngressDefaults
 	routeModel                  spritzv1.SharedHostRouteModel
 	instanceProxy               instanceProxyConfig
 	terminal                    terminalConfig
 	portForward                 portForwardConfig
 	sshGateway                  sshGatewayConfig
-	sshDefaults                 sshDefaults
-	sshMintLimiter              *sshMintLimiter
+	sshDefaults                 spritzv1.SSHDefaults
+	sshMintLimiter              *keyedLimiter
+	createLimiter               *keyedLimiter
+	activityPings               *keyedLimiter
+	waitMaxTimeout              time.Duration
+	execSessions                *execSessionLimiter
+	maxRequestBytes             int64
+	readOnly                    bool
+	sessionDrain                *sessionDrain
+	terminalHubs                *terminalHubRegistry
 	acp                         acpConfig
 	extensions                  extensionRegistry
 	instanceClasses             instanceClassCatalog
@@ -55,6 +64,7 @@ type server struct {
 	provisioners                provisionerPolicy
 	externalOwners              externalOwnerConfig
 	defaultMetadata             map[string]string
+	metadataPolicy              *metadataPolicy
 	sharedMounts                sharedMountsConfig
 	sharedMountsStore           *sharedMountsStore
 	sharedMountsLive            *sharedMountsLatestNotifier
@@ -69,12 +79,18 @@ type server struct {
 }
 
 func main() {
+	shutdownTracing, err := tracing.Setup(context.Background(), "spritz-api")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tracing setup failed: %v\n", err)
+		os.Exit(1)
+	}
+
 	scheme := runtime.NewScheme()
 	utilruntime.Must(spritzv1.AddToScheme(scheme))
 	utilruntime.Must(corev1.AddToScheme(scheme))
 
 	cfg := ctrl.GetConfigOrDie()
-	k8sClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	k8sClient, err := client.NewWithWatch(cfg, client.Options{Scheme: scheme})
 	if err != nil {
 		os.Exit(1)
 	}
@@ -111,7 +127,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "invalid auth config: %v\n", auth.configErr)
 		os.Exit(1)
 	}
-	ingressDefaults := newIngressDefaults()
+	ingressDefaults := spritzv1.IngressDefaultsFromEnv()
 	routeModel := spritzRouteModelFromEnv()
 	instanceProxy := newInstanceProxyConfig()
 	terminal := newTerminalConfig()
@@ -142,7 +158,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "invalid external owner config: %v\n", err)
 		os.Exit(1)
 	}
-	sshDefaults := newSSHDefaults()
+	sshDefaults := spritzv1.SSHDefaultsFromEnv()
 	sshGateway, err := newSSHGatewayConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "invalid ssh gateway config: %v\n", err)
@@ -155,19 +171,35 @@ func main() {
 		os.Exit(1)
 	}
 	userConfigPolicy := newUserConfigPolicy()
-	if sharedMounts.enabled && !internalAuth.enabled {
-		fmt.Fprintln(os.Stderr, "SPRITZ_INTERNAL_TOKEN must be set when shared mounts are enabled")
+	if sharedMounts.enabled && !internalAuth.enabled && sharedMounts.ownerTokenKey == "" {
+		fmt.Fprintln(os.Stderr, "SPRITZ_INTERNAL_TOKEN or SPRITZ_SHARED_MOUNTS_OWNER_TOKEN_KEY must be set when shared mounts are enabled")
 		os.Exit(1)
 	}
 	var sharedStore *sharedMountsStore
 	if sharedMounts.enabled {
-		sharedStore = newSharedMountsStore(sharedMounts)
+		sharedStore, err = newSharedMountsStore(sharedMounts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid shared mounts storage config: %v\n", err)
+			os.Exit(1)
+		}
+		if sharedMounts.startupCheck {
+			checkCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			err := sharedStore.checkReachable(checkCtx)
+			cancel()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "shared mounts startup check failed: %v\n", err)
+				os.Exit(1)
+			}
+		}
 	}
 	var sharedMountsLive *sharedMountsLatestNotifier
 	if sharedMounts.enabled {
-		sharedMountsLive = newSharedMountsLatestNotifier()
+		sharedMountsLive = newSharedMountsLatestNotifier(parseDurationEnv("SPRITZ_SHARED_MOUNTS_LATEST_CACHE_TTL", 2*time.Second))
 	}
 	sshMintLimiter := newSSHMintLimiter()
+	createLimiter := newCreateRateLimiter()
+	activityPings := newActivityPingLimiter()
+	execSessions := newExecSessionLimiter()
 	defaultAnnotations, err := parseKeyValueCSV(os.Getenv("SPRITZ_DEFAULT_ANNOTATIONS"))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "invalid SPRITZ_DEFAULT_ANNOTATIONS: %v\n", err)
@@ -191,6 +223,14 @@ func main() {
 		sshGateway:        sshGateway,
 		sshDefaults:       sshDefaults,
 		sshMintLimiter:    sshMintLimiter,
+		createLimiter:     createLimiter,
+		activityPings:     activityPings,
+		waitMaxTimeout:    spritzWaitMaxTimeout(),
+		execSessions:      execSessions,
+		maxRequestBytes:   maxRequestBytes(),
+		readOnly:          parseBoolEnv("SPRITZ_API_READ_ONLY", false),
+		sessionDrain:      newSessionDrain(parseDurationEnv("SPRITZ_SESSION_DRAIN_TIMEOUT", defaultSessionDrainTimeout)),
+		terminalHubs:      newTerminalHubRegistry(),
 		acp:               acp,
 		extensions:        extensions,
 		instanceClasses:   instanceClasses,
@@ -198,6 +238,7 @@ func main() {
 		provisioners:      provisioners,
 		externalOwners:    externalOwners,
 		defaultMetadata:   defaultAnnotations,
+		metadataPolicy:    newMetadataPolicy(k8sClient, controlNamespace),
 		sharedMounts:      sharedMounts,
 		sharedMountsStore: sharedStore,
 		sharedMountsLive:  sharedMountsLive,
@@ -208,12 +249,19 @@ func main() {
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
+	e.HTTPErrorHandler = jsendHTTPErrorHandler
+	e.Use(withTracing())
 	e.Use(withRequestLogging())
 	cors := newCORSConfig()
 	if cors.enabled() {
 		e.Use(withCORS(cors))
 	}
 	s.registerRoutes(e)
+	policyCtx, policyCancel := context.WithCancel(context.Background())
+	defer policyCancel()
+	if s.metadataPolicy != nil {
+		go s.metadataPolicy.run(policyCtx)
+	}
 	sshCtx, sshCancel := context.WithCancel(context.Background())
 	if err := s.startSSHGateway(sshCtx); err != nil {
 		fmt.Fprintf(os.Stderr, "ssh gateway failed: %v\n", err)
@@ -239,12 +287,21 @@ func main() {
 	select {
 	case sig := <-sigs:
 		fmt.Fprintf(os.Stdout, "received signal %s, shutting down\n", sig)
-		sshCancel()
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), s.sessionDrain.grace+10*time.Second)
 		defer cancel()
+		// Terminal, SSH and port-forward sessions outlive srv.Shutdown, so end
+		// them first and close the SSH listener only once they have said
+		// goodbye.
+		if err := s.sessionDrain.drain(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "session drain incomplete: %v\n", err)
+		}
+		sshCancel()
 		if err := srv.Shutdown(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "server shutdown failed: %v\n", err)
 		}
+		if err := shutdownTracing(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "trace flush failed: %v\n", err)
+		}
 	case err := <-errCh:
 		sshCancel()
 		fmt.Fprintf(os.Stderr, "server failed: %v\n", err)
@@ -253,8 +310,13 @@ func main() {
 }
 
 func (s *server) registerRoutes(e *echo.Echo) {
-	group := e.Group(s.apiPathPrefix())
+	group := e.Group(
+		s.apiPathPrefix(),
+		withBodyLimit(s.maxRequestBytes, isSharedMountRevisionUpload),
+		withReadOnly(s.readOnly, s.apiPathPrefix()),
+	)
 	group.GET("/healthz", s.handleHealthz)
+	group.GET("/openapi.json", s.getOpenAPI)
 	internal := group.Group("/internal/v1", s.internalAuthMiddleware())
 	if s.internalAuth.enabled {
 		internal.GET("/presets/:presetID", s.getInternalPreset)
@@ -271,15 +333,18 @@ func (s *server) registerRoutes(e *echo.Echo) {
 		internal.GET("/spritzes/:namespace/:name", s.getInternalSpritz)
 		internal.DELETE("/spritzes/:namespace/:name", s.deleteInternalSpritz)
 		internal.POST("/spritzes/:namespace/*", s.replaceInternalSpritz)
+		internal.POST("/auth/invalidate", s.invalidateAuthCache)
 		if s.auth.enabled() {
 			internalSecured := group.Group("/internal/v1", s.internalAuthHeaderMiddleware(), s.authMiddleware())
 			internalSecured.POST("/debug/chat/send", s.sendInternalDebugChat)
 		}
 	}
-	internal.GET("/shared-mounts/owner/:owner/:mount/latest", s.getSharedMountLatest)
-	internal.GET("/shared-mounts/owner/:owner/:mount/revisions/:revision", s.getSharedMountRevision)
-	internal.PUT("/shared-mounts/owner/:owner/:mount/revisions/:revision", s.putSharedMountRevision)
-	internal.PUT("/shared-mounts/owner/:owner/:mount/latest", s.putSharedMountLatest)
+	sharedMountsOwner := group.Group("/internal/v1/shared-mounts/owner/:owner", s.sharedMountsAuthMiddleware())
+	sharedMountsOwner.GET("/:mount/latest", s.getSharedMountLatest)
+	sharedMountsOwner.GET("/:mount/revisions", s.listSharedMountRevisions)
+	sharedMountsOwner.GET("/:mount/revisions/:revision", s.getSharedMountRevision)
+	sharedMountsOwner.PUT("/:mount/revisions/:revision", s.putSharedMountRevision)
+	sharedMountsOwner.PUT("/:mount/latest", s.putSharedMountLatest)
 	secured := group.Group("", s.authMiddleware())
 	secured.GET("/presets", s.listPresets)
 	secured.GET("/spritzes", s.listSpritzes)
@@ -288,8 +353,11 @@ func (s *server) registerRoutes(e *echo.Echo) {
 	secured.POST("/channel-conversations/upsert", s.upsertChannelConversation)
 	secured.POST("/spritzes", s.createSpritz)
 	secured.GET("/spritzes/:name", s.getSpritz)
+	secured.GET("/spritzes/:name/wait", s.waitSpritz)
 	secured.DELETE("/spritzes/:name", s.deleteSpritz)
 	secured.PATCH("/spritzes/:name/user-config", s.updateUserConfig)
+	secured.POST("/spritzes/:name/activity", s.recordActivityPing)
+	secured.POST("/spritzes/:name/transfer", s.transferSpritz)
 	secured.GET("/acp/agents", s.listACPAgents)
 	secured.GET("/acp/conversations", s.listACPConversations)
 	secured.POST("/acp/conversations", s.createACPConversation)
@@ -298,6 +366,8 @@ func (s *server) registerRoutes(e *echo.Echo) {
 	secured.PATCH("/acp/conversations/:id", s.updateACPConversation)
 	secured.POST("/acp/conversations/:id/connect-ticket", s.createACPConnectTicket)
 	secured.POST("/spritzes/:name/ssh", s.mintSSHCert)
+	secured.POST("/spritzes/:name/ssh/code", s.mintSSHCode)
+	secured.PUT("/spritzes/:name/ssh/principals", s.updateSSHPrincipals)
 	if s.terminal.enabled {
 		secured.POST("/spritzes/:name/terminal/connect-ticket", s.createTerminalConnectTicket)
 		secured.GET("/spritzes/:name/terminal/sessions", s.listTerminalSessions)
@@ -416,7 +486,7 @@ func (s *server) suggestSpritzName(c echo.Context) error {
 
 	var body suggestNameRequest
 	if err := c.Bind(&body); err != nil {
-		return writeError(c, http.StatusBadRequest, "invalid json")
+		return writeBindError(c, err)
 	}
 	s.applyProvisionerDefaultSuggestNamePreset(&body, principal)
 	metadata, err := s.resolveSuggestNameMetadata(body)
@@ -452,7 +522,7 @@ func (s *server) createSpritz(c echo.Context) error {
 
 	var body createRequest
 	if err := c.Bind(&body); err != nil {
-		return writeError(c, http.StatusBadRequest, "invalid json")
+		return writeBindError(c, err)
 	}
 	allowReplacementAnnotations, _ := c.Get(allowReplacementAnnotationsContextKey).(bool)
 	normalized, err := s.normalizeCreateRequest(
@@ -464,6 +534,14 @@ func (s *server) createSpritz(c echo.Context) error {
 	if err != nil {
 		return writeCreateRequestError(c, err)
 	}
+	// Charge the create limit only for requests that passed validation.
+	if !principal.isAdminPrincipal() {
+		if allowed, retryAfter := s.createLimiter.Allow(principal.ID); !allowed {
+			log.Printf("spritz create: rate limit user_id=%s retry_after=%s", principal.ID, retryAfter)
+			c.Response().Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+			return writeError(c, http.StatusTooManyRequests, "rate limit exceeded")
+		}
+	}
 	body = normalized.body
 	namespace := normalized.namespace
 	owner := normalized.owner
@@ -558,9 +636,10 @@ func (s *server) createSpritz(c echo.Context) error {
 	}
 	resolvedProfile := s.resolveAgentProfile(c.Request().Context(), principal, namespace, &body)
 
-	labels := map[string]string{
+	policyLabels, policyAnnotations := s.metadataPolicy.defaults(owner.ID, metadataPolicyTeams(principal, owner.ID))
+	labels := mergeStringMap(policyLabels, map[string]string{
 		ownerLabelKey: ownerLabelValue(owner.ID),
-	}
+	})
 	if principal.isService() {
 		labels[actorLabelKey] = actorLabelValue(principal.ID)
 		labels[idempotencyLabelKey] = idempotencyLabelValue(body.IdempotencyKey)
@@ -571,7 +650,7 @@ func (s *server) createSpritz(c echo.Context) error {
 	for k, v := range body.Labels {
 		labels[k] = v
 	}
-	annotations := mergeStringMap(s.defaultMetadata, body.Annotations)
+	annotations := mergeStringMap(mergeStringMap(s.defaultMetadata, policyAnnotations), body.Annotations)
 	if len(userConfigKeys) > 0 {
 		encoded, err := encodeUserConfig(userConfigKeys, userConfigPayload)
 		if err != nil {
@@ -601,13 +680,16 @@ func (s *server) createSpritz(c echo.Context) error {
 		annotations = mergeStringMap(annotations, externalOwnerAnnotations)
 	}
 
-	applySSHDefaults(&body.Spec, s.sshDefaults, namespace)
+	spritzv1.ApplySSHDefaults(&body.Spec, s.sshDefaults, namespace)
 	baseSpec := body.Spec
 
 	createSpritzResource := func(name string) (*spritzv1.Spritz, error) {
 		var spec spritzv1.SpritzSpec
 		baseSpec.DeepCopyInto(&spec)
-		applyIngressDefaults(&spec, name, namespace, s.ingressDefaults)
+		spritzv1.ApplyIngressDefaults(&spec, s.ingressDefaults)
+		if err := spritzv1.ExpandSpecTemplates(&spec, spritzv1.TemplateVars{Name: name, Namespace: namespace, Owner: owner.ID}); err != nil {
+			return nil, err
+		}
 		if spec.Ingress != nil && strings.EqualFold(spec.Ingress.Mode, "gateway") && spec.Ingress.Host == "" {
 			return nil, fmt.Errorf("spec.ingress.host is required when spec.ingress.mode=gateway")
 		}
@@ -753,7 +835,7 @@ func (s *server) listSpritzes(c echo.Context) error {
 	if s.auth.enabled() {
 		filtered := make([]spritzv1.Spritz, 0, len(list.Items))
 		for _, item := range list.Items {
-			if err := authorizeHumanOwnedAccess(principal, item.Spec.Owner.ID, true); err == nil {
+			if err := authorizeNamespacedOwnerAccess(principal, item.Namespace, item.Spec.Owner.ID, true); err == nil {
 				filtered = append(filtered, item)
 			}
 		}
@@ -788,11 +870,11 @@ func (s *server) getSpritz(c echo.Context) error {
 	if err := s.client.Get(c.Request().Context(), client.ObjectKey{Name: name, Namespace: namespace}, spritz); err != nil {
 		return writeError(c, http.StatusNotFound, err.Error())
 	}
-	if err := authorizeHumanOwnedAccess(principal, spritz.Spec.Owner.ID, s.auth.enabled()); err != nil {
+	if err := authorizeNamespacedOwnerAccess(principal, spritz.Namespace, spritz.Spec.Owner.ID, s.auth.enabled()); err != nil {
 		return writeError(c, http.StatusForbidden, "forbidden")
 	}
 
-	return writeJSON(c, http.StatusOK, spritz)
+	return writeJSON(c, http.StatusOK, spritzResponse{Spritz: spritz, SSHAccess: s.sshAccess(spritz)})
 }
 
 func (s *server) updateUserConfig(c echo.Context) error {
@@ -898,6 +980,10 @@ func (s *server) deleteSpritz(c echo.Context) error {
 	if err := authorizeHumanOnly(principal, s.auth.enabled()); err != nil {
 		return writeForbidden(c)
 	}
+	keepStorage, err := parseKeepStorage(c.QueryParam("keepStorage"))
+	if err != nil {
+		return writeError(c, http.StatusBadRequest, err.Error())
+	}
 
 	namespace := s.namespace
 	if namespace == "" {
@@ -911,10 +997,20 @@ func (s *server) deleteSpritz(c echo.Context) error {
 	if err := s.client.Get(c.Request().Context(), client.ObjectKey{Name: name, Namespace: namespace}, spritz); err != nil {
 		return writeError(c, http.StatusNotFound, err.Error())
 	}
-	if err := authorizeHumanOwnedAccess(principal, spritz.Spec.Owner.ID, s.auth.enabled()); err != nil {
+	if err := authorizeNamespacedOwnerAccess(principal, spritz.Namespace, spritz.Spec.Owner.ID, s.auth.enabled()); err != nil {
 		return writeError(c, http.StatusForbidden, "forbidden")
 	}
 
+	if keepStorage {
+		retained, err := s.retainSpritzStorage(c.Request().Context(), spritz)
+		if err != nil {
+			return writeError(c, http.StatusInternalServerError, err.Error())
+		}
+		if len(retained) > 0 {
+			log.Printf("spritz delete: retained claims name=%s namespace=%s claims=%s", name, namespace, strings.Join(retained, ","))
+		}
+	}
+
 	if err := s.client.Delete(c.Request().Context(), spritz); err != nil {
 		return writeError(c, http.StatusInternalServerError, err.Error())
 	}
@@ -938,6 +1034,30 @@ func validateRepoDir(dir string) error {
 	return nil
 }
 
+func validateRepoPostClone(commands []string) error {
+	for _, command := range commands {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("spec.repo.postClone entries must not be empty")
+		}
+	}
+	return nil
+}
+
+func validateRepoWorktrees(field string, worktrees []spritzv1.SpritzRepoWorktree) error {
+	for _, worktree := range worktrees {
+		if strings.TrimSpace(worktree.Branch) == "" {
+			return fmt.Errorf("%s entries require a branch", field)
+		}
+		if strings.TrimSpace(worktree.Dir) == "" {
+			return fmt.Errorf("%s entries require a dir", field)
+		}
+		if validateRepoDir(worktree.Dir) != nil {
+			return fmt.Errorf("%s dirs must be under /workspace", field)
+		}
+	}
+	return nil
+}
+
 func writeJSON(c echo.Context, status int, payload any) error {
 	return writeJSendSuccess(c, status, payload)
 }
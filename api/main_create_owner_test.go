@@ -96,6 +96,234 @@ func TestCreateSpritzRejectsOwnerIDMismatchForNonAdmin(t *testing.T) {
 	}
 }
 
+func TestCreateSpritzReservesImageDigestExemptionForAdmins(t *testing.T) {
+	s := newCreateSpritzTestServer(t)
+	grants, err := parseAdminNamespaceGrants(`[{"ids":["ns-lead"],"namespaces":["spritz-test"]}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.auth.adminNamespaces = grants
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	// Namespace admins are not cluster admins, so they cannot lift the
+	// cluster-wide digest policy either.
+	for _, userID := range []string{"current-user", "ns-lead"} {
+		body := []byte(`{"name":"tidal-ember","annotations":{"spritz.sh/image-digest-exempt":"true"},"spec":{"image":"example.com/spritz:latest"}}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Spritz-User-Id", userID)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("expected status 403 for %s, got %d: %s", userID, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCreateSpritzReservesPausedAnnotationForAdmins(t *testing.T) {
+	s := newCreateSpritzTestServer(t)
+	grants, err := parseAdminNamespaceGrants(`[{"ids":["ns-lead"],"namespaces":["spritz-test"]}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.auth.adminNamespaces = grants
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	for _, userID := range []string{"current-user", "ns-lead"} {
+		body := []byte(`{"name":"tidal-ember","annotations":{"spritz.sh/paused":"true"},"spec":{"image":"example.com/spritz:latest"}}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Spritz-User-Id", userID)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("expected status 403 for %s, got %d: %s", userID, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCreateSpritzReservesMaxLifetimeExemptionForAdmins(t *testing.T) {
+	s := newCreateSpritzTestServer(t)
+	grants, err := parseAdminNamespaceGrants(`[{"ids":["ns-lead"],"namespaces":["spritz-test"]}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.auth.adminNamespaces = grants
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	// The ceiling is cluster-wide, so namespace admins cannot lift it.
+	for _, userID := range []string{"current-user", "ns-lead"} {
+		body := []byte(`{"name":"tidal-ember","annotations":{"spritz.sh/max-lifetime-exempt":"true"},"spec":{"image":"example.com/spritz:latest"}}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Spritz-User-Id", userID)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("expected status 403 for %s, got %d: %s", userID, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestCreateSpritzValidatesSecurityProfiles(t *testing.T) {
+	cases := []struct {
+		name     string
+		userID   string
+		spec     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "unconfined seccomp is reserved for admins",
+			spec:     `"seccompProfile":{"type":"Unconfined"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: "reserved for admins",
+		},
+		{
+			name:     "namespace admins cannot set unconfined",
+			userID:   "ns-lead",
+			spec:     `"seccompProfile":{"type":"Unconfined"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: "reserved for admins",
+		},
+		{
+			name:     "unconfined apparmor is reserved for admins",
+			spec:     `"appArmorProfile":{"type":"Unconfined"}`,
+			wantCode: http.StatusForbidden,
+			wantBody: "reserved for admins",
+		},
+		{
+			name:     "hostPath extra volume is reserved for admins",
+			spec:     `"extraVolumes":[{"name":"src","hostPath":{"path":"/srv/src"}}]`,
+			wantCode: http.StatusForbidden,
+			wantBody: "reserved for admins",
+		},
+		{
+			name:     "secret extra volume is reserved for admins",
+			userID:   "ns-lead",
+			spec:     `"extraVolumes":[{"name":"creds","secret":{"secretName":"creds"}}]`,
+			wantCode: http.StatusForbidden,
+			wantBody: "reserved for admins",
+		},
+		{
+			name:     "emptyDir and csi extra volumes are allowed",
+			spec:     `"extraVolumes":[{"name":"scratch","emptyDir":{}},{"name":"models","csi":{"driver":"csi.example.com"}}]`,
+			wantCode: http.StatusCreated,
+			wantBody: "tidal-ember",
+		},
+		{
+			name:     "unknown seccomp type",
+			spec:     `"seccompProfile":{"type":"Strict"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "unsupported spec.seccompProfile.type",
+		},
+		{
+			name:     "localhost apparmor without profile",
+			spec:     `"appArmorProfile":{"type":"Localhost"}`,
+			wantCode: http.StatusBadRequest,
+			wantBody: "localhostProfile is required",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newCreateSpritzTestServer(t)
+			grants, err := parseAdminNamespaceGrants(`[{"ids":["ns-lead"],"namespaces":["spritz-test"]}]`)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			s.auth.adminNamespaces = grants
+			e := echo.New()
+			secured := e.Group("", s.authMiddleware())
+			secured.POST("/api/spritzes", s.createSpritz)
+
+			userID := tc.userID
+			if userID == "" {
+				userID = "current-user"
+			}
+			body := []byte(`{"name":"tidal-ember","spec":{"image":"example.com/spritz:latest",` + tc.spec + `}}`)
+			req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set("X-Spritz-User-Id", userID)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantCode || !strings.Contains(rec.Body.String(), tc.wantBody) {
+				t.Fatalf("expected %d with %q, got %d: %s", tc.wantCode, tc.wantBody, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateSpritzRejectsInvalidRepoInitImage(t *testing.T) {
+	s := newCreateSpritzTestServer(t)
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	body := []byte(`{"name":"tidal-ember","spec":{"image":"example.com/spritz:latest","repos":[{"url":"https://example.com/example-org/app.git"},{"url":"https://example.com/example-org/assets.git","initImage":"Example.com/Git LFS"}]}}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Spritz-User-Id", "current-user")
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "spec.repos[1].initImage is not a valid image reference") {
+		t.Fatalf("expected initImage validation error, got %s", rec.Body.String())
+	}
+}
+
+func TestCreateSpritzRejectsRepoWorktreeOutsideWorkspace(t *testing.T) {
+	s := newCreateSpritzTestServer(t)
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	for _, tc := range []struct {
+		body string
+		want string
+	}{
+		{
+			body: `{"name":"tidal-ember","spec":{"image":"example.com/spritz:latest","repo":{"url":"https://example.com/example-org/app.git","worktrees":[{"branch":"release","dir":"/tmp/release"}]}}}`,
+			want: "spec.repo.worktrees dirs must be under /workspace",
+		},
+		{
+			body: `{"name":"tidal-ember","spec":{"image":"example.com/spritz:latest","repos":[{"url":"https://example.com/example-org/app.git"},{"url":"https://example.com/example-org/lib.git","dir":"/workspace/lib","worktrees":[{"branch":"release"}]}]}}`,
+			want: "spec.repos[1].worktrees entries require a dir",
+		},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader([]byte(tc.body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Spritz-User-Id", "current-user")
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if !strings.Contains(rec.Body.String(), tc.want) {
+			t.Fatalf("expected worktree validation error %q, got %s", tc.want, rec.Body.String())
+		}
+	}
+}
+
 func TestSuggestSpritzNameUsesPrefixFromRequest(t *testing.T) {
 	s := newCreateSpritzTestServer(t)
 	e := echo.New()
@@ -745,6 +973,38 @@ func TestCreateSpritzRejectsProvisionerLowLevelSpecFields(t *testing.T) {
 	}
 }
 
+func TestCreateSpritzRejectsProvisionerCABundleSecret(t *testing.T) {
+	s := newCreateSpritzTestServer(t)
+	configureProvisionerTestServer(s)
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	body := []byte(`{
+		"presetId":"openclaw",
+		"ownerId":"user-123",
+		"idempotencyKey":"discord-ca-bundle",
+		"spec":{
+			"caBundleSecret":{"name":"corp-ca"}
+		}
+	}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Spritz-User-Id", "zenobot")
+	req.Header.Set("X-Spritz-Principal-Type", "service")
+	req.Header.Set("X-Spritz-Principal-Scopes", "spritz.instances.create,spritz.instances.assign_owner")
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "spec.caBundleSecret is not allowed") {
+		t.Fatalf("expected caBundleSecret validation error, got %s", rec.Body.String())
+	}
+}
+
 func TestCreateSpritzRejectsHumanProvidedServiceAccountName(t *testing.T) {
 	s := newCreateSpritzTestServer(t)
 	e := echo.New()
@@ -117,3 +117,28 @@ func TestRegisterRoutesUsesConfiguredAPIPrefix(t *testing.T) {
 		t.Fatalf("expected legacy /api/healthz to return 404 when a custom prefix is configured, got %d", legacyRec.Code)
 	}
 }
+
+func TestRegisterRoutesSupportsNestedAPIPrefix(t *testing.T) {
+	t.Setenv("SPRITZ_ROUTE_API_PATH_PREFIX", "/api/spritz/")
+
+	s := &server{
+		auth:         authConfig{mode: authModeNone},
+		internalAuth: internalAuthConfig{enabled: false},
+		terminal:     terminalConfig{enabled: false},
+		routeModel:   spritzRouteModelFromEnv(),
+	}
+	e := echo.New()
+	s.registerRoutes(e)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spritz/healthz", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected nested api prefix to return 200, got %d", rec.Code)
+	}
+
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/api/spritz/spritzes/demo/terminal/connect-ticket", nil), httptest.NewRecorder())
+	if got := s.terminalConnectPath(c, "demo", ""); got != "/api/spritz/spritzes/demo/terminal" {
+		t.Fatalf("expected terminal connect path under the nested prefix, got %q", got)
+	}
+}
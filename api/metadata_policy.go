@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// metadataPolicyKey is the ConfigMap data key holding the policy document.
+	metadataPolicyKey = "policy.json"
+	// metadataPolicyRetryInterval is how long the loader waits before reading
+	// the ConfigMap again after a watch ends, or between reads when the client
+	// cannot watch.
+	metadataPolicyRetryInterval = 30 * time.Second
+)
+
+// metadataDefaults are labels and annotations added to new spritzes.
+type metadataDefaults struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// metadataPolicyDocument is the JSON stored under policy.json. Team entries
+// are keyed by team name and owner entries by owner ID.
+type metadataPolicyDocument struct {
+	Teams  map[string]metadataDefaults `json:"teams,omitempty"`
+	Owners map[string]metadataDefaults `json:"owners,omitempty"`
+}
+
+// metadataPolicy serves per-team and per-owner metadata defaults from a
+// ConfigMap and keeps them current while the API runs. A missing ConfigMap is
+// an empty policy; an invalid one keeps the last good policy.
+type metadataPolicy struct {
+	client    client.Client
+	namespace string
+	name      string
+
+	mu      sync.RWMutex
+	current metadataPolicyDocument
+}
+
+func newMetadataPolicy(k8sClient client.Client, namespace string) *metadataPolicy {
+	name := strings.TrimSpace(os.Getenv("SPRITZ_METADATA_POLICY_CONFIGMAP"))
+	if name == "" {
+		return nil
+	}
+	if value := strings.TrimSpace(os.Getenv("SPRITZ_METADATA_POLICY_NAMESPACE")); value != "" {
+		namespace = value
+	}
+	return &metadataPolicy{client: k8sClient, namespace: namespace, name: name}
+}
+
+// defaults returns the labels and annotations for a new spritz. Team entries
+// are merged in team name order, then the owner entry, so owner values win.
+func (p *metadataPolicy) defaults(ownerID string, teams []string) (map[string]string, map[string]string) {
+	if p == nil {
+		return nil, nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	sortedTeams := append([]string(nil), teams...)
+	sort.Strings(sortedTeams)
+	var labels, annotations map[string]string
+	for _, team := range sortedTeams {
+		entry, ok := p.current.Teams[team]
+		if !ok {
+			continue
+		}
+		labels = mergeStringMap(labels, entry.Labels)
+		annotations = mergeStringMap(annotations, entry.Annotations)
+	}
+	if entry, ok := p.current.Owners[ownerID]; ok {
+		labels = mergeStringMap(labels, entry.Labels)
+		annotations = mergeStringMap(annotations, entry.Annotations)
+	}
+	return labels, annotations
+}
+
+// run loads the policy and follows changes until ctx is done.
+func (p *metadataPolicy) run(ctx context.Context) {
+	for {
+		if err := p.refresh(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("spritz metadata policy: failed to load configmap=%s namespace=%s err=%v", p.name, p.namespace, err)
+		}
+		p.watchChanges(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(metadataPolicyRetryInterval):
+		}
+	}
+}
+
+func (p *metadataPolicy) refresh(ctx context.Context) error {
+	configMap := &corev1.ConfigMap{}
+	if err := p.client.Get(ctx, clientKey(p.namespace, p.name), configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			p.set(metadataPolicyDocument{})
+			return nil
+		}
+		return err
+	}
+	return p.apply(configMap)
+}
+
+// watchChanges applies ConfigMap events until the watch ends. It returns at
+// once when the client cannot watch, leaving run to poll.
+func (p *metadataPolicy) watchChanges(ctx context.Context) {
+	watcher, ok := p.client.(client.WithWatch)
+	if !ok {
+		return
+	}
+	w, err := watcher.Watch(ctx, &corev1.ConfigMapList{}, client.InNamespace(p.namespace), client.MatchingFieldsSelector{
+		Selector: fields.OneTermEqualSelector("metadata.name", p.name),
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("spritz metadata policy: failed to watch configmap=%s namespace=%s err=%v", p.name, p.namespace, err)
+		}
+		return
+	}
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			configMap, isConfigMap := event.Object.(*corev1.ConfigMap)
+			if !isConfigMap {
+				continue
+			}
+			switch event.Type {
+			case watch.Deleted:
+				p.set(metadataPolicyDocument{})
+			case watch.Added, watch.Modified:
+				if err := p.apply(configMap); err != nil {
+					log.Printf("spritz metadata policy: keeping previous policy configmap=%s namespace=%s err=%v", p.name, p.namespace, err)
+				}
+			}
+		}
+	}
+}
+
+func (p *metadataPolicy) apply(configMap *corev1.ConfigMap) error {
+	document, err := parseMetadataPolicy(configMap.Data[metadataPolicyKey])
+	if err != nil {
+		return err
+	}
+	p.set(document)
+	return nil
+}
+
+func (p *metadataPolicy) set(document metadataPolicyDocument) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = document
+}
+
+func parseMetadataPolicy(raw string) (metadataPolicyDocument, error) {
+	var document metadataPolicyDocument
+	if strings.TrimSpace(raw) == "" {
+		return document, nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&document); err != nil {
+		return metadataPolicyDocument{}, fmt.Errorf("invalid %s: %w", metadataPolicyKey, err)
+	}
+	for _, entries := range []map[string]metadataDefaults{document.Teams, document.Owners} {
+		for key, entry := range entries {
+			for label, value := range entry.Labels {
+				if errs := validation.IsQualifiedName(label); len(errs) > 0 {
+					return metadataPolicyDocument{}, fmt.Errorf("invalid label key %q for %q: %s", label, key, strings.Join(errs, "; "))
+				}
+				if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+					return metadataPolicyDocument{}, fmt.Errorf("invalid label value for %q on %q: %s", label, key, strings.Join(errs, "; "))
+				}
+			}
+		}
+	}
+	return document, nil
+}
+
+// metadataPolicyTeams returns the teams used to pick policy entries. Teams are
+// only known for the caller, so a spritz created on behalf of another owner
+// only gets that owner's entry.
+func metadataPolicyTeams(principal principal, ownerID string) []string {
+	if principal.ID != ownerID {
+		return nil
+	}
+	return principal.Teams
+}
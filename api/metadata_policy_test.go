@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestCreateSpritzMergesMetadataPolicy(t *testing.T) {
+	s := newCreateSpritzTestServer(t)
+	s.auth.headerTeams = "X-Spritz-User-Teams"
+	s.defaultMetadata = map[string]string{"example.com/source": "global", "example.com/cost-center": "shared"}
+	s.metadataPolicy = &metadataPolicy{}
+	s.metadataPolicy.set(metadataPolicyDocument{
+		Teams: map[string]metadataDefaults{
+			"data": {
+				Labels:      map[string]string{"example.com/network": "data"},
+				Annotations: map[string]string{"example.com/cost-center": "data"},
+			},
+		},
+		Owners: map[string]metadataDefaults{
+			"user-1": {Annotations: map[string]string{"example.com/source": "owner"}},
+		},
+	})
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	body := `{"name":"tidy-otter","annotations":{"example.com/source":"request"},"spec":{"image":"example.com/spritz:latest"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader([]byte(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Spritz-User-Id", "user-1")
+	req.Header.Set("X-Spritz-User-Teams", "data")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected create to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	created := &spritzv1.Spritz{}
+	if err := s.client.Get(context.Background(), clientKey("spritz-test", "tidy-otter"), created); err != nil {
+		t.Fatalf("get created spritz: %v", err)
+	}
+	if got := created.Labels["example.com/network"]; got != "data" {
+		t.Fatalf("expected team label, got %q", got)
+	}
+	if got := created.Labels[ownerLabelKey]; got != ownerLabelValue("user-1") {
+		t.Fatalf("expected owner label to be kept, got %q", got)
+	}
+	if got := created.Annotations["example.com/cost-center"]; got != "data" {
+		t.Fatalf("expected team annotation over the global default, got %q", got)
+	}
+	if got := created.Annotations["example.com/source"]; got != "request" {
+		t.Fatalf("expected request annotation to win, got %q", got)
+	}
+}
+
+func TestMetadataPolicyFollowsConfigMapChanges(t *testing.T) {
+	s := newCreateSpritzTestServer(t)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "spritz-metadata-policy", Namespace: "spritz-test"},
+		Data:       map[string]string{metadataPolicyKey: `{"owners":{"user-1":{"labels":{"example.com/tier":"gold"}}}}`},
+	}
+	if err := s.client.Create(context.Background(), configMap); err != nil {
+		t.Fatalf("create configmap: %v", err)
+	}
+	policy := &metadataPolicy{client: s.client, namespace: "spritz-test", name: "spritz-metadata-policy"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go policy.run(ctx)
+
+	waitForLabel := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			labels, _ := policy.defaults("user-1", nil)
+			if labels["example.com/tier"] == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected tier label %q, got %#v", want, labels)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitForLabel("gold")
+
+	configMap.Data[metadataPolicyKey] = `{"owners":{"user-1":{"labels":{"example.com/tier":"silver"}}}}`
+	if err := s.client.Update(context.Background(), configMap); err != nil {
+		t.Fatalf("update configmap: %v", err)
+	}
+	waitForLabel("silver")
+
+	configMap.Data[metadataPolicyKey] = `{"owners":{"user-1":{"labels":{"example.com/tier":"not valid!"}}}}`
+	if err := s.client.Update(context.Background(), configMap); err != nil {
+		t.Fatalf("update configmap: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	waitForLabel("silver")
+}
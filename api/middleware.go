@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -23,6 +25,48 @@ func withRequestLogging() echo.MiddlewareFunc {
 	}
 }
 
+const defaultMaxRequestBytes = 4 << 20
+
+// maxRequestBytes reads SPRITZ_MAX_REQUEST_BYTES; 0 disables the limit.
+func maxRequestBytes() int64 {
+	if strings.TrimSpace(os.Getenv("SPRITZ_MAX_REQUEST_BYTES")) == "" {
+		return defaultMaxRequestBytes
+	}
+	return parseInt64Env("SPRITZ_MAX_REQUEST_BYTES")
+}
+
+// withBodyLimit rejects bodies larger than limit with 413 when the size is
+// declared and caps reads otherwise; writeBindError turns a capped JSON read
+// into 413 as well. Routes matched by skip, such as uploads
+// with their own limit, are left alone.
+func withBodyLimit(limit int64, skip func(echo.Context) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if limit <= 0 || (skip != nil && skip(c)) {
+				return next(c)
+			}
+			req := c.Request()
+			if req.ContentLength > limit {
+				return writeError(c, http.StatusRequestEntityTooLarge, "request body too large")
+			}
+			if req.Body != nil && req.Body != http.NoBody {
+				req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+			}
+			return next(c)
+		}
+	}
+}
+
+// writeBindError answers a failed JSON bind: 413 when withBodyLimit cut off
+// a body that did not declare its size, 400 otherwise.
+func writeBindError(c echo.Context, err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return writeError(c, http.StatusRequestEntityTooLarge, "request body too large")
+	}
+	return writeError(c, http.StatusBadRequest, "invalid json")
+}
+
 func withCORS(cors corsConfig) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
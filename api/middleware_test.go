@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newBodyLimitTestEcho(limit int64) *echo.Echo {
+	e := echo.New()
+	group := e.Group("/api", withBodyLimit(limit, isSharedMountRevisionUpload))
+	read := func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return writeError(c, http.StatusBadRequest, "read failed")
+		}
+		return c.String(http.StatusOK, string(body))
+	}
+	group.POST("/spritzes", read)
+	group.POST("/spritzes/suggest-name", func(c echo.Context) error {
+		var body map[string]any
+		if err := c.Bind(&body); err != nil {
+			return writeBindError(c, err)
+		}
+		return c.NoContent(http.StatusOK)
+	})
+	group.PUT("/internal/v1/shared-mounts/owner/:owner/:mount/revisions/:revision", read)
+	return e
+}
+
+func TestWithBodyLimitRejectsDeclaredOversizedBody(t *testing.T) {
+	e := newBodyLimitTestEcho(8)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/spritzes", strings.NewReader("0123456789")))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/spritzes", strings.NewReader("01234567")))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 at the limit, got %d", rec.Code)
+	}
+}
+
+func TestWithBodyLimitCapsUndeclaredBody(t *testing.T) {
+	e := newBodyLimitTestEcho(8)
+	req := httptest.NewRequest(http.MethodPost, "/api/spritzes/suggest-name", io.MultiReader(strings.NewReader(`{"name":"tidy-otter"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.ContentLength = -1
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected the capped JSON bind to return 413, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/spritzes/suggest-name", strings.NewReader(`{"a":`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected malformed JSON under the limit to return 400, got %d", rec.Code)
+	}
+}
+
+func TestWithBodyLimitSkipsSharedMountUploads(t *testing.T) {
+	e := newBodyLimitTestEcho(8)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/internal/v1/shared-mounts/owner/user-1/config/revisions/r1", strings.NewReader("0123456789")))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected upload to bypass the body limit, got %d", rec.Code)
+	}
+}
+
+func TestMaxRequestBytesDefaultsAndDisables(t *testing.T) {
+	t.Setenv("SPRITZ_MAX_REQUEST_BYTES", "")
+	if got := maxRequestBytes(); got != defaultMaxRequestBytes {
+		t.Fatalf("expected default limit, got %d", got)
+	}
+	t.Setenv("SPRITZ_MAX_REQUEST_BYTES", "0")
+	if got := maxRequestBytes(); got != 0 {
+		t.Fatalf("expected 0 to disable the limit, got %d", got)
+	}
+}
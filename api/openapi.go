@@ -0,0 +1,332 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// openAPIOperation describes one public endpoint. The operation list below is
+// kept by hand; TestOpenAPIOperationsMatchRegisteredRoutes fails when it and
+// registerRoutes drift apart. Request and response schemas are generated from
+// the Go types the handlers bind and write.
+type openAPIOperation struct {
+	method      string
+	path        string
+	summary     string
+	query       []openAPIParam
+	request     any
+	status      int
+	response    any
+	description string
+}
+
+type openAPIParam struct {
+	name        string
+	description string
+}
+
+var namespaceParam = openAPIParam{name: "namespace", description: "Namespace of the spritz when the API is not bound to one."}
+
+var openAPIOperations = []openAPIOperation{
+	{method: http.MethodGet, path: "/presets", summary: "List presets available to the caller", status: http.StatusOK, response: struct {
+		Items []publicPreset `json:"items"`
+	}{}},
+	{method: http.MethodGet, path: "/spritzes", summary: "List spritzes visible to the caller", query: []openAPIParam{namespaceParam}, status: http.StatusOK, response: spritzv1.SpritzList{}},
+	{method: http.MethodPost, path: "/spritzes", summary: "Create a spritz", request: createRequest{}, status: http.StatusCreated, response: createSpritzResponse{}},
+	{method: http.MethodPost, path: "/spritzes/suggest-name", summary: "Suggest an unused spritz name", request: suggestNameRequest{}, status: http.StatusOK, response: map[string]string{}},
+	{method: http.MethodGet, path: "/spritzes/{name}", summary: "Get a spritz", query: []openAPIParam{namespaceParam}, status: http.StatusOK, response: spritzResponse{}},
+	{method: http.MethodDelete, path: "/spritzes/{name}", summary: "Delete a spritz", query: []openAPIParam{
+		namespaceParam,
+		{name: "keepStorage", description: "When true, volume claims owned by the spritz are detached and survive the deletion."},
+	}, status: http.StatusNoContent},
+	{method: http.MethodGet, path: "/spritzes/{name}/wait", summary: "Wait until a spritz reaches a phase", query: []openAPIParam{
+		namespaceParam,
+		{name: "phase", description: "Phase to wait for. Defaults to Ready."},
+		{name: "timeoutSeconds", description: "Seconds to wait before answering 504. Defaults to 60 and is capped by the server."},
+	}, status: http.StatusOK, response: spritzResponse{}},
+	{method: http.MethodPatch, path: "/spritzes/{name}/user-config", summary: "Update the user-editable subset of a spritz spec", query: []openAPIParam{namespaceParam}, request: userConfigPayload{}, status: http.StatusOK, response: spritzv1.Spritz{}},
+	{method: http.MethodPost, path: "/spritzes/{name}/activity", summary: "Record activity for idle expiry", query: []openAPIParam{namespaceParam}, status: http.StatusOK, response: activityPingResponse{}},
+	{method: http.MethodPost, path: "/spritzes/{name}/transfer", summary: "Transfer a spritz to a new owner (namespace admins only)", query: []openAPIParam{namespaceParam}, request: transferSpritzRequest{}, status: http.StatusOK, response: spritzv1.Spritz{}},
+	{method: http.MethodPost, path: "/spritzes/{name}/ssh", summary: "Mint an SSH certificate for the gateway", query: []openAPIParam{namespaceParam}, request: sshMintRequest{}, status: http.StatusOK, response: sshMintResponse{}},
+	{method: http.MethodPost, path: "/spritzes/{name}/ssh/code", summary: "Mint a one-time SSH gateway code", query: []openAPIParam{namespaceParam}, status: http.StatusOK, response: sshCodeResponse{}},
+	{method: http.MethodPut, path: "/spritzes/{name}/ssh/principals", summary: "Replace the extra SSH principals of a spritz (namespace admins only)", query: []openAPIParam{namespaceParam}, request: updateSSHPrincipalsRequest{}, status: http.StatusOK, response: spritzv1.Spritz{}},
+	{method: http.MethodPost, path: "/spritzes/{name}/terminal/connect-ticket", summary: "Issue a browser connect ticket for the terminal", query: []openAPIParam{namespaceParam}, request: terminalConnectTicketRequest{}, status: http.StatusOK, response: connectTicketResponse{}},
+	{method: http.MethodGet, path: "/spritzes/{name}/terminal/sessions", summary: "List terminal sessions", query: []openAPIParam{namespaceParam}, status: http.StatusOK, response: terminalSessionsResponse{}},
+	{method: http.MethodGet, path: "/spritzes/{name}/terminal", summary: "Open a terminal WebSocket", query: []openAPIParam{
+		namespaceParam,
+		{name: "session", description: "Terminal session to attach to."},
+		{name: "share", description: "With shared sessions enabled, set to true to join the session other principals share instead of a private one."},
+	}, status: http.StatusSwitchingProtocols, description: "Upgrades to a WebSocket. See the terminal WebSocket protocol doc for subprotocols and framing."},
+	{method: http.MethodGet, path: "/spritzes/{name}/port-forward", summary: "Open a port-forward WebSocket", query: []openAPIParam{
+		namespaceParam,
+		{name: "port", description: "Port in the workspace container to forward to."},
+	}, status: http.StatusSwitchingProtocols, description: "Upgrades to a WebSocket that carries the raw TCP stream."},
+	{method: http.MethodPost, path: "/channel-routes/resolve", summary: "Resolve the spritz that serves an external channel (service principals only)", request: channelRouteResolveRequest{}, status: http.StatusOK, response: channelRouteResolveOutput{}},
+	{method: http.MethodPost, path: "/channel-conversations/upsert", summary: "Find or create the ACP conversation for an external channel conversation", request: channelConversationUpsertRequest{}, status: http.StatusOK, response: struct {
+		Created      bool                        `json:"created"`
+		Conversation spritzv1.SpritzConversation `json:"conversation"`
+	}{}, description: "The existing conversation. A new conversation is answered with 201 and the same body."},
+	{method: http.MethodGet, path: "/acp/agents", summary: "List the caller's spritzes that accept ACP conversations", query: []openAPIParam{namespaceParam}, status: http.StatusOK, response: struct {
+		Items []acpAgentResponse `json:"items"`
+	}{}},
+	{method: http.MethodGet, path: "/acp/conversations", summary: "List ACP conversations", query: []openAPIParam{
+		namespaceParam,
+		{name: "spritz", description: "Only list conversations with this spritz."},
+	}, status: http.StatusOK, response: struct {
+		Items []spritzv1.SpritzConversation `json:"items"`
+	}{}},
+	{method: http.MethodPost, path: "/acp/conversations", summary: "Create an ACP conversation", query: []openAPIParam{namespaceParam}, request: createACPConversationRequest{}, status: http.StatusCreated, response: spritzv1.SpritzConversation{}},
+	{method: http.MethodGet, path: "/acp/conversations/{id}", summary: "Get an ACP conversation", query: []openAPIParam{namespaceParam}, status: http.StatusOK, response: spritzv1.SpritzConversation{}},
+	{method: http.MethodPatch, path: "/acp/conversations/{id}", summary: "Update the title or working directory of an ACP conversation", query: []openAPIParam{namespaceParam}, request: updateACPConversationRequest{}, status: http.StatusOK, response: spritzv1.SpritzConversation{}},
+	{method: http.MethodPost, path: "/acp/conversations/{id}/bootstrap", summary: "Bind an ACP conversation to an agent session", query: []openAPIParam{namespaceParam}, status: http.StatusOK, response: acpBootstrapResponse{}},
+	{method: http.MethodPost, path: "/acp/conversations/{id}/connect-ticket", summary: "Issue a browser connect ticket for an ACP conversation", query: []openAPIParam{namespaceParam}, status: http.StatusOK, response: connectTicketResponse{}},
+	{method: http.MethodGet, path: "/acp/conversations/{id}/connect", summary: "Open an ACP conversation WebSocket", query: []openAPIParam{namespaceParam}, status: http.StatusSwitchingProtocols, description: "Upgrades to a WebSocket that carries ACP JSON-RPC messages."},
+}
+
+// openAPIPathParamPattern matches the `{param}` segments of an operation path.
+var openAPIPathParamPattern = regexp.MustCompile(`\{([A-Za-z]+)\}`)
+
+func (s *server) getOpenAPI(c echo.Context) error {
+	return c.JSON(http.StatusOK, buildOpenAPIDocument(s.apiPathPrefix()))
+}
+
+func buildOpenAPIDocument(prefix string) map[string]any {
+	schemas := newOpenAPISchemas()
+	paths := map[string]any{}
+	for _, op := range openAPIOperations {
+		item, _ := paths[op.path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = schemas.operation(op)
+	}
+	schemas.schemas["JSendFail"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"status":  map[string]any{"type": "string", "enum": []string{"fail", "error"}},
+			"message": map[string]any{"type": "string"},
+			"code":    map[string]any{"type": "integer"},
+			"data":    map[string]any{},
+		},
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Spritz API",
+			"version": "v1",
+		},
+		"servers": []any{map[string]any{"url": prefix}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas.schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+		"security": []any{map[string]any{"bearerAuth": []string{}}},
+	}
+}
+
+func (b *openAPISchemas) operation(op openAPIOperation) map[string]any {
+	var params []any
+	for _, match := range openAPIPathParamPattern.FindAllStringSubmatch(op.path, -1) {
+		params = append(params, map[string]any{
+			"name": match[1], "in": "path", "required": true,
+			"schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, param := range op.query {
+		params = append(params, map[string]any{
+			"name": param.name, "in": "query", "description": param.description,
+			"schema": map[string]any{"type": "string"},
+		})
+	}
+
+	success := map[string]any{"description": http.StatusText(op.status)}
+	if op.description != "" {
+		success["description"] = op.description
+	}
+	if op.status != http.StatusNoContent && op.status != http.StatusSwitchingProtocols {
+		data := map[string]any{}
+		if op.response != nil {
+			data = b.schemaFor(reflect.TypeOf(op.response))
+		}
+		success["content"] = jsonContent(map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"status": map[string]any{"type": "string", "enum": []string{"success"}},
+				"data":   data,
+			},
+		})
+	}
+	operation := map[string]any{
+		"summary":     op.summary,
+		"operationId": openAPIOperationID(op),
+		"responses": map[string]any{
+			strconv.Itoa(op.status): success,
+			"default": map[string]any{
+				"description": "JSend fail or error",
+				"content":     jsonContent(map[string]any{"$ref": "#/components/schemas/JSendFail"}),
+			},
+		},
+	}
+	if len(params) > 0 {
+		operation["parameters"] = params
+	}
+	if op.request != nil {
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content":  jsonContent(b.schemaFor(reflect.TypeOf(op.request))),
+		}
+	}
+	return operation
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// openAPIOperationID turns "POST /spritzes/{name}/ssh/code" into
+// "postSpritzesNameSshCode".
+func openAPIOperationID(op openAPIOperation) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(op.method))
+	for _, part := range strings.FieldsFunc(op.path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '{' || r == '}'
+	}) {
+		id.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return id.String()
+}
+
+// openAPISchemas collects component schemas for named struct types.
+type openAPISchemas struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+}
+
+func newOpenAPISchemas() *openAPISchemas {
+	return &openAPISchemas{schemas: map[string]any{}, names: map[reflect.Type]string{}}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	timeType          = reflect.TypeOf(time.Time{})
+	metaTimeType      = reflect.TypeOf(metav1.Time{})
+	quantityType      = reflect.TypeOf(resource.Quantity{})
+	intOrStringType   = reflect.TypeOf(intstr.IntOrString{})
+)
+
+func (b *openAPISchemas) schemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case rawMessageType:
+		return map[string]any{}
+	case timeType, metaTimeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case quantityType:
+		return map[string]any{"type": "string"}
+	case intOrStringType:
+		return map[string]any{"x-kubernetes-int-or-string": true}
+	}
+	// Other custom encodings cannot be described from the Go shape.
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + b.register(t)}
+	}
+	return map[string]any{}
+}
+
+// register names t's component schema, qualifying it with its package when
+// two packages export the same type name.
+func (b *openAPISchemas) register(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := b.schemas[name]; taken {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	b.names[t] = name
+	// Reserve the name before recursing so self-referencing types terminate.
+	b.schemas[name] = map[string]any{}
+	b.schemas[name] = b.structSchema(t)
+	return name
+}
+
+func (b *openAPISchemas) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	b.addStructProperties(t, properties)
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+func (b *openAPISchemas) addStructProperties(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				b.addStructProperties(embedded, properties)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.schemaFor(field.Type)
+	}
+}
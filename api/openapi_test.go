@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestOpenAPIOperationsMatchRegisteredRoutes(t *testing.T) {
+	s := &server{
+		auth:         authConfig{mode: authModeNone},
+		internalAuth: internalAuthConfig{enabled: true},
+		terminal:     terminalConfig{enabled: true},
+		portForward:  portForwardConfig{enabled: true},
+	}
+	e := echo.New()
+	s.registerRoutes(e)
+
+	documented := map[string]bool{}
+	for _, op := range openAPIOperations {
+		documented[op.method+" /api"+openAPIPathParamPattern.ReplaceAllString(op.path, ":$1")] = true
+	}
+	registered := map[string]bool{}
+	for _, route := range e.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		// Service-to-service routes, health and the document itself are
+		// intentionally left out.
+		if route.Method == echo.RouteNotFound || strings.HasPrefix(route.Path, "/api/internal/") ||
+			route.Path == "/api/healthz" || route.Path == "/api/openapi.json" {
+			continue
+		}
+		if !documented[key] {
+			t.Errorf("route %q is registered but missing from openAPIOperations", key)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			t.Errorf("openapi documents %q but no such route is registered", route)
+		}
+	}
+}
+
+func TestOpenAPIDocumentServesGeneratedSchemas(t *testing.T) {
+	s := &server{
+		auth:         authConfig{mode: authModeNone},
+		internalAuth: internalAuthConfig{enabled: false},
+		routeModel:   spritzv1.SharedHostRouteModel{APIPathPrefix: "/api/spritz"},
+	}
+	e := echo.New()
+	s.registerRoutes(e)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spritz/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to decode openapi document: %v", err)
+	}
+	if doc.OpenAPI == "" || len(doc.Servers) != 1 || doc.Servers[0].URL != "/api/spritz" {
+		t.Fatalf("unexpected document header: openapi=%q servers=%#v", doc.OpenAPI, doc.Servers)
+	}
+	if _, ok := doc.Paths["/spritzes/{name}/ssh"]["post"]; !ok {
+		t.Fatal("expected POST /spritzes/{name}/ssh in paths")
+	}
+	for schema, property := range map[string]string{
+		"createRequest":   "spec",
+		"SpritzSpec":      "image",
+		"sshMintResponse": "known_hosts",
+		"spritzResponse":  "sshAccess",
+		"EnvVar":          "valueFrom",
+	} {
+		if _, ok := doc.Components.Schemas[schema].Properties[property]; !ok {
+			t.Fatalf("expected schema %s to have property %q", schema, property)
+		}
+	}
+	// Embedded metadata is inlined the way encoding/json writes it.
+	if _, ok := doc.Components.Schemas["spritzResponse"].Properties["metadata"]; !ok {
+		t.Fatal("expected spritzResponse to inline the spritz fields")
+	}
+
+	// Every $ref must resolve to a component.
+	for _, ref := range strings.Split(rec.Body.String(), `"$ref":"#/components/schemas/`)[1:] {
+		name, _, _ := strings.Cut(ref, `"`)
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Fatalf("dangling schema reference %q", name)
+		}
+	}
+}
@@ -116,7 +116,19 @@ func (s *server) openPortForward(c echo.Context) error {
 		return writeError(c, http.StatusConflict, "spritz not ready")
 	}
 
-	upgrader := websocket.Upgrader{CheckOrigin: s.portForward.allowOrigin}
+	release, ok := s.execSessions.Acquire(execSessionSpritzKey(namespace, name), principal.ID)
+	if !ok {
+		log.Printf("spritz port-forward: session limit name=%s namespace=%s user_id=%s", name, namespace, principal.ID)
+		return writeError(c, http.StatusTooManyRequests, "too many port-forward sessions")
+	}
+	defer release()
+	ctx, done, ok := s.sessionDrain.track(c.Request().Context())
+	if !ok {
+		return writeError(c, http.StatusServiceUnavailable, "server shutting down")
+	}
+	defer done()
+
+	upgrader := websocket.Upgrader{CheckOrigin: s.portForward.allowOrigin, Error: websocketUpgradeError}
 	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
 	if err != nil {
 		return err
@@ -125,7 +137,7 @@ func (s *server) openPortForward(c echo.Context) error {
 		_ = conn.Close()
 	}()
 
-	ctx, cancel := context.WithCancel(c.Request().Context())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	s.startSpritzActivityLoop(ctx, spritz, s.portForward.activityRefresh, "port-forward")
 
@@ -139,8 +151,20 @@ func (s *server) openPortForward(c echo.Context) error {
 		_ = upstream.Close()
 		_ = cleanup.Close()
 	}()
+	// A drain cancels ctx; close both ends so the copy loops return.
+	go func() {
+		<-ctx.Done()
+		if shuttingDown(ctx) {
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, errServerShuttingDown.Error()), time.Now().Add(500*time.Millisecond))
+		}
+		_ = upstream.Close()
+		_ = conn.Close()
+	}()
 
 	if err := proxyWebSocketNetConn(conn, upstream); err != nil {
+		if shuttingDown(ctx) {
+			return nil
+		}
 		if errors.Is(err, context.Canceled) || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
 			return nil
 		}
@@ -330,3 +330,34 @@ func TestOpenPortForwardClosesUpstreamWhenWebSocketCloses(t *testing.T) {
 		t.Fatalf("expected upstream to close after websocket exit: %v", err)
 	}
 }
+
+func TestOpenPortForwardRejectsSessionsOverLimit(t *testing.T) {
+	t.Setenv("SPRITZ_EXEC_SESSION_LIMIT_PER_SPRITZ", "1")
+	s := newCreateSpritzTestServer(t)
+	spritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidal-falcon", Namespace: s.namespace},
+		Spec:       spritzv1.SpritzSpec{Owner: spritzv1.SpritzOwner{ID: "user-1"}},
+	}
+	if err := s.client.Create(context.Background(), spritz); err != nil {
+		t.Fatalf("create spritz: %v", err)
+	}
+	s.portForward = portForwardConfig{enabled: true, containerName: "spritz"}
+	s.findRunningPodFunc = func(ctx context.Context, namespace, name, container string) (*corev1.Pod, error) {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "tidal-falcon-pod", Namespace: namespace}}, nil
+	}
+	s.execSessions = newExecSessionLimiter()
+	if _, ok := s.execSessions.Acquire(execSessionSpritzKey(s.namespace, "tidal-falcon"), "user-1"); !ok {
+		t.Fatal("expected to reserve the only session slot")
+	}
+	e := echo.New()
+	e.GET("/api/spritzes/:name/port-forward", s.openPortForward)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spritzes/tidal-falcon/port-forward?port=3000", nil)
+	req.Header.Set("X-Spritz-User-Id", "user-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 over the session limit, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -41,6 +41,10 @@ const (
 	replacementSourceNSAnnotationKey   = "spritz.sh/replacement-source-namespace"
 	replacementSourceNameAnnotationKey = "spritz.sh/replacement-source-name"
 	replacementIDKeyAnnotationKey      = "spritz.sh/replacement-idempotency-key"
+	imageDigestExemptAnnotationKey     = "spritz.sh/image-digest-exempt"
+	pausedAnnotationKey                = "spritz.sh/paused"
+	maxLifetimeExemptAnnotationKey     = "spritz.sh/max-lifetime-exempt"
+	unrestrictedVolumesAnnotationKey   = "spritz.sh/extra-volumes-unrestricted"
 	actorLabelKey                      = "spritz.sh/actor"
 	idempotencyLabelKey                = "spritz.sh/idempotency"
 	presetLabelKey                     = "spritz.sh/preset"
@@ -381,9 +385,18 @@ func validateProvisionerRequestSurface(body *createRequest, allowReplacementAnno
 	if body.Spec.Repo != nil && body.Spec.Repo.Auth != nil {
 		return fmt.Errorf("spec.repo.auth is not allowed")
 	}
+	if body.Spec.Repo != nil && body.Spec.Repo.InitImage != "" {
+		return fmt.Errorf("spec.repo.initImage is not allowed")
+	}
 	if len(body.Spec.SharedMounts) > 0 {
 		return fmt.Errorf("spec.sharedMounts is not allowed")
 	}
+	if body.Spec.CABundleSecret != nil {
+		return fmt.Errorf("spec.caBundleSecret is not allowed")
+	}
+	if body.Spec.Proxy != nil {
+		return fmt.Errorf("spec.proxy is not allowed")
+	}
 	if !reflect.DeepEqual(body.Spec.Resources, corev1.ResourceRequirements{}) {
 		return fmt.Errorf("spec.resources is not allowed")
 	}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"container/list"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const defaultRateLimitMaxBuckets = 10000
+
+// keyedLimiter keeps one token bucket per key, such as a principal or a
+// spritz. Idle buckets are evicted after bucketTTL, and at most maxBuckets are
+// kept, dropping the least recently used one first, so memory stays bounded as
+// principals and workspaces come and go.
+type keyedLimiter struct {
+	mu              sync.Mutex
+	limit           rate.Limit
+	burst           int
+	bucketTTL       time.Duration
+	cleanupInterval time.Duration
+	maxBuckets      int
+	lastCleanup     time.Time
+	buckets         map[string]*list.Element
+	// recent orders buckets from most to least recently used.
+	recent *list.List
+	now    func() time.Time
+}
+
+type keyedBucket struct {
+	key      string
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// newKeyedLimiter returns a limiter with the given rate and burst per key. A
+// bucketTTL of zero keeps idle buckets until maxBuckets forces them out.
+func newKeyedLimiter(limit rate.Limit, burst int, bucketTTL, cleanupInterval time.Duration, maxBuckets int) *keyedLimiter {
+	if bucketTTL <= 0 {
+		bucketTTL = 0
+		cleanupInterval = 0
+	} else if cleanupInterval <= 0 || cleanupInterval > bucketTTL {
+		cleanupInterval = bucketTTL
+	}
+	if maxBuckets <= 0 {
+		maxBuckets = defaultRateLimitMaxBuckets
+	}
+	return &keyedLimiter{
+		limit:           limit,
+		burst:           burst,
+		bucketTTL:       bucketTTL,
+		cleanupInterval: cleanupInterval,
+		maxBuckets:      maxBuckets,
+		lastCleanup:     time.Now(),
+		buckets:         make(map[string]*list.Element),
+		recent:          list.New(),
+		now:             time.Now,
+	}
+}
+
+// Allow takes one token for key. When the bucket is empty it returns false
+// and how long the caller should wait before the next attempt. A nil limiter
+// or an empty key always allows.
+func (l *keyedLimiter) Allow(key string) (bool, time.Duration) {
+	if l == nil || key == "" {
+		return true, 0
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.take(l.bucket(key, now), now)
+}
+
+// Reserve takes one token for key like Allow and also returns a refund func
+// that gives the token back, for callers that only want to charge work that
+// succeeded. Refund is safe to call when the token was not taken.
+func (l *keyedLimiter) Reserve(key string) (bool, time.Duration, func()) {
+	if l == nil || key == "" {
+		return true, 0, func() {}
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bucket := l.bucket(key, now)
+	allowed, delay := l.take(bucket, now)
+	if !allowed {
+		return false, delay, func() {}
+	}
+	var once sync.Once
+	return true, 0, func() {
+		once.Do(func() { l.refund(bucket) })
+	}
+}
+
+// refund adds one token back to bucket, up to its burst. rate.Limiter cannot
+// set its token count, so the bucket gets a fresh limiter drained to the
+// refunded level.
+func (l *keyedLimiter) refund(bucket *keyedBucket) {
+	if l.limit <= 0 || l.limit == rate.Inf {
+		return
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	tokens := math.Min(float64(l.burst), bucket.limiter.TokensAt(now)+1)
+	limiter := rate.NewLimiter(l.limit, l.burst)
+	drainedAt := now.Add(-time.Duration(tokens / float64(l.limit) * float64(time.Second)))
+	limiter.AllowN(drainedAt, l.burst)
+	bucket.limiter = limiter
+}
+
+// bucket returns the bucket for key, creating it and evicting old buckets as
+// needed. l.mu must be held.
+func (l *keyedLimiter) bucket(key string, now time.Time) *keyedBucket {
+	if l.bucketTTL > 0 && l.cleanupInterval > 0 && now.Sub(l.lastCleanup) >= l.cleanupInterval {
+		l.evictIdle(now)
+		l.lastCleanup = now
+	}
+	element := l.buckets[key]
+	if element == nil {
+		element = l.recent.PushFront(&keyedBucket{key: key, limiter: rate.NewLimiter(l.limit, l.burst)})
+		l.buckets[key] = element
+		for l.maxBuckets > 0 && l.recent.Len() > l.maxBuckets {
+			l.remove(l.recent.Back())
+		}
+	} else {
+		l.recent.MoveToFront(element)
+	}
+	bucket := element.Value.(*keyedBucket)
+	bucket.lastUsed = now
+	return bucket
+}
+
+func (l *keyedLimiter) take(bucket *keyedBucket, now time.Time) (bool, time.Duration) {
+	reservation := bucket.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Minute
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// evictIdle drops buckets unused for bucketTTL. Buckets are ordered by use,
+// so the walk stops at the first one that is still fresh.
+func (l *keyedLimiter) evictIdle(now time.Time) {
+	for element := l.recent.Back(); element != nil; element = l.recent.Back() {
+		if now.Sub(element.Value.(*keyedBucket).lastUsed) < l.bucketTTL {
+			return
+		}
+		l.remove(element)
+	}
+}
+
+func (l *keyedLimiter) remove(element *list.Element) {
+	bucket := l.recent.Remove(element).(*keyedBucket)
+	delete(l.buckets, bucket.key)
+}
+
+func retryAfterSeconds(delay time.Duration) string {
+	seconds := int(math.Ceil(delay.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestKeyedLimiterBoundsBuckets(t *testing.T) {
+	limiter := newKeyedLimiter(rate.Every(time.Hour), 1, 0, 0, 100)
+	if ok, _ := limiter.Allow("keep"); !ok {
+		t.Fatal("expected first call to be allowed")
+	}
+	for i := 0; i < 1000; i++ {
+		limiter.Allow(fmt.Sprintf("key-%d", i))
+		limiter.Allow("keep")
+	}
+	if got := len(limiter.buckets); got != 100 {
+		t.Fatalf("expected buckets capped at 100, got %d", got)
+	}
+	if got := limiter.recent.Len(); got != 100 {
+		t.Fatalf("expected LRU list of 100, got %d", got)
+	}
+	if ok, _ := limiter.Allow("keep"); ok {
+		t.Fatal("expected recently used bucket to keep its exhausted state")
+	}
+	if _, ok := limiter.buckets["key-0"]; ok {
+		t.Fatal("expected least recently used bucket to be evicted")
+	}
+}
+
+func TestKeyedLimiterEvictsIdleBuckets(t *testing.T) {
+	limiter := newKeyedLimiter(rate.Every(time.Hour), 1, time.Minute, time.Minute, 0)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	limiter.lastCleanup = now
+	for i := 0; i < 10; i++ {
+		limiter.Allow(fmt.Sprintf("key-%d", i))
+	}
+	now = now.Add(2 * time.Minute)
+	ok, retryAfter := limiter.Allow("fresh")
+	if !ok || retryAfter != 0 {
+		t.Fatalf("expected new key to be allowed, got ok=%v retry_after=%s", ok, retryAfter)
+	}
+	if got := len(limiter.buckets); got != 1 {
+		t.Fatalf("expected idle buckets to be evicted, got %d buckets", got)
+	}
+	if ok, retryAfter := limiter.Allow("fresh"); ok || retryAfter != time.Hour {
+		t.Fatalf("expected empty bucket to wait 1h, got ok=%v retry_after=%s", ok, retryAfter)
+	}
+}
+
+func TestKeyedLimiterReserveRefundsToken(t *testing.T) {
+	limiter := newKeyedLimiter(rate.Every(time.Minute), 2, time.Hour, time.Hour, 0)
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	if ok, _, _ := limiter.Reserve("ns/a"); !ok {
+		t.Fatal("expected first reserve to be allowed")
+	}
+	ok, _, refund := limiter.Reserve("ns/a")
+	if !ok {
+		t.Fatal("expected second reserve to use the burst")
+	}
+	if ok, retryAfter, _ := limiter.Reserve("ns/a"); ok || retryAfter != time.Minute {
+		t.Fatalf("expected empty bucket to wait 1m, got ok=%v retry_after=%s", ok, retryAfter)
+	}
+	refund()
+	refund()
+	if ok, _, _ := limiter.Reserve("ns/a"); !ok {
+		t.Fatal("expected refunded token to be reusable")
+	}
+	if ok, _, _ := limiter.Reserve("ns/a"); ok {
+		t.Fatal("expected refund to return exactly one token")
+	}
+	now = now.Add(time.Minute)
+	if ok, _, _ := limiter.Reserve("ns/a"); !ok {
+		t.Fatal("expected bucket to refill after the interval")
+	}
+}
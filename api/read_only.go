@@ -0,0 +1,74 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	sshserver "github.com/gliderlabs/ssh"
+	"github.com/labstack/echo/v4"
+)
+
+const readOnlyMessage = "spritz API is in read-only maintenance mode; changes are disabled, try again later"
+
+// readOnlyAllowedRoutes are mutating routes that stay open in read-only mode
+// because they do not create or delete spritz resources or change a spec.
+// Activity pings only write status.lastActivityAt and are kept so idle TTLs
+// do not expire workspaces during maintenance.
+var readOnlyAllowedRoutes = map[string]bool{
+	"POST /spritzes/suggest-name":                true,
+	"POST /channel-routes/resolve":               true,
+	"POST /spritzes/:name/activity":              true,
+	"POST /acp/conversations/:id/connect-ticket": true,
+	"POST /internal/v1/auth/invalidate":          true,
+}
+
+// readOnlyBlockedRoutes are GET routes that are closed in read-only mode
+// because they exec into the workspace or open a stream to it.
+var readOnlyBlockedRoutes = map[string]bool{
+	"GET /spritzes/:name/terminal":     true,
+	"GET /spritzes/:name/port-forward": true,
+}
+
+// readOnlyAllows reports whether a request to route, relative to the API
+// path prefix, may run while SPRITZ_API_READ_ONLY is on.
+func readOnlyAllows(method, route string) bool {
+	key := method + " " + route
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return !readOnlyBlockedRoutes[key]
+	default:
+		return readOnlyAllowedRoutes[key]
+	}
+}
+
+// withReadOnly answers mutating API requests with 503 while reads keep
+// working, so the API can stay up during cluster migrations.
+func withReadOnly(enabled bool, prefix string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !enabled {
+				return next(c)
+			}
+			route := c.Path()
+			if prefix != "/" {
+				route = strings.TrimPrefix(route, prefix)
+			}
+			if readOnlyAllows(c.Request().Method, route) {
+				return next(c)
+			}
+			return writeError(c, http.StatusServiceUnavailable, readOnlyMessage)
+		}
+	}
+}
+
+// rejectSSHReadOnly refuses SSH gateway logins while SPRITZ_API_READ_ONLY is
+// on. Shells and port forwards both need a login, so this closes them along
+// with the HTTP terminal and port-forward routes.
+func (s *server) rejectSSHReadOnly(ctx sshserver.Context) bool {
+	if !s.readOnly {
+		return false
+	}
+	log.Printf("spritz ssh: auth failed user=%s remote=%s reason=read-only", ctx.User(), ctx.RemoteAddr())
+	return true
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestReadOnlyAllows(t *testing.T) {
+	cases := []struct {
+		method string
+		route  string
+		want   bool
+	}{
+		{http.MethodGet, "/spritzes", true},
+		{http.MethodGet, "/spritzes/:name", true},
+		{http.MethodPost, "/spritzes", false},
+		{http.MethodDelete, "/spritzes/:name", false},
+		{http.MethodPatch, "/spritzes/:name/user-config", false},
+		{http.MethodPost, "/spritzes/:name/ssh", false},
+		{http.MethodPost, "/spritzes/:name/terminal/connect-ticket", false},
+		{http.MethodGet, "/spritzes/:name/terminal", false},
+		{http.MethodGet, "/spritzes/:name/port-forward", false},
+		{http.MethodPut, "/internal/v1/bindings/:bindingKey", false},
+		{http.MethodPost, "/spritzes/suggest-name", true},
+		{http.MethodPost, "/spritzes/:name/activity", true},
+	}
+	for _, tc := range cases {
+		if got := readOnlyAllows(tc.method, tc.route); got != tc.want {
+			t.Fatalf("%s %s: expected %v, got %v", tc.method, tc.route, tc.want, got)
+		}
+	}
+}
+
+func TestWithReadOnlyBlocksMutationsUnderAPIPrefix(t *testing.T) {
+	e := echo.New()
+	group := e.Group("/api/spritz", withReadOnly(true, "/api/spritz"))
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	group.GET("/spritzes/:name", ok)
+	group.POST("/spritzes", ok)
+	group.POST("/spritzes/:name/activity", ok)
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/api/spritz/spritzes/tidy-otter", http.StatusOK},
+		{http.MethodPost, "/api/spritz/spritzes", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/spritz/spritzes/tidy-otter/activity", http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d: %s", tc.method, tc.path, tc.want, rec.Code, rec.Body.String())
+		}
+		if tc.want == http.StatusServiceUnavailable && !strings.Contains(rec.Body.String(), "read-only maintenance mode") {
+			t.Fatalf("expected maintenance message, got %s", rec.Body.String())
+		}
+	}
+}
+
+func TestSSHGatewayRejectsLoginsInReadOnlyMode(t *testing.T) {
+	s, addr := newSSHCodeTestServer(t, func(s *server) { s.readOnly = true })
+	principalName := formatSSHPrincipal("spritz", "spritz-test", "ssh-instance")
+	code, _, err := s.connectTickets.issue(context.Background(), connectTicketRecord{
+		Type:        connectTicketTypeSSHCode,
+		ConnectPath: principalName,
+		Principal:   principal{ID: "user-123"},
+		ExpiresAt:   time.Now().UTC().Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("issue code: %v", err)
+	}
+
+	if err := dialSSHWithCode(addr, principalName, code); err == nil {
+		t.Fatal("expected ssh login to be rejected in read-only mode")
+	}
+	if _, err := s.connectTickets.consume(context.Background(), code, func(connectTicketRecord) error { return nil }); err != nil {
+		t.Fatalf("expected rejected code to stay unused, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errServerShuttingDown is the cancel cause of sessions ended by a drain.
+var errServerShuttingDown = errors.New("server shutting down")
+
+// defaultSessionDrainTimeout is how long open sessions may keep running after
+// shutdown starts before they are canceled.
+const defaultSessionDrainTimeout = 10 * time.Second
+
+// sessionDrain tracks terminal, SSH and port-forward sessions, whose hijacked
+// connections http.Server.Shutdown does not wait for. Draining refuses new
+// sessions, gives open ones the grace period to end on their own, then
+// cancels the rest so they can tell their clients, and waits for them.
+type sessionDrain struct {
+	mu       sync.Mutex
+	draining bool
+	grace    time.Duration
+	shutdown chan struct{}
+	active   sync.WaitGroup
+}
+
+func newSessionDrain(grace time.Duration) *sessionDrain {
+	return &sessionDrain{grace: grace, shutdown: make(chan struct{})}
+}
+
+// track registers a session. The returned context is canceled with
+// errServerShuttingDown when draining starts, and done must be called when
+// the session ends. It returns false once draining has begun.
+func (d *sessionDrain) track(parent context.Context) (context.Context, func(), bool) {
+	if d == nil {
+		return parent, func() {}, true
+	}
+	d.mu.Lock()
+	if d.draining {
+		d.mu.Unlock()
+		return nil, nil, false
+	}
+	d.active.Add(1)
+	d.mu.Unlock()
+
+	ctx, cancel := context.WithCancelCause(parent)
+	go func() {
+		select {
+		case <-d.shutdown:
+			cancel(errServerShuttingDown)
+		case <-ctx.Done():
+		}
+	}()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			cancel(nil)
+			d.active.Done()
+		})
+	}, true
+}
+
+// drain refuses new sessions, waits up to the grace period for open ones to
+// end, then cancels the rest and waits until they finish or ctx expires.
+func (d *sessionDrain) drain(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	alreadyDraining := d.draining
+	d.draining = true
+	d.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		d.active.Wait()
+		close(finished)
+	}()
+	if !alreadyDraining {
+		grace := time.NewTimer(d.grace)
+		select {
+		case <-finished:
+			grace.Stop()
+		case <-grace.C:
+		case <-ctx.Done():
+			grace.Stop()
+		}
+		close(d.shutdown)
+	}
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func shuttingDown(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errServerShuttingDown)
+}
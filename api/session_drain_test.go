@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSessionDrainCancelsAndWaitsForSessions(t *testing.T) {
+	drain := newSessionDrain(0)
+	ctx, done, ok := drain.track(context.Background())
+	if !ok {
+		t.Fatal("expected session to be tracked before draining")
+	}
+	go func() {
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		done()
+	}()
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := drain.drain(drainCtx); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	if !shuttingDown(ctx) {
+		t.Fatalf("expected session context to carry the shutdown cause, got %v", context.Cause(ctx))
+	}
+	if _, _, ok := drain.track(context.Background()); ok {
+		t.Fatal("expected new sessions to be refused while draining")
+	}
+}
+
+func TestSessionDrainWaitsForGracePeriodBeforeCanceling(t *testing.T) {
+	drain := newSessionDrain(time.Hour)
+	ctx, done, _ := drain.track(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		done()
+	}()
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := drain.drain(drainCtx); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	if shuttingDown(ctx) {
+		t.Fatal("expected a session that ended within the grace period not to be canceled")
+	}
+}
+
+func TestSessionDrainCancelsAfterGracePeriod(t *testing.T) {
+	drain := newSessionDrain(20 * time.Millisecond)
+	ctx, done, _ := drain.track(context.Background())
+	go func() {
+		<-ctx.Done()
+		done()
+	}()
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := drain.drain(drainCtx); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	if !shuttingDown(ctx) {
+		t.Fatalf("expected the session to be canceled after the grace period, got %v", context.Cause(ctx))
+	}
+}
+
+func TestSessionDrainGivesUpAtDeadline(t *testing.T) {
+	drain := newSessionDrain(0)
+	if _, _, ok := drain.track(context.Background()); !ok {
+		t.Fatal("expected session to be tracked")
+	}
+	drainCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := drain.drain(drainCtx); err == nil {
+		t.Fatal("expected drain to report sessions that never finished")
+	}
+}
+
+func TestSessionDrainLeavesFinishedSessionsAlone(t *testing.T) {
+	drain := newSessionDrain(0)
+	ctx, done, _ := drain.track(context.Background())
+	done()
+	if shuttingDown(ctx) {
+		t.Fatal("expected a finished session not to report shutdown")
+	}
+	if err := drain.drain(context.Background()); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+}
+
+func TestServeTerminalViewerSendsGoingAwayOnShutdown(t *testing.T) {
+	conn, clientConn := dialTerminalTestConn(t)
+	drain := newSessionDrain(0)
+	ctx, done, _ := drain.track(context.Background())
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer done()
+		serveTerminalViewer(ctx, newTerminalHub(), conn, 0)
+	}()
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := drain.drain(drainCtx); err != nil {
+		t.Fatalf("drain failed: %v", err)
+	}
+	<-finished
+	if _, _, err := clientConn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("expected going-away close, got %v", err)
+	}
+}
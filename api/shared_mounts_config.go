@@ -12,11 +12,20 @@ import (
 type sharedMountsConfig struct {
 	enabled          bool
 	prefix           string
+	storageBackend   string
 	rcloneRemote     string
 	rcloneConfigPath string
 	bucket           string
+	s3Endpoint       string
+	s3Region         string
+	s3ForcePathStyle bool
 	mounts           map[string]sharedmounts.MountSpec
 	maxBundleBytes   int64
+	// startupCheck lists the store prefix at boot and refuses to start when it fails.
+	startupCheck bool
+	// ownerTokenKey, when set, requires syncers to present a token derived for
+	// the owner in the request path instead of the shared internal token.
+	ownerTokenKey string
 }
 
 func newSharedMountsConfig() (sharedMountsConfig, error) {
@@ -41,8 +50,15 @@ func newSharedMountsConfig() (sharedMountsConfig, error) {
 	if !enabled {
 		return sharedMountsConfig{enabled: false}, nil
 	}
-	if remote == "" {
-		return sharedMountsConfig{}, fmt.Errorf("SPRITZ_SHARED_MOUNTS_RCLONE_REMOTE is required when shared mounts are enabled")
+	backend := strings.ToLower(envOrDefault("SPRITZ_SHARED_MOUNTS_STORAGE_BACKEND", sharedMountsBackendRclone))
+	switch backend {
+	case sharedMountsBackendRclone:
+		if remote == "" {
+			return sharedMountsConfig{}, fmt.Errorf("SPRITZ_SHARED_MOUNTS_RCLONE_REMOTE is required when shared mounts are enabled")
+		}
+	case sharedMountsBackendS3:
+	default:
+		return sharedMountsConfig{}, fmt.Errorf("unsupported SPRITZ_SHARED_MOUNTS_STORAGE_BACKEND: %s", backend)
 	}
 	if bucket == "" {
 		return sharedMountsConfig{}, fmt.Errorf("SPRITZ_SHARED_MOUNTS_BUCKET is required when shared mounts are enabled")
@@ -57,11 +73,17 @@ func newSharedMountsConfig() (sharedMountsConfig, error) {
 	return sharedMountsConfig{
 		enabled:          true,
 		prefix:           prefix,
+		storageBackend:   backend,
 		rcloneRemote:     remote,
 		rcloneConfigPath: configPath,
 		bucket:           bucket,
+		s3Endpoint:       strings.TrimSpace(os.Getenv("SPRITZ_SHARED_MOUNTS_S3_ENDPOINT")),
+		s3Region:         strings.TrimSpace(os.Getenv("SPRITZ_SHARED_MOUNTS_S3_REGION")),
+		s3ForcePathStyle: parseBoolEnv("SPRITZ_SHARED_MOUNTS_S3_FORCE_PATH_STYLE", false),
 		mounts:           allowed,
 		maxBundleBytes:   maxBundleBytes,
+		startupCheck:     parseBoolEnv("SPRITZ_SHARED_MOUNTS_STARTUP_CHECK", false),
+		ownerTokenKey:    strings.TrimSpace(os.Getenv("SPRITZ_SHARED_MOUNTS_OWNER_TOKEN_KEY")),
 	}, nil
 }
 
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +19,13 @@ import (
 	"spritz.sh/operator/sharedmounts"
 )
 
+type sharedMountRevision struct {
+	Revision  string `json:"revision"`
+	Size      int64  `json:"size"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	Latest    bool   `json:"latest"`
+}
+
 type sharedMountError struct {
 	status  int
 	message string
@@ -62,7 +71,7 @@ func (s *server) getSharedMountLatest(c echo.Context) error {
 
 	waitSeconds := parseSharedMountWaitSeconds(c)
 	if waitSeconds <= 0 || s.sharedMountsLive == nil {
-		manifest, err := s.fetchSharedMountLatest(c.Request().Context(), ownerID, mountName)
+		manifest, err := s.readSharedMountLatest(c.Request().Context(), ownerID, mountName)
 		if err != nil {
 			if errors.Is(err, errSharedMountNotFound) {
 				return writeError(c, http.StatusNotFound, "not found")
@@ -82,7 +91,7 @@ func (s *server) getSharedMountLatest(c echo.Context) error {
 	ch := s.sharedMountsLive.subscribe(key)
 	defer s.sharedMountsLive.unsubscribe(key, ch)
 
-	manifest, fetchErr := s.fetchSharedMountLatest(c.Request().Context(), ownerID, mountName)
+	manifest, fetchErr := s.readSharedMountLatest(c.Request().Context(), ownerID, mountName)
 	found := fetchErr == nil
 	if fetchErr != nil && !errors.Is(fetchErr, errSharedMountNotFound) {
 		return writeError(c, http.StatusInternalServerError, fetchErr.Error())
@@ -105,7 +114,7 @@ func (s *server) getSharedMountLatest(c echo.Context) error {
 		}
 		return writeError(c, http.StatusNotFound, "not found")
 	case <-ch:
-		latest, err := s.fetchSharedMountLatest(c.Request().Context(), ownerID, mountName)
+		latest, err := s.readSharedMountLatest(c.Request().Context(), ownerID, mountName)
 		if err != nil {
 			if errors.Is(err, errSharedMountNotFound) {
 				return writeError(c, http.StatusNotFound, "not found")
@@ -129,7 +138,23 @@ func (s *server) getSharedMountRevision(c echo.Context) error {
 		return writeError(c, http.StatusBadRequest, err.Error())
 	}
 	objectPath := s.sharedMountsStore.revisionPath(ownerID, mountName, revision)
+	size, err := s.sharedMountsStore.objectSize(c.Request().Context(), objectPath)
+	if err != nil {
+		if errors.Is(err, errSharedMountNotFound) {
+			return writeError(c, http.StatusNotFound, "not found")
+		}
+		return writeError(c, http.StatusInternalServerError, err.Error())
+	}
+	// Only the latest manifest records a checksum, so older revisions are served
+	// without an ETag.
+	if latest, err := s.readSharedMountLatest(c.Request().Context(), ownerID, mountName); err == nil && latest.Revision == revision {
+		c.Response().Header().Set("ETag", strconv.Quote(latest.Checksum))
+		if etagMatches(c.Request().Header.Get("If-None-Match"), latest.Checksum) {
+			return c.NoContent(http.StatusNotModified)
+		}
+	}
 	c.Response().Header().Set("Content-Type", "application/gzip")
+	c.Response().Header().Set("Content-Length", strconv.FormatInt(size, 10))
 	if err := s.sharedMountsStore.streamObject(c.Request().Context(), objectPath, c.Response().Writer); err != nil {
 		if errors.Is(err, errSharedMountNotFound) {
 			return writeError(c, http.StatusNotFound, "not found")
@@ -139,6 +164,63 @@ func (s *server) getSharedMountRevision(c echo.Context) error {
 	return nil
 }
 
+// etagMatches reports whether an If-None-Match header names the given checksum.
+func etagMatches(header, checksum string) bool {
+	if checksum == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if strings.Trim(candidate, "\"") == checksum {
+			return true
+		}
+	}
+	return false
+}
+
+// listSharedMountRevisions returns stored revisions, newest first, so callers can
+// pick one to roll back to by pointing latest at it.
+func (s *server) listSharedMountRevisions(c echo.Context) error {
+	ownerID, mountName, err := s.requireSharedMount(c)
+	if err != nil {
+		return writeSharedMountError(c, err)
+	}
+	objects, err := s.sharedMountsStore.listRevisions(c.Request().Context(), ownerID, mountName)
+	if err != nil {
+		return writeError(c, http.StatusInternalServerError, err.Error())
+	}
+	latestRevision := ""
+	if latest, err := s.readSharedMountLatest(c.Request().Context(), ownerID, mountName); err == nil {
+		latestRevision = latest.Revision
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].modTime.After(objects[j].modTime)
+	})
+	revisions := make([]sharedMountRevision, 0, len(objects))
+	for _, object := range objects {
+		name := path.Base(object.key)
+		if strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".tar.gz") {
+			continue
+		}
+		revision := sharedMountRevision{
+			Revision: strings.TrimSuffix(name, ".tar.gz"),
+			Size:     object.size,
+		}
+		if !object.modTime.IsZero() {
+			revision.UpdatedAt = object.modTime.UTC().Format(time.RFC3339)
+		}
+		revision.Latest = revision.Revision == latestRevision
+		revisions = append(revisions, revision)
+	}
+	return writeJSON(c, http.StatusOK, map[string]any{"revisions": revisions})
+}
+
+// isSharedMountRevisionUpload matches bundle uploads, which are bounded by
+// SPRITZ_SHARED_MOUNTS_MAX_BUNDLE_BYTES instead of the global body limit.
+func isSharedMountRevisionUpload(c echo.Context) bool {
+	return c.Request().Method == http.MethodPut && strings.HasSuffix(c.Path(), "/:mount/revisions/:revision")
+}
+
 func (s *server) putSharedMountRevision(c echo.Context) error {
 	ownerID, mountName, err := s.requireSharedMount(c)
 	if err != nil {
@@ -170,20 +252,46 @@ func (s *server) putSharedMountLatest(c echo.Context) error {
 	}
 	var manifest sharedmounts.LatestManifest
 	if err := c.Bind(&manifest); err != nil {
-		return writeError(c, http.StatusBadRequest, "invalid json")
+		return writeBindError(c, err)
+	}
+	if err := sharedmounts.ValidateRevision(manifest.Revision); err != nil {
+		return writeError(c, http.StatusBadRequest, err.Error())
+	}
+	ctx := c.Request().Context()
+	if _, err := s.sharedMountsStore.objectSize(ctx, s.sharedMountsStore.revisionPath(ownerID, mountName, manifest.Revision)); err != nil {
+		if errors.Is(err, errSharedMountNotFound) {
+			return writeError(c, http.StatusBadRequest, "revision not found")
+		}
+		return writeError(c, http.StatusInternalServerError, err.Error())
+	}
+	if strings.TrimSpace(manifest.Checksum) == "" {
+		// A rollback may name only the revision; restore the checksum it was
+		// originally published with.
+		recorded, err := s.readSharedMountHistory(ctx, ownerID, mountName, manifest.Revision)
+		if err != nil && !errors.Is(err, errSharedMountNotFound) {
+			return writeError(c, http.StatusInternalServerError, err.Error())
+		}
+		manifest.Checksum = recorded.Checksum
+	}
+	if strings.TrimSpace(manifest.UpdatedAt) == "" {
+		manifest.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
 	}
 	if err := validateLatestManifest(manifest); err != nil {
 		return writeError(c, http.StatusBadRequest, err.Error())
 	}
-	if err := s.ensureLatestMatch(c.Request().Context(), ownerID, mountName, c.Request()); err != nil {
+	if err := s.ensureLatestMatch(ctx, ownerID, mountName, c.Request()); err != nil {
 		return writeSharedMountError(c, err)
 	}
 	payload, err := json.Marshal(manifest)
 	if err != nil {
 		return writeError(c, http.StatusInternalServerError, err.Error())
 	}
+	historyPath := s.sharedMountsStore.historyPath(ownerID, mountName, manifest.Revision)
+	if err := s.sharedMountsStore.writeObject(ctx, historyPath, bytes.NewReader(payload)); err != nil {
+		return writeError(c, http.StatusInternalServerError, err.Error())
+	}
 	objectPath := s.sharedMountsStore.latestPath(ownerID, mountName)
-	if err := s.sharedMountsStore.writeObject(c.Request().Context(), objectPath, bytes.NewReader(payload)); err != nil {
+	if err := s.sharedMountsStore.writeObject(ctx, objectPath, bytes.NewReader(payload)); err != nil {
 		return writeError(c, http.StatusInternalServerError, err.Error())
 	}
 	if s.sharedMountsLive != nil {
@@ -213,6 +321,17 @@ func (s *server) ownerHasMount(ctx context.Context, ownerID, mountName string) (
 	return false, nil
 }
 
+// readSharedMountLatest serves read-only callers from the coalescing latest cache.
+// Conditional writes use fetchSharedMountLatest directly so they always see the store.
+func (s *server) readSharedMountLatest(ctx context.Context, ownerID, mountName string) (sharedmounts.LatestManifest, error) {
+	if s.sharedMountsLive == nil {
+		return s.fetchSharedMountLatest(ctx, ownerID, mountName)
+	}
+	return s.sharedMountsLive.fetchLatest(ctx, sharedMountLatestKey(ownerID, mountName), func(ctx context.Context) (sharedmounts.LatestManifest, error) {
+		return s.fetchSharedMountLatest(ctx, ownerID, mountName)
+	})
+}
+
 func (s *server) fetchSharedMountLatest(ctx context.Context, ownerID, mountName string) (sharedmounts.LatestManifest, error) {
 	objectPath := s.sharedMountsStore.latestPath(ownerID, mountName)
 	data, err := s.sharedMountsStore.readObject(ctx, objectPath)
@@ -234,6 +353,18 @@ func (s *server) fetchSharedMountLatest(ctx context.Context, ownerID, mountName
 	return manifest, nil
 }
 
+func (s *server) readSharedMountHistory(ctx context.Context, ownerID, mountName, revision string) (sharedmounts.LatestManifest, error) {
+	data, err := s.sharedMountsStore.readObject(ctx, s.sharedMountsStore.historyPath(ownerID, mountName, revision))
+	if err != nil {
+		return sharedmounts.LatestManifest{}, err
+	}
+	var manifest sharedmounts.LatestManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return sharedmounts.LatestManifest{}, err
+	}
+	return manifest, nil
+}
+
 func (s *server) ensureLatestMatch(ctx context.Context, ownerID, mountName string, req *http.Request) error {
 	expected := strings.TrimSpace(req.URL.Query().Get("ifMatchRevision"))
 	if expected == "" {
@@ -1,19 +1,92 @@
 package main
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"spritz.sh/operator/sharedmounts"
+)
+
+const sharedMountLatestFetchTimeout = 30 * time.Second
 
 // sharedMountsLatestNotifier provides a minimal in-process notification mechanism for
 // long-polling shared mount "latest" requests.
 //
-// This intentionally does not persist state; callers should always re-fetch the latest
-// manifest after being notified.
+// It also keeps a short-lived copy of each latest manifest so that many long-pollers
+// waking up for the same owner mount share one store read. notify drops the cached
+// copy; the TTL bounds staleness for writes that land on another API replica.
 type sharedMountsLatestNotifier struct {
-	mu      sync.Mutex
-	waiters map[string]map[chan struct{}]struct{}
+	mu       sync.Mutex
+	waiters  map[string]map[chan struct{}]struct{}
+	cacheTTL time.Duration
+	latest   map[string]*sharedMountLatestEntry
+}
+
+// sharedMountLatestEntry is one in-flight or completed latest read. done is closed
+// once manifest and err are set.
+type sharedMountLatestEntry struct {
+	done      chan struct{}
+	manifest  sharedmounts.LatestManifest
+	err       error
+	fetchedAt time.Time
+}
+
+func newSharedMountsLatestNotifier(cacheTTL time.Duration) *sharedMountsLatestNotifier {
+	return &sharedMountsLatestNotifier{
+		waiters:  map[string]map[chan struct{}]struct{}{},
+		cacheTTL: cacheTTL,
+		latest:   map[string]*sharedMountLatestEntry{},
+	}
 }
 
-func newSharedMountsLatestNotifier() *sharedMountsLatestNotifier {
-	return &sharedMountsLatestNotifier{waiters: map[string]map[chan struct{}]struct{}{}}
+// fetchLatest returns the latest manifest for key, joining an in-flight read or
+// reusing a fresh cached result instead of calling fetch again. Not-found results
+// are cached; other errors are not.
+func (n *sharedMountsLatestNotifier) fetchLatest(
+	ctx context.Context,
+	key string,
+	fetch func(context.Context) (sharedmounts.LatestManifest, error),
+) (sharedmounts.LatestManifest, error) {
+	n.mu.Lock()
+	entry := n.latest[key]
+	if entry != nil {
+		select {
+		case <-entry.done:
+			if (entry.err != nil && !errors.Is(entry.err, errSharedMountNotFound)) || time.Since(entry.fetchedAt) >= n.cacheTTL {
+				entry = nil
+			}
+		default:
+		}
+	}
+	if entry == nil {
+		entry = &sharedMountLatestEntry{done: make(chan struct{})}
+		n.latest[key] = entry
+		n.mu.Unlock()
+		// The read is shared, so it must not be cancelled by the first caller leaving.
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedMountLatestFetchTimeout)
+		entry.manifest, entry.err = fetch(fetchCtx)
+		cancel()
+		entry.fetchedAt = time.Now()
+		close(entry.done)
+		if entry.err != nil && !errors.Is(entry.err, errSharedMountNotFound) {
+			n.mu.Lock()
+			if n.latest[key] == entry {
+				delete(n.latest, key)
+			}
+			n.mu.Unlock()
+		}
+		return entry.manifest, entry.err
+	}
+	n.mu.Unlock()
+
+	select {
+	case <-entry.done:
+		return entry.manifest, entry.err
+	case <-ctx.Done():
+		return sharedmounts.LatestManifest{}, ctx.Err()
+	}
 }
 
 func (n *sharedMountsLatestNotifier) subscribe(key string) chan struct{} {
@@ -49,6 +122,9 @@ func (n *sharedMountsLatestNotifier) notify(key string) {
 	n.mu.Lock()
 	waiters := n.waiters[key]
 	delete(n.waiters, key)
+	// A read that is still in flight may have started before the write, so drop
+	// it too; callers that already joined it keep the old result.
+	delete(n.latest, key)
 	n.mu.Unlock()
 
 	for ch := range waiters {
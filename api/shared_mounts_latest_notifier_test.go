@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"spritz.sh/operator/sharedmounts"
+)
+
+func TestSharedMountsLatestNotifierCoalescesConcurrentReads(t *testing.T) {
+	notifier := newSharedMountsLatestNotifier(time.Minute)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (sharedmounts.LatestManifest, error) {
+		calls.Add(1)
+		<-release
+		return sharedmounts.LatestManifest{Revision: "rev-1", Checksum: "sha256:abc"}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			manifest, err := notifier.fetchLatest(context.Background(), "owner/config", fetch)
+			if err == nil {
+				results[i] = manifest.Revision
+			}
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected one shared store read, got %d", got)
+	}
+	for i, revision := range results {
+		if revision != "rev-1" {
+			t.Fatalf("expected reader %d to get rev-1, got %q", i, revision)
+		}
+	}
+}
+
+func TestSharedMountsLatestNotifierNotifyInvalidatesCache(t *testing.T) {
+	notifier := newSharedMountsLatestNotifier(time.Minute)
+	revision := "rev-1"
+	var calls atomic.Int32
+	fetch := func(context.Context) (sharedmounts.LatestManifest, error) {
+		calls.Add(1)
+		return sharedmounts.LatestManifest{Revision: revision, Checksum: "sha256:abc"}, nil
+	}
+
+	if _, err := notifier.fetchLatest(context.Background(), "owner/config", fetch); err != nil {
+		t.Fatalf("fetchLatest failed: %v", err)
+	}
+	if _, err := notifier.fetchLatest(context.Background(), "owner/config", fetch); err != nil {
+		t.Fatalf("fetchLatest failed: %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected cached read, got %d store reads", got)
+	}
+
+	revision = "rev-2"
+	notifier.notify("owner/config")
+	manifest, err := notifier.fetchLatest(context.Background(), "owner/config", fetch)
+	if err != nil {
+		t.Fatalf("fetchLatest failed: %v", err)
+	}
+	if manifest.Revision != "rev-2" || calls.Load() != 2 {
+		t.Fatalf("expected notify to force a fresh read, got %q after %d reads", manifest.Revision, calls.Load())
+	}
+}
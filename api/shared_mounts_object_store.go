@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	sharedMountsBackendRclone = "rclone"
+	sharedMountsBackendS3     = "s3"
+)
+
+// objectStore is the blob storage behind shared mounts. Keys are bucket-relative
+// object paths. Implementations return errSharedMountNotFound for missing objects,
+// and write must be atomic: readers see either the previous object or the new one.
+type objectStore interface {
+	read(ctx context.Context, key string) ([]byte, error)
+	stream(ctx context.Context, key string, out io.Writer) error
+	write(ctx context.Context, key string, body io.Reader) error
+	stat(ctx context.Context, key string) (objectInfo, error)
+	list(ctx context.Context, prefix string) ([]objectInfo, error)
+	delete(ctx context.Context, key string) error
+	// check does the cheapest listing under prefix to prove the store is reachable.
+	check(ctx context.Context, prefix string) error
+}
+
+type objectInfo struct {
+	key     string
+	size    int64
+	modTime time.Time
+}
+
+func newObjectStore(config sharedMountsConfig) (objectStore, error) {
+	switch config.storageBackend {
+	case "", sharedMountsBackendRclone:
+		return &rcloneObjectStore{
+			remote:     config.rcloneRemote,
+			bucket:     config.bucket,
+			configPath: config.rcloneConfigPath,
+		}, nil
+	case sharedMountsBackendS3:
+		return newS3ObjectStore(context.Background(), config)
+	default:
+		return nil, fmt.Errorf("unsupported shared mounts storage backend: %s", config.storageBackend)
+	}
+}
@@ -1,14 +1,11 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"os/exec"
 	"path"
-	"strings"
 
 	"spritz.sh/operator/sharedmounts"
 )
@@ -16,11 +13,25 @@ import (
 var errSharedMountNotFound = errors.New("shared mount object not found")
 
 type sharedMountsStore struct {
-	config sharedMountsConfig
+	config  sharedMountsConfig
+	objects objectStore
 }
 
-func newSharedMountsStore(config sharedMountsConfig) *sharedMountsStore {
-	return &sharedMountsStore{config: config}
+func newSharedMountsStore(config sharedMountsConfig) (*sharedMountsStore, error) {
+	objects, err := newObjectStore(config)
+	if err != nil {
+		return nil, err
+	}
+	return &sharedMountsStore{config: config, objects: traceObjectStore(objects, config.storageBackend)}, nil
+}
+
+// checkReachable lists the configured prefix so a wrong remote, bucket, or
+// credential fails at boot instead of on the first shared mount request.
+func (s *sharedMountsStore) checkReachable(ctx context.Context) error {
+	if err := s.objects.check(ctx, s.config.prefix); err != nil {
+		return fmt.Errorf("shared mounts store %s is unreachable: %w", s.config.bucket, err)
+	}
+	return nil
 }
 
 func (s *sharedMountsStore) latestPath(ownerID, mount string) string {
@@ -32,64 +43,39 @@ func (s *sharedMountsStore) revisionPath(ownerID, mount, revision string) string
 	return path.Join(sharedmounts.StoragePrefix(s.config.prefix, "owner", ownerID, mount), "revisions", file)
 }
 
-func (s *sharedMountsStore) remotePath(objectPath string) string {
-	return fmt.Sprintf("%s:%s/%s", s.config.rcloneRemote, s.config.bucket, objectPath)
+// historyPath records the manifest each revision was published with, so a
+// rollback can restore its checksum.
+func (s *sharedMountsStore) historyPath(ownerID, mount, revision string) string {
+	file := fmt.Sprintf("%s.json", revision)
+	return path.Join(sharedmounts.StoragePrefix(s.config.prefix, "owner", ownerID, mount), "history", file)
 }
 
-func (s *sharedMountsStore) readObject(ctx context.Context, objectPath string) ([]byte, error) {
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	args := s.rcloneArgs("cat", s.remotePath(objectPath))
-	cmd := exec.CommandContext(ctx, "rclone", args...)
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		if isRcloneNotFound(stderr.String()) {
-			return nil, errSharedMountNotFound
-		}
-		return nil, fmt.Errorf("rclone cat failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+// listRevisions returns the revision bundles stored for a mount.
+func (s *sharedMountsStore) listRevisions(ctx context.Context, ownerID, mount string) ([]objectInfo, error) {
+	prefix := path.Join(sharedmounts.StoragePrefix(s.config.prefix, "owner", ownerID, mount), "revisions") + "/"
+	objects, err := s.objects.list(ctx, prefix)
+	if errors.Is(err, errSharedMountNotFound) {
+		return nil, nil
 	}
-	return stdout.Bytes(), nil
+	return objects, err
 }
 
-func (s *sharedMountsStore) streamObject(ctx context.Context, objectPath string, out io.Writer) error {
-	var stderr bytes.Buffer
-	args := s.rcloneArgs("cat", s.remotePath(objectPath))
-	cmd := exec.CommandContext(ctx, "rclone", args...)
-	cmd.Stdout = out
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		if isRcloneNotFound(stderr.String()) {
-			return errSharedMountNotFound
-		}
-		return fmt.Errorf("rclone cat failed: %w: %s", err, strings.TrimSpace(stderr.String()))
-	}
-	return nil
+func (s *sharedMountsStore) readObject(ctx context.Context, objectPath string) ([]byte, error) {
+	return s.objects.read(ctx, objectPath)
 }
 
-func (s *sharedMountsStore) writeObject(ctx context.Context, objectPath string, body io.Reader) error {
-	var stderr bytes.Buffer
-	args := s.rcloneArgs("rcat", s.remotePath(objectPath))
-	cmd := exec.CommandContext(ctx, "rclone", args...)
-	cmd.Stdin = body
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("rclone rcat failed: %w: %s", err, strings.TrimSpace(stderr.String()))
-	}
-	return nil
+func (s *sharedMountsStore) streamObject(ctx context.Context, objectPath string, out io.Writer) error {
+	return s.objects.stream(ctx, objectPath, out)
 }
 
-func (s *sharedMountsStore) rcloneArgs(args ...string) []string {
-	if s.config.rcloneConfigPath != "" {
-		return append([]string{"--config", s.config.rcloneConfigPath}, args...)
+func (s *sharedMountsStore) objectSize(ctx context.Context, objectPath string) (int64, error) {
+	info, err := s.objects.stat(ctx, objectPath)
+	if err != nil {
+		return 0, err
 	}
-	return args
+	return info.size, nil
 }
 
-func isRcloneNotFound(stderr string) bool {
-	lower := strings.ToLower(stderr)
-	return strings.Contains(lower, "not found") ||
-		strings.Contains(lower, "no such object") ||
-		strings.Contains(lower, "does not exist") ||
-		strings.Contains(lower, "object not found")
+func (s *sharedMountsStore) writeObject(ctx context.Context, objectPath string, body io.Reader) error {
+	return s.objects.write(ctx, objectPath, body)
 }
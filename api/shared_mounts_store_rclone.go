@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+// rcloneObjectStore shells out to the rclone binary, which lets shared mounts use
+// any remote rclone supports.
+type rcloneObjectStore struct {
+	remote     string
+	bucket     string
+	configPath string
+}
+
+func (s *rcloneObjectStore) remotePath(key string) string {
+	return fmt.Sprintf("%s:%s/%s", s.remote, s.bucket, key)
+}
+
+func (s *rcloneObjectStore) read(ctx context.Context, key string) ([]byte, error) {
+	var stdout bytes.Buffer
+	if err := s.run(ctx, "cat", &stdout, nil, "cat", s.remotePath(key)); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+func (s *rcloneObjectStore) stream(ctx context.Context, key string, out io.Writer) error {
+	return s.run(ctx, "cat", out, nil, "cat", s.remotePath(key))
+}
+
+// write uploads to a temporary sibling key and then moves it over the destination,
+// so an interrupted upload never leaves a partial latest.json or revision behind.
+func (s *rcloneObjectStore) write(ctx context.Context, key string, body io.Reader) error {
+	tempKey, err := rcloneTempKey(key)
+	if err != nil {
+		return err
+	}
+	if err := s.run(ctx, "rcat", nil, body, "rcat", s.remotePath(tempKey)); err != nil {
+		_ = s.delete(context.WithoutCancel(ctx), tempKey)
+		return err
+	}
+	if err := s.run(ctx, "moveto", nil, nil, "moveto", s.remotePath(tempKey), s.remotePath(key)); err != nil {
+		_ = s.delete(context.WithoutCancel(ctx), tempKey)
+		return err
+	}
+	return nil
+}
+
+func rcloneTempKey(key string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	dir, file := path.Split(key)
+	return path.Join(dir, fmt.Sprintf(".tmp-%s-%s", hex.EncodeToString(suffix), file)), nil
+}
+
+func (s *rcloneObjectStore) stat(ctx context.Context, key string) (objectInfo, error) {
+	var stdout bytes.Buffer
+	if err := s.run(ctx, "lsjson", &stdout, nil, "lsjson", "--stat", s.remotePath(key)); err != nil {
+		return objectInfo{}, err
+	}
+	var entry struct {
+		Size int64 `json:"Size"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &entry); err != nil {
+		return objectInfo{}, fmt.Errorf("rclone lsjson decode failed: %w", err)
+	}
+	return objectInfo{key: key, size: entry.Size}, nil
+}
+
+func (s *rcloneObjectStore) list(ctx context.Context, prefix string) ([]objectInfo, error) {
+	var stdout bytes.Buffer
+	if err := s.run(ctx, "lsjson", &stdout, nil, "lsjson", "-R", "--files-only", s.remotePath(prefix)); err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		Path    string    `json:"Path"`
+		Size    int64     `json:"Size"`
+		ModTime time.Time `json:"ModTime"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &entries); err != nil {
+		return nil, fmt.Errorf("rclone lsjson decode failed: %w", err)
+	}
+	objects := make([]objectInfo, 0, len(entries))
+	for _, entry := range entries {
+		objects = append(objects, objectInfo{key: path.Join(prefix, entry.Path), size: entry.Size, modTime: entry.ModTime})
+	}
+	return objects, nil
+}
+
+func (s *rcloneObjectStore) delete(ctx context.Context, key string) error {
+	err := s.run(ctx, "deletefile", nil, nil, "deletefile", s.remotePath(key))
+	if errors.Is(err, errSharedMountNotFound) {
+		return nil
+	}
+	return err
+}
+
+// check lists prefix. A prefix that does not exist yet is only accepted when
+// the bucket itself lists, so a wrong remote or bucket still fails.
+func (s *rcloneObjectStore) check(ctx context.Context, prefix string) error {
+	err := s.run(ctx, "lsf", io.Discard, nil, "lsf", "--max-depth", "1", s.remotePath(prefix))
+	if !errors.Is(err, errSharedMountNotFound) {
+		return err
+	}
+	err = s.run(ctx, "lsf", io.Discard, nil, "lsf", "--max-depth", "1", s.remotePath(""))
+	if errors.Is(err, errSharedMountNotFound) {
+		return fmt.Errorf("bucket %s not found on remote %s", s.bucket, s.remote)
+	}
+	return err
+}
+
+func (s *rcloneObjectStore) run(ctx context.Context, op string, stdout io.Writer, stdin io.Reader, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "rclone", s.args(args...)...)
+	cmd.Stdout = stdout
+	cmd.Stdin = stdin
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if isRcloneNotFound(stderr.String()) {
+			return errSharedMountNotFound
+		}
+		return fmt.Errorf("rclone %s failed: %w: %s", op, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (s *rcloneObjectStore) args(args ...string) []string {
+	if s.configPath != "" {
+		return append([]string{"--config", s.configPath}, args...)
+	}
+	return args
+}
+
+func isRcloneNotFound(stderr string) bool {
+	lower := strings.ToLower(stderr)
+	return strings.Contains(lower, "not found") ||
+		strings.Contains(lower, "no such object") ||
+		strings.Contains(lower, "does not exist") ||
+		strings.Contains(lower, "object not found")
+}
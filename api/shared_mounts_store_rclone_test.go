@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeRcloneScript maps "remote:bucket/key" paths onto $FAKE_RCLONE_ROOT. When
+// FAKE_RCLONE_FAIL_RCAT is set, rcat writes part of its input and then fails,
+// like a connection that drops mid-upload.
+const fakeRcloneScript = `#!/bin/sh
+if [ "$1" = "--config" ]; then shift 2; fi
+op="$1"; shift
+local_path() { echo "$FAKE_RCLONE_ROOT/${1#*:}"; }
+case "$op" in
+cat)
+  target=$(local_path "$1")
+  [ -f "$target" ] || { echo "object not found" >&2; exit 3; }
+  cat "$target" ;;
+rcat)
+  target=$(local_path "$1")
+  mkdir -p "$(dirname "$target")"
+  if [ -n "$FAKE_RCLONE_FAIL_RCAT" ]; then
+    head -c 4 > "$target"
+    echo "connection reset" >&2
+    exit 1
+  fi
+  cat > "$target" ;;
+moveto)
+  src=$(local_path "$1"); dst=$(local_path "$2")
+  mkdir -p "$(dirname "$dst")"
+  mv "$src" "$dst" ;;
+lsf)
+  [ -z "$FAKE_RCLONE_UNREACHABLE" ] || { echo "couldn't connect: dial tcp: i/o timeout" >&2; exit 1; }
+  target=$(local_path "$3")
+  [ -d "$target" ] || { echo "directory not found" >&2; exit 3; }
+  ls "$target" ;;
+deletefile)
+  target=$(local_path "$1")
+  [ -f "$target" ] || { echo "object not found" >&2; exit 3; }
+  rm "$target" ;;
+*)
+  echo "unsupported op $op" >&2; exit 1 ;;
+esac
+`
+
+func newFakeRcloneStore(t *testing.T) (*sharedMountsStore, string) {
+	t.Helper()
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "rclone"), []byte(fakeRcloneScript), 0o755); err != nil {
+		t.Fatalf("write fake rclone: %v", err)
+	}
+	root := t.TempDir()
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_RCLONE_ROOT", root)
+	store, err := newSharedMountsStore(sharedMountsConfig{
+		storageBackend: sharedMountsBackendRclone,
+		rcloneRemote:   "shared",
+		bucket:         "example-bucket",
+		prefix:         "spritz-shared",
+	})
+	if err != nil {
+		t.Fatalf("newSharedMountsStore failed: %v", err)
+	}
+	return store, root
+}
+
+func TestRcloneWriteObjectInterruptedKeepsPreviousLatest(t *testing.T) {
+	store, root := newFakeRcloneStore(t)
+	ctx := context.Background()
+	latest := store.latestPath("user-1", "config")
+
+	if err := store.writeObject(ctx, latest, strings.NewReader(`{"revision":"r1"}`)); err != nil {
+		t.Fatalf("initial write failed: %v", err)
+	}
+
+	t.Setenv("FAKE_RCLONE_FAIL_RCAT", "1")
+	if err := store.writeObject(ctx, latest, strings.NewReader(`{"revision":"r2"}`)); err == nil {
+		t.Fatal("expected interrupted write to fail")
+	}
+
+	data, err := store.readObject(ctx, latest)
+	if err != nil {
+		t.Fatalf("read latest failed: %v", err)
+	}
+	if !bytes.Equal(data, []byte(`{"revision":"r1"}`)) {
+		t.Fatalf("expected previous latest to survive an interrupted write, got %q", data)
+	}
+	entries, err := os.ReadDir(filepath.Dir(filepath.Join(root, "example-bucket", latest)))
+	if err != nil {
+		t.Fatalf("read store dir: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".tmp-") {
+			t.Fatalf("expected interrupted temp object to be cleaned up, found %s", entry.Name())
+		}
+	}
+}
+
+func TestRcloneWriteObjectReplacesLatest(t *testing.T) {
+	store, _ := newFakeRcloneStore(t)
+	ctx := context.Background()
+	latest := store.latestPath("user-1", "config")
+
+	for _, body := range []string{`{"revision":"r1"}`, `{"revision":"r2"}`} {
+		if err := store.writeObject(ctx, latest, strings.NewReader(body)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	data, err := store.readObject(ctx, latest)
+	if err != nil {
+		t.Fatalf("read latest failed: %v", err)
+	}
+	if string(data) != `{"revision":"r2"}` {
+		t.Fatalf("expected latest to be replaced, got %q", data)
+	}
+}
+
+func TestSharedMountsStoreCheckReachable(t *testing.T) {
+	store, root := newFakeRcloneStore(t)
+	err := store.checkReachable(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "bucket example-bucket not found") {
+		t.Fatalf("expected a missing bucket to fail the startup check, got %v", err)
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "example-bucket"), 0o755); err != nil {
+		t.Fatalf("create bucket dir: %v", err)
+	}
+	if err := store.checkReachable(context.Background()); err != nil {
+		t.Fatalf("expected a missing prefix under an existing bucket to pass the startup check, got %v", err)
+	}
+
+	t.Setenv("FAKE_RCLONE_UNREACHABLE", "1")
+	err = store.checkReachable(context.Background())
+	if err == nil {
+		t.Fatal("expected unreachable remote to fail the startup check")
+	}
+	if !strings.Contains(err.Error(), "example-bucket is unreachable") {
+		t.Fatalf("expected a clear unreachable error, got %v", err)
+	}
+}
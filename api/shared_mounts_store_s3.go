@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3ObjectStore talks to S3 or any S3-compatible API (GCS interoperability, MinIO)
+// through the AWS SDK. Credentials come from the default AWS chain.
+type s3ObjectStore struct {
+	client *s3.Client
+	bucket string
+}
+
+func newS3ObjectStore(ctx context.Context, config sharedMountsConfig) (*s3ObjectStore, error) {
+	opts := []func(*awsconfig.LoadOptions) error{}
+	if config.s3Region != "" {
+		opts = append(opts, awsconfig.WithRegion(config.s3Region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("load s3 config: %w", err)
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if config.s3Endpoint != "" {
+			o.BaseEndpoint = aws.String(config.s3Endpoint)
+		}
+		o.UsePathStyle = config.s3ForcePathStyle
+		// S3-compatible stores such as GCS reject the SDK's default trailing checksums.
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	})
+	return &s3ObjectStore{client: client, bucket: config.bucket}, nil
+}
+
+func (s *s3ObjectStore) read(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, s3Error("get", err)
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+func (s *s3ObjectStore) stream(ctx context.Context, key string, w io.Writer) error {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	if err != nil {
+		return s3Error("get", err)
+	}
+	defer out.Body.Close()
+	_, err = io.Copy(w, out.Body)
+	return err
+}
+
+// write needs a seekable body so the SDK can sign the payload; other readers are
+// spooled to a temp file first.
+func (s *s3ObjectStore) write(ctx context.Context, key string, body io.Reader) error {
+	seeker, ok := body.(io.ReadSeeker)
+	if !ok {
+		spool, err := os.CreateTemp("", "spritz-shared-upload-*")
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = spool.Close()
+			_ = os.Remove(spool.Name())
+		}()
+		if _, err := io.Copy(spool, body); err != nil {
+			return err
+		}
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		seeker = spool
+	}
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   seeker,
+	})
+	if err != nil {
+		return s3Error("put", err)
+	}
+	return nil
+}
+
+func (s *s3ObjectStore) stat(ctx context.Context, key string) (objectInfo, error) {
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	if err != nil {
+		return objectInfo{}, s3Error("head", err)
+	}
+	return objectInfo{key: key, size: aws.ToInt64(out.ContentLength)}, nil
+}
+
+func (s *s3ObjectStore) list(ctx context.Context, prefix string) ([]objectInfo, error) {
+	objects := []objectInfo{}
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, s3Error("list", err)
+		}
+		for _, item := range page.Contents {
+			objects = append(objects, objectInfo{
+				key:     aws.ToString(item.Key),
+				size:    aws.ToInt64(item.Size),
+				modTime: aws.ToTime(item.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+func (s *s3ObjectStore) delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	if err != nil {
+		if err := s3Error("delete", err); !errors.Is(err, errSharedMountNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *s3ObjectStore) check(ctx context.Context, prefix string) error {
+	_, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.bucket),
+		Prefix:  aws.String(prefix),
+		MaxKeys: aws.Int32(1),
+	})
+	if err != nil {
+		return s3Error("list", err)
+	}
+	return nil
+}
+
+func s3Error(op string, err error) error {
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) && responseErr.HTTPStatusCode() == http.StatusNotFound {
+		return errSharedMountNotFound
+	}
+	return fmt.Errorf("s3 %s failed: %w", op, err)
+}
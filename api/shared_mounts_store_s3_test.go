@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeS3Server implements the path-style subset of the S3 API the object store uses.
+func fakeS3Server(t *testing.T, bucket string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		prefix := "/" + bucket
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			http.Error(w, "unknown bucket", http.StatusNotFound)
+			return
+		}
+		key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		switch {
+		case r.Method == http.MethodGet && key == "" && r.URL.Query().Get("list-type") == "2":
+			type content struct {
+				Key  string `xml:"Key"`
+				Size int64  `xml:"Size"`
+			}
+			result := struct {
+				XMLName  xml.Name  `xml:"ListBucketResult"`
+				Name     string    `xml:"Name"`
+				Contents []content `xml:"Contents"`
+			}{Name: bucket}
+			keys := []string{}
+			for existing := range objects {
+				if strings.HasPrefix(existing, r.URL.Query().Get("prefix")) {
+					keys = append(keys, existing)
+				}
+			}
+			sort.Strings(keys)
+			for _, existing := range keys {
+				result.Contents = append(result.Contents, content{Key: existing, Size: int64(len(objects[existing]))})
+			}
+			w.Header().Set("Content-Type", "application/xml")
+			_ = xml.NewEncoder(w).Encode(result)
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[key] = body
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet || r.Method == http.MethodHead:
+			body, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(body)
+			}
+		case r.Method == http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestS3ObjectStore(t *testing.T) *s3ObjectStore {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "example-access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "example-secret-key")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_CONFIG_FILE", t.TempDir()+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", t.TempDir()+"/credentials")
+	server := fakeS3Server(t, "example-bucket")
+	store, err := newS3ObjectStore(context.Background(), sharedMountsConfig{
+		bucket:           "example-bucket",
+		s3Endpoint:       server.URL,
+		s3Region:         "us-east-1",
+		s3ForcePathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("newS3ObjectStore failed: %v", err)
+	}
+	return store
+}
+
+func TestS3ObjectStoreRoundTrip(t *testing.T) {
+	store := newTestS3ObjectStore(t)
+	ctx := context.Background()
+
+	// A non-seekable body exercises the temp file spool.
+	if err := store.write(ctx, "spritz/owner/user-1/config/latest.json", io.MultiReader(strings.NewReader(`{"revision":"r1"}`))); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := store.write(ctx, "spritz/owner/user-1/config/revisions/r1.tar.gz", bytes.NewReader([]byte("bundle"))); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	data, err := store.read(ctx, "spritz/owner/user-1/config/latest.json")
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(data) != `{"revision":"r1"}` {
+		t.Fatalf("unexpected object body %q", data)
+	}
+	var streamed bytes.Buffer
+	if err := store.stream(ctx, "spritz/owner/user-1/config/revisions/r1.tar.gz", &streamed); err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+	if streamed.String() != "bundle" {
+		t.Fatalf("unexpected streamed body %q", streamed.String())
+	}
+
+	info, err := store.stat(ctx, "spritz/owner/user-1/config/revisions/r1.tar.gz")
+	if err != nil {
+		t.Fatalf("stat failed: %v", err)
+	}
+	if info.size != int64(len("bundle")) {
+		t.Fatalf("expected size %d, got %d", len("bundle"), info.size)
+	}
+
+	listed, err := store.list(ctx, "spritz/owner/user-1/config/revisions/")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(listed) != 1 || listed[0].key != "spritz/owner/user-1/config/revisions/r1.tar.gz" {
+		t.Fatalf("unexpected list result %+v", listed)
+	}
+
+	if err := store.delete(ctx, "spritz/owner/user-1/config/revisions/r1.tar.gz"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := store.read(ctx, "spritz/owner/user-1/config/revisions/r1.tar.gz"); !errors.Is(err, errSharedMountNotFound) {
+		t.Fatalf("expected errSharedMountNotFound after delete, got %v", err)
+	}
+}
+
+func TestS3ObjectStoreMissingObjectIsNotFound(t *testing.T) {
+	store := newTestS3ObjectStore(t)
+	ctx := context.Background()
+
+	if _, err := store.read(ctx, "spritz/owner/user-1/config/latest.json"); !errors.Is(err, errSharedMountNotFound) {
+		t.Fatalf("expected errSharedMountNotFound from read, got %v", err)
+	}
+	if _, err := store.stat(ctx, "spritz/owner/user-1/config/latest.json"); !errors.Is(err, errSharedMountNotFound) {
+		t.Fatalf("expected errSharedMountNotFound from stat, got %v", err)
+	}
+}
+
+func TestSharedMountsConfigS3BackendDoesNotRequireRcloneRemote(t *testing.T) {
+	t.Setenv("SPRITZ_SHARED_MOUNTS_STORAGE_BACKEND", "s3")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_BUCKET", "example-bucket")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_RCLONE_REMOTE", "")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_S3_FORCE_PATH_STYLE", "true")
+
+	config, err := newSharedMountsConfig()
+	if err != nil {
+		t.Fatalf("expected s3 config to load without an rclone remote: %v", err)
+	}
+	if config.storageBackend != sharedMountsBackendS3 || !config.s3ForcePathStyle {
+		t.Fatalf("unexpected shared mounts config %+v", config)
+	}
+}
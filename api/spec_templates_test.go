@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestCreateSpritzExpandsSpecTemplates(t *testing.T) {
+	s := newCreateSpritzTestServer(t)
+	s.ingressDefaults = spritzv1.IngressDefaults{Mode: "ingress", HostTemplate: "{name}.example.com"}
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/spritzes", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Spritz-User-Id", "user-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post(`{"name":"tidy-otter","spec":{"image":"example.com/spritz:latest","env":[{"name":"APP_URL","value":"https://{name}.example.com/{owner}"}]}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected create to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	created := &spritzv1.Spritz{}
+	if err := s.client.Get(context.Background(), clientKey("spritz-test", "tidy-otter"), created); err != nil {
+		t.Fatalf("get created spritz: %v", err)
+	}
+	if created.Spec.Ingress == nil || created.Spec.Ingress.Host != "tidy-otter.example.com" {
+		t.Fatalf("expected default ingress host to be expanded, got %#v", created.Spec.Ingress)
+	}
+	if got := created.Spec.Env[0].Value; got != "https://tidy-otter.example.com/user-1" {
+		t.Fatalf("expected env value to be expanded, got %q", got)
+	}
+
+	rec = post(`{"name":"quiet-harbor","spec":{"image":"example.com/spritz:latest","env":[{"name":"APP_URL","value":"{hostname}"}]}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected unknown token to be left alone, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := s.client.Get(context.Background(), clientKey("spritz-test", "quiet-harbor"), created); err != nil {
+		t.Fatalf("get created spritz: %v", err)
+	}
+	if got := created.Spec.Env[0].Value; got != "{hostname}" {
+		t.Fatalf("expected unknown token to pass through, got %q", got)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// spritzResponse is the GET /spritzes/:name payload: the stored spritz plus
+// fields the API computes on read.
+type spritzResponse struct {
+	*spritzv1.Spritz
+	SSHAccess *spritzSSHAccess `json:"sshAccess,omitempty"`
+}
+
+// spritzSSHAccess is a ready-to-paste form of the SSH endpoint. Through the
+// API SSH gateway the commands still need a certificate from
+// POST /spritzes/:name/ssh or a code from POST /spritzes/:name/ssh/code.
+type spritzSSHAccess struct {
+	Host       string `json:"host"`
+	Port       int    `json:"port"`
+	User       string `json:"user"`
+	Command    string `json:"command"`
+	SCPExample string `json:"scpExample"`
+}
+
+// sshAccess prefers the API SSH gateway, which is what the CLI and minted
+// certificates target, and falls back to the direct endpoint the operator
+// publishes in status.ssh. It returns nil when neither is usable.
+func (s *server) sshAccess(spritz *spritzv1.Spritz) *spritzSSHAccess {
+	if spritz == nil || !isSSHEnabled(spritz.Spec) {
+		return nil
+	}
+	if s.sshGateway.enabled {
+		user := formatSSHPrincipal(s.sshGateway.principalPrefix, spritz.Namespace, spritz.Name)
+		return newSpritzSSHAccess(s.sshGateway.publicHost, s.sshGateway.publicPort, user)
+	}
+	info := spritz.Status.SSH
+	if info == nil || strings.TrimSpace(info.Host) == "" || strings.TrimSpace(info.User) == "" {
+		return nil
+	}
+	port := int(info.Port)
+	if port <= 0 {
+		port = 22
+	}
+	return newSpritzSSHAccess(info.Host, port, info.User)
+}
+
+// newSpritzSSHAccess passes the user to scp with -o User= because scp would
+// read the ':' in gateway principals as the start of the remote path.
+func newSpritzSSHAccess(host string, port int, user string) *spritzSSHAccess {
+	return &spritzSSHAccess{
+		Host:       host,
+		Port:       port,
+		User:       user,
+		Command:    fmt.Sprintf("ssh -p %d %s@%s", port, user, host),
+		SCPExample: fmt.Sprintf("scp -P %d -o User=%s ./file %s:~/", port, user, host),
+	}
+}
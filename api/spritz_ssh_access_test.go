@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestGetSpritzIncludesGatewaySSHAccess(t *testing.T) {
+	s := newListSpritzTestServer(t, spritzForOwner("tidy-otter", "user-1", nil))
+	s.sshGateway = sshGatewayConfig{enabled: true, principalPrefix: "spritz", publicHost: "ssh.example.com", publicPort: 2222}
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.GET("/api/spritzes/:name", s.getSpritz)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spritzes/tidy-otter", nil)
+	req.Header.Set("X-Spritz-User-Id", "user-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var payload struct {
+		Data struct {
+			spritzv1.Spritz
+			SSHAccess *spritzSSHAccess `json:"sshAccess"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if payload.Data.Name != "tidy-otter" || payload.Data.Spec.Owner.ID != "user-1" {
+		t.Fatalf("expected spritz fields to stay at the top level, got %#v", payload.Data.Spritz)
+	}
+	access := payload.Data.SSHAccess
+	if access == nil {
+		t.Fatal("expected sshAccess in response")
+	}
+	if access.Command != "ssh -p 2222 spritz:spritz-test:tidy-otter@ssh.example.com" {
+		t.Fatalf("unexpected ssh command %q", access.Command)
+	}
+	if access.SCPExample != "scp -P 2222 -o User=spritz:spritz-test:tidy-otter ./file ssh.example.com:~/" {
+		t.Fatalf("unexpected scp example %q", access.SCPExample)
+	}
+}
+
+func TestSSHAccessFallsBackToStatusEndpoint(t *testing.T) {
+	s := &server{}
+	spritz := spritzForOwner("tidy-otter", "user-1", nil)
+	if access := s.sshAccess(spritz); access != nil {
+		t.Fatalf("expected no ssh access without an endpoint, got %#v", access)
+	}
+
+	spritz.Status.SSH = &spritzv1.SpritzSSHInfo{Host: "tidy-otter.spritz-test.svc.cluster.local", User: "dev"}
+	access := s.sshAccess(spritz)
+	if access == nil || access.Command != "ssh -p 22 dev@tidy-otter.spritz-test.svc.cluster.local" {
+		t.Fatalf("unexpected ssh access %#v", access)
+	}
+
+	spritz.Spec.SSH = &spritzv1.SpritzSSH{Enabled: false}
+	if access := s.sshAccess(spritz); access != nil {
+		t.Fatalf("expected no ssh access when ssh is disabled, got %#v", access)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// parseKeepStorage reads the keepStorage query parameter of DELETE
+// /spritzes/:name. An empty value keeps the default, where claims owned by
+// the spritz are garbage collected with it.
+func parseKeepStorage(raw string) (bool, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return false, nil
+	}
+	keep, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("keepStorage must be true or false")
+	}
+	return keep, nil
+}
+
+// retainSpritzStorage removes the spritz owner reference from the
+// PersistentVolumeClaims the operator created for it so the claims survive
+// the spritz deletion. Only claims labelled with the spritz name and
+// controlled by the spritz count; claims that merely list the spritz as an
+// owner, and claims without an owner reference such as per-owner claims, are
+// not touched. It returns the retained claim names.
+func (s *server) retainSpritzStorage(ctx context.Context, spritz *spritzv1.Spritz) ([]string, error) {
+	claims := &corev1.PersistentVolumeClaimList{}
+	if err := s.client.List(ctx, claims, client.InNamespace(spritz.Namespace), client.MatchingLabels{nameLabelKey: spritz.Name}); err != nil {
+		return nil, err
+	}
+	retained := []string{}
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		controller := metav1.GetControllerOf(claim)
+		if spritz.UID == "" || controller == nil || controller.UID != spritz.UID {
+			continue
+		}
+		original := claim.DeepCopy()
+		claim.OwnerReferences = withoutOwnerReference(claim.OwnerReferences, spritz.UID)
+		patch := client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})
+		if err := s.client.Patch(ctx, claim, patch); err != nil {
+			return retained, fmt.Errorf("retain claim %s: %w", claim.Name, err)
+		}
+		retained = append(retained, claim.Name)
+	}
+	return retained, nil
+}
+
+func withoutOwnerReference(refs []metav1.OwnerReference, uid types.UID) []metav1.OwnerReference {
+	out := make([]metav1.OwnerReference, 0, len(refs))
+	for _, ref := range refs {
+		if ref.UID != uid {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
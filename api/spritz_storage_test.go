@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func spritzOwnedClaim(name, spritzName string, ownerUID types.UID, controller bool) *corev1.PersistentVolumeClaim {
+	claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "spritz-test"}}
+	if spritzName != "" {
+		claim.Labels = map[string]string{nameLabelKey: spritzName}
+	}
+	if ownerUID != "" {
+		claim.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "spritz.sh/v1",
+			Kind:       "Spritz",
+			Name:       spritzName,
+			UID:        ownerUID,
+			Controller: &controller,
+		}}
+	}
+	return claim
+}
+
+func TestDeleteSpritzKeepStorageRetainsOwnedClaims(t *testing.T) {
+	tidy := spritzForOwner("tidy-otter", "user-1", nil)
+	tidy.UID = "uid-tidy"
+	quiet := spritzForOwner("quiet-harbor", "user-1", nil)
+	quiet.UID = "uid-quiet"
+	s := newListSpritzTestServer(t,
+		tidy,
+		quiet,
+		spritzOwnedClaim("tidy-otter-data", "tidy-otter", tidy.UID, true),
+		spritzOwnedClaim("tidy-otter-shared", "", tidy.UID, false),
+		spritzOwnedClaim("quiet-harbor-data", "quiet-harbor", quiet.UID, true),
+		spritzOwnedClaim("user-1-home", "", "", false),
+	)
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.DELETE("/api/spritzes/:name", s.deleteSpritz)
+
+	del := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, target, nil)
+		req.Header.Set("X-Spritz-User-Id", "user-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := del("/api/spritzes/tidy-otter?keepStorage=maybe"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid keepStorage to be rejected, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := del("/api/spritzes/tidy-otter?keepStorage=true"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected delete to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	claim := &corev1.PersistentVolumeClaim{}
+	if err := s.client.Get(context.Background(), clientKey("spritz-test", "tidy-otter-data"), claim); err != nil {
+		t.Fatalf("get retained claim: %v", err)
+	}
+	if len(claim.OwnerReferences) != 0 {
+		t.Fatalf("expected owner reference to be removed, got %#v", claim.OwnerReferences)
+	}
+	if err := s.client.Get(context.Background(), clientKey("spritz-test", "tidy-otter-shared"), claim); err != nil {
+		t.Fatalf("get unlabelled claim: %v", err)
+	}
+	if len(claim.OwnerReferences) != 1 {
+		t.Fatalf("expected claims the operator did not create to keep their owner, got %#v", claim.OwnerReferences)
+	}
+	if err := s.client.Get(context.Background(), clientKey("spritz-test", "quiet-harbor-data"), claim); err != nil {
+		t.Fatalf("get unrelated claim: %v", err)
+	}
+	if len(claim.OwnerReferences) != 1 {
+		t.Fatalf("expected other spritz claims to keep their owner, got %#v", claim.OwnerReferences)
+	}
+
+	if rec := del("/api/spritzes/quiet-harbor?keepStorage=false"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected delete to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := s.client.Get(context.Background(), clientKey("spritz-test", "quiet-harbor-data"), claim); err != nil {
+		t.Fatalf("get owned claim: %v", err)
+	}
+	if len(claim.OwnerReferences) != 1 {
+		t.Fatalf("expected keepStorage=false to leave the claim to garbage collection, got %#v", claim.OwnerReferences)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+	"spritz.sh/operator/sharedmounts"
+)
+
+type transferSpritzRequest struct {
+	OwnerID string `json:"ownerId"`
+	Team    string `json:"team,omitempty"`
+	// Force transfers even when owner-scoped shared mounts would be left
+	// behind with the previous owner.
+	Force bool `json:"force,omitempty"`
+}
+
+// transferSpritz hands a spritz to a new owner. It is limited to admins of the
+// spritz namespace because the caller is, by definition, not the new owner.
+func (s *server) transferSpritz(c echo.Context) error {
+	name := c.Param("name")
+	if name == "" {
+		return writeError(c, http.StatusNotFound, "not found")
+	}
+	principal, ok := principalFromContext(c)
+	if s.auth.enabled() && (!ok || principal.ID == "") {
+		return writeError(c, http.StatusUnauthorized, "unauthenticated")
+	}
+
+	var body transferSpritzRequest
+	if err := c.Bind(&body); err != nil {
+		return writeBindError(c, err)
+	}
+	ownerID := strings.TrimSpace(body.OwnerID)
+	if ownerID == "" {
+		return writeError(c, http.StatusBadRequest, "ownerId is required")
+	}
+
+	namespace := s.namespace
+	if namespace == "" {
+		namespace = c.QueryParam("namespace")
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	if s.auth.enabled() && !principal.isAdminForNamespace(namespace) {
+		return writeForbidden(c)
+	}
+
+	spritz := &spritzv1.Spritz{}
+	if err := s.client.Get(c.Request().Context(), client.ObjectKey{Name: name, Namespace: namespace}, spritz); err != nil {
+		return writeError(c, http.StatusNotFound, err.Error())
+	}
+	previousOwnerID := spritz.Spec.Owner.ID
+	if previousOwnerID == ownerID {
+		return writeJSON(c, http.StatusOK, spritz)
+	}
+	if orphaned := s.ownerScopedSharedMounts(spritz); len(orphaned) > 0 && !body.Force {
+		return writeJSendFailData(c, http.StatusConflict, map[string]any{
+			"message":      "transfer would leave owner-scoped shared mount data with the previous owner; retry with force",
+			"sharedMounts": orphaned,
+		})
+	}
+
+	spritz.Spec.Owner = spritzv1.SpritzOwner{ID: ownerID, Team: strings.TrimSpace(body.Team)}
+	if spritz.Labels == nil {
+		spritz.Labels = map[string]string{}
+	}
+	spritz.Labels[ownerLabelKey] = ownerLabelValue(ownerID)
+	if err := s.client.Update(c.Request().Context(), spritz); err != nil {
+		return writeError(c, http.StatusInternalServerError, err.Error())
+	}
+	log.Printf("spritz transfer: name=%s namespace=%s from=%s to=%s by=%s force=%t", name, namespace, previousOwnerID, ownerID, principal.ID, body.Force)
+	return writeJSON(c, http.StatusOK, spritz)
+}
+
+// ownerScopedSharedMounts returns the shared mounts whose data is stored under
+// the spritz owner, resolved the way the operator does: the spec mounts when
+// set, otherwise the configured defaults.
+func (s *server) ownerScopedSharedMounts(spritz *spritzv1.Spritz) []string {
+	mounts := sharedmounts.NormalizeMounts(spritz.Spec.SharedMounts)
+	if len(mounts) == 0 {
+		for _, mount := range s.sharedMounts.mounts {
+			mounts = append(mounts, mount)
+		}
+	}
+	names := []string{}
+	for _, mount := range mounts {
+		if mount.Scope == sharedmounts.ScopeOwner {
+			names = append(names, mount.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
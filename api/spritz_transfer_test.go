@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+	"spritz.sh/operator/sharedmounts"
+)
+
+func TestTransferSpritzUpdatesOwnerAndLabel(t *testing.T) {
+	s := newListSpritzTestServer(t, spritzForOwner("tidy-otter", "user-1", map[string]string{ownerLabelKey: ownerLabelValue("user-1")}))
+	s.auth.adminIDs = map[string]struct{}{"admin-1": {}}
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes/:name/transfer", s.transferSpritz)
+	transfer := func(userID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/spritzes/tidy-otter/transfer", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Spritz-User-Id", userID)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := transfer("user-1", `{"ownerId":"user-2"}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected owner without admin rights to be forbidden, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := transfer("admin-1", `{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing ownerId to be rejected, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := transfer("admin-1", `{"ownerId":"user-2","team":"platform"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected transfer to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	spritz := &spritzv1.Spritz{}
+	if err := s.client.Get(context.Background(), clientKey("spritz-test", "tidy-otter"), spritz); err != nil {
+		t.Fatalf("get spritz: %v", err)
+	}
+	if spritz.Spec.Owner.ID != "user-2" || spritz.Spec.Owner.Team != "platform" {
+		t.Fatalf("expected new owner, got %#v", spritz.Spec.Owner)
+	}
+	if spritz.Labels[ownerLabelKey] != ownerLabelValue("user-2") {
+		t.Fatalf("expected owner label to follow the new owner, got %q", spritz.Labels[ownerLabelKey])
+	}
+}
+
+func TestTransferSpritzRequiresForceForOwnerScopedMounts(t *testing.T) {
+	spritz := spritzForOwner("tidy-otter", "user-1", nil)
+	spritz.Spec.SharedMounts = []sharedmounts.MountSpec{{Name: "config", MountPath: "/home/dev/.config"}}
+	s := newListSpritzTestServer(t, spritz)
+	s.auth.adminIDs = map[string]struct{}{"admin-1": {}}
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes/:name/transfer", s.transferSpritz)
+	transfer := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/spritzes/tidy-otter/transfer", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Spritz-User-Id", "admin-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := transfer(`{"ownerId":"user-2"}`)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), `"config"`) {
+		t.Fatalf("expected conflict naming the shared mount, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := transfer(`{"ownerId":"user-2","force":true}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected forced transfer to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
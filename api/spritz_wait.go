@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+const (
+	defaultSpritzWaitTimeout = time.Minute
+	spritzWaitPollInterval   = 2 * time.Second
+)
+
+var errSpritzWaitGone = errors.New("spritz deleted while waiting")
+
+// spritzWaitMaxTimeout reads SPRITZ_WAIT_MAX_TIMEOUT, the longest a
+// GET /spritzes/:name/wait request may hold its connection.
+func spritzWaitMaxTimeout() time.Duration {
+	value := parseDurationEnv("SPRITZ_WAIT_MAX_TIMEOUT", 5*time.Minute)
+	if value <= 0 {
+		return 5 * time.Minute
+	}
+	return value
+}
+
+// parseSpritzWaitTimeout reads timeoutSeconds, defaulting to one minute and
+// clamping to the configured maximum.
+func parseSpritzWaitTimeout(raw string, max time.Duration) (time.Duration, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return min(defaultSpritzWaitTimeout, max), true
+	}
+	seconds, err := strconv.Atoi(raw)
+	if err != nil || seconds <= 0 {
+		return 0, false
+	}
+	timeout := time.Duration(seconds) * time.Second
+	if timeout > max {
+		return max, true
+	}
+	return timeout, true
+}
+
+// waitSpritz handles GET /spritzes/:name/wait, which long-polls until the
+// spritz reaches the requested phase (Ready by default) so scripts do not have
+// to busy-poll GET /spritzes/:name. It answers 504 when the timeout passes.
+func (s *server) waitSpritz(c echo.Context) error {
+	name := c.Param("name")
+	if name == "" {
+		return writeError(c, http.StatusNotFound, "not found")
+	}
+	principal, ok := principalFromContext(c)
+	if s.auth.enabled() && (!ok || principal.ID == "") {
+		return writeError(c, http.StatusUnauthorized, "unauthenticated")
+	}
+	if err := authorizeHumanOnly(principal, s.auth.enabled()); err != nil {
+		return writeForbidden(c)
+	}
+	phase := strings.TrimSpace(c.QueryParam("phase"))
+	if phase == "" {
+		phase = "Ready"
+	}
+	timeout, ok := parseSpritzWaitTimeout(c.QueryParam("timeoutSeconds"), s.waitMaxTimeout)
+	if !ok {
+		return writeError(c, http.StatusBadRequest, "timeoutSeconds must be a positive integer")
+	}
+
+	namespace := s.namespace
+	if namespace == "" {
+		namespace = c.QueryParam("namespace")
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	spritz := &spritzv1.Spritz{}
+	if err := s.client.Get(c.Request().Context(), clientKey(namespace, name), spritz); err != nil {
+		return writeError(c, http.StatusNotFound, err.Error())
+	}
+	if err := authorizeHumanOwnedAccess(principal, spritz.Spec.Owner.ID, s.auth.enabled()); err != nil {
+		return writeError(c, http.StatusForbidden, "forbidden")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+	defer cancel()
+	spritz, err := s.waitForSpritzPhase(ctx, namespace, name, phase)
+	switch {
+	case err == nil:
+		return writeJSON(c, http.StatusOK, spritzResponse{Spritz: spritz, SSHAccess: s.sshAccess(spritz)})
+	case errors.Is(err, errSpritzWaitGone):
+		return writeError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return writeError(c, http.StatusGatewayTimeout, "timed out waiting for phase "+phase)
+	default:
+		return writeError(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// spritzReachedPhase reports whether the operator has put the spritz in phase
+// after processing its latest spec, so a phase left over from an older
+// generation does not end the wait early.
+func spritzReachedPhase(spritz *spritzv1.Spritz, phase string) bool {
+	return spritz.Status.Phase == phase && spritz.Status.ObservedGeneration >= spritz.Generation
+}
+
+// waitForSpritzPhase watches the spritz when the client supports it and polls
+// otherwise. The watch starts before the first read so a transition between
+// the two is not missed.
+func (s *server) waitForSpritzPhase(ctx context.Context, namespace, name, phase string) (*spritzv1.Spritz, error) {
+	var events <-chan watch.Event
+	if watcher, ok := s.client.(client.WithWatch); ok {
+		w, err := watcher.Watch(ctx, &spritzv1.SpritzList{}, client.InNamespace(namespace), client.MatchingFieldsSelector{
+			Selector: fields.OneTermEqualSelector("metadata.name", name),
+		})
+		if err == nil {
+			defer w.Stop()
+			events = w.ResultChan()
+		}
+	}
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	if events == nil {
+		ticker = time.NewTicker(spritzWaitPollInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		current := &spritzv1.Spritz{}
+		if err := s.client.Get(ctx, clientKey(namespace, name), current); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errSpritzWaitGone
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		if spritzReachedPhase(current, phase) {
+			return current, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-tick:
+		case event, ok := <-events:
+			if !ok {
+				// The API server closed the watch; keep waiting by polling.
+				events = nil
+				ticker = time.NewTicker(spritzWaitPollInterval)
+				defer ticker.Stop()
+				tick = ticker.C
+				continue
+			}
+			if event.Type == watch.Deleted {
+				return nil, errSpritzWaitGone
+			}
+		}
+	}
+}
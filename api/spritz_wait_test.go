@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func newSpritzWaitTestEcho(s *server) *echo.Echo {
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.GET("/api/spritzes/:name/wait", s.waitSpritz)
+	return e
+}
+
+func TestWaitSpritzReturnsOnceReady(t *testing.T) {
+	spritz := spritzForOwner("tidy-otter", "user-1", nil)
+	spritz.Status.Phase = "Provisioning"
+	s := newListSpritzTestServer(t, spritz)
+	s.waitMaxTimeout = time.Minute
+	e := newSpritzWaitTestEcho(s)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		current := &spritzv1.Spritz{}
+		if err := s.client.Get(context.Background(), clientKey("spritz-test", "tidy-otter"), current); err != nil {
+			t.Errorf("get spritz: %v", err)
+			return
+		}
+		current.Status.Phase = "Ready"
+		if err := s.client.Update(context.Background(), current); err != nil {
+			t.Errorf("update spritz: %v", err)
+		}
+	}()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spritzes/tidy-otter/wait?timeoutSeconds=10", nil)
+	req.Header.Set("X-Spritz-User-Id", "user-1")
+	rec := httptest.NewRecorder()
+	started := time.Now()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("expected wait to return soon after the phase change, took %s", elapsed)
+	}
+}
+
+func TestWaitSpritzTimesOutAndChecksOwner(t *testing.T) {
+	spritz := spritzForOwner("tidy-otter", "user-1", nil)
+	spritz.Status.Phase = "Provisioning"
+	s := newListSpritzTestServer(t, spritz)
+	s.waitMaxTimeout = time.Second
+	e := newSpritzWaitTestEcho(s)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spritzes/tidy-otter/wait", nil)
+	req.Header.Set("X-Spritz-User-Id", "user-2")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for another user, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/spritzes/tidy-otter/wait?timeoutSeconds=600", nil)
+	req.Header.Set("X-Spritz-User-Id", "user-1")
+	rec = httptest.NewRecorder()
+	started := time.Now()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status 504, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("expected timeout to be capped at the max, took %s", elapsed)
+	}
+}
+
+func TestSpritzReachedPhaseRequiresCurrentGeneration(t *testing.T) {
+	spritz := spritzForOwner("tidy-otter", "user-1", nil)
+	spritz.Generation = 3
+	spritz.Status.Phase = "Ready"
+	spritz.Status.ObservedGeneration = 2
+	if spritzReachedPhase(spritz, "Ready") {
+		t.Fatal("expected a Ready phase from an older generation to be ignored")
+	}
+	spritz.Status.ObservedGeneration = 3
+	if !spritzReachedPhase(spritz, "Ready") {
+		t.Fatal("expected Ready at the current generation to end the wait")
+	}
+	if spritzReachedPhase(spritz, "Expired") {
+		t.Fatal("expected a different phase not to end the wait")
+	}
+}
+
+func TestParseSpritzWaitTimeout(t *testing.T) {
+	max := 2 * time.Minute
+	cases := []struct {
+		raw  string
+		want time.Duration
+		ok   bool
+	}{
+		{raw: "", want: time.Minute, ok: true},
+		{raw: "30", want: 30 * time.Second, ok: true},
+		{raw: "900", want: max, ok: true},
+		{raw: "0", ok: false},
+		{raw: "soon", ok: false},
+	}
+	for _, tc := range cases {
+		got, ok := parseSpritzWaitTimeout(tc.raw, max)
+		if ok != tc.ok || got != tc.want {
+			t.Fatalf("parseSpritzWaitTimeout(%q) = %s, %v; want %s, %v", tc.raw, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	sshserver "github.com/gliderlabs/ssh"
+	"github.com/labstack/echo/v4"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// connectTicketTypeSSHCode marks connect tickets that authorize one SSH
+// gateway login through keyboard-interactive auth.
+const connectTicketTypeSSHCode = "ssh-code"
+
+const sshCodePrompt = "Spritz SSH code: "
+
+type sshCodePrincipalKey struct{}
+
+type sshCodeResponse struct {
+	Host       string `json:"host"`
+	Port       int    `json:"port"`
+	User       string `json:"user"`
+	Code       string `json:"code"`
+	KnownHosts string `json:"known_hosts,omitempty"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+// mintSSHCode issues a single-use code that the gateway accepts in place of a
+// certificate. Codes live in the connect ticket store so any API replica can
+// redeem them.
+func (s *server) mintSSHCode(c echo.Context) error {
+	if !s.sshGateway.enabled || !s.sshGateway.codeEnabled {
+		return writeError(c, http.StatusNotFound, "ssh codes disabled")
+	}
+	target, err := s.authorizeSSHMint(c)
+	if err != nil {
+		return writeSSHMintError(c, err)
+	}
+
+	principalName := formatSSHPrincipal(s.sshGateway.principalPrefix, target.namespace, target.name)
+	code, record, err := s.connectTickets.issue(c.Request().Context(), connectTicketRecord{
+		Type:        connectTicketTypeSSHCode,
+		ConnectPath: principalName,
+		Principal:   target.principal,
+		ExpiresAt:   time.Now().UTC().Add(s.sshGateway.codeTTL),
+	})
+	if err != nil {
+		log.Printf("spritz ssh: code issue failed name=%s namespace=%s user_id=%s err=%v", target.name, target.namespace, target.principal.ID, err)
+		return writeError(c, http.StatusInternalServerError, "failed to issue code")
+	}
+
+	expiresAt := record.ExpiresAt.UTC().Format(time.RFC3339)
+	log.Printf("spritz ssh: code issued name=%s namespace=%s user_id=%s expires_at=%s", target.name, target.namespace, target.principal.ID, expiresAt)
+	if err := s.markSpritzActivity(c.Request().Context(), target.namespace, target.name, time.Now()); err != nil {
+		log.Printf("spritz ssh: failed to record activity name=%s namespace=%s user_id=%s err=%v", target.name, target.namespace, target.principal.ID, err)
+	}
+	return writeJSON(c, http.StatusOK, sshCodeResponse{
+		Host:       s.sshGateway.publicHost,
+		Port:       s.sshGateway.publicPort,
+		User:       principalName,
+		Code:       code,
+		KnownHosts: formatKnownHosts(s.sshGateway.publicHost, s.sshGateway.publicPort, s.sshGateway.hostPublicKey),
+		ExpiresAt:  expiresAt,
+	})
+}
+
+// handleSSHCodeAuth redeems a one-time code for the principal the client
+// logs in as. A code minted for another spritz, or presented in read-only
+// mode, is rejected without being consumed.
+func (s *server) handleSSHCodeAuth(ctx sshserver.Context, challenge gossh.KeyboardInteractiveChallenge) bool {
+	if s.rejectSSHReadOnly(ctx) {
+		return false
+	}
+	answers, err := challenge("", "", []string{sshCodePrompt}, []bool{false})
+	if err != nil || len(answers) != 1 {
+		log.Printf("spritz ssh: auth failed user=%s remote=%s reason=code-challenge err=%v", ctx.User(), ctx.RemoteAddr(), err)
+		return false
+	}
+	record, err := s.connectTickets.consume(ctx, strings.TrimSpace(answers[0]), func(record connectTicketRecord) error {
+		if record.Type != connectTicketTypeSSHCode || record.ConnectPath != ctx.User() {
+			return errInvalidConnectTicket
+		}
+		return nil
+	})
+	if err != nil {
+		reason := "code-error"
+		if errors.Is(err, errInvalidConnectTicket) || errors.Is(err, errExpiredConnectTicket) || errors.Is(err, errUsedConnectTicket) {
+			reason = "invalid-code"
+		}
+		log.Printf("spritz ssh: auth failed user=%s remote=%s reason=%s err=%v", ctx.User(), ctx.RemoteAddr(), reason, err)
+		return false
+	}
+	ctx.SetValue(sshCodePrincipalKey{}, record.Principal.ID)
+	return true
+}
+
+// sshSessionUserID returns the principal ID behind a session, from the cert
+// key ID or from the redeemed code.
+func sshSessionUserID(sess sshserver.Session) string {
+	if cert, ok := sess.PublicKey().(*gossh.Certificate); ok {
+		return strings.TrimPrefix(cert.KeyId, "spritz:")
+	}
+	if id, ok := sess.Context().Value(sshCodePrincipalKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlclientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func newSSHCodeTestServer(t *testing.T, configure ...func(*server)) (*server, string) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := spritzv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add spritz scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+	hostSigner := newTestSSHSigner(t)
+	s := &server{
+		client:         ctrlclientfake.NewClientBuilder().WithScheme(scheme).Build(),
+		connectTickets: newConnectTicketStore(ctrlclientfake.NewClientBuilder().WithScheme(scheme).Build(), "spritz-system"),
+		sshGateway: sshGatewayConfig{
+			enabled:         true,
+			principalPrefix: "spritz",
+			hostSigner:      hostSigner,
+			hostPublicKey:   hostSigner.PublicKey(),
+			certChecker:     &gossh.CertChecker{},
+			codeEnabled:     true,
+			codeTTL:         time.Minute,
+		},
+	}
+	for _, apply := range configure {
+		apply(s)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen ssh: %v", err)
+	}
+	sshServer := s.newSSHGatewayServer()
+	sshServer.AddHostKey(hostSigner)
+	go func() {
+		_ = sshServer.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = sshServer.Close()
+	})
+	return s, listener.Addr().String()
+}
+
+func dialSSHWithCode(addr, user, code string) error {
+	client, err := gossh.Dial("tcp", addr, &gossh.ClientConfig{
+		User: user,
+		Auth: []gossh.AuthMethod{gossh.KeyboardInteractive(func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+			answers := make([]string, len(questions))
+			for i := range questions {
+				answers[i] = code
+			}
+			return answers, nil
+		})},
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	return client.Close()
+}
+
+func TestSSHCodeAuthAcceptsCodeOnceForItsSpritz(t *testing.T) {
+	s, addr := newSSHCodeTestServer(t)
+	principalName := formatSSHPrincipal("spritz", "spritz-test", "ssh-instance")
+	code, _, err := s.connectTickets.issue(context.Background(), connectTicketRecord{
+		Type:        connectTicketTypeSSHCode,
+		ConnectPath: principalName,
+		Principal:   principal{ID: "user-123"},
+		ExpiresAt:   time.Now().UTC().Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("issue code: %v", err)
+	}
+
+	if err := dialSSHWithCode(addr, formatSSHPrincipal("spritz", "spritz-test", "other"), code); err == nil {
+		t.Fatal("expected code for another spritz to be rejected")
+	}
+	if err := dialSSHWithCode(addr, principalName, "not-a-code"); err == nil {
+		t.Fatal("expected unknown code to be rejected")
+	}
+	if err := dialSSHWithCode(addr, principalName, code); err != nil {
+		t.Fatalf("expected code to authenticate: %v", err)
+	}
+	if err := dialSSHWithCode(addr, principalName, code); err == nil {
+		t.Fatal("expected code to be single use")
+	}
+}
+
+func TestSSHCodeAuthRejectsExpiredCode(t *testing.T) {
+	s, addr := newSSHCodeTestServer(t)
+	principalName := formatSSHPrincipal("spritz", "spritz-test", "ssh-instance")
+	code, _, err := s.connectTickets.issue(context.Background(), connectTicketRecord{
+		Type:        connectTicketTypeSSHCode,
+		ConnectPath: principalName,
+		Principal:   principal{ID: "user-123"},
+		ExpiresAt:   time.Now().UTC().Add(-time.Second),
+	})
+	if err != nil {
+		t.Fatalf("issue code: %v", err)
+	}
+	if err := dialSSHWithCode(addr, principalName, code); err == nil {
+		t.Fatal("expected expired code to be rejected")
+	}
+}
+
+func TestSSHGatewayOmitsKeyboardInteractiveWhenCodesDisabled(t *testing.T) {
+	s := &server{sshGateway: sshGatewayConfig{enabled: true}}
+	if s.newSSHGatewayServer().KeyboardInteractiveHandler != nil {
+		t.Fatal("expected keyboard-interactive auth to stay off by default")
+	}
+}
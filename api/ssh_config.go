@@ -4,13 +4,12 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"strings"
 	"time"
 
 	"golang.org/x/crypto/ssh"
-
-	spritzv1 "spritz.sh/operator/api/v1"
 )
 
 type sshGatewayConfig struct {
@@ -28,26 +27,20 @@ type sshGatewayConfig struct {
 	hostSigner      ssh.Signer
 	hostPublicKey   ssh.PublicKey
 	certChecker     *ssh.CertChecker
-}
-
-type sshDefaults struct {
-	enabled          bool
-	mode             string
-	gatewayService   string
-	gatewayNamespace string
-	gatewayPort      int32
-	user             string
-}
-
-func newSSHDefaults() sshDefaults {
-	return sshDefaults{
-		enabled:          parseBoolEnv("SPRITZ_DEFAULT_SSH_ENABLED", false),
-		mode:             envOrDefault("SPRITZ_DEFAULT_SSH_MODE", "gateway"),
-		gatewayService:   os.Getenv("SPRITZ_DEFAULT_SSH_GATEWAY_SERVICE"),
-		gatewayNamespace: os.Getenv("SPRITZ_DEFAULT_SSH_GATEWAY_NAMESPACE"),
-		gatewayPort:      int32(parseIntEnv("SPRITZ_DEFAULT_SSH_GATEWAY_PORT", 22)),
-		user:             envOrDefault("SPRITZ_DEFAULT_SSH_USER", "spritz"),
-	}
+	// codeEnabled adds keyboard-interactive auth with one-time codes for
+	// clients that cannot present certificates.
+	codeEnabled bool
+	codeTTL     time.Duration
+	// proxyProtocol requires a PROXY protocol header on every connection so
+	// logs and limits see the client address behind an L4 load balancer.
+	proxyProtocol bool
+	// directPodDial serves sessions from an sshd in the workspace pod, dialed
+	// on the pod IP, instead of exec through the API server. The pod sshd must
+	// trust caSigner as a user CA for the <namespace>/<name> principal, and
+	// present a host certificate signed by one of directPodHostCAs.
+	directPodDial    bool
+	directPodPort    int
+	directPodHostCAs []ssh.PublicKey
 }
 
 func newSSHGatewayConfig() (sshGatewayConfig, error) {
@@ -70,7 +63,16 @@ func newSSHGatewayConfig() (sshGatewayConfig, error) {
 	if publicHost == "" {
 		return sshGatewayConfig{}, errors.New("SPRITZ_SSH_PUBLIC_HOST is required when SSH gateway is enabled")
 	}
-	listenPort := parseIntEnv("SPRITZ_SSH_GATEWAY_PORT", 2222)
+	// Bind explicitly on IPv4 by default so Kubernetes Service traffic can reach
+	// the SSH gateway even on clusters where an unspecified address becomes
+	// IPv6-only.
+	listenAddr := fmt.Sprintf("0.0.0.0:%d", parseIntEnv("SPRITZ_SSH_GATEWAY_PORT", 2222))
+	if value := strings.TrimSpace(os.Getenv("SPRITZ_SSH_GATEWAY_LISTEN")); value != "" {
+		if _, _, err := net.SplitHostPort(value); err != nil {
+			return sshGatewayConfig{}, fmt.Errorf("SPRITZ_SSH_GATEWAY_LISTEN: %w", err)
+		}
+		listenAddr = value
+	}
 	user := envOrDefault("SPRITZ_SSH_USER", "spritz")
 	principalPrefix := envOrDefault("SPRITZ_SSH_PRINCIPAL_PREFIX", "spritz")
 	certTTL := parseDurationEnv("SPRITZ_SSH_CERT_TTL", 15*time.Minute)
@@ -83,6 +85,10 @@ func newSSHGatewayConfig() (sshGatewayConfig, error) {
 	}
 	containerName := envOrDefault("SPRITZ_SSH_CONTAINER", "spritz")
 	command := splitCommand(envOrDefault("SPRITZ_SSH_COMMAND", "bash -l"))
+	codeTTL := parseDurationEnv("SPRITZ_SSH_CODE_TTL", 2*time.Minute)
+	if codeTTL <= 0 {
+		codeTTL = 2 * time.Minute
+	}
 
 	checker := &ssh.CertChecker{
 		IsUserAuthority: func(auth ssh.PublicKey) bool {
@@ -90,23 +96,36 @@ func newSSHGatewayConfig() (sshGatewayConfig, error) {
 		},
 	}
 
+	directPodDial := parseBoolEnv("SPRITZ_SSH_DIRECT_POD_DIAL", false)
+	var directPodHostCAs []ssh.PublicKey
+	if directPodDial {
+		directPodHostCAs, err = loadSSHPublicKeys("SPRITZ_SSH_DIRECT_POD_HOST_CA", "SPRITZ_SSH_DIRECT_POD_HOST_CA_FILE")
+		if err != nil {
+			return sshGatewayConfig{}, fmt.Errorf("ssh direct pod dial host CA: %w", err)
+		}
+	}
+
 	return sshGatewayConfig{
-		enabled:         true,
-		// Bind explicitly on IPv4 so Kubernetes Service traffic can reach the SSH
-		// gateway even on clusters where an unspecified address becomes IPv6-only.
-		listenAddr:      fmt.Sprintf("0.0.0.0:%d", listenPort),
-		publicHost:      publicHost,
-		publicPort:      publicPort,
-		user:            user,
-		principalPrefix: principalPrefix,
-		certTTL:         certTTL,
-		activityRefresh: activityRefresh,
-		containerName:   containerName,
-		command:         command,
-		caSigner:        caSigner,
-		hostSigner:      hostSigner,
-		hostPublicKey:   hostSigner.PublicKey(),
-		certChecker:     checker,
+		enabled:          true,
+		listenAddr:       listenAddr,
+		proxyProtocol:    parseBoolEnv("SPRITZ_SSH_PROXY_PROTOCOL", false),
+		publicHost:       publicHost,
+		publicPort:       publicPort,
+		user:             user,
+		principalPrefix:  principalPrefix,
+		certTTL:          certTTL,
+		activityRefresh:  activityRefresh,
+		containerName:    containerName,
+		command:          command,
+		caSigner:         caSigner,
+		hostSigner:       hostSigner,
+		hostPublicKey:    hostSigner.PublicKey(),
+		certChecker:      checker,
+		codeEnabled:      parseBoolEnv("SPRITZ_SSH_CODE_ENABLED", false),
+		codeTTL:          codeTTL,
+		directPodDial:    directPodDial,
+		directPodPort:    parseIntEnv("SPRITZ_SSH_DIRECT_POD_PORT", 22),
+		directPodHostCAs: directPodHostCAs,
 	}, nil
 }
 
@@ -132,46 +151,30 @@ func loadSSHSigner(valueEnv, fileEnv string) (ssh.Signer, error) {
 	return ssh.ParsePrivateKey(data)
 }
 
-func applySSHDefaults(spec *spritzv1.SpritzSpec, defaults sshDefaults, namespace string) {
-	if !defaults.enabled {
-		return
-	}
-	if spec.SSH != nil && !spec.SSH.Enabled {
-		return
-	}
-	if spec.Features != nil && spec.Features.SSH != nil && !*spec.Features.SSH {
-		return
-	}
-	if spec.SSH == nil {
-		spec.SSH = &spritzv1.SpritzSSH{Enabled: true}
-	} else if !spec.SSH.Enabled {
-		spec.SSH.Enabled = true
-	}
-	if spec.Features == nil {
-		spec.Features = &spritzv1.SpritzFeatures{}
-	}
-	if spec.Features.SSH == nil {
-		enabled := true
-		spec.Features.SSH = &enabled
-	}
-	if spec.SSH.Mode == "" {
-		spec.SSH.Mode = defaults.mode
-	}
-	if spec.SSH.User == "" {
-		spec.SSH.User = defaults.user
-	}
-	if strings.EqualFold(spec.SSH.Mode, "gateway") {
-		if spec.SSH.GatewayService == "" {
-			spec.SSH.GatewayService = defaults.gatewayService
+// loadSSHPublicKeys reads one or more public keys in authorized_keys format.
+func loadSSHPublicKeys(valueEnv, fileEnv string) ([]ssh.PublicKey, error) {
+	data := []byte(strings.TrimSpace(os.Getenv(valueEnv)))
+	if len(data) == 0 {
+		path := strings.TrimSpace(os.Getenv(fileEnv))
+		if path == "" {
+			return nil, fmt.Errorf("%s or %s must be set", valueEnv, fileEnv)
 		}
-		if spec.SSH.GatewayNamespace == "" {
-			spec.SSH.GatewayNamespace = defaults.gatewayNamespace
-			if spec.SSH.GatewayNamespace == "" {
-				spec.SSH.GatewayNamespace = namespace
-			}
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
 		}
-		if spec.SSH.GatewayPort == 0 {
-			spec.SSH.GatewayPort = defaults.gatewayPort
+	}
+	var keys []ssh.PublicKey
+	for len(bytes.TrimSpace(data)) > 0 {
+		key, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			return nil, err
 		}
+		keys = append(keys, key)
+		data = rest
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s has no keys", valueEnv)
 	}
+	return keys, nil
 }
@@ -6,6 +6,8 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"testing"
+
+	"golang.org/x/crypto/ssh"
 )
 
 func TestNewSSHGatewayConfigBindsIPv4ListenAddr(t *testing.T) {
@@ -24,6 +26,55 @@ func TestNewSSHGatewayConfigBindsIPv4ListenAddr(t *testing.T) {
 	}
 }
 
+func TestNewSSHGatewayConfigUsesExplicitListenAddr(t *testing.T) {
+	t.Setenv("SPRITZ_SSH_GATEWAY_ENABLED", "true")
+	t.Setenv("SPRITZ_SSH_PUBLIC_HOST", "ssh.example.com")
+	t.Setenv("SPRITZ_SSH_GATEWAY_PORT", "2022")
+	t.Setenv("SPRITZ_SSH_GATEWAY_LISTEN", "[::]:2200")
+	t.Setenv("SPRITZ_SSH_PROXY_PROTOCOL", "true")
+	t.Setenv("SPRITZ_SSH_CA_KEY", newTestSSHPrivateKeyPEM(t))
+	t.Setenv("SPRITZ_SSH_HOST_KEY", newTestSSHPrivateKeyPEM(t))
+
+	cfg, err := newSSHGatewayConfig()
+	if err != nil {
+		t.Fatalf("newSSHGatewayConfig() error = %v", err)
+	}
+	if cfg.listenAddr != "[::]:2200" {
+		t.Fatalf("listenAddr = %q, want %q", cfg.listenAddr, "[::]:2200")
+	}
+	if !cfg.proxyProtocol {
+		t.Fatal("expected proxy protocol to be enabled")
+	}
+
+	t.Setenv("SPRITZ_SSH_GATEWAY_LISTEN", "2200")
+	if _, err := newSSHGatewayConfig(); err == nil {
+		t.Fatal("expected listen address without a port to be rejected")
+	}
+}
+
+func TestNewSSHGatewayConfigRequiresDirectPodHostCA(t *testing.T) {
+	t.Setenv("SPRITZ_SSH_GATEWAY_ENABLED", "true")
+	t.Setenv("SPRITZ_SSH_PUBLIC_HOST", "ssh.example.com")
+	t.Setenv("SPRITZ_SSH_CA_KEY", newTestSSHPrivateKeyPEM(t))
+	t.Setenv("SPRITZ_SSH_HOST_KEY", newTestSSHPrivateKeyPEM(t))
+	t.Setenv("SPRITZ_SSH_DIRECT_POD_DIAL", "true")
+
+	if _, err := newSSHGatewayConfig(); err == nil {
+		t.Fatal("expected direct pod dial without a host CA to be rejected")
+	}
+
+	hostCA := string(ssh.MarshalAuthorizedKey(newTestSSHSigner(t).PublicKey()))
+	otherCA := string(ssh.MarshalAuthorizedKey(newTestSSHSigner(t).PublicKey()))
+	t.Setenv("SPRITZ_SSH_DIRECT_POD_HOST_CA", hostCA+otherCA)
+	cfg, err := newSSHGatewayConfig()
+	if err != nil {
+		t.Fatalf("newSSHGatewayConfig() error = %v", err)
+	}
+	if len(cfg.directPodHostCAs) != 2 {
+		t.Fatalf("expected 2 host CAs, got %d", len(cfg.directPodHostCAs))
+	}
+}
+
 func newTestSSHPrivateKeyPEM(t *testing.T) string {
 	t.Helper()
 
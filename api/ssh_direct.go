@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	sshserver "github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// sshDirectDialTimeout bounds connecting to a pod sshd before the gateway
+// falls back to exec through the API server.
+const sshDirectDialTimeout = 5 * time.Second
+
+// directPodSSHTarget returns the pod sshd address and login user when direct
+// pod dialing is enabled and the pod has an IP.
+func (s *server) directPodSSHTarget(spritz *spritzv1.Spritz, pod *corev1.Pod) (string, string, bool) {
+	if !s.sshGateway.directPodDial || pod == nil || pod.Status.PodIP == "" {
+		return "", "", false
+	}
+	port := s.sshGateway.directPodPort
+	user := s.sshGateway.user
+	if spritz.Spec.SSH != nil {
+		if spritz.Spec.SSH.ContainerPort > 0 {
+			port = int(spritz.Spec.SSH.ContainerPort)
+		}
+		if strings.TrimSpace(spritz.Spec.SSH.User) != "" {
+			user = strings.TrimSpace(spritz.Spec.SSH.User)
+		}
+	}
+	if user == "" {
+		user = "spritz"
+	}
+	return net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(port)), user, true
+}
+
+// podSSHPrincipal is the certificate principal that binds gateway certs and
+// pod host certs to one spritz. User certs use formatSSHPrincipal, which never
+// contains a slash, so they cannot pass for it.
+func podSSHPrincipal(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// dialPodSSH connects to the sshd in a workspace pod with a short-lived
+// certificate from the gateway CA for podPrincipal, which the pod must
+// authorize for user. The pod must present a host certificate for the same
+// principal signed by a directPodHostCAs key.
+func (s *server) dialPodSSH(ctx context.Context, addr, user, podPrincipal string) (*gossh.Client, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := gossh.NewSignerFromKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := s.signSSHCert(signer.PublicKey(), podPrincipal, "gateway", false, minSSHCertTTL)
+	if err != nil {
+		return nil, err
+	}
+	certSigner, err := gossh.NewCertSigner(cert, signer)
+	if err != nil {
+		return nil, err
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, sshDirectDialTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(dialCtx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := dialCtx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	clientConn, channels, requests, err := gossh.NewClientConn(conn, addr, &gossh.ClientConfig{
+		User:            user,
+		Auth:            []gossh.AuthMethod{gossh.PublicKeys(certSigner)},
+		HostKeyCallback: s.podHostKeyCallback(podPrincipal),
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return gossh.NewClient(clientConn, channels, requests), nil
+}
+
+// podHostKeyCallback accepts only host certificates signed by a
+// directPodHostCAs key that list podPrincipal, so a pod IP that was reused by
+// another workload cannot receive the session.
+func (s *server) podHostKeyCallback(podPrincipal string) gossh.HostKeyCallback {
+	checker := &gossh.CertChecker{
+		IsHostAuthority: func(auth gossh.PublicKey, _ string) bool {
+			for _, ca := range s.sshGateway.directPodHostCAs {
+				if keysEqual(auth, ca) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+	return func(_ string, _ net.Addr, key gossh.PublicKey) error {
+		cert, ok := key.(*gossh.Certificate)
+		if !ok || cert.CertType != gossh.HostCert {
+			return errors.New("pod sshd did not present a host certificate")
+		}
+		if !checker.IsHostAuthority(cert.SignatureKey, "") {
+			return errors.New("pod host certificate is not signed by a trusted CA")
+		}
+		if len(cert.ValidPrincipals) == 0 {
+			return fmt.Errorf("pod host certificate must list principal %q", podPrincipal)
+		}
+		return checker.CheckCert(podPrincipal, cert)
+	}
+}
+
+// streamPodSSH runs command in a session on client and copies its streams.
+// It returns *gossh.ExitError when the command exits non-zero.
+func streamPodSSH(ctx context.Context, client *gossh.Client, command []string, stdin io.Reader, stdout, stderr io.Writer, pty *sshserver.Pty, sizes <-chan remotecommand.TerminalSize) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdout = stdout
+	session.Stderr = stderr
+	// Stdin is copied by hand: a Session.Stdin reader would make Wait block
+	// until the client closes its input, even after the command exits.
+	remoteStdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if pty != nil {
+		if err := session.RequestPty(pty.Term, pty.Window.Height, pty.Window.Width, gossh.TerminalModes{}); err != nil {
+			return err
+		}
+	}
+	if err := session.Start(shellJoin(command)); err != nil {
+		return err
+	}
+	// Closing the pipe on return makes the copy stop at its next write. A
+	// read still pending then ends when the gateway closes the session.
+	defer remoteStdin.Close()
+	go func() {
+		_, _ = io.Copy(remoteStdin, stdin)
+		_ = remoteStdin.Close()
+	}()
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case size := <-sizes:
+			_ = session.WindowChange(int(size.Height), int(size.Width))
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// streamSSHDirect serves sess from the pod sshd. It reports false, without
+// writing to sess, when the pod cannot be reached so the caller can fall back
+// to exec.
+func (s *server) streamSSHDirect(ctx context.Context, spritz *spritzv1.Spritz, pod *corev1.Pod, sess sshserver.Session, command []string, sizeQueue *terminalSizeQueue) (bool, error) {
+	addr, user, ok := s.directPodSSHTarget(spritz, pod)
+	if !ok {
+		return false, nil
+	}
+	client, err := s.dialPodSSH(ctx, addr, user, podSSHPrincipal(spritz.Namespace, spritz.Name))
+	if err != nil {
+		log.Printf("spritz ssh: direct pod dial failed, using exec name=%s namespace=%s addr=%s err=%v", spritz.Name, spritz.Namespace, addr, err)
+		return false, nil
+	}
+	defer client.Close()
+	var pty *sshserver.Pty
+	if value, _, hasPty := sess.Pty(); hasPty {
+		pty = &value
+	}
+	stderr := sess.Stderr()
+	if stderr == nil {
+		stderr = sess
+	}
+	return true, streamPodSSH(ctx, client, command, sess, sess, stderr, pty, sizeQueue.sizes)
+}
+
+// shellJoin quotes args for the remote shell that sshd runs commands with.
+func shellJoin(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	sshserver "github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// newTestPodHostSigner returns a host key certified by hostCA for principals.
+func newTestPodHostSigner(t *testing.T, hostCA gossh.Signer, principals ...string) gossh.Signer {
+	t.Helper()
+	key := newTestSSHSigner(t)
+	cert := &gossh.Certificate{
+		Key:             key.PublicKey(),
+		CertType:        gossh.HostCert,
+		ValidPrincipals: principals,
+		ValidBefore:     gossh.CertTimeInfinity,
+	}
+	if err := cert.SignCert(rand.Reader, hostCA); err != nil {
+		t.Fatalf("sign host cert: %v", err)
+	}
+	signer, err := gossh.NewCertSigner(cert, key)
+	if err != nil {
+		t.Fatalf("host cert signer: %v", err)
+	}
+	return signer
+}
+
+// newTestPodSSHD starts an sshd stand-in that trusts ca for the principal
+// "spritz-test/tidy-otter", presents hostKey, echoes the raw command and
+// stdin, then exits with status 3.
+func newTestPodSSHD(t *testing.T, ca, hostKey gossh.Signer) string {
+	t.Helper()
+	checker := &gossh.CertChecker{
+		IsUserAuthority: func(auth gossh.PublicKey) bool {
+			return keysEqual(auth, ca.PublicKey())
+		},
+	}
+	authenticate := func(ctx sshserver.Context, key sshserver.PublicKey) bool {
+		cert, ok := key.(*gossh.Certificate)
+		return ok && checker.IsUserAuthority(cert.SignatureKey) && checker.CheckCert("spritz-test/tidy-otter", cert) == nil
+	}
+	server := &sshserver.Server{
+		Handler: func(sess sshserver.Session) {
+			input, _ := io.ReadAll(sess)
+			_, _ = fmt.Fprintf(sess, "%s|%s", sess.RawCommand(), input)
+			_ = sess.Exit(3)
+		},
+		PublicKeyHandler: authenticate,
+	}
+	server.AddHostKey(hostKey)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+	return listener.Addr().String()
+}
+
+func newTestDirectDialServer(t *testing.T) (*server, gossh.Signer) {
+	t.Helper()
+	hostCA := newTestSSHSigner(t)
+	return &server{sshGateway: sshGatewayConfig{
+		caSigner:         newTestSSHSigner(t),
+		directPodHostCAs: []gossh.PublicKey{hostCA.PublicKey()},
+	}}, hostCA
+}
+
+func TestStreamPodSSHRunsCommandOnPodSSHD(t *testing.T) {
+	s, hostCA := newTestDirectDialServer(t)
+	addr := newTestPodSSHD(t, s.sshGateway.caSigner, newTestPodHostSigner(t, hostCA, "spritz-test/tidy-otter"))
+
+	client, err := s.dialPodSSH(context.Background(), addr, "spritz", podSSHPrincipal("spritz-test", "tidy-otter"))
+	if err != nil {
+		t.Fatalf("dial pod sshd: %v", err)
+	}
+	defer client.Close()
+
+	var stdout, stderr bytes.Buffer
+	err = streamPodSSH(context.Background(), client, []string{"echo", "it's"}, strings.NewReader("hello"), &stdout, &stderr, nil, make(chan remotecommand.TerminalSize))
+	if status, exited := sshExitStatus(err); status != 3 || !exited {
+		t.Fatalf("expected exit status 3, got %d (exited=%t, err=%v)", status, exited, err)
+	}
+	if got := stdout.String(); got != `'echo' 'it'\''s'|hello` {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
+
+func TestDialPodSSHRejectsUntrustedCA(t *testing.T) {
+	s, hostCA := newTestDirectDialServer(t)
+	addr := newTestPodSSHD(t, newTestSSHSigner(t), newTestPodHostSigner(t, hostCA, "spritz-test/tidy-otter"))
+	if _, err := s.dialPodSSH(context.Background(), addr, "spritz", podSSHPrincipal("spritz-test", "tidy-otter")); err == nil {
+		t.Fatal("expected pod sshd to reject a cert from another CA")
+	}
+}
+
+func TestDialPodSSHCertIsBoundToSpritz(t *testing.T) {
+	s, hostCA := newTestDirectDialServer(t)
+	addr := newTestPodSSHD(t, s.sshGateway.caSigner, newTestPodHostSigner(t, hostCA, "spritz-test/tidy-otter", "spritz-test/quiet-harbor"))
+	if _, err := s.dialPodSSH(context.Background(), addr, "spritz", podSSHPrincipal("spritz-test", "quiet-harbor")); err == nil {
+		t.Fatal("expected pod sshd to reject a cert for another spritz")
+	}
+}
+
+func TestDialPodSSHVerifiesHostCertificate(t *testing.T) {
+	s, hostCA := newTestDirectDialServer(t)
+	principal := podSSHPrincipal("spritz-test", "tidy-otter")
+	cases := map[string]gossh.Signer{
+		"plain host key":   newTestSSHSigner(t),
+		"untrusted CA":     newTestPodHostSigner(t, newTestSSHSigner(t), principal),
+		"other spritz":     newTestPodHostSigner(t, hostCA, "spritz-test/quiet-harbor"),
+		"empty principals": newTestPodHostSigner(t, hostCA),
+	}
+	for name, hostKey := range cases {
+		t.Run(name, func(t *testing.T) {
+			addr := newTestPodSSHD(t, s.sshGateway.caSigner, hostKey)
+			if _, err := s.dialPodSSH(context.Background(), addr, "spritz", principal); err == nil {
+				t.Fatal("expected dial to reject the pod host key")
+			}
+		})
+	}
+}
+
+func TestDirectPodSSHTargetRequiresFlagAndPodIP(t *testing.T) {
+	spritz := spritzForOwner("tidy-otter", "user-1", nil)
+	pod := &corev1.Pod{Status: corev1.PodStatus{PodIP: "10.0.0.7"}}
+	s := &server{sshGateway: sshGatewayConfig{user: "dev", directPodPort: 22}}
+	if _, _, ok := s.directPodSSHTarget(spritz, pod); ok {
+		t.Fatal("expected direct dial to be off by default")
+	}
+	s.sshGateway.directPodDial = true
+	if _, _, ok := s.directPodSSHTarget(spritz, &corev1.Pod{}); ok {
+		t.Fatal("expected pods without an IP to fall back to exec")
+	}
+	addr, user, ok := s.directPodSSHTarget(spritz, pod)
+	if !ok || addr != "10.0.0.7:22" || user != "dev" {
+		t.Fatalf("unexpected target addr=%q user=%q ok=%t", addr, user, ok)
+	}
+}
@@ -14,12 +14,14 @@ import (
 	"time"
 
 	sshserver "github.com/gliderlabs/ssh"
+	proxyproto "github.com/pires/go-proxyproto"
 	gossh "golang.org/x/crypto/ssh"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/tools/remotecommand"
 	transportspdy "k8s.io/client-go/transport/spdy"
+	utilexec "k8s.io/client-go/util/exec"
 
 	spritzv1 "spritz.sh/operator/api/v1"
 )
@@ -66,12 +68,20 @@ func (s *server) startSSHGateway(ctx context.Context) error {
 		return nil
 	}
 
+	listener, err := net.Listen("tcp", cfg.listenAddr)
+	if err != nil {
+		return err
+	}
+	if cfg.proxyProtocol {
+		listener = newSSHProxyProtocolListener(listener)
+	}
+
 	server := s.newSSHGatewayServer()
 	server.AddHostKey(cfg.hostSigner)
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- server.ListenAndServe()
+		errCh <- server.Serve(listener)
 	}()
 
 	go func() {
@@ -83,14 +93,30 @@ func (s *server) startSSHGateway(ctx context.Context) error {
 	case err := <-errCh:
 		return err
 	case <-time.After(250 * time.Millisecond):
-		log.Printf("spritz ssh gateway listening on %s", cfg.listenAddr)
+		log.Printf("spritz ssh gateway listening on %s proxy_protocol=%t", cfg.listenAddr, cfg.proxyProtocol)
 		return nil
 	}
 }
 
+// sshProxyHeaderTimeout bounds how long a connection may take to send its
+// PROXY protocol header.
+const sshProxyHeaderTimeout = 5 * time.Second
+
+// newSSHProxyProtocolListener rejects connections without a PROXY header, so
+// a client that reaches the gateway directly cannot claim another address.
+func newSSHProxyProtocolListener(listener net.Listener) net.Listener {
+	return &proxyproto.Listener{
+		Listener: listener,
+		ConnPolicy: func(proxyproto.ConnPolicyOptions) (proxyproto.Policy, error) {
+			return proxyproto.REQUIRE, nil
+		},
+		ReadHeaderTimeout: sshProxyHeaderTimeout,
+	}
+}
+
 func (s *server) newSSHGatewayServer() *sshserver.Server {
 	cfg := s.sshGateway
-	return &sshserver.Server{
+	server := &sshserver.Server{
 		Addr:             cfg.listenAddr,
 		Handler:          s.handleSSHSession,
 		PublicKeyHandler: s.handleSSHAuth,
@@ -101,16 +127,27 @@ func (s *server) newSSHGatewayServer() *sshserver.Server {
 		},
 		LocalPortForwardingCallback: s.allowSSHPortForwardDestination,
 	}
+	if cfg.codeEnabled {
+		server.KeyboardInteractiveHandler = s.handleSSHCodeAuth
+	}
+	return server
 }
 
 func (s *server) handleSSHAuth(ctx sshserver.Context, key sshserver.PublicKey) bool {
+	if s.rejectSSHReadOnly(ctx) {
+		return false
+	}
 	cert, ok := key.(*gossh.Certificate)
 	if !ok {
-		log.Printf("spritz ssh: auth failed user=%s reason=missing-cert", ctx.User())
+		log.Printf("spritz ssh: auth failed user=%s remote=%s reason=missing-cert", ctx.User(), ctx.RemoteAddr())
 		return false
 	}
 	if err := s.sshGateway.certChecker.CheckCert(ctx.User(), cert); err != nil {
-		log.Printf("spritz ssh: auth failed user=%s key_id=%s err=%v", ctx.User(), cert.KeyId, err)
+		log.Printf("spritz ssh: auth failed user=%s remote=%s key_id=%s err=%v", ctx.User(), ctx.RemoteAddr(), cert.KeyId, err)
+		return false
+	}
+	if !s.sshAdditionalPrincipalStillGranted(ctx, ctx.User(), cert) {
+		log.Printf("spritz ssh: auth failed user=%s remote=%s key_id=%s reason=principal-revoked", ctx.User(), ctx.RemoteAddr(), cert.KeyId)
 		return false
 	}
 	return true
@@ -125,11 +162,8 @@ func (s *server) handleSSHSession(sess sshserver.Session) {
 		_ = sess.Exit(1)
 		return
 	}
-	keyID := ""
-	if cert, ok := sess.PublicKey().(*gossh.Certificate); ok {
-		keyID = strings.TrimPrefix(cert.KeyId, "spritz:")
-	}
-	log.Printf("spritz ssh: session start name=%s namespace=%s user_id=%s", name, namespace, keyID)
+	keyID := sshSessionUserID(sess)
+	log.Printf("spritz ssh: session start name=%s namespace=%s user_id=%s remote=%s", name, namespace, keyID, sess.RemoteAddr())
 	defer log.Printf("spritz ssh: session end name=%s namespace=%s user_id=%s", name, namespace, keyID)
 
 	spritz := &spritzv1.Spritz{}
@@ -147,6 +181,21 @@ func (s *server) handleSSHSession(sess sshserver.Session) {
 		_ = sess.Exit(1)
 		return
 	}
+	release, ok := s.execSessions.Acquire(execSessionSpritzKey(namespace, name), keyID)
+	if !ok {
+		log.Printf("spritz ssh: session limit name=%s namespace=%s user_id=%s", name, namespace, keyID)
+		_, _ = io.WriteString(sess, "too many ssh sessions\n")
+		_ = sess.Exit(1)
+		return
+	}
+	defer release()
+	ctx, done, ok := s.sessionDrain.track(sess.Context())
+	if !ok {
+		_, _ = io.WriteString(sess, "server shutting down\n")
+		_ = sess.Exit(1)
+		return
+	}
+	defer done()
 	s.ensureSSHActivityLoop(sess.Context(), spritz)
 
 	pty, winCh, hasPty := sess.Pty()
@@ -160,12 +209,36 @@ func (s *server) handleSSHSession(sess sshserver.Session) {
 		}()
 	}
 
-	if err := s.streamSSH(sess.Context(), pod, sess, hasPty, sizeQueue); err != nil {
-		log.Printf("spritz ssh: stream failed name=%s namespace=%s err=%v", name, namespace, err)
-		_ = sess.Exit(1)
+	err = s.streamSSH(ctx, spritz, pod, sess, hasPty, sizeQueue)
+	if shuttingDown(ctx) {
+		_, _ = io.WriteString(sess.Stderr(), "\r\nspritz: server shutting down, reconnect to continue\r\n")
+		_ = sess.Exit(255)
 		return
 	}
-	_ = sess.Exit(0)
+	status, exited := sshExitStatus(err)
+	if err != nil && !exited {
+		log.Printf("spritz ssh: stream failed name=%s namespace=%s err=%v", name, namespace, err)
+		_, _ = io.WriteString(sess.Stderr(), "ssh session failed\n")
+	}
+	_ = sess.Exit(status)
+}
+
+// sshExitStatus maps a streamSSH result to the status reported to the client.
+// A remote command that exited keeps its own code; failures to start or stream
+// the command report 255, as OpenSSH does for connection errors.
+func sshExitStatus(err error) (int, bool) {
+	if err == nil {
+		return 0, true
+	}
+	var podExitErr *gossh.ExitError
+	if errors.As(err, &podExitErr) {
+		return podExitErr.ExitStatus(), true
+	}
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		return exitErr.ExitStatus(), true
+	}
+	return 255, false
 }
 
 func (s *server) handleSSHPortForward(srv *sshserver.Server, conn *gossh.ServerConn, newChan gossh.NewChannel, ctx sshserver.Context) {
@@ -208,10 +281,18 @@ func (s *server) handleSSHPortForward(srv *sshserver.Server, conn *gossh.ServerC
 		return
 	}
 
+	forwardCtx, done, ok := s.sessionDrain.track(ctx)
+	if !ok {
+		_ = upstream.Close()
+		_ = cleanup.Close()
+		newChan.Reject(gossh.ConnectionFailed, "server shutting down")
+		return
+	}
 	channel, requests, err := newChan.Accept()
 	if err != nil {
 		_ = upstream.Close()
 		_ = cleanup.Close()
+		done()
 		return
 	}
 	go gossh.DiscardRequests(requests)
@@ -222,8 +303,13 @@ func (s *server) handleSSHPortForward(srv *sshserver.Server, conn *gossh.ServerC
 			_ = channel.Close()
 			_ = upstream.Close()
 			_ = cleanup.Close()
+			done()
 		})
 	}
+	go func() {
+		<-forwardCtx.Done()
+		closeAll()
+	}()
 
 	go func() {
 		defer closeAll()
@@ -371,10 +457,15 @@ func (s *server) startSSHActivityLoop(ctx context.Context, spritz *spritzv1.Spri
 	s.startSpritzActivityLoop(ctx, spritz, s.sshGateway.activityRefresh, "ssh")
 }
 
-func (s *server) streamSSH(ctx context.Context, pod *corev1.Pod, sess sshserver.Session, hasPty bool, sizeQueue *terminalSizeQueue) error {
-	if len(s.sshGateway.command) == 0 {
+func (s *server) streamSSH(ctx context.Context, spritz *spritzv1.Spritz, pod *corev1.Pod, sess sshserver.Session, hasPty bool, sizeQueue *terminalSizeQueue) error {
+	command := terminalCommandFor(spritz, s.sshGateway.command)
+	if len(command) == 0 {
 		return fmt.Errorf("ssh command missing")
 	}
+	command = s.terminal.forwardEnv.withTerminalEnv(spritz, command)
+	if handled, err := s.streamSSHDirect(ctx, spritz, pod, sess, command, sizeQueue); handled {
+		return err
+	}
 
 	req := s.clientset.CoreV1().RESTClient().
 		Post().
@@ -384,7 +475,7 @@ func (s *server) streamSSH(ctx context.Context, pod *corev1.Pod, sess sshserver.
 		SubResource("exec").
 		VersionedParams(&corev1.PodExecOptions{
 			Container: s.sshGateway.containerName,
-			Command:   s.sshGateway.command,
+			Command:   command,
 			Stdin:     true,
 			Stdout:    true,
 			Stderr:    true,
@@ -402,7 +493,7 @@ func (s *server) streamSSH(ctx context.Context, pod *corev1.Pod, sess sshserver.
 		stderr = sess
 	}
 
-	return executor.Stream(remotecommand.StreamOptions{
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
 		Stdin:             sess,
 		Stdout:            stdout,
 		Stderr:            stderr,
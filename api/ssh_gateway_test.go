@@ -4,6 +4,8 @@ import (
 	"context"
 	"crypto/ed25519"
 	"crypto/rand"
+	"errors"
+	"fmt"
 	"io"
 	"net"
 	"sync/atomic"
@@ -14,6 +16,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilexec "k8s.io/client-go/util/exec"
 	ctrlclientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	spritzv1 "spritz.sh/operator/api/v1"
@@ -55,6 +58,66 @@ func TestStartSSHActivityLoopRefreshesWhileSessionIsOpen(t *testing.T) {
 	}
 }
 
+func TestSSHExitStatusPropagatesRemoteExitCode(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		exited bool
+	}{
+		{name: "success", err: nil, status: 0, exited: true},
+		{name: "exit code", err: utilexec.CodeExitError{Err: errors.New("command terminated with exit code 3"), Code: 3}, status: 3, exited: true},
+		{name: "wrapped exit code", err: fmt.Errorf("stream: %w", utilexec.CodeExitError{Err: errors.New("exit"), Code: 42}), status: 42, exited: true},
+		{name: "stream failure", err: errors.New("error dialing backend"), status: 255, exited: false},
+	}
+	for _, tc := range cases {
+		status, exited := sshExitStatus(tc.err)
+		if status != tc.status || exited != tc.exited {
+			t.Fatalf("%s: expected (%d, %v), got (%d, %v)", tc.name, tc.status, tc.exited, status, exited)
+		}
+	}
+}
+
+func TestSSHProxyProtocolListenerReportsClientAddr(t *testing.T) {
+	base, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	listener := newSSHProxyProtocolListener(base)
+	defer listener.Close()
+
+	accepted := make(chan net.Addr, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// The header is parsed on first read.
+		buf := make([]byte, 3)
+		_, _ = io.ReadFull(conn, buf)
+		accepted <- conn.RemoteAddr()
+	}()
+
+	conn, err := net.Dial("tcp", base.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, "PROXY TCP4 203.0.113.7 10.0.0.1 51234 22\r\nSSH"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	select {
+	case addr := <-accepted:
+		if addr.String() != "203.0.113.7:51234" {
+			t.Fatalf("expected client address from PROXY header, got %s", addr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for proxied connection")
+	}
+}
+
 func TestIsLoopbackSSHForwardHost(t *testing.T) {
 	t.Parallel()
 
@@ -177,7 +240,7 @@ func TestSSHGatewayPortForwardProxiesToInjectedUpstream(t *testing.T) {
 
 	principal := formatSSHPrincipal(s.sshGateway.principalPrefix, "spritz-test", "ssh-instance")
 	userSigner := newTestSSHSigner(t)
-	cert, err := s.signSSHCert(userSigner.PublicKey(), principal, "user-123")
+	cert, err := s.signSSHCert(userSigner.PublicKey(), principal, "user-123", false, time.Minute)
 	if err != nil {
 		t.Fatalf("sign cert: %v", err)
 	}
@@ -3,6 +3,7 @@ package main
 import (
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -17,8 +18,15 @@ import (
 
 type sshMintRequest struct {
 	PublicKey string `json:"public_key"`
+	// TTLSeconds requests a certificate shorter than the configured TTL.
+	// Zero uses the configured TTL.
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
 }
 
+// minSSHCertTTL is the shortest certificate a client may request, long
+// enough to finish connecting.
+const minSSHCertTTL = time.Minute
+
 type sshMintResponse struct {
 	Host       string `json:"host"`
 	Port       int    `json:"port"`
@@ -28,31 +36,31 @@ type sshMintResponse struct {
 	ExpiresAt  string `json:"expires_at"`
 }
 
+type sshMintError struct {
+	status  int
+	message string
+}
+
+func (e sshMintError) Error() string {
+	return e.message
+}
+
+type sshMintTarget struct {
+	principal principal
+	namespace string
+	name      string
+	// additional is set when access comes from spec.ssh.additionalPrincipals
+	// rather than ownership.
+	additional bool
+}
+
 func (s *server) mintSSHCert(c echo.Context) error {
 	if !s.sshGateway.enabled {
 		return writeError(c, http.StatusNotFound, "ssh gateway disabled")
 	}
-	principal, ok := principalFromContext(c)
-	if s.auth.enabled() && (!ok || principal.ID == "") {
-		return writeError(c, http.StatusUnauthorized, "unauthenticated")
-	}
-
-	name := strings.TrimSpace(c.Param("name"))
-	if name == "" {
-		return writeError(c, http.StatusBadRequest, "spritz name required")
-	}
-
-	namespace := s.namespace
-	if namespace == "" {
-		namespace = c.QueryParam("namespace")
-	}
-	if namespace == "" {
-		namespace = "default"
-	}
-
 	var body sshMintRequest
 	if err := c.Bind(&body); err != nil {
-		return writeError(c, http.StatusBadRequest, "invalid json")
+		return writeBindError(c, err)
 	}
 	if strings.TrimSpace(body.PublicKey) == "" {
 		return writeError(c, http.StatusBadRequest, "public_key is required")
@@ -62,34 +70,26 @@ func (s *server) mintSSHCert(c echo.Context) error {
 	if err != nil {
 		return writeError(c, http.StatusBadRequest, "invalid public_key")
 	}
-
-	spritz := &spritzv1.Spritz{}
-	if err := s.client.Get(c.Request().Context(), clientKey(namespace, name), spritz); err != nil {
-		log.Printf("spritz ssh: spritz not found name=%s namespace=%s user_id=%s err=%v", name, namespace, principal.ID, err)
-		return writeError(c, http.StatusNotFound, "spritz not found")
-	}
-	if err := authorizeHumanOwnedAccess(principal, spritz.Spec.Owner.ID, s.auth.enabled()); err != nil {
-		log.Printf("spritz ssh: owner mismatch name=%s namespace=%s user_id=%s owner_id=%s", name, namespace, principal.ID, spritz.Spec.Owner.ID)
-		return writeError(c, http.StatusForbidden, "owner mismatch")
-	}
-	if !isSSHEnabled(spritz.Spec) {
-		log.Printf("spritz ssh: ssh disabled name=%s namespace=%s user_id=%s", name, namespace, principal.ID)
-		return writeError(c, http.StatusNotFound, "ssh disabled")
+	ttl, err := s.resolveSSHCertTTL(body.TTLSeconds)
+	if err != nil {
+		return writeError(c, http.StatusBadRequest, err.Error())
 	}
-	if !s.allowSSHMint(principal.ID, namespace, name) {
-		log.Printf("spritz ssh: rate limit name=%s namespace=%s user_id=%s", name, namespace, principal.ID)
-		return writeError(c, http.StatusTooManyRequests, "rate limit exceeded")
+
+	target, err := s.authorizeSSHMint(c)
+	if err != nil {
+		return writeSSHMintError(c, err)
 	}
+	principal, namespace, name := target.principal, target.namespace, target.name
 
 	principalName := formatSSHPrincipal(s.sshGateway.principalPrefix, namespace, name)
-	cert, err := s.signSSHCert(pubKey, principalName, principal.ID)
+	cert, err := s.signSSHCert(pubKey, principalName, principal.ID, target.additional, ttl)
 	if err != nil {
 		return writeError(c, http.StatusInternalServerError, "failed to issue cert")
 	}
 
 	knownHosts := formatKnownHosts(s.sshGateway.publicHost, s.sshGateway.publicPort, s.sshGateway.hostPublicKey)
 	expiresAt := time.Unix(int64(cert.ValidBefore), 0).UTC().Format(time.RFC3339)
-	log.Printf("spritz ssh: cert issued name=%s namespace=%s user_id=%s expires_at=%s", name, namespace, principal.ID, expiresAt)
+	log.Printf("spritz ssh: cert issued name=%s namespace=%s user_id=%s additional=%t expires_at=%s", name, namespace, principal.ID, target.additional, expiresAt)
 	if err := s.markSpritzActivity(c.Request().Context(), namespace, name, time.Now()); err != nil {
 		log.Printf("spritz ssh: failed to record activity name=%s namespace=%s user_id=%s err=%v", name, namespace, principal.ID, err)
 	}
@@ -104,7 +104,79 @@ func (s *server) mintSSHCert(c echo.Context) error {
 	return writeJSON(c, http.StatusOK, resp)
 }
 
-func (s *server) signSSHCert(pubKey ssh.PublicKey, principalName, keyID string) (*ssh.Certificate, error) {
+// authorizeSSHMint resolves the spritz named in the request and applies the
+// owner, feature, rate, and session checks shared by cert and code minting.
+func (s *server) authorizeSSHMint(c echo.Context) (sshMintTarget, error) {
+	principal, ok := principalFromContext(c)
+	if s.auth.enabled() && (!ok || principal.ID == "") {
+		return sshMintTarget{}, sshMintError{status: http.StatusUnauthorized, message: "unauthenticated"}
+	}
+
+	name := strings.TrimSpace(c.Param("name"))
+	if name == "" {
+		return sshMintTarget{}, sshMintError{status: http.StatusBadRequest, message: "spritz name required"}
+	}
+
+	namespace := s.namespace
+	if namespace == "" {
+		namespace = c.QueryParam("namespace")
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	spritz := &spritzv1.Spritz{}
+	if err := s.client.Get(c.Request().Context(), clientKey(namespace, name), spritz); err != nil {
+		log.Printf("spritz ssh: spritz not found name=%s namespace=%s user_id=%s err=%v", name, namespace, principal.ID, err)
+		return sshMintTarget{}, sshMintError{status: http.StatusNotFound, message: "spritz not found"}
+	}
+	additional := false
+	if err := authorizeHumanOwnedAccess(principal, spritz.Spec.Owner.ID, s.auth.enabled()); err != nil {
+		if !principal.isHuman() || !isSSHAdditionalPrincipal(spritz.Spec, principal.ID) {
+			log.Printf("spritz ssh: owner mismatch name=%s namespace=%s user_id=%s owner_id=%s", name, namespace, principal.ID, spritz.Spec.Owner.ID)
+			return sshMintTarget{}, sshMintError{status: http.StatusForbidden, message: "owner mismatch"}
+		}
+		additional = true
+	}
+	if !isSSHEnabled(spritz.Spec) {
+		log.Printf("spritz ssh: ssh disabled name=%s namespace=%s user_id=%s", name, namespace, principal.ID)
+		return sshMintTarget{}, sshMintError{status: http.StatusNotFound, message: "ssh disabled"}
+	}
+	if !s.allowSSHMint(principal.ID, namespace, name) {
+		log.Printf("spritz ssh: rate limit name=%s namespace=%s user_id=%s", name, namespace, principal.ID)
+		return sshMintTarget{}, sshMintError{status: http.StatusTooManyRequests, message: "rate limit exceeded"}
+	}
+	// The gateway enforces the limit per session; refusing here spares clients
+	// a credential they could not use yet.
+	if s.execSessions.Full(execSessionSpritzKey(namespace, name), principal.ID) {
+		log.Printf("spritz ssh: session limit name=%s namespace=%s user_id=%s", name, namespace, principal.ID)
+		return sshMintTarget{}, sshMintError{status: http.StatusTooManyRequests, message: "too many ssh sessions"}
+	}
+	return sshMintTarget{principal: principal, namespace: namespace, name: name, additional: additional}, nil
+}
+
+func writeSSHMintError(c echo.Context, err error) error {
+	var mintErr sshMintError
+	if errors.As(err, &mintErr) {
+		return writeError(c, mintErr.status, mintErr.message)
+	}
+	return writeError(c, http.StatusInternalServerError, err.Error())
+}
+
+// resolveSSHCertTTL bounds a requested certificate lifetime by minSSHCertTTL
+// and the configured TTL, which is also the default.
+func (s *server) resolveSSHCertTTL(seconds int) (time.Duration, error) {
+	if seconds == 0 {
+		return s.sshGateway.certTTL, nil
+	}
+	ttl := time.Duration(seconds) * time.Second
+	if seconds < 0 || ttl < minSSHCertTTL || ttl > s.sshGateway.certTTL {
+		return 0, fmt.Errorf("ttl_seconds must be between %d and %d", int(minSSHCertTTL.Seconds()), int(s.sshGateway.certTTL.Seconds()))
+	}
+	return ttl, nil
+}
+
+func (s *server) signSSHCert(pubKey ssh.PublicKey, principalName, keyID string, additional bool, ttl time.Duration) (*ssh.Certificate, error) {
 	now := time.Now().UTC()
 	serial, err := randomSerial()
 	if err != nil {
@@ -117,13 +189,16 @@ func (s *server) signSSHCert(pubKey ssh.PublicKey, principalName, keyID string)
 		KeyId:           fmt.Sprintf("spritz:%s", keyID),
 		ValidPrincipals: []string{principalName},
 		ValidAfter:      uint64(now.Add(-30 * time.Second).Unix()),
-		ValidBefore:     uint64(now.Add(s.sshGateway.certTTL).Unix()),
+		ValidBefore:     uint64(now.Add(ttl).Unix()),
 		Permissions: ssh.Permissions{
 			Extensions: map[string]string{
 				"permit-pty": "",
 			},
 		},
 	}
+	if additional {
+		cert.Permissions.Extensions[sshAdditionalPrincipalExtension] = ""
+	}
 	if err := cert.SignCert(rand.Reader, s.sshGateway.caSigner); err != nil {
 		return nil, err
 	}
@@ -142,11 +217,9 @@ func formatKnownHosts(host string, port int, key ssh.PublicKey) string {
 }
 
 func (s *server) allowSSHMint(principalID, namespace, name string) bool {
-	if s.sshMintLimiter == nil {
-		return true
-	}
 	key := fmt.Sprintf("%s:%s/%s", principalID, namespace, name)
-	return s.sshMintLimiter.Allow(key)
+	allowed, _ := s.sshMintLimiter.Allow(key)
+	return allowed
 }
 
 func isSSHEnabled(spec spritzv1.SpritzSpec) bool {
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestResolveSSHCertTTLBoundsRequestedTTL(t *testing.T) {
+	s := &server{sshGateway: sshGatewayConfig{certTTL: 15 * time.Minute}}
+	for seconds, want := range map[int]time.Duration{
+		0:   15 * time.Minute,
+		60:  time.Minute,
+		900: 15 * time.Minute,
+	} {
+		got, err := s.resolveSSHCertTTL(seconds)
+		if err != nil || got != want {
+			t.Fatalf("ttl_seconds=%d: expected %s, got %s (err=%v)", seconds, want, got, err)
+		}
+	}
+	for _, seconds := range []int{-1, 59, 901} {
+		if _, err := s.resolveSSHCertTTL(seconds); err == nil {
+			t.Fatalf("expected ttl_seconds=%d to be rejected", seconds)
+		}
+	}
+}
+
+func TestMintSSHCertUsesRequestedTTL(t *testing.T) {
+	s, e := newSSHPrincipalsTestServer(t)
+	s.sshGateway.certTTL = 15 * time.Minute
+	publicKey := string(gossh.MarshalAuthorizedKey(newTestSSHSigner(t).PublicKey()))
+
+	body, _ := json.Marshal(sshMintRequest{PublicKey: publicKey, TTLSeconds: 3600})
+	if rec := serveSSHPrincipalsRequest(e, http.MethodPost, "/api/spritzes/tidy-otter/ssh", "user-1", string(body)); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected ttl above the maximum to be rejected, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	body, _ = json.Marshal(sshMintRequest{PublicKey: publicKey, TTLSeconds: 120})
+	before := time.Now()
+	rec := serveSSHPrincipalsRequest(e, http.MethodPost, "/api/spritzes/tidy-otter/ssh", "user-1", string(body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected mint to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload struct {
+		Data sshMintResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, payload.Data.ExpiresAt)
+	if err != nil {
+		t.Fatalf("parse expires_at: %v", err)
+	}
+	if ttl := expiresAt.Sub(before); ttl < 115*time.Second || ttl > 125*time.Second {
+		t.Fatalf("expected about 2m of validity, got %s", ttl)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	gossh "golang.org/x/crypto/ssh"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// sshAdditionalPrincipalExtension marks certificates minted through
+// spec.ssh.additionalPrincipals rather than ownership, so the gateway can
+// refuse them once the grant is removed.
+const sshAdditionalPrincipalExtension = "additional-principal@spritz.sh"
+
+type updateSSHPrincipalsRequest struct {
+	Principals []string `json:"principals"`
+}
+
+// isSSHAdditionalPrincipal reports whether id was granted SSH access to the
+// spritz through spec.ssh.additionalPrincipals.
+func isSSHAdditionalPrincipal(spec spritzv1.SpritzSpec, id string) bool {
+	id = strings.TrimSpace(id)
+	if id == "" || spec.SSH == nil {
+		return false
+	}
+	for _, candidate := range spec.SSH.AdditionalPrincipals {
+		if strings.TrimSpace(candidate) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sshAdditionalPrincipalStillGranted re-checks the grant behind a certificate
+// minted for an additional principal. Owner and admin certificates pass.
+func (s *server) sshAdditionalPrincipalStillGranted(ctx context.Context, user string, cert *gossh.Certificate) bool {
+	if _, ok := cert.Extensions[sshAdditionalPrincipalExtension]; !ok {
+		return true
+	}
+	namespace, name, ok := parseSSHPrincipal(s.sshGateway.principalPrefix, user)
+	if !ok {
+		return false
+	}
+	spritz := &spritzv1.Spritz{}
+	if err := s.client.Get(ctx, clientKey(namespace, name), spritz); err != nil {
+		return false
+	}
+	return isSSHAdditionalPrincipal(spritz.Spec, strings.TrimPrefix(cert.KeyId, "spritz:"))
+}
+
+// updateSSHPrincipals replaces spec.ssh.additionalPrincipals. It is limited to
+// admins of the spritz namespace so owners cannot widen access on their own.
+func (s *server) updateSSHPrincipals(c echo.Context) error {
+	name := c.Param("name")
+	if name == "" {
+		return writeError(c, http.StatusNotFound, "not found")
+	}
+	principal, ok := principalFromContext(c)
+	if s.auth.enabled() && (!ok || principal.ID == "") {
+		return writeError(c, http.StatusUnauthorized, "unauthenticated")
+	}
+
+	var body updateSSHPrincipalsRequest
+	if err := c.Bind(&body); err != nil {
+		return writeBindError(c, err)
+	}
+
+	namespace := s.namespace
+	if namespace == "" {
+		namespace = c.QueryParam("namespace")
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	if s.auth.enabled() && !principal.isAdminForNamespace(namespace) {
+		return writeForbidden(c)
+	}
+
+	spritz := &spritzv1.Spritz{}
+	if err := s.client.Get(c.Request().Context(), clientKey(namespace, name), spritz); err != nil {
+		return writeError(c, http.StatusNotFound, err.Error())
+	}
+	principals := dedupeStrings(body.Principals)
+	if spritz.Spec.SSH == nil {
+		if len(principals) == 0 {
+			return writeJSON(c, http.StatusOK, spritz)
+		}
+		// Creating spec.ssh here would also change the ports the operator
+		// renders, so the spritz must already configure it.
+		return writeError(c, http.StatusConflict, "spec.ssh is not configured for this spritz")
+	}
+	spritz.Spec.SSH.AdditionalPrincipals = principals
+	if err := s.client.Update(c.Request().Context(), spritz); err != nil {
+		return writeError(c, http.StatusInternalServerError, err.Error())
+	}
+	log.Printf("spritz ssh: principals updated name=%s namespace=%s principals=%s by=%s", name, namespace, strings.Join(principals, ","), principal.ID)
+	return writeJSON(c, http.StatusOK, spritz)
+}
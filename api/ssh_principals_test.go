@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	gossh "golang.org/x/crypto/ssh"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func newSSHPrincipalsTestServer(t *testing.T, principals ...string) (*server, *echo.Echo) {
+	t.Helper()
+	spritz := spritzForOwner("tidy-otter", "user-1", nil)
+	spritz.Spec.SSH = &spritzv1.SpritzSSH{Enabled: true, Mode: "gateway", AdditionalPrincipals: principals}
+	s := newListSpritzTestServer(t, spritz)
+	s.auth.adminIDs = map[string]struct{}{"admin-1": {}}
+	s.sshGateway = sshGatewayConfig{
+		enabled:         true,
+		principalPrefix: "spritz",
+		caSigner:        newTestSSHSigner(t),
+		certTTL:         time.Minute,
+	}
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes/:name/ssh", s.mintSSHCert)
+	secured.PUT("/api/spritzes/:name/ssh/principals", s.updateSSHPrincipals)
+	return s, e
+}
+
+func serveSSHPrincipalsRequest(e *echo.Echo, method, path, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("X-Spritz-User-Id", userID)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUpdateSSHPrincipalsIsAdminOnly(t *testing.T) {
+	s, e := newSSHPrincipalsTestServer(t)
+	path := "/api/spritzes/tidy-otter/ssh/principals"
+
+	if rec := serveSSHPrincipalsRequest(e, http.MethodPut, path, "user-1", `{"principals":["user-2"]}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected owner to be forbidden, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveSSHPrincipalsRequest(e, http.MethodPut, path, "admin-1", `{"principals":["user-2"," user-2 ","user-3"]}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected admin update to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	spritz := &spritzv1.Spritz{}
+	if err := s.client.Get(context.Background(), clientKey("spritz-test", "tidy-otter"), spritz); err != nil {
+		t.Fatalf("get spritz: %v", err)
+	}
+	got := spritz.Spec.SSH.AdditionalPrincipals
+	if len(got) != 2 || got[0] != "user-2" || got[1] != "user-3" {
+		t.Fatalf("expected deduplicated principals, got %#v", got)
+	}
+}
+
+func TestMintSSHCertAllowsAdditionalPrincipalUntilRevoked(t *testing.T) {
+	s, e := newSSHPrincipalsTestServer(t, "user-2")
+	publicKey := string(gossh.MarshalAuthorizedKey(newTestSSHSigner(t).PublicKey()))
+	body, _ := json.Marshal(sshMintRequest{PublicKey: publicKey})
+
+	if rec := serveSSHPrincipalsRequest(e, http.MethodPost, "/api/spritzes/tidy-otter/ssh", "user-3", string(body)); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected unlisted user to be forbidden, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec := serveSSHPrincipalsRequest(e, http.MethodPost, "/api/spritzes/tidy-otter/ssh", "user-2", string(body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected additional principal to mint, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var payload struct {
+		Data sshMintResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	parsed, _, _, _, err := gossh.ParseAuthorizedKey([]byte(payload.Data.Cert))
+	if err != nil {
+		t.Fatalf("parse cert: %v", err)
+	}
+	cert := parsed.(*gossh.Certificate)
+	if _, ok := cert.Extensions[sshAdditionalPrincipalExtension]; !ok {
+		t.Fatalf("expected cert to carry the additional principal extension, got %#v", cert.Extensions)
+	}
+	if !s.sshAdditionalPrincipalStillGranted(context.Background(), payload.Data.User, cert) {
+		t.Fatal("expected granted cert to pass the gateway check")
+	}
+
+	if rec := serveSSHPrincipalsRequest(e, http.MethodPut, "/api/spritzes/tidy-otter/ssh/principals", "admin-1", `{"principals":[]}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected admin to clear principals, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if s.sshAdditionalPrincipalStillGranted(context.Background(), payload.Data.User, cert) {
+		t.Fatal("expected revoked cert to be refused by the gateway check")
+	}
+}
+
+func TestCreateSpritzReservesSSHAdditionalPrincipalsForAdmins(t *testing.T) {
+	s := newCreateSpritzTestServer(t)
+	e := echo.New()
+	secured := e.Group("", s.authMiddleware())
+	secured.POST("/api/spritzes", s.createSpritz)
+
+	body := `{"name":"tidal-ember","spec":{"image":"example.com/spritz:latest","ssh":{"enabled":true,"additionalPrincipals":["user-2"]}}}`
+	rec := serveSSHPrincipalsRequest(e, http.MethodPost, "/api/spritzes", "current-user", body)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "additionalPrincipals") {
+		t.Fatalf("expected non-admin create to be forbidden, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
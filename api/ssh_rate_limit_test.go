@@ -1,6 +1,10 @@
 package main
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+	"time"
+)
 
 func TestNewSSHMintLimiterAllowsZeroLimit(t *testing.T) {
 	t.Setenv("SPRITZ_SSH_MINT_LIMIT", "0")
@@ -12,3 +16,40 @@ func TestNewSSHMintLimiterAllowsZeroLimit(t *testing.T) {
 		t.Fatal("expected limiter to be disabled when SPRITZ_SSH_MINT_LIMIT=0")
 	}
 }
+
+func TestSSHMintLimiterBoundsBuckets(t *testing.T) {
+	t.Setenv("SPRITZ_SSH_MINT_LIMIT", "1")
+	t.Setenv("SPRITZ_SSH_MINT_WINDOW", "1h")
+	t.Setenv("SPRITZ_SSH_MINT_MAX_BUCKETS", "100")
+
+	limiter := newSSHMintLimiter()
+	if limiter.maxBuckets != 100 {
+		t.Fatalf("expected max buckets of 100, got %d", limiter.maxBuckets)
+	}
+	for i := 0; i < 1000; i++ {
+		limiter.Allow(fmt.Sprintf("user-%d:spritz-test/workspace-%d", i, i))
+	}
+	if got := len(limiter.buckets); got != 100 {
+		t.Fatalf("expected 100 buckets, got %d", got)
+	}
+}
+
+func TestSSHMintLimiterReadsBucketTTL(t *testing.T) {
+	t.Setenv("SPRITZ_SSH_MINT_LIMIT", "1")
+	t.Setenv("SPRITZ_SSH_MINT_WINDOW", "1h")
+	t.Setenv("SPRITZ_SSH_MINT_BUCKET_TTL", "10m")
+	t.Setenv("SPRITZ_SSH_MINT_BUCKET_CLEANUP", "1m")
+
+	limiter := newSSHMintLimiter()
+	if limiter.bucketTTL != 10*time.Minute || limiter.cleanupInterval != time.Minute {
+		t.Fatalf("expected ttl 10m and cleanup 1m, got %s and %s", limiter.bucketTTL, limiter.cleanupInterval)
+	}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	if ok, _ := limiter.Allow("user-1:spritz-test/workspace"); !ok {
+		t.Fatal("expected first mint to be allowed")
+	}
+	if ok, retryAfter := limiter.Allow("user-1:spritz-test/workspace"); ok || retryAfter != time.Hour {
+		t.Fatalf("expected second mint to wait 1h, got ok=%v retry_after=%s", ok, retryAfter)
+	}
+}
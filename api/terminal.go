@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -27,29 +29,58 @@ import (
 )
 
 type terminalConfig struct {
-	enabled          bool
-	containerName    string
-	command          []string
-	allowedOrigins   map[string]struct{}
-	sessionMode      terminalSessionMode
-	activityDebounce time.Duration
+	enabled        bool
+	containerName  string
+	command        []string
+	allowedOrigins map[string]struct{}
+	// allowIngressOrigin also accepts the spritz's own spec.ingress.host as
+	// an https origin, in addition to allowedOrigins, when the host is under
+	// ingressOriginSuffix. Users set the host themselves, so the suffix keeps
+	// it to domains the operator serves.
+	allowIngressOrigin  bool
+	ingressOriginSuffix string
+	sessionMode         terminalSessionMode
+	activityDebounce    time.Duration
+	// pingInterval spaces websocket pings; a peer that misses two in a row is
+	// treated as gone. Zero disables keepalive.
+	pingInterval time.Duration
+	// sharedSessions lets later connections to a session watch the first one
+	// read-only instead of opening their own exec stream.
+	sharedSessions bool
+	// forwardEnv selects the spec.env entries exported into terminal shells.
+	forwardEnv terminalEnvFilter
+	// subprotocols are extra websocket subprotocols accepted after
+	// spritz-terminal.v1, for client libraries that insist on their own.
+	subprotocols []string
 }
 
 type terminalSessionMode string
 
+// terminalRoleHeader tells shared-session clients whether they own the
+// terminal or only watch it.
+const terminalRoleHeader = "X-Spritz-Terminal-Role"
+
 const (
-	terminalSessionNone terminalSessionMode = "none"
-	terminalSessionZmx  terminalSessionMode = "zmx"
+	terminalSessionNone   terminalSessionMode = "none"
+	terminalSessionZmx    terminalSessionMode = "zmx"
+	terminalSessionTmux   terminalSessionMode = "tmux"
+	terminalSessionScreen terminalSessionMode = "screen"
 )
 
 func newTerminalConfig() terminalConfig {
 	return terminalConfig{
-		enabled:          parseBoolEnv("SPRITZ_TERMINAL_ENABLED", true),
-		containerName:    envOrDefault("SPRITZ_TERMINAL_CONTAINER", "spritz"),
-		command:          splitCommand(envOrDefault("SPRITZ_TERMINAL_COMMAND", "bash -l")),
-		allowedOrigins:   splitSet(os.Getenv("SPRITZ_TERMINAL_ORIGINS")),
-		sessionMode:      parseTerminalSessionMode(os.Getenv("SPRITZ_TERMINAL_SESSION_MODE")),
-		activityDebounce: parseDurationEnv("SPRITZ_TERMINAL_ACTIVITY_DEBOUNCE", 5*time.Second),
+		enabled:             parseBoolEnv("SPRITZ_TERMINAL_ENABLED", true),
+		containerName:       envOrDefault("SPRITZ_TERMINAL_CONTAINER", "spritz"),
+		command:             splitCommand(envOrDefault("SPRITZ_TERMINAL_COMMAND", "bash -l")),
+		allowedOrigins:      splitSet(os.Getenv("SPRITZ_TERMINAL_ORIGINS")),
+		allowIngressOrigin:  parseBoolEnv("SPRITZ_TERMINAL_ALLOW_INGRESS_ORIGIN", false),
+		ingressOriginSuffix: strings.ToLower(strings.Trim(strings.TrimSpace(os.Getenv("SPRITZ_TERMINAL_INGRESS_ORIGIN_SUFFIX")), ".")),
+		sessionMode:         parseTerminalSessionMode(os.Getenv("SPRITZ_TERMINAL_SESSION_MODE")),
+		activityDebounce:    parseDurationEnv("SPRITZ_TERMINAL_ACTIVITY_DEBOUNCE", 5*time.Second),
+		pingInterval:        parseDurationEnv("SPRITZ_TERMINAL_PING_INTERVAL", 30*time.Second),
+		sharedSessions:      parseBoolEnv("SPRITZ_TERMINAL_SHARED_SESSIONS", false),
+		forwardEnv:          parseTerminalEnvFilter(os.Getenv("SPRITZ_TERMINAL_FORWARD_ENV")),
+		subprotocols:        splitList(os.Getenv("SPRITZ_TERMINAL_SUBPROTOCOLS")),
 	}
 }
 
@@ -68,6 +99,10 @@ func parseTerminalSessionMode(value string) terminalSessionMode {
 		return terminalSessionZmx
 	case "zmx":
 		return terminalSessionZmx
+	case "tmux":
+		return terminalSessionTmux
+	case "screen":
+		return terminalSessionScreen
 	case "none":
 		return terminalSessionNone
 	default:
@@ -75,6 +110,19 @@ func parseTerminalSessionMode(value string) terminalSessionMode {
 	}
 }
 
+// acceptedSubprotocols lists the subprotocols the upgrader may select, in
+// server preference order. The upgrader echoes the first one the client
+// offered; a client that offers none of them gets no subprotocol.
+func (t terminalConfig) acceptedSubprotocols(authenticated []string) []string {
+	accepted := append([]string(nil), authenticated...)
+	for _, protocol := range t.subprotocols {
+		if !slices.Contains(accepted, protocol) {
+			accepted = append(accepted, protocol)
+		}
+	}
+	return accepted
+}
+
 func (t terminalConfig) allowOrigin(r *http.Request) bool {
 	origin := strings.TrimSpace(r.Header.Get("Origin"))
 	if len(t.allowedOrigins) == 0 {
@@ -93,6 +141,33 @@ func (t terminalConfig) allowOrigin(r *http.Request) bool {
 	return hasSetValue(t.allowedOrigins, origin)
 }
 
+// allowOriginFor extends allowOrigin with the spritz's ingress host, so a
+// workspace served at its own domain can open its terminal without listing
+// every host in SPRITZ_TERMINAL_ORIGINS. Only https origins on a host under
+// ingressOriginSuffix are accepted.
+func (t terminalConfig) allowOriginFor(spritz *spritzv1.Spritz) func(*http.Request) bool {
+	ingressHost := ""
+	if t.allowIngressOrigin && t.ingressOriginSuffix != "" && spritz != nil && spritz.Spec.Ingress != nil {
+		host := strings.ToLower(strings.TrimSpace(spritz.Spec.Ingress.Host))
+		if strings.HasSuffix(host, "."+t.ingressOriginSuffix) {
+			ingressHost = host
+		}
+	}
+	return func(r *http.Request) bool {
+		if t.allowOrigin(r) {
+			return true
+		}
+		if ingressHost == "" {
+			return false
+		}
+		parsed, err := url.Parse(strings.TrimSpace(r.Header.Get("Origin")))
+		if err != nil || parsed.Scheme != "https" {
+			return false
+		}
+		return strings.EqualFold(parsed.Host, ingressHost) || strings.EqualFold(parsed.Hostname(), ingressHost)
+	}
+}
+
 func hasSetValue(values map[string]struct{}, key string) bool {
 	if _, ok := values[key]; ok {
 		return true
@@ -148,12 +223,53 @@ func (s *server) openTerminal(c echo.Context) error {
 		log.Printf("spritz terminal: pod not ready name=%s namespace=%s user_id=%s err=%v", name, namespace, principal.ID, err)
 		return writeError(c, http.StatusConflict, "spritz not ready")
 	}
+	ctx, done, ok := s.sessionDrain.track(c.Request().Context())
+	if !ok {
+		return writeError(c, http.StatusServiceUnavailable, "server shutting down")
+	}
+	defer done()
 
 	upgrader := websocket.Upgrader{
-		CheckOrigin:  s.terminal.allowOrigin,
-		Subprotocols: subprotocols,
+		CheckOrigin:  s.terminal.allowOriginFor(spritz),
+		Subprotocols: s.terminal.acceptedSubprotocols(subprotocols),
+		Error:        websocketUpgradeError,
+	}
+	// Viewers count against the session limits like owners: each one holds a
+	// websocket and a fan-out buffer.
+	release, ok := s.execSessions.Acquire(execSessionSpritzKey(namespace, name), principal.ID)
+	if !ok {
+		log.Printf("spritz terminal: session limit name=%s namespace=%s user_id=%s", name, namespace, principal.ID)
+		return writeError(c, http.StatusTooManyRequests, "too many terminal sessions")
 	}
-	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	defer release()
+
+	session := strings.TrimSpace(c.QueryParam("session"))
+	var hub *terminalHub
+	if s.terminal.sharedSessions && s.terminalHubs != nil {
+		shared, _ := strconv.ParseBool(strings.TrimSpace(c.QueryParam("share")))
+		key := terminalHubKey(namespace, name, session, principal.ID, shared)
+		var owner bool
+		hub, owner = s.terminalHubs.join(key)
+		if !owner {
+			conn, err := upgrader.Upgrade(c.Response(), c.Request(), http.Header{terminalRoleHeader: []string{"viewer"}})
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = conn.Close()
+			}()
+			log.Printf("spritz terminal: viewer attach name=%s namespace=%s session=%s user_id=%s", name, namespace, session, principal.ID)
+			serveTerminalViewer(ctx, hub, conn, s.terminal.pingInterval)
+			return nil
+		}
+		defer s.terminalHubs.release(key, hub)
+	}
+
+	var responseHeader http.Header
+	if hub != nil {
+		responseHeader = http.Header{terminalRoleHeader: []string{"owner"}}
+	}
+	conn, err := upgrader.Upgrade(c.Response(), c.Request(), responseHeader)
 	if err != nil {
 		return err
 	}
@@ -161,18 +277,19 @@ func (s *server) openTerminal(c echo.Context) error {
 		_ = conn.Close()
 	}()
 
-	session := strings.TrimSpace(c.QueryParam("session"))
-	command, resolvedSession, usingZmx, err := s.resolveTerminalCommand(c.Request().Context(), pod, namespace, name, session)
+	command, resolvedSession, attached, err := s.resolveTerminalCommand(ctx, spritz, pod, namespace, name, session)
 	if err != nil {
-		return err
+		log.Printf("spritz terminal: session resolve failed name=%s namespace=%s session=%s user_id=%s err=%v", name, namespace, session, principal.ID, err)
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "terminal session unavailable"), time.Now().Add(500*time.Millisecond))
+		return nil
 	}
-	if err := s.markSpritzActivity(c.Request().Context(), namespace, name, time.Now()); err != nil {
+	if err := s.markSpritzActivity(ctx, namespace, name, time.Now()); err != nil {
 		log.Printf("spritz terminal: failed to record activity name=%s namespace=%s user_id=%s err=%v", name, namespace, principal.ID, err)
 	}
-	if usingZmx {
-		log.Printf("spritz terminal: zmx attach name=%s namespace=%s session=%s user_id=%s", name, namespace, resolvedSession, principal.ID)
+	if attached {
+		log.Printf("spritz terminal: session attach mode=%s name=%s namespace=%s session=%s user_id=%s", s.terminal.sessionMode, name, namespace, resolvedSession, principal.ID)
 	}
-	if err := s.streamTerminal(c.Request().Context(), namespace, name, pod, conn, command); err != nil {
+	if err := s.streamTerminal(ctx, namespace, name, pod, conn, command, hub); err != nil {
 		if errors.Is(err, context.Canceled) {
 			return nil
 		}
@@ -209,11 +326,14 @@ func (s *server) findRunningPod(ctx context.Context, namespace, name, container
 	return nil, fmt.Errorf("spritz not ready")
 }
 
-func (s *server) streamTerminal(ctx context.Context, namespace, name string, pod *corev1.Pod, conn *websocket.Conn, command []string) error {
+// streamTerminal runs command in the pod and wires it to conn. When hub is set,
+// output is also copied to the session's viewers.
+func (s *server) streamTerminal(ctx context.Context, namespace, name string, pod *corev1.Pod, conn *websocket.Conn, command []string, hub *terminalHub) error {
 	if len(command) == 0 {
 		return errors.New("terminal command missing")
 	}
 
+	sessionCtx := ctx
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -239,7 +359,10 @@ func (s *server) streamTerminal(ctx context.Context, namespace, name string, pod
 
 	stdinReader, stdinWriter := io.Pipe()
 	sizeQueue := newTerminalSizeQueue()
-	wsWriter := &terminalWSWriter{conn: conn}
+	var output io.Writer = &terminalWSWriter{conn: conn}
+	if hub != nil {
+		output = io.MultiWriter(output, hub)
+	}
 	reportActivity := debounceTerminalActivity(s.terminal.activityDebounce, func() {
 		refreshCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		defer cancel()
@@ -248,21 +371,26 @@ func (s *server) streamTerminal(ctx context.Context, namespace, name string, pod
 		}
 	})
 
+	keepTerminalAlive(ctx, conn, s.terminal.pingInterval)
+
 	readErr := make(chan error, 1)
 	go func() {
-		readErr <- readTerminalInput(ctx, conn, stdinWriter, sizeQueue, reportActivity)
+		err := readTerminalInput(ctx, conn, stdinWriter, sizeQueue, reportActivity)
+		readErr <- err
+		// A closed or silent peer cannot receive output; stop the exec stream.
+		cancel()
 	}()
 
 	streamErr := executor.StreamWithContext(ctx, remotecommand.StreamOptions{
 		Stdin:             stdinReader,
-		Stdout:            wsWriter,
-		Stderr:            wsWriter,
+		Stdout:            output,
+		Stderr:            output,
 		Tty:               true,
 		TerminalSizeQueue: sizeQueue,
 	})
 	_ = stdinWriter.Close()
 	cancel()
-	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(500*time.Millisecond))
+	_ = conn.WriteControl(websocket.CloseMessage, terminalCloseMessage(sessionCtx), time.Now().Add(500*time.Millisecond))
 	_ = conn.Close()
 
 	select {
@@ -276,12 +404,24 @@ func (s *server) streamTerminal(ctx context.Context, namespace, name string, pod
 	return streamErr
 }
 
+// terminalCloseMessage tells the client why its terminal ended. A going-away
+// close during shutdown lets it reconnect to another replica.
+func terminalCloseMessage(ctx context.Context) []byte {
+	if shuttingDown(ctx) {
+		return websocket.FormatCloseMessage(websocket.CloseGoingAway, errServerShuttingDown.Error())
+	}
+	return websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+}
+
 type resizeMessage struct {
 	Type string `json:"type"`
 	Cols int    `json:"cols"`
 	Rows int    `json:"rows"`
 }
 
+// readTerminalInput forwards client frames to stdin. Binary and text frames
+// are both accepted as input; a text frame holding a resize message such as
+// {"type":"resize","cols":80,"rows":24} resizes the terminal instead.
 func readTerminalInput(ctx context.Context, conn *websocket.Conn, stdin *io.PipeWriter, sizeQueue *terminalSizeQueue, onInput func()) error {
 	for {
 		select {
@@ -345,6 +485,36 @@ func handleResize(payload []byte, sizeQueue *terminalSizeQueue) bool {
 	return true
 }
 
+// keepTerminalAlive pings the browser so idle proxies keep the connection open,
+// and expires reads when pongs stop arriving so dead peers are noticed.
+func keepTerminalAlive(ctx context.Context, conn *websocket.Conn, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	pongWait := 2 * interval
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// WriteControl is safe to call concurrently with terminalWSWriter.
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// terminalWSWriter sends terminal output as binary frames: output is raw
+// bytes and may split UTF-8 sequences, which text frames may not carry.
 type terminalWSWriter struct {
 	conn *websocket.Conn
 	mu   sync.Mutex
@@ -390,34 +560,58 @@ func terminalDefaultSession(namespace, name string) string {
 	return fmt.Sprintf("spritz:%s:%s", ns, strings.TrimSpace(name))
 }
 
-func (s *server) resolveTerminalCommand(ctx context.Context, pod *corev1.Pod, namespace, name, session string) ([]string, string, bool, error) {
-	if len(s.terminal.command) == 0 {
+// terminalCommandFor prefers spec.terminal.command over the configured default.
+func terminalCommandFor(spritz *spritzv1.Spritz, fallback []string) []string {
+	if spritz != nil && spritz.Spec.Terminal != nil && len(spritz.Spec.Terminal.Command) > 0 {
+		return spritz.Spec.Terminal.Command
+	}
+	return fallback
+}
+
+func validateTerminalCommand(terminal *spritzv1.SpritzTerminal) error {
+	if terminal == nil {
+		return nil
+	}
+	if len(terminal.Command) == 0 {
+		return fmt.Errorf("spec.terminal.command must not be empty")
+	}
+	for _, part := range terminal.Command {
+		if strings.TrimSpace(part) == "" {
+			return fmt.Errorf("spec.terminal.command entries must not be empty")
+		}
+	}
+	return nil
+}
+
+func (s *server) resolveTerminalCommand(ctx context.Context, spritz *spritzv1.Spritz, pod *corev1.Pod, namespace, name, session string) ([]string, string, bool, error) {
+	base := terminalCommandFor(spritz, s.terminal.command)
+	if len(base) == 0 {
 		return nil, "", false, errors.New("terminal command missing")
 	}
-	if s.terminal.sessionMode != terminalSessionZmx {
-		return s.terminal.command, "", false, nil
+	base = s.terminal.forwardEnv.withTerminalEnv(spritz, base)
+	provider, ok := s.terminalSessionProvider()
+	if !ok {
+		return base, "", false, nil
 	}
 	checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
-	available, err := s.zmxAvailable(checkCtx, pod)
+	available, err := s.sessionProviderAvailable(checkCtx, pod, provider)
 	if err != nil {
-		log.Printf("spritz terminal: zmx check failed name=%s namespace=%s err=%v", name, namespace, err)
-		return s.terminal.command, "", false, nil
+		log.Printf("spritz terminal: %s check failed name=%s namespace=%s err=%v", provider.binary(), name, namespace, err)
+		return base, "", false, nil
 	}
 	if !available {
-		return s.terminal.command, "", false, nil
+		return base, "", false, nil
 	}
 	resolved := strings.TrimSpace(session)
 	if resolved == "" {
 		resolved = terminalDefaultSession(namespace, name)
 	}
+	resolved = provider.sessionName(resolved)
 	if resolved == "" {
-		return s.terminal.command, "", false, nil
+		return base, "", false, nil
 	}
-	command := make([]string, 0, len(s.terminal.command)+3)
-	command = append(command, "zmx", "attach", resolved)
-	command = append(command, s.terminal.command...)
-	return command, resolved, true, nil
+	return provider.attachCommand(resolved, base), resolved, true, nil
 }
 
 func (s *server) execInContainer(ctx context.Context, pod *corev1.Pod, command []string) (string, string, error) {
@@ -453,67 +647,6 @@ func (s *server) execInContainer(ctx context.Context, pod *corev1.Pod, command [
 	return stdout.String(), stderr.String(), nil
 }
 
-func (s *server) zmxAvailable(ctx context.Context, pod *corev1.Pod) (bool, error) {
-	stdout, _, err := s.execInContainer(ctx, pod, []string{"sh", "-lc", "if command -v zmx >/dev/null 2>&1; then echo ready; else echo missing; fi"})
-	if err != nil {
-		return false, err
-	}
-	return strings.Contains(stdout, "ready"), nil
-}
-
-func parseZmxSessionList(output string) []string {
-	lines := strings.Split(output, "\n")
-	if len(lines) == 0 {
-		return nil
-	}
-	sessions := make([]string, 0, len(lines))
-	seen := make(map[string]struct{})
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		if strings.HasPrefix(strings.ToLower(line), "no sessions found") {
-			return nil
-		}
-		fields := strings.Fields(line)
-		if len(fields) == 0 {
-			continue
-		}
-		name := ""
-		for _, field := range fields {
-			if strings.HasPrefix(field, "session_name=") {
-				name = strings.TrimPrefix(field, "session_name=")
-				break
-			}
-		}
-		if name == "" {
-			name = fields[0]
-		}
-		for strings.HasPrefix(name, "session_name=") {
-			name = strings.TrimPrefix(name, "session_name=")
-		}
-		name = strings.TrimSpace(name)
-		if name == "" {
-			continue
-		}
-		if _, ok := seen[name]; ok {
-			continue
-		}
-		seen[name] = struct{}{}
-		sessions = append(sessions, name)
-	}
-	return sessions
-}
-
-func (s *server) listZmxSessions(ctx context.Context, pod *corev1.Pod) ([]string, error) {
-	stdout, stderr, err := s.execInContainer(ctx, pod, []string{"zmx", "list"})
-	if err != nil {
-		return nil, fmt.Errorf("zmx list failed: %w (stderr=%s)", err, strings.TrimSpace(stderr))
-	}
-	return parseZmxSessionList(stdout), nil
-}
-
 func clientKey(namespace, name string) client.ObjectKey {
 	return client.ObjectKey{Namespace: namespace, Name: name}
 }
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// terminalViewerBuffer is how many output frames a viewer may lag behind
+// before it is disconnected.
+const terminalViewerBuffer = 256
+
+// terminalHubRegistry tracks the terminal sessions that viewers can watch,
+// keyed by spritz, session name, and who may watch.
+type terminalHubRegistry struct {
+	mu   sync.Mutex
+	hubs map[string]*terminalHub
+}
+
+func newTerminalHubRegistry() *terminalHubRegistry {
+	return &terminalHubRegistry{hubs: map[string]*terminalHub{}}
+}
+
+// terminalHubKey scopes a session hub to principalID, so only that
+// principal's later connections watch it. With shared set, the hub is open to
+// every caller allowed to open the terminal who also asks to share.
+func terminalHubKey(namespace, name, session, principalID string, shared bool) string {
+	if session == "" {
+		session = terminalDefaultSession(namespace, name)
+	}
+	scope := "principal:" + principalID
+	if shared {
+		scope = "shared"
+	}
+	return namespace + "/" + name + "/" + session + "#" + scope
+}
+
+// join returns the hub for key. The caller that creates it owns the session
+// and must call release when its exec stream ends.
+func (r *terminalHubRegistry) join(key string) (*terminalHub, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hub, ok := r.hubs[key]; ok {
+		return hub, false
+	}
+	hub := newTerminalHub()
+	r.hubs[key] = hub
+	return hub, true
+}
+
+func (r *terminalHubRegistry) release(key string, hub *terminalHub) {
+	r.mu.Lock()
+	if r.hubs[key] == hub {
+		delete(r.hubs, key)
+	}
+	r.mu.Unlock()
+	hub.close()
+}
+
+// terminalHub fans the owner's terminal output out to read-only viewers.
+type terminalHub struct {
+	mu      sync.Mutex
+	viewers map[*terminalViewer]struct{}
+	closed  bool
+}
+
+type terminalViewer struct {
+	send chan []byte
+	// dropped is set before send is closed when the viewer fell behind.
+	dropped bool
+}
+
+func newTerminalHub() *terminalHub {
+	return &terminalHub{viewers: map[*terminalViewer]struct{}{}}
+}
+
+// Write never blocks: a viewer whose buffer is full is dropped instead of
+// stalling the owner and the other viewers.
+func (h *terminalHub) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed || len(h.viewers) == 0 {
+		return len(p), nil
+	}
+	frame := append([]byte(nil), p...)
+	for viewer := range h.viewers {
+		select {
+		case viewer.send <- frame:
+		default:
+			viewer.dropped = true
+			delete(h.viewers, viewer)
+			close(viewer.send)
+		}
+	}
+	return len(p), nil
+}
+
+func (h *terminalHub) add() (*terminalViewer, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, false
+	}
+	viewer := &terminalViewer{send: make(chan []byte, terminalViewerBuffer)}
+	h.viewers[viewer] = struct{}{}
+	return viewer, true
+}
+
+func (h *terminalHub) remove(viewer *terminalViewer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.viewers[viewer]; ok {
+		delete(h.viewers, viewer)
+		close(viewer.send)
+	}
+}
+
+func (h *terminalHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for viewer := range h.viewers {
+		delete(h.viewers, viewer)
+		close(viewer.send)
+	}
+}
+
+// serveTerminalViewer streams hub output to conn until the owner's session
+// ends, the viewer disconnects, or it falls behind. Viewer input is discarded.
+func serveTerminalViewer(ctx context.Context, hub *terminalHub, conn *websocket.Conn, pingInterval time.Duration) {
+	viewer, ok := hub.add()
+	if !ok {
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "terminal session ended"), time.Now().Add(500*time.Millisecond))
+		return
+	}
+	defer hub.remove(viewer)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	keepTerminalAlive(ctx, conn, pingInterval)
+	go func() {
+		// Reading services pongs and notices the viewer leaving.
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if shuttingDown(ctx) {
+				_ = conn.WriteControl(websocket.CloseMessage, terminalCloseMessage(ctx), time.Now().Add(500*time.Millisecond))
+			}
+			return
+		case frame, ok := <-viewer.send:
+			if !ok {
+				code, reason := websocket.CloseNormalClosure, "terminal session ended"
+				if viewer.dropped {
+					code, reason = websocket.ClosePolicyViolation, "terminal viewer fell behind"
+				}
+				_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(500*time.Millisecond))
+				return
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
+				return
+			}
+		}
+	}
+}
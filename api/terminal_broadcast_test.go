@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestTerminalHubRegistryMakesFirstJoinerOwner(t *testing.T) {
+	registry := newTerminalHubRegistry()
+	key := terminalHubKey("default", "demo", "", "user-1", false)
+	hub, owner := registry.join(key)
+	if !owner {
+		t.Fatal("expected first joiner to own the session")
+	}
+	again, owner := registry.join(key)
+	if owner || again != hub {
+		t.Fatal("expected later joiners to share the owner's hub")
+	}
+	registry.release(key, hub)
+	if _, ok := hub.add(); ok {
+		t.Fatal("expected released hub to refuse new viewers")
+	}
+	if _, owner := registry.join(key); !owner {
+		t.Fatal("expected a new owner after release")
+	}
+}
+
+func TestTerminalHubKeyIsPerPrincipalUnlessShared(t *testing.T) {
+	own := terminalHubKey("default", "demo", "", "user-1", false)
+	if other := terminalHubKey("default", "demo", "", "user-2", false); other == own {
+		t.Fatal("expected each principal to get its own hub")
+	}
+	shared := terminalHubKey("default", "demo", "", "user-1", true)
+	if shared == own {
+		t.Fatal("expected a shared hub to be separate from the private one")
+	}
+	if other := terminalHubKey("default", "demo", "", "user-2", true); other != shared {
+		t.Fatal("expected principals that ask to share to join the same hub")
+	}
+}
+
+func TestOpenTerminalCountsViewersAgainstSessionLimit(t *testing.T) {
+	t.Setenv("SPRITZ_EXEC_SESSION_LIMIT_PER_SPRITZ", "1")
+	s := newCreateSpritzTestServer(t)
+	spritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidal-falcon", Namespace: s.namespace},
+		Spec:       spritzv1.SpritzSpec{Owner: spritzv1.SpritzOwner{ID: "user-1"}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidal-falcon-pod", Namespace: s.namespace, Labels: map[string]string{nameLabelKey: "tidal-falcon"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	for _, obj := range []client.Object{spritz, pod} {
+		if err := s.client.Create(context.Background(), obj); err != nil {
+			t.Fatalf("create %T: %v", obj, err)
+		}
+	}
+	s.terminal = terminalConfig{enabled: true, containerName: "spritz", sharedSessions: true}
+	s.terminalHubs = newTerminalHubRegistry()
+	s.execSessions = newExecSessionLimiter()
+	// An owner already holds the session and the only slot.
+	if _, ok := s.execSessions.Acquire(execSessionSpritzKey(s.namespace, "tidal-falcon"), "user-1"); !ok {
+		t.Fatal("expected to reserve the only session slot")
+	}
+	s.terminalHubs.join(terminalHubKey(s.namespace, "tidal-falcon", "", "user-1", false))
+	e := echo.New()
+	e.GET("/api/spritzes/:name/terminal", s.openTerminal)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spritzes/tidal-falcon/terminal", nil)
+	req.Header.Set("X-Spritz-User-Id", "user-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected viewer over the session limit to get 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTerminalHubDropsSlowViewerWithoutBlocking(t *testing.T) {
+	hub := newTerminalHub()
+	fast, _ := hub.add()
+	slow, _ := hub.add()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < terminalViewerBuffer+1; i++ {
+			_, _ = hub.Write([]byte("x"))
+			<-fast.send
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected writes to continue while a viewer is not reading")
+	}
+
+	for range slow.send {
+	}
+	if !slow.dropped {
+		t.Fatal("expected the slow viewer to be dropped")
+	}
+	if fast.dropped {
+		t.Fatal("expected the fast viewer to stay attached")
+	}
+}
+
+func TestServeTerminalViewerStreamsOutputUntilSessionEnds(t *testing.T) {
+	conn, clientConn := dialTerminalTestConn(t)
+	hub := newTerminalHub()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serveTerminalViewer(context.Background(), hub, conn, 0)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		hub.mu.Lock()
+		attached := len(hub.viewers)
+		hub.mu.Unlock()
+		if attached == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for viewer to attach")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := hub.Write([]byte("hello")); err != nil {
+		t.Fatalf("hub write failed: %v", err)
+	}
+	_, payload, err := clientConn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read viewer output: %v", err)
+	}
+	if string(payload) != "hello" {
+		t.Fatalf("unexpected viewer output %q", payload)
+	}
+
+	hub.close()
+	if _, _, err := clientConn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Fatalf("expected normal close when the session ends, got %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for viewer to exit")
+	}
+}
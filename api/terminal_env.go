@@ -0,0 +1,72 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// terminalEnvFilter selects the spec.env entries passed to terminal and SSH
+// shells. Only entries with a literal value are forwarded: valueFrom entries
+// may reference secrets, and resolving them here would copy secret material
+// into exec arguments. The zero value forwards nothing.
+type terminalEnvFilter struct {
+	all   bool
+	names map[string]struct{}
+}
+
+// parseTerminalEnvFilter reads SPRITZ_TERMINAL_FORWARD_ENV. Forwarding is
+// opt-in because forwarded values end up in exec URLs, apiserver audit logs
+// and the process list: empty or "none" forwards nothing, "*" every literal
+// entry, and anything else is a comma-separated allowlist of variable names.
+func parseTerminalEnvFilter(value string) terminalEnvFilter {
+	trimmed := strings.TrimSpace(value)
+	switch strings.ToLower(trimmed) {
+	case "", "none":
+		return terminalEnvFilter{}
+	case "*":
+		return terminalEnvFilter{all: true}
+	}
+	return terminalEnvFilter{names: splitSet(trimmed)}
+}
+
+func (f terminalEnvFilter) allows(name string) bool {
+	if f.all {
+		return true
+	}
+	_, ok := f.names[name]
+	return ok
+}
+
+var terminalEnvNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// terminalEnv returns the NAME=value pairs forwarded from the spritz spec.
+// Names a shell could not export are skipped.
+func (f terminalEnvFilter) terminalEnv(spritz *spritzv1.Spritz) []string {
+	if spritz == nil {
+		return nil
+	}
+	var env []string
+	for _, entry := range spritz.Spec.Env {
+		name := strings.TrimSpace(entry.Name)
+		if entry.ValueFrom != nil || !terminalEnvNamePattern.MatchString(name) || !f.allows(name) {
+			continue
+		}
+		env = append(env, name+"="+entry.Value)
+	}
+	return env
+}
+
+// withTerminalEnv prefixes command with `env NAME=value ...` so the shell,
+// including one started inside a session manager, sees the spritz env.
+func (f terminalEnvFilter) withTerminalEnv(spritz *spritzv1.Spritz, command []string) []string {
+	env := f.terminalEnv(spritz)
+	if len(env) == 0 || len(command) == 0 {
+		return command
+	}
+	wrapped := make([]string, 0, len(env)+len(command)+1)
+	wrapped = append(wrapped, "env")
+	wrapped = append(wrapped, env...)
+	return append(wrapped, command...)
+}
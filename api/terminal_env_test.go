@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func terminalEnvTestSpritz() *spritzv1.Spritz {
+	return &spritzv1.Spritz{Spec: spritzv1.SpritzSpec{Env: []corev1.EnvVar{
+		{Name: "EDITOR", Value: "vim"},
+		{Name: "API_TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "tokens"},
+			Key:                  "api",
+		}}},
+		{Name: "-i", Value: "ignored"},
+		{Name: "GREETING", Value: "hello world"},
+	}}}
+}
+
+func TestTerminalEnvForwardsOnlyLiteralValues(t *testing.T) {
+	env := parseTerminalEnvFilter("*").terminalEnv(terminalEnvTestSpritz())
+	if strings.Join(env, ",") != "EDITOR=vim,GREETING=hello world" {
+		t.Fatalf("unexpected forwarded env %v", env)
+	}
+}
+
+func TestTerminalEnvForwardsNothingByDefault(t *testing.T) {
+	if env := parseTerminalEnvFilter("").terminalEnv(terminalEnvTestSpritz()); len(env) != 0 {
+		t.Fatalf("expected no forwarded env without SPRITZ_TERMINAL_FORWARD_ENV, got %v", env)
+	}
+}
+
+func TestTerminalEnvFilterHonorsAllowlist(t *testing.T) {
+	env := parseTerminalEnvFilter("GREETING, API_TOKEN").terminalEnv(terminalEnvTestSpritz())
+	if strings.Join(env, ",") != "GREETING=hello world" {
+		t.Fatalf("unexpected forwarded env %v", env)
+	}
+	if env := parseTerminalEnvFilter("none").terminalEnv(terminalEnvTestSpritz()); len(env) != 0 {
+		t.Fatalf("expected no forwarded env, got %v", env)
+	}
+}
+
+func TestResolveTerminalCommandForwardsSpritzEnv(t *testing.T) {
+	s := &server{terminal: terminalConfig{
+		command:     []string{"bash", "-l"},
+		sessionMode: terminalSessionNone,
+		forwardEnv:  parseTerminalEnvFilter("*"),
+	}}
+
+	command, _, _, err := s.resolveTerminalCommand(context.Background(), terminalEnvTestSpritz(), nil, "default", "demo", "")
+	if err != nil {
+		t.Fatalf("resolveTerminalCommand failed: %v", err)
+	}
+	expected := []string{"env", "EDITOR=vim", "GREETING=hello world", "bash", "-l"}
+	if strings.Join(command, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected %v, got %v", expected, command)
+	}
+}
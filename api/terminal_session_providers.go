@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// terminalSessionProvider keeps terminal sessions alive inside the workload
+// container so a browser can reattach after a disconnect.
+type terminalSessionProvider interface {
+	// binary is the executable that must exist in the container.
+	binary() string
+	// sessionName maps a requested session to a name the provider accepts.
+	sessionName(session string) string
+	// attachCommand runs command in session, creating it or reattaching to it.
+	attachCommand(session string, command []string) []string
+	// listCommand prints the running sessions; parseSessions reads its output.
+	listCommand() []string
+	parseSessions(output string) []string
+}
+
+var terminalSessionProviders = map[terminalSessionMode]terminalSessionProvider{
+	terminalSessionZmx:    zmxSessionProvider{},
+	terminalSessionTmux:   tmuxSessionProvider{},
+	terminalSessionScreen: screenSessionProvider{},
+}
+
+func (s *server) terminalSessionProvider() (terminalSessionProvider, bool) {
+	provider, ok := terminalSessionProviders[s.terminal.sessionMode]
+	return provider, ok
+}
+
+func (s *server) sessionProviderAvailable(ctx context.Context, pod *corev1.Pod, provider terminalSessionProvider) (bool, error) {
+	check := fmt.Sprintf("if command -v %s >/dev/null 2>&1; then echo ready; else echo missing; fi", provider.binary())
+	stdout, _, err := s.execInContainer(ctx, pod, []string{"sh", "-lc", check})
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(stdout, "ready"), nil
+}
+
+func (s *server) listProviderSessions(ctx context.Context, pod *corev1.Pod, provider terminalSessionProvider) ([]string, error) {
+	stdout, stderr, err := s.execInContainer(ctx, pod, provider.listCommand())
+	if err != nil {
+		return nil, fmt.Errorf("%s list failed: %w (stderr=%s)", provider.binary(), err, strings.TrimSpace(stderr))
+	}
+	return provider.parseSessions(stdout), nil
+}
+
+type zmxSessionProvider struct{}
+
+func (zmxSessionProvider) binary() string { return "zmx" }
+
+func (zmxSessionProvider) sessionName(session string) string { return session }
+
+func (zmxSessionProvider) attachCommand(session string, command []string) []string {
+	attach := make([]string, 0, len(command)+3)
+	attach = append(attach, "zmx", "attach", session)
+	return append(attach, command...)
+}
+
+func (zmxSessionProvider) listCommand() []string { return []string{"zmx", "list"} }
+
+func (zmxSessionProvider) parseSessions(output string) []string { return parseZmxSessionList(output) }
+
+// tmuxSessionProvider attaches with `new-session -A`, which joins an existing
+// session or creates it, so several tabs can share one session.
+type tmuxSessionProvider struct{}
+
+func (tmuxSessionProvider) binary() string { return "tmux" }
+
+// tmux treats ':' and '.' in targets as window and pane separators.
+func (tmuxSessionProvider) sessionName(session string) string { return sanitizeSessionName(session) }
+
+func (tmuxSessionProvider) attachCommand(session string, command []string) []string {
+	attach := make([]string, 0, len(command)+5)
+	attach = append(attach, "tmux", "new-session", "-A", "-s", session)
+	return append(attach, command...)
+}
+
+// listCommand tolerates tmux exiting non-zero when no server is running yet.
+func (tmuxSessionProvider) listCommand() []string {
+	return []string{"sh", "-c", "tmux list-sessions -F '#{session_name}' 2>/dev/null || true"}
+}
+
+func (tmuxSessionProvider) parseSessions(output string) []string {
+	return uniqueSessionNames(strings.Split(output, "\n"))
+}
+
+// screenSessionProvider reattaches with `-D -R`, detaching any other client,
+// because screen shares a session only between explicitly multi-user displays.
+type screenSessionProvider struct{}
+
+func (screenSessionProvider) binary() string { return "screen" }
+
+// screen prefixes session names with "<pid>." and resolves targets by prefix.
+func (screenSessionProvider) sessionName(session string) string { return sanitizeSessionName(session) }
+
+func (screenSessionProvider) attachCommand(session string, command []string) []string {
+	attach := make([]string, 0, len(command)+5)
+	attach = append(attach, "screen", "-D", "-R", "-S", session)
+	return append(attach, command...)
+}
+
+// listCommand tolerates screen -ls exiting non-zero, which some versions do
+// whenever sessions exist.
+func (screenSessionProvider) listCommand() []string {
+	return []string{"sh", "-c", "screen -ls 2>/dev/null || true"}
+}
+
+// parseSessions reads `screen -ls` lines such as "\t1234.name\t(Detached)".
+func (screenSessionProvider) parseSessions(output string) []string {
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[1], "(") {
+			continue
+		}
+		pid, name, ok := strings.Cut(fields[0], ".")
+		if !ok || pid == "" || strings.Trim(pid, "0123456789") != "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return uniqueSessionNames(names)
+}
+
+var unsafeSessionNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+func sanitizeSessionName(session string) string {
+	return unsafeSessionNameChars.ReplaceAllString(strings.TrimSpace(session), "_")
+}
+
+func uniqueSessionNames(names []string) []string {
+	sessions := make([]string, 0, len(names))
+	seen := make(map[string]struct{})
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		sessions = append(sessions, name)
+	}
+	return sessions
+}
+
+func parseZmxSessionList(output string) []string {
+	lines := strings.Split(output, "\n")
+	if len(lines) == 0 {
+		return nil
+	}
+	sessions := make([]string, 0, len(lines))
+	seen := make(map[string]struct{})
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(line), "no sessions found") {
+			return nil
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		name := ""
+		for _, field := range fields {
+			if strings.HasPrefix(field, "session_name=") {
+				name = strings.TrimPrefix(field, "session_name=")
+				break
+			}
+		}
+		if name == "" {
+			name = fields[0]
+		}
+		for strings.HasPrefix(name, "session_name=") {
+			name = strings.TrimPrefix(name, "session_name=")
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		sessions = append(sessions, name)
+	}
+	return sessions
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTerminalSessionModeSupportsTmuxAndScreen(t *testing.T) {
+	cases := map[string]terminalSessionMode{
+		"":        terminalSessionZmx,
+		"zmx":     terminalSessionZmx,
+		" TMUX ":  terminalSessionTmux,
+		"screen":  terminalSessionScreen,
+		"none":    terminalSessionNone,
+		"unknown": terminalSessionNone,
+	}
+	for value, want := range cases {
+		if got := parseTerminalSessionMode(value); got != want {
+			t.Fatalf("parseTerminalSessionMode(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestTerminalSessionProvidersAttachCommands(t *testing.T) {
+	command := []string{"bash", "-l"}
+	cases := []struct {
+		mode    terminalSessionMode
+		session string
+		want    []string
+	}{
+		{terminalSessionZmx, "spritz:default:demo", []string{"zmx", "attach", "spritz:default:demo", "bash", "-l"}},
+		{terminalSessionTmux, "spritz:default:demo", []string{"tmux", "new-session", "-A", "-s", "spritz_default_demo", "bash", "-l"}},
+		{terminalSessionScreen, "spritz:default:demo.v2", []string{"screen", "-D", "-R", "-S", "spritz_default_demo_v2", "bash", "-l"}},
+	}
+	for _, tc := range cases {
+		provider := terminalSessionProviders[tc.mode]
+		got := provider.attachCommand(provider.sessionName(tc.session), command)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s attach command = %v, want %v", tc.mode, got, tc.want)
+		}
+	}
+	if _, ok := terminalSessionProviders[terminalSessionNone]; ok {
+		t.Fatal("expected no session provider for mode none")
+	}
+}
+
+func TestTerminalSessionProvidersParseSessions(t *testing.T) {
+	tmux := terminalSessionProviders[terminalSessionTmux].parseSessions("spritz_default_demo\nscratch\n\nscratch\n")
+	if !reflect.DeepEqual(tmux, []string{"spritz_default_demo", "scratch"}) {
+		t.Fatalf("unexpected tmux sessions: %v", tmux)
+	}
+
+	screenOutput := "There are screens on:\n" +
+		"\t4821.spritz_default_demo\t(04/01/2026 10:00:00 AM)\t(Detached)\n" +
+		"\t4900.scratch\t(Attached)\n" +
+		"2 Sockets in /run/screen/S-dev.\n"
+	screen := terminalSessionProviders[terminalSessionScreen].parseSessions(screenOutput)
+	if !reflect.DeepEqual(screen, []string{"spritz_default_demo", "scratch"}) {
+		t.Fatalf("unexpected screen sessions: %v", screen)
+	}
+	if got := terminalSessionProviders[terminalSessionScreen].parseSessions("No Sockets found in /run/screen/S-dev.\n"); len(got) != 0 {
+		t.Fatalf("expected no screen sessions, got %v", got)
+	}
+
+	zmx := terminalSessionProviders[terminalSessionZmx].parseSessions("session_name=spritz:default:demo pid=12\n")
+	if !reflect.DeepEqual(zmx, []string{"spritz:default:demo"}) {
+		t.Fatalf("unexpected zmx sessions: %v", zmx)
+	}
+}
@@ -65,16 +65,18 @@ func (s *server) listTerminalSessions(c echo.Context) error {
 		DefaultSession: terminalDefaultSession(namespace, name),
 	}
 
-	if s.terminal.sessionMode != terminalSessionZmx {
+	provider, ok := s.terminalSessionProvider()
+	if !ok {
 		response.Mode = string(terminalSessionNone)
 		return writeJSendSuccess(c, http.StatusOK, response)
 	}
+	response.DefaultSession = provider.sessionName(response.DefaultSession)
 
 	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
 	defer cancel()
-	available, err := s.zmxAvailable(ctx, pod)
+	available, err := s.sessionProviderAvailable(ctx, pod, provider)
 	if err != nil {
-		log.Printf("spritz terminal sessions: zmx check failed name=%s namespace=%s err=%v", name, namespace, err)
+		log.Printf("spritz terminal sessions: %s check failed name=%s namespace=%s err=%v", provider.binary(), name, namespace, err)
 		return writeError(c, http.StatusInternalServerError, "failed to check terminal sessions")
 	}
 	response.Available = available
@@ -82,7 +84,7 @@ func (s *server) listTerminalSessions(c echo.Context) error {
 		return writeJSendSuccess(c, http.StatusOK, response)
 	}
 
-	sessions, err := s.listZmxSessions(ctx, pod)
+	sessions, err := s.listProviderSessions(ctx, pod, provider)
 	if err != nil {
 		log.Printf("spritz terminal sessions: list failed name=%s namespace=%s err=%v", name, namespace, err)
 		return writeError(c, http.StatusInternalServerError, "failed to list terminal sessions")
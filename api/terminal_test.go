@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	spritzv1 "spritz.sh/operator/api/v1"
 )
 
 func TestReadTerminalInputInvokesActivityCallbackOnInput(t *testing.T) {
@@ -105,3 +109,199 @@ func TestDebounceTerminalActivityCoalescesRapidInput(t *testing.T) {
 		t.Fatalf("expected a second activity write after debounce window, got %d", callbacks.Load())
 	}
 }
+
+func TestResolveTerminalCommandPrefersSpritzCommand(t *testing.T) {
+	s := &server{terminal: terminalConfig{command: []string{"bash", "-l"}, sessionMode: terminalSessionNone}}
+	spritz := &spritzv1.Spritz{Spec: spritzv1.SpritzSpec{Terminal: &spritzv1.SpritzTerminal{Command: []string{"sh", "-l"}}}}
+
+	command, _, usingZmx, err := s.resolveTerminalCommand(context.Background(), spritz, nil, "default", "demo", "")
+	if err != nil {
+		t.Fatalf("resolveTerminalCommand failed: %v", err)
+	}
+	if usingZmx || strings.Join(command, " ") != "sh -l" {
+		t.Fatalf("expected per-spritz command, got %v", command)
+	}
+
+	command, _, _, err = s.resolveTerminalCommand(context.Background(), &spritzv1.Spritz{}, nil, "default", "demo", "")
+	if err != nil {
+		t.Fatalf("resolveTerminalCommand failed: %v", err)
+	}
+	if strings.Join(command, " ") != "bash -l" {
+		t.Fatalf("expected default command without an override, got %v", command)
+	}
+}
+
+func TestValidateTerminalCommandRejectsEmptyCommand(t *testing.T) {
+	if err := validateTerminalCommand(nil); err != nil {
+		t.Fatalf("expected unset terminal to be valid, got %v", err)
+	}
+	if err := validateTerminalCommand(&spritzv1.SpritzTerminal{}); err == nil {
+		t.Fatal("expected empty terminal command to be rejected")
+	}
+	if err := validateTerminalCommand(&spritzv1.SpritzTerminal{Command: []string{"sh", " "}}); err == nil {
+		t.Fatal("expected blank terminal command entry to be rejected")
+	}
+	if err := validateTerminalCommand(&spritzv1.SpritzTerminal{Command: []string{"python3"}}); err != nil {
+		t.Fatalf("expected command to be valid, got %v", err)
+	}
+}
+
+func dialTerminalTestConn(t *testing.T) (*websocket.Conn, *websocket.Conn) {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	serverConn := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		serverConn <- conn
+	}))
+	t.Cleanup(srv.Close)
+
+	wsURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server url: %v", err)
+	}
+	wsURL.Scheme = "ws"
+	clientConn, _, err := websocket.DefaultDialer.Dial(wsURL.String(), nil)
+	if err != nil {
+		t.Fatalf("failed to dial websocket: %v", err)
+	}
+	t.Cleanup(func() { _ = clientConn.Close() })
+	conn := <-serverConn
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn, clientConn
+}
+
+func TestKeepTerminalAliveClosesSilentPeers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A responsive browser answers pings while it reads, so the reader stays open.
+	conn, clientConn := dialTerminalTestConn(t)
+	go func() {
+		for {
+			if _, _, err := clientConn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	keepTerminalAlive(ctx, conn, 50*time.Millisecond)
+	reader, writer := io.Pipe()
+	defer reader.Close()
+	done := make(chan error, 1)
+	go func() {
+		done <- readTerminalInput(ctx, conn, writer, newTerminalSizeQueue(), nil)
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("expected responsive peer to stay connected, got %v", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// A peer that never reads never pongs, so the read deadline expires.
+	silentConn, _ := dialTerminalTestConn(t)
+	keepTerminalAlive(ctx, silentConn, 50*time.Millisecond)
+	silentReader, silentWriter := io.Pipe()
+	defer silentReader.Close()
+	silentDone := make(chan error, 1)
+	go func() {
+		silentDone <- readTerminalInput(ctx, silentConn, silentWriter, newTerminalSizeQueue(), nil)
+	}()
+	select {
+	case err := <-silentDone:
+		var netErr interface{ Timeout() bool }
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Fatalf("expected read timeout for silent peer, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for silent peer to be dropped")
+	}
+}
+
+func TestTerminalAcceptedSubprotocolsPreferSpritzProtocol(t *testing.T) {
+	cfg := terminalConfig{subprotocols: []string{"tty", connectTicketTerminalProtocol}}
+	got := cfg.acceptedSubprotocols([]string{connectTicketTerminalProtocol})
+	if strings.Join(got, ",") != connectTicketTerminalProtocol+",tty" {
+		t.Fatalf("unexpected accepted subprotocols %v", got)
+	}
+	if got := cfg.acceptedSubprotocols(nil); strings.Join(got, ",") != "tty,"+connectTicketTerminalProtocol {
+		t.Fatalf("expected configured subprotocols without authenticated ones, got %v", got)
+	}
+}
+
+func TestTerminalUpgradeEchoesConfiguredSubprotocol(t *testing.T) {
+	cfg := terminalConfig{subprotocols: []string{"tty"}}
+	upgrader := websocket.Upgrader{
+		CheckOrigin:  func(*http.Request) bool { return true },
+		Subprotocols: cfg.acceptedSubprotocols(nil),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = conn.Close()
+	}))
+	defer srv.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"binary", "tty"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != "tty" {
+		t.Fatalf("expected negotiated subprotocol tty, got %q", conn.Subprotocol())
+	}
+}
+
+func TestTerminalAllowOriginForAcceptsSpritzIngressHost(t *testing.T) {
+	spritz := &spritzv1.Spritz{Spec: spritzv1.SpritzSpec{Ingress: &spritzv1.SpritzIngress{Host: "tidy-otter.spritz.example.com"}}}
+	cfg := terminalConfig{
+		allowedOrigins:      map[string]struct{}{"https://console.example.com": {}},
+		allowIngressOrigin:  true,
+		ingressOriginSuffix: "spritz.example.com",
+	}
+	cases := []struct {
+		origin string
+		want   bool
+	}{
+		{origin: "https://console.example.com", want: true},
+		{origin: "https://Tidy-Otter.spritz.example.com", want: true},
+		{origin: "https://tidy-otter.spritz.example.com:8443", want: true},
+		{origin: "http://tidy-otter.spritz.example.com", want: false},
+		{origin: "https://other.spritz.example.com", want: false},
+		{origin: "wss://tidy-otter.spritz.example.com", want: false},
+		{origin: "", want: false},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "https://api.example.com/api/spritzes/tidy-otter/terminal", nil)
+		if tc.origin != "" {
+			req.Header.Set("Origin", tc.origin)
+		}
+		if got := cfg.allowOriginFor(spritz)(req); got != tc.want {
+			t.Fatalf("origin %q: expected %v, got %v", tc.origin, tc.want, got)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.example.com/api/spritzes/tidy-otter/terminal", nil)
+	req.Header.Set("Origin", "https://attacker.example.net")
+	outside := &spritzv1.Spritz{Spec: spritzv1.SpritzSpec{Ingress: &spritzv1.SpritzIngress{Host: "attacker.example.net"}}}
+	if cfg.allowOriginFor(outside)(req) {
+		t.Fatal("expected an ingress host outside the suffix to be rejected")
+	}
+
+	req.Header.Set("Origin", "https://tidy-otter.spritz.example.com")
+	cfg.ingressOriginSuffix = ""
+	if cfg.allowOriginFor(spritz)(req) {
+		t.Fatal("expected ingress origin to be rejected without a suffix")
+	}
+	cfg.ingressOriginSuffix = "spritz.example.com"
+	cfg.allowIngressOrigin = false
+	if cfg.allowOriginFor(spritz)(req) {
+		t.Fatal("expected ingress origin to be rejected when disabled")
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"spritz.sh/operator/tracing"
+)
+
+var tracer = tracing.Tracer("spritz.sh/api")
+
+// withTracing starts a server span per request, continuing the caller's trace
+// when it sent a traceparent header. Handlers see the span through the
+// request context. It is a no-op until tracing.Setup installs a provider.
+func withTracing() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			route := c.Path()
+			if route == "" {
+				route = req.URL.Path
+			}
+			ctx, span := tracer.Start(ctx, req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", req.Method),
+					attribute.String("http.route", route),
+				),
+			)
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			status := c.Response().Status
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				status = httpErr.Code
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if err == nil && status >= http.StatusInternalServerError {
+				err = errors.New(http.StatusText(status))
+			}
+			tracing.End(span, err)
+			return err
+		}
+	}
+}
+
+// tracedObjectStore gives each shared mounts storage call its own span under
+// the request that caused it.
+type tracedObjectStore struct {
+	next    objectStore
+	backend string
+}
+
+func traceObjectStore(next objectStore, backend string) objectStore {
+	if backend == "" {
+		backend = sharedMountsBackendRclone
+	}
+	return &tracedObjectStore{next: next, backend: backend}
+}
+
+func (s *tracedObjectStore) start(ctx context.Context, operation, key string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "sharedMounts."+operation, trace.WithAttributes(
+		attribute.String("spritz.shared_mounts.backend", s.backend),
+		attribute.String("spritz.shared_mounts.key", key),
+	))
+}
+
+// end treats a missing object as a normal outcome rather than a failure.
+func (s *tracedObjectStore) end(span trace.Span, err error) {
+	if errors.Is(err, errSharedMountNotFound) {
+		span.SetAttributes(attribute.Bool("spritz.shared_mounts.not_found", true))
+		err = nil
+	}
+	tracing.End(span, err)
+}
+
+func (s *tracedObjectStore) read(ctx context.Context, key string) (data []byte, err error) {
+	ctx, span := s.start(ctx, "read", key)
+	defer func() { s.end(span, err) }()
+	return s.next.read(ctx, key)
+}
+
+func (s *tracedObjectStore) stream(ctx context.Context, key string, out io.Writer) (err error) {
+	ctx, span := s.start(ctx, "stream", key)
+	defer func() { s.end(span, err) }()
+	return s.next.stream(ctx, key, out)
+}
+
+func (s *tracedObjectStore) write(ctx context.Context, key string, body io.Reader) (err error) {
+	ctx, span := s.start(ctx, "write", key)
+	defer func() { s.end(span, err) }()
+	return s.next.write(ctx, key, body)
+}
+
+func (s *tracedObjectStore) stat(ctx context.Context, key string) (info objectInfo, err error) {
+	ctx, span := s.start(ctx, "stat", key)
+	defer func() { s.end(span, err) }()
+	return s.next.stat(ctx, key)
+}
+
+func (s *tracedObjectStore) list(ctx context.Context, prefix string) (objects []objectInfo, err error) {
+	ctx, span := s.start(ctx, "list", prefix)
+	defer func() { s.end(span, err) }()
+	return s.next.list(ctx, prefix)
+}
+
+func (s *tracedObjectStore) delete(ctx context.Context, key string) (err error) {
+	ctx, span := s.start(ctx, "delete", key)
+	defer func() { s.end(span, err) }()
+	return s.next.delete(ctx, key)
+}
+
+func (s *tracedObjectStore) check(ctx context.Context, prefix string) (err error) {
+	ctx, span := s.start(ctx, "check", prefix)
+	defer func() { s.end(span, err) }()
+	return s.next.check(ctx, prefix)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTracingContinuesCallerTraceIntoSharedMountsStore(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	// The package tracer is bound at init; rebind it to the test provider.
+	previousTracer := tracer
+	tracer = provider.Tracer("spritz.sh/api")
+	t.Cleanup(func() { tracer = previousTracer })
+
+	objects := traceObjectStore(newMemoryObjectStore(), "")
+	e := echo.New()
+	e.Use(withTracing())
+	e.GET("/api/objects/:key", func(c echo.Context) error {
+		if _, err := objects.read(c.Request().Context(), c.Param("key")); err != nil {
+			return c.NoContent(http.StatusNotFound)
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/objects/latest.json", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected request and store spans, got %d", len(spans))
+	}
+	store, request := spans[0], spans[1]
+	if request.Name() != "GET /api/objects/:key" {
+		t.Fatalf("unexpected request span name %q", request.Name())
+	}
+	if request.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("expected request span to continue the caller trace, got %s", request.SpanContext().TraceID())
+	}
+	if store.Name() != "sharedMounts.read" || store.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Fatalf("expected store span under the request span, got %q parent %s", store.Name(), store.Parent().SpanID())
+	}
+	if store.Status().Code == codes.Error {
+		t.Fatal("expected a missing object not to mark the store span as failed")
+	}
+}
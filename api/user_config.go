@@ -134,6 +134,9 @@ func normalizeUserConfig(policy userConfigPolicy, keys map[string]json.RawMessag
 		if err := validateRepoDir(cfg.Repo.Dir); err != nil {
 			return cfg, err
 		}
+		if err := validateRepoPostClone(cfg.Repo.PostClone); err != nil {
+			return cfg, err
+		}
 	}
 
 	if _, ok := keys["ttl"]; ok && cfg.TTL != nil && *cfg.TTL != "" {
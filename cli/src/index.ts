@@ -504,10 +504,12 @@ Usage:
   spritz list [--namespace <ns>]
   spritz create [name] [--preset <id>] [--preset-input <key=value>] [--image <image>] [--repo <url>] [--branch <branch>] [--owner-provider <provider> --owner-subject <subject> [--owner-tenant <tenant>] | --owner-id <id>] [--idle-ttl <duration>] [--ttl <duration>] [--idempotency-key <id>] [--source <source>] [--request-id <id>] [--name-prefix <prefix>] [--namespace <ns>]
   spritz suggest-name [--preset <id>] [--image <image>] [--name-prefix <prefix>] [--namespace <ns>]
-  spritz delete <name> [--namespace <ns>]
+  spritz delete <name> [--namespace <ns>] [--keep-storage]
+  spritz transfer <name> --to <owner-id> [--team <team>] [--force] [--namespace <ns>]
+  spritz ssh-principals <name> (--principal <owner-id>... | --clear) [--namespace <ns>]
   spritz open <name> [--namespace <ns>]
   spritz terminal <name> [--namespace <ns>] [--session <name>] [--transport <ws|ssh>] [--print]
-  spritz ssh <name> [--namespace <ns>] [--session <name>] [--transport <ws|ssh>] [--print]
+  spritz ssh <name> [--namespace <ns>] [--session <name>] [--transport <ws|ssh>] [--cert-ttl <seconds>] [--print]
   spritz port-forward <name> [--namespace <ns>] --local <port> --remote <port> [--transport <ws|ssh>] [--print]
   spritz chat send (--instance <name> | --conversation <id>) --message <text> [--reason <text>] [--cwd <path>] [--title <title>] [--namespace <ns>] [--json]
   spritz profile list
@@ -1237,13 +1239,18 @@ async function openSSHConnection(
   printOnly: boolean,
   extraArgs: string[] = [],
 ) {
+  const certTtl = argValue('--cert-ttl');
+  const ttlSeconds = certTtl ? Number(certTtl) : undefined;
+  if (ttlSeconds !== undefined && (!Number.isInteger(ttlSeconds) || ttlSeconds <= 0)) {
+    throw new Error('--cert-ttl must be a positive number of seconds');
+  }
   const keypair = await generateSSHKeypair();
   let keepTemp = false;
   try {
     const data = await request(`/spritzes/${encodeURIComponent(name)}/ssh${namespace ? `?namespace=${encodeURIComponent(namespace)}` : ''}`, {
       method: 'POST',
       headers: { 'Content-Type': 'application/json' },
-      body: JSON.stringify({ public_key: keypair.publicKey }),
+      body: JSON.stringify({ public_key: keypair.publicKey, ttl_seconds: ttlSeconds }),
     });
     if (!data?.host || !data?.user || !data?.cert) {
       throw new Error('ssh credentials not available');
@@ -1817,13 +1824,50 @@ async function main() {
     const name = rest[0];
     if (!name) throw new Error('name is required');
     const ns = await resolveNamespace();
-    await request(`/spritzes/${encodeURIComponent(name)}${ns ? `?namespace=${encodeURIComponent(ns)}` : ''}`,
+    const params = new URLSearchParams();
+    if (ns) params.set('namespace', ns);
+    if (hasFlag('--keep-storage')) params.set('keepStorage', 'true');
+    const query = params.toString();
+    await request(`/spritzes/${encodeURIComponent(name)}${query ? `?${query}` : ''}`,
       { method: 'DELETE' },
     );
     console.log('deleted');
     return;
   }
 
+  if (command === 'transfer') {
+    const name = rest[0];
+    if (!name) throw new Error('name is required');
+    const ownerId = argValue('--to');
+    if (!ownerId) throw new Error('--to is required');
+    const ns = await resolveNamespace();
+    const data = await request(`/spritzes/${encodeURIComponent(name)}/transfer${ns ? `?namespace=${encodeURIComponent(ns)}` : ''}`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ ownerId, team: argValue('--team'), force: hasFlag('--force') }),
+    });
+    console.log(`transferred to ${data?.spec?.owner?.id || ownerId}`);
+    return;
+  }
+
+  if (command === 'ssh-principals') {
+    const name = rest[0];
+    if (!name) throw new Error('name is required');
+    const principals = argValues('--principal');
+    if (principals.length === 0 && !hasFlag('--clear')) {
+      throw new Error('--principal or --clear is required');
+    }
+    const ns = await resolveNamespace();
+    const data = await request(`/spritzes/${encodeURIComponent(name)}/ssh/principals${ns ? `?namespace=${encodeURIComponent(ns)}` : ''}`, {
+      method: 'PUT',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ principals }),
+    });
+    const current: string[] = data?.spec?.ssh?.additionalPrincipals || [];
+    console.log(current.length > 0 ? `ssh principals: ${current.join(', ')}` : 'ssh principals cleared');
+    return;
+  }
+
   if (command === 'open') {
     const name = rest[0];
     if (!name) throw new Error('name is required');
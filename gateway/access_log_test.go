@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessLogWritesOneRedactedLine(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	handler := accessLog(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("short and stout"))
+	}), logger, "https://llm.example.com")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/responses?key=query-secret", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Api-Key", "secret")
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Fatalf("expected credentials to be left out of the log, got %s", buf.String())
+	}
+	var entry struct {
+		Method   string            `json:"method"`
+		Path     string            `json:"path"`
+		Status   int               `json:"status"`
+		Upstream string            `json:"upstream"`
+		Bytes    int64             `json:"bytes"`
+		Headers  map[string]string `json:"headers"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON line, got %q: %v", buf.String(), err)
+	}
+	if entry.Method != http.MethodPost || entry.Path != "/v1/responses" || entry.Upstream != "https://llm.example.com" {
+		t.Fatalf("unexpected request fields: %+v", entry)
+	}
+	if entry.Status != http.StatusTeapot || entry.Bytes != int64(len("short and stout")) {
+		t.Fatalf("unexpected response fields: %+v", entry)
+	}
+	if entry.Headers["Authorization"] != "[redacted]" || entry.Headers["X-Api-Key"] != "[redacted]" {
+		t.Fatalf("expected sensitive headers to be redacted, got %v", entry.Headers)
+	}
+	if entry.Headers["Content-Type"] != "application/json" {
+		t.Fatalf("expected other headers to be logged, got %v", entry.Headers)
+	}
+}
+
+func TestStatusRecorderDefaultsToOK(t *testing.T) {
+	recorder := &statusRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	_, _ = recorder.Write([]byte("ok"))
+	recorder.WriteHeader(http.StatusBadGateway)
+	if recorder.status != http.StatusOK {
+		t.Fatalf("expected implicit 200 to stick after the first write, got %d", recorder.status)
+	}
+	if _, ok := any(recorder).(http.Flusher); !ok {
+		t.Fatal("expected status recorder to keep Flush for streamed responses")
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type breakerState string
+
+const (
+	breakerClosed   breakerState = "closed"
+	breakerOpen     breakerState = "open"
+	breakerHalfOpen breakerState = "half-open"
+)
+
+// circuitBreaker fast-fails requests after threshold consecutive upstream
+// failures. Once cooldown passes it lets a single probe through: success
+// closes the breaker, failure reopens it for another cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 || cooldown <= 0 {
+		return nil
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     breakerClosed,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a request may go upstream. A true result in the
+// half-open state reserves the probe, which record or release must return.
+func (b *circuitBreaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		b.state = breakerHalfOpen
+	}
+	switch b.state {
+	case breakerOpen:
+		return false
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+func (b *circuitBreaker) record(success bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// release frees a half-open probe whose outcome says nothing about the
+// upstream, such as a request the client abandoned.
+func (b *circuitBreaker) release() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+func (b *circuitBreaker) currentState() breakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return breakerHalfOpen
+	}
+	return b.state
+}
+
+// retryAfter is the remaining cooldown rounded up to whole seconds.
+func (b *circuitBreaker) retryAfter() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	remaining := b.cooldown - b.now().Sub(b.openedAt)
+	if remaining <= 0 {
+		return 1
+	}
+	return int((remaining + time.Second - 1) / time.Second)
+}
+
+// upstreamFailed treats gateway-style statuses as upstream outages; other
+// responses, including 500s from the provider's own logic, prove it is up.
+func upstreamFailed(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// wrap records each request's outcome as soon as the response headers are
+// written, so a long stream does not hold the half-open probe slot for its
+// whole duration.
+func (b *circuitBreaker) wrap(next http.Handler) http.Handler {
+	if b == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !b.allow() {
+			w.Header().Set("Retry-After", strconv.Itoa(b.retryAfter()))
+			http.Error(w, "gateway upstream unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		writer := &breakerResponseWriter{ResponseWriter: w, breaker: b}
+		next.ServeHTTP(writer, r)
+		if writer.recorded {
+			return
+		}
+		if r.Context().Err() != nil {
+			b.release()
+			return
+		}
+		writer.recordStatus(http.StatusOK)
+	})
+}
+
+// breakerResponseWriter reports the response status to the breaker on the
+// first WriteHeader or Write.
+type breakerResponseWriter struct {
+	http.ResponseWriter
+	breaker  *circuitBreaker
+	recorded bool
+}
+
+func (w *breakerResponseWriter) recordStatus(status int) {
+	if w.recorded {
+		return
+	}
+	w.recorded = true
+	w.breaker.record(!upstreamFailed(status))
+}
+
+func (w *breakerResponseWriter) WriteHeader(status int) {
+	// 1xx responses are informational; the final status follows.
+	if status >= http.StatusOK {
+		w.recordStatus(status)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *breakerResponseWriter) Write(p []byte) (int, error) {
+	w.recordStatus(http.StatusOK)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *breakerResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *breakerResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*circuitBreaker, *time.Time) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreaker(threshold, cooldown)
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	breaker, now := newTestBreaker(2, 30*time.Second)
+
+	for i := 0; i < 2; i++ {
+		if !breaker.allow() {
+			t.Fatalf("expected closed breaker to allow request %d", i)
+		}
+		breaker.record(false)
+	}
+	if state := breaker.currentState(); state != breakerOpen {
+		t.Fatalf("expected breaker to open after threshold failures, got %s", state)
+	}
+	if breaker.allow() {
+		t.Fatal("expected open breaker to fast-fail")
+	}
+	if got := breaker.retryAfter(); got != 30 {
+		t.Fatalf("expected 30s retry after, got %d", got)
+	}
+
+	*now = now.Add(30 * time.Second)
+	if state := breaker.currentState(); state != breakerHalfOpen {
+		t.Fatalf("expected breaker to be half-open after cooldown, got %s", state)
+	}
+	if !breaker.allow() {
+		t.Fatal("expected half-open breaker to allow one probe")
+	}
+	if breaker.allow() {
+		t.Fatal("expected half-open breaker to refuse a second probe")
+	}
+	breaker.record(false)
+	if state := breaker.currentState(); state != breakerOpen {
+		t.Fatalf("expected failed probe to reopen the breaker, got %s", state)
+	}
+
+	*now = now.Add(30 * time.Second)
+	if !breaker.allow() {
+		t.Fatal("expected probe after second cooldown")
+	}
+	breaker.record(true)
+	if state := breaker.currentState(); state != breakerClosed {
+		t.Fatalf("expected successful probe to close the breaker, got %s", state)
+	}
+	if !breaker.allow() || !breaker.allow() {
+		t.Fatal("expected closed breaker to allow requests")
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	breaker, _ := newTestBreaker(2, 30*time.Second)
+	breaker.record(false)
+	breaker.record(true)
+	breaker.record(false)
+	if state := breaker.currentState(); state != breakerClosed {
+		t.Fatalf("expected failures to be consecutive, got %s", state)
+	}
+}
+
+func TestCircuitBreakerWrapCountsGatewayStatuses(t *testing.T) {
+	breaker, _ := newTestBreaker(1, time.Minute)
+	status := http.StatusInternalServerError
+	handler := breaker.wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+	if state := breaker.currentState(); state != breakerClosed {
+		t.Fatalf("expected provider 500 not to count as an outage, got %s", state)
+	}
+
+	status = http.StatusBadGateway
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected open breaker to answer 503, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("expected Retry-After 60, got %q", rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestCircuitBreakerReleasesProbeWhenHeadersArrive(t *testing.T) {
+	breaker, now := newTestBreaker(1, time.Second)
+	breaker.record(false)
+	*now = now.Add(time.Second)
+
+	headersSent := make(chan struct{})
+	finish := make(chan struct{})
+	handler := breaker.wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		close(headersSent)
+		<-finish
+	}))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+	}()
+
+	<-headersSent
+	if state := breaker.currentState(); state != breakerClosed {
+		t.Fatalf("expected probe to close the breaker once headers arrived, got %s", state)
+	}
+	if !breaker.allow() {
+		t.Fatal("expected other requests to pass while the probe still streams")
+	}
+	close(finish)
+	<-done
+}
+
+func TestCircuitBreakerReleasesAbandonedProbe(t *testing.T) {
+	breaker, now := newTestBreaker(1, time.Second)
+	breaker.record(false)
+	*now = now.Add(time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := breaker.wrap(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		cancel()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/v1/responses", nil).WithContext(ctx))
+
+	if state := breaker.currentState(); state != breakerHalfOpen {
+		t.Fatalf("expected abandoned probe to leave the breaker half-open, got %s", state)
+	}
+	if !breaker.allow() {
+		t.Fatal("expected the probe slot to be free again")
+	}
+}
+
+func TestNewCircuitBreakerDisabled(t *testing.T) {
+	if newCircuitBreaker(0, time.Minute) != nil {
+		t.Fatal("expected zero threshold to disable the breaker")
+	}
+	var breaker *circuitBreaker
+	if !breaker.allow() {
+		t.Fatal("expected nil breaker to allow requests")
+	}
+}
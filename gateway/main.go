@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -14,13 +20,80 @@ func main() {
 	listenAddr := ":" + envOrDefault("PORT", "8080")
 	upstreamRaw := envOrDefault("SPRITZ_GATEWAY_UPSTREAM", "https://api.openai.com")
 	stripPrefix := strings.TrimSpace(os.Getenv("SPRITZ_GATEWAY_STRIP_PREFIX"))
+	preserveHost, err := parseBoolEnv("SPRITZ_GATEWAY_PRESERVE_HOST", false)
+	if err != nil {
+		log.Fatalf("invalid SPRITZ_GATEWAY_PRESERVE_HOST: %v", err)
+	}
+	setHeaders, err := parseHeadersEnv("SPRITZ_GATEWAY_SET_HEADERS")
+	if err != nil {
+		log.Fatalf("invalid SPRITZ_GATEWAY_SET_HEADERS: %v", err)
+	}
+	accessLogEnabled, err := parseBoolEnv("SPRITZ_GATEWAY_ACCESS_LOG", false)
+	if err != nil {
+		log.Fatalf("invalid SPRITZ_GATEWAY_ACCESS_LOG: %v", err)
+	}
+	breakerThreshold, err := parseIntEnv("SPRITZ_GATEWAY_BREAKER_THRESHOLD", 5)
+	if err != nil {
+		log.Fatalf("invalid SPRITZ_GATEWAY_BREAKER_THRESHOLD: %v", err)
+	}
+	breakerCooldown, err := parseDurationEnv("SPRITZ_GATEWAY_BREAKER_COOLDOWN", 30*time.Second)
+	if err != nil {
+		log.Fatalf("invalid SPRITZ_GATEWAY_BREAKER_COOLDOWN: %v", err)
+	}
+	breaker := newCircuitBreaker(breakerThreshold, breakerCooldown)
+	timeouts := proxyTimeouts{}
+	if timeouts.responseHeader, err = parseDurationEnv("SPRITZ_GATEWAY_RESPONSE_HEADER_TIMEOUT", 2*time.Minute); err != nil {
+		log.Fatalf("invalid SPRITZ_GATEWAY_RESPONSE_HEADER_TIMEOUT: %v", err)
+	}
+	if timeouts.request, err = parseDurationEnv("SPRITZ_GATEWAY_REQUEST_TIMEOUT", 5*time.Minute); err != nil {
+		log.Fatalf("invalid SPRITZ_GATEWAY_REQUEST_TIMEOUT: %v", err)
+	}
+	if timeouts.streamIdle, err = parseDurationEnv("SPRITZ_GATEWAY_STREAM_IDLE_TIMEOUT", 2*time.Minute); err != nil {
+		log.Fatalf("invalid SPRITZ_GATEWAY_STREAM_IDLE_TIMEOUT: %v", err)
+	}
 
 	upstream, err := url.Parse(upstreamRaw)
 	if err != nil {
 		log.Fatalf("invalid SPRITZ_GATEWAY_UPSTREAM: %v", err)
 	}
 
+	proxy := newProxy(upstream, stripPrefix, preserveHost, setHeaders, timeouts)
+
+	mux := http.NewServeMux()
+	// The gateway stays healthy while the breaker is open; the breaker line is
+	// informational so an upstream outage does not restart gateway pods.
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		body := "ok"
+		if breaker != nil {
+			body += "\nbreaker=" + string(breaker.currentState())
+		}
+		_, _ = w.Write([]byte(body))
+	})
+	handler := breaker.wrap(timeouts.wrap(proxy))
+	if accessLogEnabled {
+		handler = accessLog(handler, slog.New(slog.NewJSONHandler(os.Stdout, nil)), upstreamRedacted(upstream))
+	}
+	mux.Handle("/", handler)
+
+	server := &http.Server{
+		Addr:              listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("spritz gateway listening on %s -> %s", listenAddr, upstreamRedacted(upstream))
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("server error: %v", err)
+	}
+}
+
+// newProxy returns the reverse proxy to upstream. Timeouts that fire answer
+// 504; other transport errors answer 502.
+func newProxy(upstream *url.URL, stripPrefix string, preserveHost bool, setHeaders map[string]string, timeouts proxyTimeouts) *httputil.ReverseProxy {
 	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	proxy.Transport = timeouts.transport()
+	proxy.ModifyResponse = timeouts.modifyResponse
 	originalDirector := proxy.Director
 	proxy.Director = func(req *http.Request) {
 		originalDirector(req)
@@ -30,39 +103,78 @@ func main() {
 				req.URL.Path = "/"
 			}
 		}
-		req.Host = upstream.Host
+		if !preserveHost {
+			req.Host = upstream.Host
+		}
+		for name, value := range setHeaders {
+			req.Header.Set(name, value)
+		}
 	}
 
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if errors.Is(context.Cause(r.Context()), errUpstreamTimeout) {
+			log.Printf("proxy timeout: %v", err)
+			http.Error(w, "gateway upstream timeout", http.StatusGatewayTimeout)
+			return
+		}
 		log.Printf("proxy error: %v", err)
 		http.Error(w, "gateway upstream error", http.StatusBadGateway)
 	}
+	return proxy
+}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", "text/plain")
-		_, _ = w.Write([]byte("ok"))
-	})
-	mux.Handle("/", proxy)
+func envOrDefault(key, fallback string) string {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	return value
+}
 
-	server := &http.Server{
-		Addr:              listenAddr,
-		Handler:           mux,
-		ReadHeaderTimeout: 10 * time.Second,
+func parseIntEnv(key string, fallback int) (int, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
 	}
+	return strconv.Atoi(value)
+}
 
-	log.Printf("spritz gateway listening on %s -> %s", listenAddr, upstreamRedacted(upstream))
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("server error: %v", err)
+func parseDurationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
 	}
+	return time.ParseDuration(value)
 }
 
-func envOrDefault(key, fallback string) string {
+func parseBoolEnv(key string, fallback bool) (bool, error) {
 	value := strings.TrimSpace(os.Getenv(key))
 	if value == "" {
-		return fallback
+		return fallback, nil
 	}
-	return value
+	return strconv.ParseBool(value)
+}
+
+// parseHeadersEnv reads a JSON object of header names to values that are set
+// on every upstream request, replacing any value sent by the client.
+func parseHeadersEnv(key string) (map[string]string, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil, nil
+	}
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(value), &headers); err != nil {
+		return nil, err
+	}
+	for name, headerValue := range headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.ContainsAny(headerValue, "\r\n") {
+			return nil, fmt.Errorf("header %s value must not contain line breaks", name)
+		}
+	}
+	return headers, nil
 }
 
 func upstreamRedacted(u *url.URL) string {
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// proxyTimeouts bounds upstream requests without cutting off streamed
+// completions. Normal responses must finish within request; streaming
+// responses have no overall limit and are only aborted after streamIdle
+// without data. Zero disables a limit.
+type proxyTimeouts struct {
+	responseHeader time.Duration
+	request        time.Duration
+	streamIdle     time.Duration
+}
+
+type requestDeadlineKey struct{}
+
+// errUpstreamTimeout is the cancel cause when a proxy timeout fires, so the
+// error handler can answer 504 instead of 502.
+var errUpstreamTimeout = errors.New("upstream timeout")
+
+// requestDeadline cancels a proxied request once its timer fires. A response
+// that turns out to stream stops the timer and switches to idle tracking.
+type requestDeadline struct {
+	cancel func()
+	mu     sync.Mutex
+	timer  *time.Timer
+}
+
+func (d *requestDeadline) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
+
+// transport returns the upstream transport. ResponseHeaderTimeout only covers
+// waiting for headers, so streamed bodies are not limited by it.
+func (t proxyTimeouts) transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = t.responseHeader
+	return transport
+}
+
+func (t proxyTimeouts) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancelCause(r.Context())
+		defer cancel(nil)
+		deadline := &requestDeadline{cancel: func() { cancel(errUpstreamTimeout) }}
+		if t.request > 0 {
+			deadline.timer = time.AfterFunc(t.request, deadline.cancel)
+		}
+		defer deadline.stop()
+		next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, requestDeadlineKey{}, deadline)))
+	})
+}
+
+// modifyResponse lifts the request deadline for streaming responses and
+// aborts them only when the upstream goes quiet.
+func (t proxyTimeouts) modifyResponse(resp *http.Response) error {
+	if resp.Request == nil || !isStreamingResponse(resp) {
+		return nil
+	}
+	deadline, ok := resp.Request.Context().Value(requestDeadlineKey{}).(*requestDeadline)
+	if !ok {
+		return nil
+	}
+	deadline.stop()
+	if t.streamIdle > 0 {
+		resp.Body = newIdleTimeoutBody(resp.Body, t.streamIdle, deadline.cancel)
+	}
+	return nil
+}
+
+func isStreamingResponse(resp *http.Response) bool {
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil && mediaType == "text/event-stream" {
+		return true
+	}
+	return resp.ContentLength < 0 && slices.Contains(resp.TransferEncoding, "chunked")
+}
+
+// idleTimeoutBody cancels the upstream request when no read completes within
+// idle of the previous one.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	idle  time.Duration
+	timer *time.Timer
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, idle time.Duration, cancel func()) *idleTimeoutBody {
+	return &idleTimeoutBody{ReadCloser: body, idle: idle, timer: time.AfterFunc(idle, cancel)}
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.timer.Reset(b.idle)
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.ReadCloser.Close()
+}
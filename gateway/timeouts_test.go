@@ -0,0 +1,123 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTimeoutTestProxy(t *testing.T, timeouts proxyTimeouts, upstream http.HandlerFunc) http.Handler {
+	t.Helper()
+	server := httptest.NewServer(upstream)
+	t.Cleanup(server.Close)
+	upstreamURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("parse upstream url: %v", err)
+	}
+	return timeouts.wrap(newProxy(upstreamURL, "", false, nil, timeouts))
+}
+
+func TestProxyRequestTimeoutAnswers504(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	proxy := newTimeoutTestProxy(t, proxyTimeouts{request: 50 * time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected 504 after the request timeout, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestProxyResponseHeaderTimeoutAnswers502(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	proxy := newTimeoutTestProxy(t, proxyTimeouts{responseHeader: 50 * time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("expected transport header timeout to answer 502, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestProxyStreamOutlivesRequestTimeout(t *testing.T) {
+	proxy := newTimeoutTestProxy(t, proxyTimeouts{request: 50 * time.Millisecond, streamIdle: time.Second}, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for i := 0; i < 5; i++ {
+			_, _ = io.WriteString(w, "data: chunk\n\n")
+			flusher.Flush()
+			time.Sleep(30 * time.Millisecond)
+		}
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	})
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected stream to succeed, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.HasSuffix(body, "data: [DONE]\n\n") {
+		t.Fatalf("expected the full stream past the request timeout, got %q", body)
+	}
+}
+
+func TestProxyStreamIdleTimeoutAbortsQuietUpstream(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	proxy := newTimeoutTestProxy(t, proxyTimeouts{streamIdle: 50 * time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+		done <- rec
+	}()
+	select {
+	case rec := <-done:
+		if rec.Body.String() != "data: first\n\n" {
+			t.Fatalf("expected only the data sent before the stall, got %q", rec.Body.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected idle stream to be aborted")
+	}
+}
+
+func TestIsStreamingResponse(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		resp *http.Response
+		want bool
+	}{
+		{name: "event stream", resp: &http.Response{Header: http.Header{"Content-Type": {"text/event-stream; charset=utf-8"}}, ContentLength: 10}, want: true},
+		{name: "chunked", resp: &http.Response{Header: http.Header{}, ContentLength: -1, TransferEncoding: []string{"chunked"}}, want: true},
+		{name: "json", resp: &http.Response{Header: http.Header{"Content-Type": {"application/json"}}, ContentLength: 10}, want: false},
+	} {
+		if got := isStreamingResponse(tc.resp); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
@@ -9,75 +10,296 @@ import (
 )
 
 type config struct {
-	AppID               int64
-	InstallationID      int64
-	PrivateKeySecret    string
-	PrivateKeyKey       string
-	PrivateKeyNamespace string
-	APIURL              string
-	AllowedHosts        []string
-	AnnotationKey       string
-	AnnotationValue     string
-	Namespace           string
+	Apps            []appConfig
+	AnnotationKey   string
+	AnnotationValue string
+	Namespace       string
+	// TokenScope is tokenScopeRepo to mint one token per repository, or
+	// tokenScopeOrg to mint one installation-wide token per repo owner and share
+	// its secret across that owner's repos.
+	TokenScope string
+	// CredentialMode is credentialModeNetrc to write a .netrc file, or
+	// credentialModeStore to write a git credential-store file.
+	CredentialMode string
+	// Providers serves repos that no GitHub App matches.
+	Providers []providerConfig
 }
 
+// appConfig is one GitHub App installation. Repos are routed to the app whose
+// allowed hosts include the repo host and, when Orgs is set, whose orgs include
+// the repo owner.
+type appConfig struct {
+	AppID               int64    `json:"appId"`
+	InstallationID      int64    `json:"installationId"`
+	PrivateKeySecret    string   `json:"privateKeySecret"`
+	PrivateKeyKey       string   `json:"privateKeyKey,omitempty"`
+	PrivateKeyNamespace string   `json:"privateKeyNamespace,omitempty"`
+	APIURL              string   `json:"apiUrl,omitempty"`
+	AllowedHosts        []string `json:"allowedHosts,omitempty"`
+	Orgs                []string `json:"orgs,omitempty"`
+}
+
+// providerConfig is one non-GitHub repo auth provider. Type selects the
+// implementation: providerTypeGitLab mints project access tokens with the
+// token in TokenSecret, and providerTypeStatic references SecretName, which the
+// user keeps in each Spritz namespace, as repo.auth.
+type providerConfig struct {
+	Type           string   `json:"type"`
+	AllowedHosts   []string `json:"allowedHosts"`
+	Orgs           []string `json:"orgs,omitempty"`
+	APIURL         string   `json:"apiUrl,omitempty"`
+	TokenSecret    string   `json:"tokenSecret,omitempty"`
+	TokenKey       string   `json:"tokenKey,omitempty"`
+	TokenNamespace string   `json:"tokenNamespace,omitempty"`
+	SecretName     string   `json:"secretName,omitempty"`
+	NetrcKey       string   `json:"netrcKey,omitempty"`
+	UsernameKey    string   `json:"usernameKey,omitempty"`
+	PasswordKey    string   `json:"passwordKey,omitempty"`
+}
+
+const (
+	tokenScopeRepo = "repo"
+	tokenScopeOrg  = "org"
+
+	credentialModeNetrc = "netrc"
+	credentialModeStore = "store"
+
+	defaultAnnotationKey   = "spritz.sh/integration.repo-auth"
+	defaultAnnotationValue = "github-app"
+)
+
+// loadConfig reads SPRITZ_GITHUB_APPS (a JSON list of apps) when set, and
+// otherwise a single app from the SPRITZ_GITHUB_APP_* variables. GitHub Apps
+// are optional when SPRITZ_REPO_AUTH_PROVIDERS lists other providers.
 func loadConfig() (config, error) {
-	appID, err := requireInt64("SPRITZ_GITHUB_APP_ID")
+	defaultNamespace := strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_APP_PRIVATE_KEY_NAMESPACE"))
+	if defaultNamespace == "" {
+		defaultNamespace = strings.TrimSpace(os.Getenv("POD_NAMESPACE"))
+	}
+	if defaultNamespace == "" {
+		defaultNamespace = "default"
+	}
+
+	providers, err := loadProviderConfigs(defaultNamespace)
 	if err != nil {
 		return config{}, err
 	}
+
+	var apps []appConfig
+	if raw := strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_APPS")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &apps); err != nil {
+			return config{}, fmt.Errorf("invalid SPRITZ_GITHUB_APPS: %w", err)
+		}
+		if len(apps) == 0 {
+			return config{}, fmt.Errorf("SPRITZ_GITHUB_APPS must list at least one app")
+		}
+	} else if len(providers) == 0 || strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_APP_ID")) != "" {
+		app, err := loadSingleAppConfig()
+		if err != nil {
+			return config{}, err
+		}
+		apps = []appConfig{app}
+	}
+	for i := range apps {
+		if err := normalizeAppConfig(&apps[i], defaultNamespace); err != nil {
+			return config{}, err
+		}
+	}
+	if err := validateAppOverlap(apps); err != nil {
+		return config{}, err
+	}
+
+	tokenScope := strings.ToLower(strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_APP_TOKEN_SCOPE")))
+	if tokenScope == "" {
+		tokenScope = tokenScopeRepo
+	}
+	if tokenScope != tokenScopeRepo && tokenScope != tokenScopeOrg {
+		return config{}, fmt.Errorf("SPRITZ_GITHUB_APP_TOKEN_SCOPE must be %s or %s", tokenScopeRepo, tokenScopeOrg)
+	}
+
+	credentialMode := strings.ToLower(strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_APP_CREDENTIAL_MODE")))
+	if credentialMode == "" {
+		credentialMode = credentialModeNetrc
+	}
+	if credentialMode != credentialModeNetrc && credentialMode != credentialModeStore {
+		return config{}, fmt.Errorf("SPRITZ_GITHUB_APP_CREDENTIAL_MODE must be %s or %s", credentialModeNetrc, credentialModeStore)
+	}
+
+	ns := strings.TrimSpace(os.Getenv("SPRITZ_NAMESPACE"))
+	annotationKey := strings.TrimSpace(os.Getenv("SPRITZ_REPO_AUTH_ANNOTATION_KEY"))
+	if annotationKey == "" {
+		annotationKey = defaultAnnotationKey
+	}
+	// An explicitly empty value matches any Spritz carrying the annotation key.
+	annotationValue, ok := os.LookupEnv("SPRITZ_REPO_AUTH_ANNOTATION_VALUE")
+	if !ok {
+		annotationValue = defaultAnnotationValue
+	}
+	annotationValue = strings.TrimSpace(annotationValue)
+
+	return config{
+		Apps:            apps,
+		AnnotationKey:   annotationKey,
+		AnnotationValue: annotationValue,
+		Namespace:       ns,
+		TokenScope:      tokenScope,
+		CredentialMode:  credentialMode,
+		Providers:       providers,
+	}, nil
+}
+
+// loadProviderConfigs reads SPRITZ_REPO_AUTH_PROVIDERS, a JSON list of
+// providerConfig entries. Repos go to the first entry whose hosts and orgs match.
+func loadProviderConfigs(defaultNamespace string) ([]providerConfig, error) {
+	raw := strings.TrimSpace(os.Getenv("SPRITZ_REPO_AUTH_PROVIDERS"))
+	if raw == "" {
+		return nil, nil
+	}
+	var providers []providerConfig
+	if err := json.Unmarshal([]byte(raw), &providers); err != nil {
+		return nil, fmt.Errorf("invalid SPRITZ_REPO_AUTH_PROVIDERS: %w", err)
+	}
+	for i := range providers {
+		if err := normalizeProviderConfig(&providers[i], defaultNamespace); err != nil {
+			return nil, fmt.Errorf("SPRITZ_REPO_AUTH_PROVIDERS[%d]: %w", i, err)
+		}
+	}
+	return providers, nil
+}
+
+func normalizeProviderConfig(provider *providerConfig, defaultNamespace string) error {
+	provider.Type = strings.ToLower(strings.TrimSpace(provider.Type))
+	provider.AllowedHosts = parseHosts(strings.Join(provider.AllowedHosts, ","))
+	provider.Orgs = parseHosts(strings.Join(provider.Orgs, ","))
+	switch provider.Type {
+	case providerTypeGitLab:
+		if provider.APIURL = strings.TrimSpace(provider.APIURL); provider.APIURL == "" {
+			provider.APIURL = defaultGitLabAPIURL
+		}
+		if len(provider.AllowedHosts) == 0 {
+			if host := apiHost(provider.APIURL); host != "" {
+				provider.AllowedHosts = []string{host}
+			}
+		}
+		if provider.TokenSecret = strings.TrimSpace(provider.TokenSecret); provider.TokenSecret == "" {
+			return fmt.Errorf("gitlab provider requires tokenSecret")
+		}
+		if provider.TokenKey = strings.TrimSpace(provider.TokenKey); provider.TokenKey == "" {
+			provider.TokenKey = defaultProviderKeyName
+		}
+		if provider.TokenNamespace = strings.TrimSpace(provider.TokenNamespace); provider.TokenNamespace == "" {
+			provider.TokenNamespace = defaultNamespace
+		}
+	case providerTypeStatic:
+		if len(provider.AllowedHosts) == 0 {
+			return fmt.Errorf("static provider requires allowedHosts")
+		}
+		if provider.SecretName = strings.TrimSpace(provider.SecretName); provider.SecretName == "" {
+			return fmt.Errorf("static provider requires secretName")
+		}
+		provider.UsernameKey = strings.TrimSpace(provider.UsernameKey)
+		provider.PasswordKey = strings.TrimSpace(provider.PasswordKey)
+		if (provider.UsernameKey == "") != (provider.PasswordKey == "") {
+			return fmt.Errorf("static provider requires both usernameKey and passwordKey for basic auth")
+		}
+		if provider.NetrcKey = strings.TrimSpace(provider.NetrcKey); provider.NetrcKey == "" && provider.UsernameKey == "" {
+			provider.NetrcKey = netrcKey
+		}
+	default:
+		return fmt.Errorf("unsupported provider type %q", provider.Type)
+	}
+	if len(provider.AllowedHosts) == 0 {
+		return fmt.Errorf("%s provider requires allowedHosts", provider.Type)
+	}
+	return nil
+}
+
+func loadSingleAppConfig() (appConfig, error) {
+	appID, err := requireInt64("SPRITZ_GITHUB_APP_ID")
+	if err != nil {
+		return appConfig{}, err
+	}
 	installationID, err := requireInt64("SPRITZ_GITHUB_APP_INSTALLATION_ID")
 	if err != nil {
-		return config{}, err
+		return appConfig{}, err
 	}
 	secret := strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_APP_PRIVATE_KEY_SECRET"))
 	if secret == "" {
-		return config{}, fmt.Errorf("SPRITZ_GITHUB_APP_PRIVATE_KEY_SECRET is required")
+		return appConfig{}, fmt.Errorf("SPRITZ_GITHUB_APP_PRIVATE_KEY_SECRET is required")
+	}
+	return appConfig{
+		AppID:            appID,
+		InstallationID:   installationID,
+		PrivateKeySecret: secret,
+		PrivateKeyKey:    strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_APP_PRIVATE_KEY_KEY")),
+		APIURL:           strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_API_URL")),
+		AllowedHosts:     parseHosts(os.Getenv("SPRITZ_GITHUB_ALLOWED_HOSTS")),
+	}, nil
+}
+
+// normalizeAppConfig fills defaults: the private key key and namespace, the
+// public GitHub API, and allowed hosts derived from the API host.
+func normalizeAppConfig(app *appConfig, defaultNamespace string) error {
+	if app.AppID <= 0 || app.InstallationID <= 0 {
+		return fmt.Errorf("github app entries require appId and installationId")
 	}
-	secretKey := strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_APP_PRIVATE_KEY_KEY"))
-	if secretKey == "" {
-		secretKey = "private-key"
+	app.PrivateKeySecret = strings.TrimSpace(app.PrivateKeySecret)
+	if app.PrivateKeySecret == "" {
+		return fmt.Errorf("github app %d requires privateKeySecret", app.AppID)
 	}
-	apiURL := strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_API_URL"))
-	if apiURL == "" {
-		apiURL = "https://api.github.com"
+	if app.PrivateKeyKey = strings.TrimSpace(app.PrivateKeyKey); app.PrivateKeyKey == "" {
+		app.PrivateKeyKey = "private-key"
 	}
-
-	privateKeyNamespace := strings.TrimSpace(os.Getenv("SPRITZ_GITHUB_APP_PRIVATE_KEY_NAMESPACE"))
-	if privateKeyNamespace == "" {
-		privateKeyNamespace = strings.TrimSpace(os.Getenv("POD_NAMESPACE"))
+	if app.PrivateKeyNamespace = strings.TrimSpace(app.PrivateKeyNamespace); app.PrivateKeyNamespace == "" {
+		app.PrivateKeyNamespace = defaultNamespace
 	}
-	if privateKeyNamespace == "" {
-		privateKeyNamespace = "default"
+	if app.APIURL = strings.TrimSpace(app.APIURL); app.APIURL == "" {
+		app.APIURL = "https://api.github.com"
 	}
-
-	allowedHosts := parseHosts(os.Getenv("SPRITZ_GITHUB_ALLOWED_HOSTS"))
-	if len(allowedHosts) == 0 {
-		if host := apiHost(apiURL); host != "" {
+	app.AllowedHosts = parseHosts(strings.Join(app.AllowedHosts, ","))
+	if len(app.AllowedHosts) == 0 {
+		if host := apiHost(app.APIURL); host != "" {
 			if strings.EqualFold(host, "api.github.com") {
-				allowedHosts = []string{"github.com", host}
+				app.AllowedHosts = []string{"github.com", host}
 			} else {
-				allowedHosts = []string{host}
+				app.AllowedHosts = []string{host}
 			}
 		}
 	}
+	app.Orgs = parseHosts(strings.Join(app.Orgs, ","))
+	return nil
+}
 
-	ns := strings.TrimSpace(os.Getenv("SPRITZ_NAMESPACE"))
-	annotationKey := "spritz.sh/integration.repo-auth"
-	annotationValue := "github-app"
+// validateAppOverlap rejects configs where one repo could match two apps: two
+// apps that share a host must both list orgs, and those orgs must be disjoint.
+func validateAppOverlap(apps []appConfig) error {
+	for i := range apps {
+		for j := i + 1; j < len(apps); j++ {
+			for _, host := range apps[i].AllowedHosts {
+				if !containsFold(apps[j].AllowedHosts, host) {
+					continue
+				}
+				if len(apps[i].Orgs) == 0 || len(apps[j].Orgs) == 0 {
+					return fmt.Errorf("github apps %d and %d both match host %s", apps[i].AppID, apps[j].AppID, host)
+				}
+				for _, org := range apps[i].Orgs {
+					if containsFold(apps[j].Orgs, org) {
+						return fmt.Errorf("github apps %d and %d both match %s/%s", apps[i].AppID, apps[j].AppID, host, org)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
 
-	return config{
-		AppID:               appID,
-		InstallationID:      installationID,
-		PrivateKeySecret:    secret,
-		PrivateKeyKey:       secretKey,
-		PrivateKeyNamespace: privateKeyNamespace,
-		APIURL:              apiURL,
-		AllowedHosts:        allowedHosts,
-		AnnotationKey:       annotationKey,
-		AnnotationValue:     annotationValue,
-		Namespace:           ns,
-	}, nil
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(strings.TrimSpace(candidate), value) {
+			return true
+		}
+	}
+	return false
 }
 
 func parseHosts(raw string) []string {
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigSingleAppFromEnv(t *testing.T) {
+	t.Setenv("SPRITZ_GITHUB_APPS", "")
+	t.Setenv("SPRITZ_GITHUB_APP_ID", "1")
+	t.Setenv("SPRITZ_GITHUB_APP_INSTALLATION_ID", "2")
+	t.Setenv("SPRITZ_GITHUB_APP_PRIVATE_KEY_SECRET", "github-app")
+	t.Setenv("SPRITZ_GITHUB_ALLOWED_HOSTS", "")
+	t.Setenv("SPRITZ_GITHUB_API_URL", "")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if len(cfg.Apps) != 1 {
+		t.Fatalf("expected one app, got %d", len(cfg.Apps))
+	}
+	app := cfg.Apps[0]
+	if app.PrivateKeyKey != "private-key" || app.APIURL != "https://api.github.com" {
+		t.Fatalf("expected defaults to be filled, got %+v", app)
+	}
+	if !containsFold(app.AllowedHosts, "github.com") {
+		t.Fatalf("expected github.com to be allowed by default, got %v", app.AllowedHosts)
+	}
+}
+
+func TestLoadConfigRoutesReposAcrossApps(t *testing.T) {
+	t.Setenv("SPRITZ_GITHUB_APPS", `[
+		{"appId":1,"installationId":10,"privateKeySecret":"app-one","orgs":["example-org"]},
+		{"appId":2,"installationId":20,"privateKeySecret":"app-two","orgs":["example-labs"]},
+		{"appId":3,"installationId":30,"privateKeySecret":"app-three","apiUrl":"https://git.example.com/api/v3"}
+	]`)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	r := &spritzReconciler{Config: cfg}
+	cases := []struct {
+		host, repo string
+		want       int64
+	}{
+		{"github.com", "example-org/app", 1},
+		{"github.com", "Example-Labs/api", 2},
+		{"git.example.com", "any-team/service", 3},
+	}
+	for _, tc := range cases {
+		app, ok := r.appForRepo(tc.host, tc.repo)
+		if !ok || app.AppID != tc.want {
+			t.Fatalf("expected %s/%s to route to app %d, got %+v (ok=%v)", tc.host, tc.repo, tc.want, app, ok)
+		}
+	}
+	if _, ok := r.appForRepo("github.com", "other-org/app"); ok {
+		t.Fatal("expected repos outside every app's orgs to be skipped")
+	}
+}
+
+func TestLoadConfigRejectsOverlappingApps(t *testing.T) {
+	cases := map[string]string{
+		"shared host without orgs": `[
+			{"appId":1,"installationId":10,"privateKeySecret":"app-one"},
+			{"appId":2,"installationId":20,"privateKeySecret":"app-two","orgs":["example-labs"]}
+		]`,
+		"shared org": `[
+			{"appId":1,"installationId":10,"privateKeySecret":"app-one","orgs":["example-org"]},
+			{"appId":2,"installationId":20,"privateKeySecret":"app-two","orgs":["EXAMPLE-ORG"]}
+		]`,
+	}
+	for name, raw := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("SPRITZ_GITHUB_APPS", raw)
+			_, err := loadConfig()
+			if err == nil || !strings.Contains(err.Error(), "both match") {
+				t.Fatalf("expected overlap error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigAnnotationOverrides(t *testing.T) {
+	t.Setenv("SPRITZ_GITHUB_APPS", `[{"appId":1,"installationId":2,"privateKeySecret":"github-app"}]`)
+
+	t.Setenv("SPRITZ_REPO_AUTH_ANNOTATION_KEY", "")
+	t.Setenv("SPRITZ_REPO_AUTH_ANNOTATION_VALUE", "")
+	// Setenv restores the variable after the test; unset it to exercise the default.
+	os.Unsetenv("SPRITZ_REPO_AUTH_ANNOTATION_VALUE")
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if cfg.AnnotationKey != defaultAnnotationKey || cfg.AnnotationValue != defaultAnnotationValue {
+		t.Fatalf("expected default annotation, got %s=%s", cfg.AnnotationKey, cfg.AnnotationValue)
+	}
+
+	t.Setenv("SPRITZ_REPO_AUTH_ANNOTATION_KEY", "example.com/repo-auth")
+	t.Setenv("SPRITZ_REPO_AUTH_ANNOTATION_VALUE", "gitlab")
+	cfg, err = loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if cfg.AnnotationKey != "example.com/repo-auth" || cfg.AnnotationValue != "gitlab" {
+		t.Fatalf("expected annotation override, got %s=%s", cfg.AnnotationKey, cfg.AnnotationValue)
+	}
+
+	t.Setenv("SPRITZ_REPO_AUTH_ANNOTATION_VALUE", "")
+	cfg, err = loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if cfg.AnnotationValue != "" {
+		t.Fatalf("expected an empty value to match any annotation value, got %q", cfg.AnnotationValue)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"crypto/sha256"
+	stderrors "errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -20,6 +23,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"spritz.sh/operator/tracing"
 )
 
 type spritzReconciler struct {
@@ -27,6 +32,7 @@ type spritzReconciler struct {
 	Scheme     *runtime.Scheme
 	Config     config
 	HTTPClient httpClient
+	Tokens     *installationTokenCache
 }
 
 type httpClient interface {
@@ -38,6 +44,8 @@ var spritzGVK = schema.GroupVersionKind{Group: "spritz.sh", Version: "v1", Kind:
 const (
 	tokenExpiryAnnotation = "spritz.sh/github-app-token-expires-at"
 	tokenRepoAnnotation   = "spritz.sh/github-app-repo"
+	appErrorAnnotation    = "spritz.sh/github-app-error"
+	accessRetryInterval   = 10 * time.Minute
 	tokenRefreshLead      = 15 * time.Minute
 	labelManagedBy        = "spritz.sh/managedBy"
 	labelPurpose          = "spritz.sh/purpose"
@@ -45,9 +53,15 @@ const (
 	purposeRepoAuth       = "repo-auth"
 	netrcKey              = "netrc"
 	netrcLoginToken       = "x-access-token"
+	gitCredentialsKey     = "git-credentials"
 )
 
-func (r *spritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *spritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := tracer.Start(ctx, "Spritz.Reconcile", trace.WithAttributes(
+		attribute.String("k8s.namespace.name", req.Namespace),
+		attribute.String("spritz.name", req.Name),
+	))
+	defer func() { tracing.End(span, err) }()
 	logger := ctrl.LoggerFrom(ctx).WithValues("spritz", req.NamespacedName)
 
 	var spritz unstructured.Unstructured
@@ -76,6 +90,7 @@ func (r *spritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	shouldPatch := false
 	var updatedRepos []interface{}
 	var minRequeue *time.Duration
+	var accessErrors []string
 
 	updateMinRequeue := func(value time.Duration) {
 		if value <= 0 {
@@ -99,12 +114,21 @@ func (r *spritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		if err := validateRepoPath(repoPath); err != nil {
 			return r.recordError(logger, "invalid repo path", err)
 		}
-		if !r.allowedHost(repoHost) {
+		provider, ok := r.providerForRepo(repoHost, repoPath)
+		if !ok {
 			logger.Info("repo host not allowed", "host", repoHost)
 			return nil
 		}
+		if passthrough, ok := provider.(passthroughProvider); ok {
+			if authSecretName == "" {
+				repo["auth"] = passthrough.RepoAuth()
+				shouldPatch = true
+			}
+			return nil
+		}
 
-		secretName := repoAuthSecretName(spritz.GetName(), repoPath)
+		scopeKey := provider.ScopeKey(repoHost, repoPath)
+		secretName := repoAuthSecretName(spritz.GetName(), scopeKey)
 		if authSecretName != "" && authSecretName != secretName {
 			return nil
 		}
@@ -124,25 +148,43 @@ func (r *spritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			return nil
 		}
 
-		shouldPatchAuth := shouldPatchRepoAuth(authSecretName, secretExists, managedSecret)
+		credentialKey := r.credentialKey()
+		shouldPatchAuth := shouldPatchRepoAuth(authSecretName, secretExists, managedSecret) ||
+			(authSecretName == secretName && !repoAuthHasKey(repo, credentialKey))
 		if secretExists {
-			shouldRefresh, requeueAfter := tokenNeedsRefresh(secret, time.Now(), repoPath)
+			shouldRefresh, requeueAfter := tokenNeedsRefresh(secret, time.Now(), scopeKey)
+			// A credential mode change rewrites the secret in the new format.
+			if _, ok := secret.Data[credentialKey]; !ok {
+				shouldRefresh = true
+			}
 			updateMinRequeue(requeueAfter)
 			if !shouldRefresh && !shouldPatchAuth {
 				return nil
 			}
 			if !shouldRefresh && shouldPatchAuth {
-				setRepoAuth(repo, secretName)
+				r.setRepoAuth(repo, secretName)
 				shouldPatch = true
 				return nil
 			}
 		}
 
-		token, expiry, err := r.githubAppInstallationToken(ctx, repoPath)
+		minted, err := provider.Credentials(ctx, repoHost, repoPath, scopeKey)
 		if err != nil {
+			recordMintFailure(provider.Type(), err)
+			var accessErr *repoAccessError
+			if stderrors.As(err, &accessErr) {
+				logger.Info("repo not accessible to provider", "provider", provider.Type(), "repo", repoPath, "reason", accessErr.message)
+				accessErrors = append(accessErrors, accessErr.Error())
+				updateMinRequeue(accessRetryInterval)
+				return nil
+			}
 			return r.recordError(logger, "token mint failed", err)
 		}
-		netrc := buildNetrc(repoHost, token)
+		expiry := minted.expiry
+		credentials := buildNetrc(repoHost, minted.login, minted.token)
+		if credentialKey == gitCredentialsKey {
+			credentials = buildGitCredentials(repoHost, minted.login, minted.token)
+		}
 
 		secret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
 			Name:      secretName,
@@ -165,10 +207,10 @@ func (r *spritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			} else {
 				delete(secret.Annotations, tokenExpiryAnnotation)
 			}
-			secret.Annotations[tokenRepoAnnotation] = repoPath
+			secret.Annotations[tokenRepoAnnotation] = scopeKey
 			secret.Type = corev1.SecretTypeOpaque
 			secret.Data = map[string][]byte{
-				netrcKey: []byte(netrc),
+				credentialKey: []byte(credentials),
 			}
 			return nil
 		})
@@ -177,7 +219,7 @@ func (r *spritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		}
 
 		if shouldPatchAuth || authSecretName == "" {
-			setRepoAuth(repo, secretName)
+			r.setRepoAuth(repo, secretName)
 			shouldPatch = true
 		}
 
@@ -223,6 +265,10 @@ func (r *spritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		}
 	}
 
+	if setAppErrorAnnotation(&spritz, strings.Join(accessErrors, "; ")) {
+		shouldPatch = true
+	}
+
 	if shouldPatch {
 		if err := r.Patch(ctx, &spritz, client.MergeFrom(original)); err != nil {
 			if errors.IsConflict(err) {
@@ -245,13 +291,34 @@ func shouldPatchRepoAuth(authSecretName string, secretExists bool, managedSecret
 	return secretExists && managedSecret
 }
 
+// setAppErrorAnnotation records repo access failures on the Spritz so users
+// see that the app needs installing, and clears the annotation once resolved.
+// It reports whether the annotations changed.
+func setAppErrorAnnotation(obj client.Object, message string) bool {
+	annotations := obj.GetAnnotations()
+	current, exists := annotations[appErrorAnnotation]
+	if message == "" {
+		if !exists {
+			return false
+		}
+		delete(annotations, appErrorAnnotation)
+		obj.SetAnnotations(annotations)
+		return true
+	}
+	if exists && current == message {
+		return false
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[appErrorAnnotation] = message
+	obj.SetAnnotations(annotations)
+	return true
+}
+
 func (r *spritzReconciler) patchRepoAuth(ctx context.Context, spritz *unstructured.Unstructured, secretName string) error {
 	original := spritz.DeepCopy()
-	auth := map[string]interface{}{
-		"secretName": secretName,
-		"netrcKey":   netrcKey,
-	}
-	if err := unstructured.SetNestedField(spritz.Object, auth, "spec", "repo", "auth"); err != nil {
+	if err := unstructured.SetNestedField(spritz.Object, r.repoAuthSpec(secretName), "spec", "repo", "auth"); err != nil {
 		return err
 	}
 	return r.Patch(ctx, spritz, client.MergeFrom(original))
@@ -326,16 +393,34 @@ func tokenNeedsRefresh(secret *corev1.Secret, now time.Time, repoPath string) (b
 	return false, time.Until(refreshAt)
 }
 
-func (r *spritzReconciler) allowedHost(host string) bool {
+// appForRepo picks the GitHub App that serves a repo: its allowed hosts must
+// include the repo host, and when it lists orgs, the repo owner must be one of them.
+func (r *spritzReconciler) appForRepo(host, repoPath string) (appConfig, bool) {
 	if host == "" {
-		return false
+		return appConfig{}, false
 	}
-	for _, allowed := range r.Config.AllowedHosts {
-		if strings.EqualFold(strings.TrimSpace(allowed), host) {
-			return true
+	owner := strings.SplitN(repoPath, "/", 2)[0]
+	for _, app := range r.Config.Apps {
+		if !containsFold(app.AllowedHosts, host) {
+			continue
 		}
+		if len(app.Orgs) > 0 && !containsFold(app.Orgs, owner) {
+			continue
+		}
+		return app, true
 	}
-	return false
+	return appConfig{}, false
+}
+
+// tokenScopeKey identifies what a minted token covers: the repo path for
+// repo-scoped tokens, or "owner/*" for org-scoped tokens so every repo of the
+// same owner shares one secret.
+func (r *spritzReconciler) tokenScopeKey(repoPath string) string {
+	if r.Config.TokenScope != tokenScopeOrg {
+		return repoPath
+	}
+	owner := strings.SplitN(repoPath, "/", 2)[0]
+	return owner + "/*"
 }
 
 func repoAuthSecretName(name, repoPath string) string {
@@ -358,16 +443,48 @@ func readRepoSpec(repo map[string]interface{}) (string, string) {
 	return strings.TrimSpace(repoURL), strings.TrimSpace(authSecretName)
 }
 
-func setRepoAuth(repo map[string]interface{}, secretName string) {
+func (r *spritzReconciler) setRepoAuth(repo map[string]interface{}, secretName string) {
 	if repo == nil {
 		return
 	}
-	repo["auth"] = map[string]interface{}{
+	repo["auth"] = r.repoAuthSpec(secretName)
+}
+
+// repoAuthSpec points repo.auth at the secret key that matches the credential mode.
+func (r *spritzReconciler) repoAuthSpec(secretName string) map[string]interface{} {
+	if r.credentialKey() == gitCredentialsKey {
+		return map[string]interface{}{
+			"secretName":     secretName,
+			"credentialsKey": gitCredentialsKey,
+		}
+	}
+	return map[string]interface{}{
 		"secretName": secretName,
 		"netrcKey":   netrcKey,
 	}
 }
 
+// credentialKey is the secret key the integration writes for the configured mode.
+func (r *spritzReconciler) credentialKey() string {
+	if r.Config.CredentialMode == credentialModeStore {
+		return gitCredentialsKey
+	}
+	return netrcKey
+}
+
+func repoAuthHasKey(repo map[string]interface{}, key string) bool {
+	auth, ok := repo["auth"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	field := "netrcKey"
+	if key == gitCredentialsKey {
+		field = "credentialsKey"
+	}
+	value, _ := auth[field].(string)
+	return value == key
+}
+
 func parseRepoURL(raw string) (string, string, error) {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -405,6 +522,11 @@ func validateRepoPath(repo string) error {
 	return nil
 }
 
-func buildNetrc(host, token string) string {
-	return fmt.Sprintf("machine %s\n  login %s\n  password %s\n", host, netrcLoginToken, token)
+func buildNetrc(host, login, token string) string {
+	return fmt.Sprintf("machine %s\n  login %s\n  password %s\n", host, login, token)
+}
+
+// buildGitCredentials renders a `git credential-store` file entry.
+func buildGitCredentials(host, login, token string) string {
+	return fmt.Sprintf("https://%s@%s\n", url.UserPassword(login, token).String(), host)
 }
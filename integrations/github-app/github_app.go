@@ -11,8 +11,12 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+
+	"spritz.sh/operator/tracing"
 )
 
 type githubTokenResponse struct {
@@ -20,22 +24,35 @@ type githubTokenResponse struct {
 	ExpiresAt string `json:"expires_at"`
 }
 
-func (r *spritzReconciler) githubAppInstallationToken(ctx context.Context, repo string) (string, *time.Time, error) {
-	privateKey, err := r.githubAppPrivateKey(ctx)
-	if err != nil {
-		return "", nil, err
-	}
-	jwtToken, err := githubAppJWT(r.Config.AppID, privateKey)
+type githubRepoInstallationResponse struct {
+	ID int64 `json:"id"`
+}
+
+// repoAccessError reports that a provider cannot serve a repo, typically
+// because the GitHub App is not installed on it. Retrying quickly will not help.
+type repoAccessError struct {
+	provider string
+	repo     string
+	message  string
+}
+
+func (e *repoAccessError) Error() string {
+	return fmt.Sprintf("%s cannot access %s: %s", e.provider, e.repo, e.message)
+}
+
+func (r *spritzReconciler) githubAppInstallationToken(ctx context.Context, app appConfig, repo string) (string, *time.Time, error) {
+	jwtToken, err := r.githubAppBearer(ctx, app)
 	if err != nil {
 		return "", nil, err
 	}
 
-	endpoint := fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimRight(r.Config.APIURL, "/"), r.Config.InstallationID)
-	repoName := repoNameFromPath(repo)
+	endpoint := fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimRight(app.APIURL, "/"), app.InstallationID)
 	payload := struct {
 		Repositories []string `json:"repositories,omitempty"`
-	}{
-		Repositories: []string{repoName},
+	}{}
+	// Org-scoped tokens cover every repository the installation can access.
+	if r.Config.TokenScope != tokenScopeOrg {
+		payload.Repositories = []string{repoNameFromPath(repo)}
 	}
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -55,6 +72,9 @@ func (r *spritzReconciler) githubAppInstallationToken(ctx context.Context, repo
 		return "", nil, err
 	}
 	defer resp.Body.Close()
+	if isRateLimited(resp) {
+		return "", nil, &rateLimitError{provider: "github", status: resp.StatusCode}
+	}
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		payload, readErr := io.ReadAll(resp.Body)
 		if readErr != nil {
@@ -79,16 +99,80 @@ func (r *spritzReconciler) githubAppInstallationToken(ctx context.Context, repo
 	return parsed.Token, expiry, nil
 }
 
-func (r *spritzReconciler) githubAppPrivateKey(ctx context.Context) (*rsa.PrivateKey, error) {
+// checkRepoAccess asks GitHub which installation of the app covers repo, using
+// the app JWT. A 403/404 or a different installation id becomes a
+// repoAccessError so the caller can report it instead of retrying the mint.
+func (r *spritzReconciler) checkRepoAccess(ctx context.Context, app appConfig, repo string) (err error) {
+	ctx, span := tracer.Start(ctx, "github.checkRepoAccess", trace.WithAttributes(
+		attribute.Int64("github.app_id", app.AppID),
+		attribute.String("github.repo", repo),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	jwtToken, err := r.githubAppBearer(ctx, app)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/installation", strings.TrimRight(app.APIURL, "/"), repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwtToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case isRateLimited(resp):
+		return &rateLimitError{provider: "github", status: resp.StatusCode}
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden:
+		return &repoAccessError{
+			provider: "github app",
+			repo:     repo,
+			message:  fmt.Sprintf("app %d is not installed on this repository (status %d)", app.AppID, resp.StatusCode),
+		}
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		payload, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("github repo installation request failed: status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+
+	var parsed githubRepoInstallationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return err
+	}
+	if parsed.ID != 0 && parsed.ID != app.InstallationID {
+		return &repoAccessError{
+			provider: "github app",
+			repo:     repo,
+			message:  fmt.Sprintf("repository belongs to installation %d, not the configured installation %d", parsed.ID, app.InstallationID),
+		}
+	}
+	return nil
+}
+
+func (r *spritzReconciler) githubAppBearer(ctx context.Context, app appConfig) (string, error) {
+	privateKey, err := r.githubAppPrivateKey(ctx, app)
+	if err != nil {
+		return "", err
+	}
+	return githubAppJWT(app.AppID, privateKey)
+}
+
+func (r *spritzReconciler) githubAppPrivateKey(ctx context.Context, app appConfig) (*rsa.PrivateKey, error) {
 	secret := &corev1.Secret{}
 	key := types.NamespacedName{
-		Name:      r.Config.PrivateKeySecret,
-		Namespace: r.Config.PrivateKeyNamespace,
+		Name:      app.PrivateKeySecret,
+		Namespace: app.PrivateKeyNamespace,
 	}
 	if err := r.Get(ctx, key, secret); err != nil {
 		return nil, err
 	}
-	raw, ok := secret.Data[r.Config.PrivateKeyKey]
+	raw, ok := secret.Data[app.PrivateKeyKey]
 	if !ok {
 		return nil, fmt.Errorf("github app private key not found in secret")
 	}
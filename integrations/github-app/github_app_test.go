@@ -1,6 +1,30 @@
 package main
 
-import "testing"
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type httpClientFunc func(req *http.Request) (*http.Response, error)
+
+func (f httpClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
 
 func TestRepoNameFromPath(t *testing.T) {
 	cases := []struct {
@@ -20,3 +44,152 @@ func TestRepoNameFromPath(t *testing.T) {
 		})
 	}
 }
+
+func newTokenTestReconciler(t *testing.T, scope string, requests *[]map[string]any) *spritzReconciler {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add scheme: %v", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-app", Namespace: "spritz-system"},
+		Data:       map[string][]byte{"private-key": keyPEM},
+	}
+	return &spritzReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(),
+		Scheme: scheme,
+		Config: config{
+			Apps: []appConfig{{
+				AppID:               1,
+				InstallationID:      2,
+				PrivateKeySecret:    "github-app",
+				PrivateKeyKey:       "private-key",
+				PrivateKeyNamespace: "spritz-system",
+				APIURL:              "https://api.github.example.com",
+				AllowedHosts:        []string{"github.example.com"},
+			}},
+			TokenScope: scope,
+		},
+		HTTPClient: httpClientFunc(func(req *http.Request) (*http.Response, error) {
+			var payload map[string]any
+			_ = json.NewDecoder(req.Body).Decode(&payload)
+			*requests = append(*requests, payload)
+			body := `{"token":"ghs_example","expires_at":"2030-01-01T00:00:00Z"}`
+			return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}),
+	}
+}
+
+func TestInstallationTokenScope(t *testing.T) {
+	var requests []map[string]any
+	repoScoped := newTokenTestReconciler(t, tokenScopeRepo, &requests)
+	if _, _, err := repoScoped.githubAppInstallationToken(context.Background(), repoScoped.Config.Apps[0], "example-org/app"); err != nil {
+		t.Fatalf("repo token: %v", err)
+	}
+	orgScoped := newTokenTestReconciler(t, tokenScopeOrg, &requests)
+	if _, _, err := orgScoped.githubAppInstallationToken(context.Background(), orgScoped.Config.Apps[0], "example-org/app"); err != nil {
+		t.Fatalf("org token: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected two token requests, got %d", len(requests))
+	}
+	repos, ok := requests[0]["repositories"].([]any)
+	if !ok || len(repos) != 1 || repos[0] != "app" {
+		t.Fatalf("expected repo-scoped request to name the repo, got %v", requests[0])
+	}
+	if _, ok := requests[1]["repositories"]; ok {
+		t.Fatalf("expected org-scoped request to omit repositories, got %v", requests[1])
+	}
+}
+
+func TestTokenScopeKeySharesOrgSecret(t *testing.T) {
+	repoScoped := &spritzReconciler{Config: config{TokenScope: tokenScopeRepo}}
+	if repoScoped.tokenScopeKey("example-org/app") == repoScoped.tokenScopeKey("example-org/api") {
+		t.Fatal("expected repo-scoped tokens to use one secret per repo")
+	}
+	orgScoped := &spritzReconciler{Config: config{TokenScope: tokenScopeOrg}}
+	if got := orgScoped.tokenScopeKey("example-org/app"); got != "example-org/*" {
+		t.Fatalf("unexpected org scope key %q", got)
+	}
+	if repoAuthSecretName("tidal-ember", orgScoped.tokenScopeKey("example-org/app")) != repoAuthSecretName("tidal-ember", orgScoped.tokenScopeKey("example-org/api")) {
+		t.Fatal("expected org-scoped repos of one owner to share a secret")
+	}
+}
+
+func TestInstallationTokenReusesCachedToken(t *testing.T) {
+	var requests []map[string]any
+	r := newTokenTestReconciler(t, tokenScopeOrg, &requests)
+	r.Tokens = newInstallationTokenCache()
+
+	for _, repo := range []string{"example-org/app", "example-org/api", "example-org/app"} {
+		token, expiry, err := r.installationToken(context.Background(), r.Config.Apps[0], repo, r.tokenScopeKey(repo))
+		if err != nil {
+			t.Fatalf("installation token for %s: %v", repo, err)
+		}
+		if token != "ghs_example" || expiry == nil {
+			t.Fatalf("unexpected token %q expiry %v", token, expiry)
+		}
+	}
+	if len(requests) != 1 {
+		t.Fatalf("expected one token mint for repos sharing a scope, got %d", len(requests))
+	}
+}
+
+func TestInstallationTokenCacheExpiresBeforeRefreshLead(t *testing.T) {
+	cache := newInstallationTokenCache()
+	now := time.Unix(1_700_000_000, 0)
+	cache.now = func() time.Time { return now }
+	expiry := now.Add(tokenRefreshLead + time.Minute)
+	cache.put("2:example-org/*", "ghs_example", &expiry)
+
+	if _, _, ok := cache.get("2:example-org/*"); !ok {
+		t.Fatal("expected cached token before the refresh lead")
+	}
+	now = now.Add(2 * time.Minute)
+	if _, _, ok := cache.get("2:example-org/*"); ok {
+		t.Fatal("expected cached token to be dropped inside the refresh lead")
+	}
+}
+
+func TestCheckRepoAccessReportsMissingInstallation(t *testing.T) {
+	var requests []map[string]any
+	r := newTokenTestReconciler(t, tokenScopeRepo, &requests)
+	cases := []struct {
+		name       string
+		status     int
+		body       string
+		wantAccess bool
+		wantErr    bool
+	}{
+		{"installed", http.StatusOK, `{"id":2}`, false, false},
+		{"not installed", http.StatusNotFound, `{"message":"Not Found"}`, true, true},
+		{"other installation", http.StatusOK, `{"id":7}`, true, true},
+		{"server error", http.StatusBadGateway, `upstream`, false, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r.HTTPClient = httpClientFunc(func(req *http.Request) (*http.Response, error) {
+				if req.Method != http.MethodGet || req.URL.Path != "/repos/example-org/app/installation" {
+					t.Fatalf("unexpected request %s %s", req.Method, req.URL.Path)
+				}
+				if !strings.HasPrefix(req.Header.Get("Authorization"), "Bearer ") {
+					t.Fatal("expected app JWT bearer auth")
+				}
+				return &http.Response{StatusCode: tc.status, Body: io.NopCloser(strings.NewReader(tc.body))}, nil
+			})
+			err := r.checkRepoAccess(context.Background(), r.Config.Apps[0], "example-org/app")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			var accessErr *repoAccessError
+			if errors.As(err, &accessErr) != tc.wantAccess {
+				t.Fatalf("expected access error %v, got %v", tc.wantAccess, err)
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -15,6 +17,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"spritz.sh/operator/tracing"
 )
 
 func main() {
@@ -51,12 +55,26 @@ func main() {
 	}
 	if cfg.Namespace != "" {
 		namespaces := map[string]cache.Config{cfg.Namespace: {}}
-		if cfg.PrivateKeyNamespace != "" && cfg.PrivateKeyNamespace != cfg.Namespace {
-			namespaces[cfg.PrivateKeyNamespace] = cache.Config{}
+		for _, app := range cfg.Apps {
+			if app.PrivateKeyNamespace != "" {
+				namespaces[app.PrivateKeyNamespace] = cache.Config{}
+			}
+		}
+		for _, provider := range cfg.Providers {
+			if provider.TokenNamespace != "" {
+				namespaces[provider.TokenNamespace] = cache.Config{}
+			}
 		}
 		managerOpts.Cache = cache.Options{DefaultNamespaces: namespaces}
 	}
 
+	ctx := ctrl.SetupSignalHandler()
+	shutdownTracing, err := tracing.Setup(ctx, "spritz-github-app")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tracing error: %v\n", err)
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), managerOpts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "manager error: %v\n", err)
@@ -68,6 +86,7 @@ func main() {
 		Scheme:     mgr.GetScheme(),
 		Config:     cfg,
 		HTTPClient: &http.Client{},
+		Tokens:     newInstallationTokenCache(),
 	}
 
 	if err := reconciler.SetupWithManager(mgr); err != nil {
@@ -84,8 +103,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "manager stopped: %v\n", err)
 		os.Exit(1)
 	}
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "trace flush error: %v\n", err)
+	}
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	mintFailureAccessDenied = "access_denied"
+	mintFailureRateLimited  = "rate_limited"
+	mintFailureError        = "error"
+)
+
+var (
+	tokensMintedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "spritz_repo_auth_tokens_minted_total",
+		Help: "Repo auth tokens minted from a provider API, excluding cache hits.",
+	}, []string{"provider"})
+	mintFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "spritz_repo_auth_mint_failures_total",
+		Help: "Repo auth token mints that failed, by reason.",
+	}, []string{"provider", "reason"})
+	tokenTTLSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "spritz_repo_auth_token_ttl_seconds",
+		Help:    "Remaining lifetime of newly minted repo auth tokens.",
+		Buckets: prometheus.ExponentialBuckets(300, 2, 10),
+	}, []string{"provider"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(tokensMintedTotal, mintFailuresTotal, tokenTTLSeconds)
+}
+
+// rateLimitError reports that a provider API refused a request because the
+// caller exceeded its rate limit.
+type rateLimitError struct {
+	provider string
+	status   int
+}
+
+func (e *rateLimitError) Error() string {
+	return e.provider + " rate limit exceeded"
+}
+
+// isRateLimited recognizes 429 responses and GitHub's 403 responses with an
+// exhausted X-RateLimit-Remaining budget.
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+func recordTokenMinted(provider string, expiry *time.Time) {
+	tokensMintedTotal.WithLabelValues(provider).Inc()
+	if expiry != nil {
+		tokenTTLSeconds.WithLabelValues(provider).Observe(time.Until(*expiry).Seconds())
+	}
+}
+
+func recordMintFailure(provider string, err error) {
+	mintFailuresTotal.WithLabelValues(provider, mintFailureReason(err)).Inc()
+}
+
+func mintFailureReason(err error) string {
+	var accessErr *repoAccessError
+	var limitErr *rateLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		return mintFailureRateLimited
+	case errors.As(err, &accessErr):
+		return mintFailureAccessDenied
+	default:
+		return mintFailureError
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMintFailureReason(t *testing.T) {
+	cases := map[string]error{
+		mintFailureRateLimited:  fmt.Errorf("mint: %w", &rateLimitError{provider: "github", status: http.StatusForbidden}),
+		mintFailureAccessDenied: &repoAccessError{provider: "github app", repo: "example-org/app"},
+		mintFailureError:        fmt.Errorf("boom"),
+	}
+	for want, err := range cases {
+		if got := mintFailureReason(err); got != want {
+			t.Fatalf("expected %s for %v, got %s", want, err, got)
+		}
+	}
+}
+
+func TestCheckRepoAccessRecognizesRateLimit(t *testing.T) {
+	var requests []map[string]any
+	r := newTokenTestReconciler(t, tokenScopeRepo, &requests)
+	r.HTTPClient = httpClientFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("X-RateLimit-Remaining", "0")
+		return &http.Response{StatusCode: http.StatusForbidden, Header: header, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+	})
+	err := r.checkRepoAccess(t.Context(), r.Config.Apps[0], "example-org/app")
+	if got := mintFailureReason(err); got != mintFailureRateLimited {
+		t.Fatalf("expected rate limited failure, got %s (%v)", got, err)
+	}
+}
+
+func TestInstallationTokenRecordsMint(t *testing.T) {
+	var requests []map[string]any
+	r := newTokenTestReconciler(t, tokenScopeRepo, &requests)
+	r.Tokens = newInstallationTokenCache()
+	before := testutil.ToFloat64(tokensMintedTotal.WithLabelValues("github-app"))
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := r.installationToken(t.Context(), r.Config.Apps[0], "example-org/app", "example-org/app"); err != nil {
+			t.Fatalf("installation token: %v", err)
+		}
+	}
+	if got := testutil.ToFloat64(tokensMintedTotal.WithLabelValues("github-app")) - before; got != 1 {
+		t.Fatalf("expected one minted token to be counted, got %v", got)
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	providerTypeGitLab = "gitlab"
+	providerTypeStatic = "static"
+
+	gitlabLogin            = "oauth2"
+	gitlabTokenName        = "spritz-repo-auth"
+	gitlabTokenLifetime    = 48 * time.Hour
+	gitlabReporterAccess   = 20
+	defaultGitLabAPIURL    = "https://gitlab.com/api/v4"
+	defaultProviderKeyName = "token"
+)
+
+// repoAuthProvider mints credentials for the repos it serves. processRepo
+// stores the result in a managed secret and schedules refreshes from the
+// reported expiry.
+type repoAuthProvider interface {
+	// Type names the provider in logs.
+	Type() string
+	// ScopeKey identifies what one credential covers; repos with the same key
+	// share a secret.
+	ScopeKey(host, repoPath string) string
+	// Credentials returns a login and token for repoPath.
+	Credentials(ctx context.Context, host, repoPath, scopeKey string) (providerCredentials, error)
+}
+
+// passthroughProvider serves repos from a secret the user already manages, so
+// the integration only points repo.auth at it and never writes a secret.
+type passthroughProvider interface {
+	RepoAuth() map[string]interface{}
+}
+
+type providerCredentials struct {
+	login  string
+	token  string
+	expiry *time.Time
+}
+
+// providerForRepo routes a repo to a GitHub App first, then to the first entry
+// in SPRITZ_REPO_AUTH_PROVIDERS whose hosts and orgs match.
+func (r *spritzReconciler) providerForRepo(host, repoPath string) (repoAuthProvider, bool) {
+	if app, ok := r.appForRepo(host, repoPath); ok {
+		return &githubAppProvider{r: r, app: app}, true
+	}
+	if host == "" {
+		return nil, false
+	}
+	owner := strings.SplitN(repoPath, "/", 2)[0]
+	for _, cfg := range r.Config.Providers {
+		if !containsFold(cfg.AllowedHosts, host) {
+			continue
+		}
+		if len(cfg.Orgs) > 0 && !containsFold(cfg.Orgs, owner) {
+			continue
+		}
+		switch cfg.Type {
+		case providerTypeGitLab:
+			return &gitlabProvider{r: r, cfg: cfg}, true
+		case providerTypeStatic:
+			return &staticProvider{cfg: cfg}, true
+		}
+	}
+	return nil, false
+}
+
+type githubAppProvider struct {
+	r   *spritzReconciler
+	app appConfig
+}
+
+func (p *githubAppProvider) Type() string { return "github-app" }
+
+func (p *githubAppProvider) ScopeKey(_, repoPath string) string {
+	return p.r.tokenScopeKey(repoPath)
+}
+
+func (p *githubAppProvider) Credentials(ctx context.Context, _, repoPath, scopeKey string) (providerCredentials, error) {
+	if err := p.r.checkRepoAccess(ctx, p.app, repoPath); err != nil {
+		return providerCredentials{}, err
+	}
+	token, expiry, err := p.r.installationToken(ctx, p.app, repoPath, scopeKey)
+	if err != nil {
+		return providerCredentials{}, err
+	}
+	return providerCredentials{login: netrcLoginToken, token: token, expiry: expiry}, nil
+}
+
+// gitlabProvider mints short-lived project access tokens with read_repository
+// scope, authenticating with a token that may create them. GitLab only accepts
+// a date for expires_at, so tokens live for up to gitlabTokenLifetime. After a
+// mint, earlier spritz tokens of the project are revoked so they do not pile
+// up against GitLab's per-project token limit.
+type gitlabProvider struct {
+	r   *spritzReconciler
+	cfg providerConfig
+}
+
+type gitlabTokenResponse struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+func (p *gitlabProvider) Type() string { return providerTypeGitLab }
+
+// ScopeKey includes the host so a GitLab repo never shares a secret with a
+// GitHub repo of the same path.
+func (p *gitlabProvider) ScopeKey(host, repoPath string) string {
+	return host + "/" + repoPath
+}
+
+func (p *gitlabProvider) Credentials(ctx context.Context, _, repoPath, scopeKey string) (providerCredentials, error) {
+	cacheKey := providerTypeGitLab + ":" + scopeKey
+	if token, expiry, ok := p.r.Tokens.get(cacheKey); ok {
+		return providerCredentials{login: gitlabLogin, token: token, expiry: expiry}, nil
+	}
+	adminToken, err := p.r.providerSecretValue(ctx, p.cfg.TokenNamespace, p.cfg.TokenSecret, p.cfg.TokenKey)
+	if err != nil {
+		return providerCredentials{}, err
+	}
+
+	expiresAt := time.Now().UTC().Add(gitlabTokenLifetime).Format("2006-01-02")
+	body, err := json.Marshal(map[string]any{
+		"name":         gitlabTokenName,
+		"scopes":       []string{"read_repository"},
+		"access_level": gitlabReporterAccess,
+		"expires_at":   expiresAt,
+	})
+	if err != nil {
+		return providerCredentials{}, err
+	}
+	endpoint := p.accessTokensURL(repoPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(string(body)))
+	if err != nil {
+		return providerCredentials{}, err
+	}
+	req.Header.Set("PRIVATE-TOKEN", adminToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.r.HTTPClient.Do(req)
+	if err != nil {
+		return providerCredentials{}, err
+	}
+	defer resp.Body.Close()
+	if isRateLimited(resp) {
+		return providerCredentials{}, &rateLimitError{provider: providerTypeGitLab, status: resp.StatusCode}
+	}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return providerCredentials{}, &repoAccessError{
+			provider: "gitlab",
+			repo:     repoPath,
+			message:  fmt.Sprintf("gitlab token cannot create project access tokens (status %d)", resp.StatusCode),
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		payload, _ := io.ReadAll(resp.Body)
+		return providerCredentials{}, fmt.Errorf("gitlab token request failed: status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+	var parsed gitlabTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return providerCredentials{}, err
+	}
+	if parsed.Token == "" {
+		return providerCredentials{}, fmt.Errorf("gitlab token response missing token")
+	}
+	var expiry *time.Time
+	if parsed.ExpiresAt != "" {
+		if ts, err := time.Parse("2006-01-02", parsed.ExpiresAt); err == nil {
+			expiry = &ts
+		}
+	}
+	recordTokenMinted(providerTypeGitLab, expiry)
+	p.r.Tokens.put(cacheKey, parsed.Token, expiry)
+	if err := p.revokePreviousTokens(ctx, adminToken, repoPath, parsed.ID); err != nil {
+		ctrl.LoggerFrom(ctx).Info("failed to revoke previous gitlab tokens", "repo", repoPath, "error", err.Error())
+	}
+	return providerCredentials{login: gitlabLogin, token: parsed.Token, expiry: expiry}, nil
+}
+
+func (p *gitlabProvider) accessTokensURL(repoPath string) string {
+	return fmt.Sprintf("%s/projects/%s/access_tokens", strings.TrimRight(p.cfg.APIURL, "/"), url.PathEscape(repoPath))
+}
+
+// revokePreviousTokens revokes the project's active spritz tokens other than
+// keepID. It lists them instead of remembering IDs so tokens minted before a
+// restart are revoked as well.
+func (p *gitlabProvider) revokePreviousTokens(ctx context.Context, adminToken, repoPath string, keepID int64) error {
+	if keepID == 0 {
+		return nil
+	}
+	endpoint := p.accessTokensURL(repoPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?state=active&per_page=100", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", adminToken)
+	resp, err := p.r.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("gitlab token list failed: status=%d", resp.StatusCode)
+	}
+	var tokens []gitlabTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.Name != gitlabTokenName || token.ID == keepID {
+			continue
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("%s/%d", endpoint, token.ID), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("PRIVATE-TOKEN", adminToken)
+		resp, err := p.r.HTTPClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+			return fmt.Errorf("gitlab token revoke failed: id=%d status=%d", token.ID, resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+// staticProvider references a user-provided token secret, for example a
+// Bitbucket app password or any basic-auth PAT, as repo.auth.
+type staticProvider struct {
+	cfg providerConfig
+}
+
+func (p *staticProvider) Type() string { return providerTypeStatic }
+
+func (p *staticProvider) ScopeKey(host, repoPath string) string {
+	return host + "/" + repoPath
+}
+
+func (p *staticProvider) Credentials(context.Context, string, string, string) (providerCredentials, error) {
+	return providerCredentials{}, fmt.Errorf("static provider does not mint credentials")
+}
+
+func (p *staticProvider) RepoAuth() map[string]interface{} {
+	auth := map[string]interface{}{"secretName": p.cfg.SecretName}
+	if p.cfg.UsernameKey != "" {
+		auth["usernameKey"] = p.cfg.UsernameKey
+		auth["passwordKey"] = p.cfg.PasswordKey
+		return auth
+	}
+	auth["netrcKey"] = p.cfg.NetrcKey
+	return auth
+}
+
+func (r *spritzReconciler) providerSecretValue(ctx context.Context, namespace, name, key string) (string, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		return "", err
+	}
+	raw, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("provider token key %s not found in secret %s", key, name)
+	}
+	value := strings.TrimSpace(string(raw))
+	if value == "" {
+		return "", fmt.Errorf("provider token in secret %s is empty", name)
+	}
+	return value, nil
+}
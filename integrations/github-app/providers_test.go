@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLoadConfigProvidersWithoutGitHubApp(t *testing.T) {
+	t.Setenv("SPRITZ_GITHUB_APPS", "")
+	t.Setenv("SPRITZ_GITHUB_APP_ID", "")
+	t.Setenv("SPRITZ_GITHUB_APP_PRIVATE_KEY_NAMESPACE", "spritz-system")
+	t.Setenv("SPRITZ_REPO_AUTH_PROVIDERS", `[
+		{"type":"gitlab","apiUrl":"https://gitlab.example.com/api/v4","tokenSecret":"gitlab-token"},
+		{"type":"static","allowedHosts":["bitbucket.example.com"],"secretName":"bitbucket-pat","usernameKey":"username","passwordKey":"password"}
+	]`)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if len(cfg.Apps) != 0 || len(cfg.Providers) != 2 {
+		t.Fatalf("expected only providers, got apps=%d providers=%d", len(cfg.Apps), len(cfg.Providers))
+	}
+	gitlab := cfg.Providers[0]
+	if !containsFold(gitlab.AllowedHosts, "gitlab.example.com") || gitlab.TokenKey != "token" || gitlab.TokenNamespace != "spritz-system" {
+		t.Fatalf("expected gitlab defaults to be filled, got %+v", gitlab)
+	}
+}
+
+func TestLoadConfigRejectsInvalidProvider(t *testing.T) {
+	t.Setenv("SPRITZ_GITHUB_APPS", "")
+	t.Setenv("SPRITZ_GITHUB_APP_ID", "")
+	for _, raw := range []string{
+		`[{"type":"svn","allowedHosts":["svn.example.com"]}]`,
+		`[{"type":"static","allowedHosts":["git.example.com"]}]`,
+		`[{"type":"static","allowedHosts":["git.example.com"],"secretName":"pat","usernameKey":"username"}]`,
+		`[{"type":"gitlab"}]`,
+	} {
+		t.Setenv("SPRITZ_REPO_AUTH_PROVIDERS", raw)
+		if _, err := loadConfig(); err == nil {
+			t.Fatalf("expected error for %s", raw)
+		}
+	}
+}
+
+func TestProviderForRepoRoutesByHost(t *testing.T) {
+	r := &spritzReconciler{Config: config{
+		Apps: []appConfig{{AppID: 1, InstallationID: 2, AllowedHosts: []string{"github.com"}}},
+		Providers: []providerConfig{
+			{Type: providerTypeGitLab, AllowedHosts: []string{"gitlab.example.com"}},
+			{Type: providerTypeStatic, AllowedHosts: []string{"bitbucket.example.com"}, SecretName: "bitbucket-pat", NetrcKey: "netrc"},
+		},
+	}}
+	cases := map[string]string{
+		"github.com":            "github-app",
+		"gitlab.example.com":    providerTypeGitLab,
+		"bitbucket.example.com": providerTypeStatic,
+	}
+	for host, want := range cases {
+		provider, ok := r.providerForRepo(host, "example-org/app")
+		if !ok || provider.Type() != want {
+			t.Fatalf("expected %s to route to %s, got %v", host, want, provider)
+		}
+	}
+	if _, ok := r.providerForRepo("git.example.net", "example-org/app"); ok {
+		t.Fatal("expected unknown host to be rejected")
+	}
+
+	provider, _ := r.providerForRepo("bitbucket.example.com", "example-org/app")
+	passthrough, ok := provider.(passthroughProvider)
+	if !ok {
+		t.Fatal("expected static provider to pass through its secret")
+	}
+	auth := passthrough.RepoAuth()
+	if auth["secretName"] != "bitbucket-pat" || auth["netrcKey"] != "netrc" {
+		t.Fatalf("unexpected static repo auth %v", auth)
+	}
+}
+
+func newGitLabTestReconciler(t *testing.T, handler httpClientFunc) *spritzReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add scheme: %v", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "gitlab-token", Namespace: "spritz-system"},
+		Data:       map[string][]byte{"token": []byte("glpat-example")},
+	}
+	return &spritzReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(),
+		Scheme: scheme,
+		Config: config{Providers: []providerConfig{{
+			Type:           providerTypeGitLab,
+			APIURL:         "https://gitlab.example.com/api/v4",
+			AllowedHosts:   []string{"gitlab.example.com"},
+			TokenSecret:    "gitlab-token",
+			TokenKey:       "token",
+			TokenNamespace: "spritz-system",
+		}}},
+		HTTPClient: handler,
+		Tokens:     newInstallationTokenCache(),
+	}
+}
+
+func TestGitLabProviderMintsProjectAccessToken(t *testing.T) {
+	var requests int
+	r := newGitLabTestReconciler(t, func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`[]`))}, nil
+		}
+		requests++
+		if req.URL.EscapedPath() != "/api/v4/projects/example-org%2Fapp/access_tokens" {
+			t.Fatalf("unexpected path %s", req.URL.EscapedPath())
+		}
+		if req.Header.Get("PRIVATE-TOKEN") != "glpat-example" {
+			t.Fatal("expected the configured gitlab token")
+		}
+		var payload map[string]any
+		_ = json.NewDecoder(req.Body).Decode(&payload)
+		if scopes, _ := payload["scopes"].([]any); len(scopes) != 1 || scopes[0] != "read_repository" {
+			t.Fatalf("unexpected scopes %v", payload["scopes"])
+		}
+		body := `{"id":7,"name":"spritz-repo-auth","token":"glpat-minted","expires_at":"2030-01-02"}`
+		return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})
+
+	provider, ok := r.providerForRepo("gitlab.example.com", "example-org/app")
+	if !ok {
+		t.Fatal("expected gitlab provider")
+	}
+	scopeKey := provider.ScopeKey("gitlab.example.com", "example-org/app")
+	for i := 0; i < 2; i++ {
+		creds, err := provider.Credentials(context.Background(), "gitlab.example.com", "example-org/app", scopeKey)
+		if err != nil {
+			t.Fatalf("credentials: %v", err)
+		}
+		if creds.login != gitlabLogin || creds.token != "glpat-minted" || creds.expiry == nil {
+			t.Fatalf("unexpected credentials %+v", creds)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("expected cached gitlab token to be reused, got %d requests", requests)
+	}
+}
+
+func TestGitLabProviderRevokesPreviousTokens(t *testing.T) {
+	var revoked []string
+	r := newGitLabTestReconciler(t, func(req *http.Request) (*http.Response, error) {
+		switch req.Method {
+		case http.MethodPost:
+			body := `{"id":7,"name":"spritz-repo-auth","token":"glpat-minted","expires_at":"2030-01-02"}`
+			return &http.Response{StatusCode: http.StatusCreated, Body: io.NopCloser(strings.NewReader(body))}, nil
+		case http.MethodGet:
+			if req.URL.Query().Get("state") != "active" {
+				t.Fatalf("expected only active tokens to be listed, got %s", req.URL.RawQuery)
+			}
+			body := `[{"id":5,"name":"spritz-repo-auth"},{"id":6,"name":"ci-deploy"},{"id":7,"name":"spritz-repo-auth"}]`
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+		case http.MethodDelete:
+			revoked = append(revoked, req.URL.EscapedPath())
+			return &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}
+		t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		return nil, nil
+	})
+	provider, _ := r.providerForRepo("gitlab.example.com", "example-org/app")
+	if _, err := provider.Credentials(context.Background(), "gitlab.example.com", "example-org/app", "gitlab.example.com/example-org/app"); err != nil {
+		t.Fatalf("credentials: %v", err)
+	}
+	if len(revoked) != 1 || revoked[0] != "/api/v4/projects/example-org%2Fapp/access_tokens/5" {
+		t.Fatalf("expected only the previous spritz token to be revoked, got %v", revoked)
+	}
+}
+
+func TestGitLabProviderReportsMissingAccess(t *testing.T) {
+	r := newGitLabTestReconciler(t, func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusForbidden, Body: io.NopCloser(strings.NewReader(`{"message":"403 Forbidden"}`))}, nil
+	})
+	provider, _ := r.providerForRepo("gitlab.example.com", "example-org/app")
+	_, err := provider.Credentials(context.Background(), "gitlab.example.com", "example-org/app", "gitlab.example.com/example-org/app")
+	var accessErr *repoAccessError
+	if !errors.As(err, &accessErr) {
+		t.Fatalf("expected repo access error, got %v", err)
+	}
+}
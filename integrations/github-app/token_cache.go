@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"spritz.sh/operator/tracing"
+)
+
+// installationTokenCache keeps minted installation tokens in memory so repeated
+// reconciles, and repos covered by the same token scope, reuse one token until
+// it is within tokenRefreshLead of expiring. Reconciles can run concurrently,
+// so access is guarded by a mutex.
+type installationTokenCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedInstallationToken
+	now     func() time.Time
+}
+
+type cachedInstallationToken struct {
+	token  string
+	expiry time.Time
+}
+
+func newInstallationTokenCache() *installationTokenCache {
+	return &installationTokenCache{
+		entries: map[string]cachedInstallationToken{},
+		now:     time.Now,
+	}
+}
+
+func (c *installationTokenCache) get(key string) (string, *time.Time, bool) {
+	if c == nil {
+		return "", nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", nil, false
+	}
+	if !c.now().Before(entry.expiry.Add(-tokenRefreshLead)) {
+		delete(c.entries, key)
+		return "", nil, false
+	}
+	expiry := entry.expiry
+	return entry.token, &expiry, true
+}
+
+// put stores a token. Tokens without a reported expiry are not cached because
+// there is no safe point to stop reusing them.
+func (c *installationTokenCache) put(key, token string, expiry *time.Time) {
+	if c == nil || expiry == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for existingKey, entry := range c.entries {
+		if !now.Before(entry.expiry) {
+			delete(c.entries, existingKey)
+		}
+	}
+	c.entries[key] = cachedInstallationToken{token: token, expiry: *expiry}
+}
+
+// installationToken returns a cached token for the repo's token scope or mints
+// a new one.
+func (r *spritzReconciler) installationToken(ctx context.Context, app appConfig, repoPath, scopeKey string) (_ string, _ *time.Time, err error) {
+	ctx, span := tracer.Start(ctx, "github.installationToken", trace.WithAttributes(
+		attribute.Int64("github.installation_id", app.InstallationID),
+		attribute.String("github.repo", repoPath),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	cacheKey := fmt.Sprintf("%d:%s", app.InstallationID, scopeKey)
+	if token, expiry, ok := r.Tokens.get(cacheKey); ok {
+		span.SetAttributes(attribute.Bool("github.token_cached", true))
+		return token, expiry, nil
+	}
+	token, expiry, err := r.githubAppInstallationToken(ctx, app, repoPath)
+	if err != nil {
+		return "", nil, err
+	}
+	recordTokenMinted("github-app", expiry)
+	r.Tokens.put(cacheKey, token, expiry)
+	return token, expiry, nil
+}
@@ -0,0 +1,7 @@
+package main
+
+import "spritz.sh/operator/tracing"
+
+// tracer uses the setup shared with the operator and API; main installs the
+// provider with tracing.Setup.
+var tracer = tracing.Tracer("spritz.sh/integrations/github-app")
@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SSHDefaults are the cluster defaults that turn on SSH for new spritzes. They
+// are applied by the API create path and the operator's defaulting webhook.
+type SSHDefaults struct {
+	Enabled          bool
+	Mode             string
+	GatewayService   string
+	GatewayNamespace string
+	GatewayPort      int32
+	User             string
+}
+
+// SSHDefaultsFromEnv reads the SPRITZ_DEFAULT_SSH_* settings.
+func SSHDefaultsFromEnv() SSHDefaults {
+	defaults := SSHDefaults{
+		Mode:             "gateway",
+		GatewayService:   strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_SSH_GATEWAY_SERVICE")),
+		GatewayNamespace: strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_SSH_GATEWAY_NAMESPACE")),
+		GatewayPort:      22,
+		User:             "spritz",
+	}
+	if enabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_SSH_ENABLED"))); err == nil {
+		defaults.Enabled = enabled
+	}
+	if mode := strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_SSH_MODE")); mode != "" {
+		defaults.Mode = mode
+	}
+	if port, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_SSH_GATEWAY_PORT"))); err == nil && port > 0 {
+		defaults.GatewayPort = int32(port)
+	}
+	if user := strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_SSH_USER")); user != "" {
+		defaults.User = user
+	}
+	return defaults
+}
+
+// ApplySSHDefaults enables SSH with the cluster defaults unless the spec
+// turns it off. namespace is the gateway namespace fallback.
+func ApplySSHDefaults(spec *SpritzSpec, defaults SSHDefaults, namespace string) {
+	if !defaults.Enabled {
+		return
+	}
+	if spec.SSH != nil && !spec.SSH.Enabled {
+		return
+	}
+	if spec.Features != nil && spec.Features.SSH != nil && !*spec.Features.SSH {
+		return
+	}
+	if spec.SSH == nil {
+		spec.SSH = &SpritzSSH{Enabled: true}
+	} else if !spec.SSH.Enabled {
+		spec.SSH.Enabled = true
+	}
+	if spec.Features == nil {
+		spec.Features = &SpritzFeatures{}
+	}
+	if spec.Features.SSH == nil {
+		enabled := true
+		spec.Features.SSH = &enabled
+	}
+	if spec.SSH.Mode == "" {
+		spec.SSH.Mode = defaults.Mode
+	}
+	if spec.SSH.User == "" {
+		spec.SSH.User = defaults.User
+	}
+	if strings.EqualFold(spec.SSH.Mode, "gateway") {
+		if spec.SSH.GatewayService == "" {
+			spec.SSH.GatewayService = defaults.GatewayService
+		}
+		if spec.SSH.GatewayNamespace == "" {
+			spec.SSH.GatewayNamespace = defaults.GatewayNamespace
+			if spec.SSH.GatewayNamespace == "" {
+				spec.SSH.GatewayNamespace = namespace
+			}
+		}
+		if spec.SSH.GatewayPort == 0 {
+			spec.SSH.GatewayPort = defaults.GatewayPort
+		}
+	}
+}
+
+// IngressDefaults are the cluster defaults for spec.ingress. HostTemplate and
+// Path may contain {name}, {namespace} and {owner} placeholders, which
+// ExpandSpecTemplates expands once the spritz name is known.
+type IngressDefaults struct {
+	Mode               string
+	HostTemplate       string
+	Path               string
+	ClassName          string
+	GatewayName        string
+	GatewayNamespace   string
+	GatewaySectionName string
+}
+
+// IngressDefaultsFromEnv reads the SPRITZ_DEFAULT_INGRESS_* settings.
+func IngressDefaultsFromEnv() IngressDefaults {
+	return IngressDefaults{
+		Mode:               strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_MODE")),
+		HostTemplate:       strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_HOST_TEMPLATE")),
+		Path:               strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_PATH")),
+		ClassName:          strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_CLASS_NAME")),
+		GatewayName:        strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_GATEWAY_NAME")),
+		GatewayNamespace:   strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_GATEWAY_NAMESPACE")),
+		GatewaySectionName: strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_GATEWAY_SECTION_NAME")),
+	}
+}
+
+// Enabled reports whether any ingress default is configured.
+func (d IngressDefaults) Enabled() bool {
+	return d.Mode != "" || d.HostTemplate != "" || d.Path != "" || d.ClassName != "" ||
+		d.GatewayName != "" || d.GatewayNamespace != "" || d.GatewaySectionName != ""
+}
+
+// ApplyIngressDefaults fills unset ingress fields. Spritzes with the web
+// feature turned off and no ingress are left alone. The host and path
+// defaults are copied as templates.
+func ApplyIngressDefaults(spec *SpritzSpec, defaults IngressDefaults) {
+	if !defaults.Enabled() {
+		return
+	}
+	if spec.Ingress == nil && !IsWebEnabled(*spec) {
+		return
+	}
+
+	if spec.Ingress == nil {
+		spec.Ingress = &SpritzIngress{}
+	}
+
+	if spec.Ingress.Mode == "" && defaults.Mode != "" {
+		spec.Ingress.Mode = defaults.Mode
+	}
+	if spec.Ingress.Host == "" && defaults.HostTemplate != "" {
+		spec.Ingress.Host = defaults.HostTemplate
+	}
+	if spec.Ingress.Path == "" && defaults.Path != "" {
+		spec.Ingress.Path = defaults.Path
+	}
+	if spec.Ingress.ClassName == "" && defaults.ClassName != "" {
+		spec.Ingress.ClassName = defaults.ClassName
+	}
+	if spec.Ingress.GatewayName == "" && defaults.GatewayName != "" {
+		spec.Ingress.GatewayName = defaults.GatewayName
+	}
+	if spec.Ingress.GatewayNamespace == "" && defaults.GatewayNamespace != "" {
+		spec.Ingress.GatewayNamespace = defaults.GatewayNamespace
+	}
+	if spec.Ingress.GatewaySectionName == "" && defaults.GatewaySectionName != "" {
+		spec.Ingress.GatewaySectionName = defaults.GatewaySectionName
+	}
+}
@@ -0,0 +1,35 @@
+package v1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ValidateDNS checks the pod DNS settings requested by a spritz spec.
+func ValidateDNS(policy corev1.DNSPolicy, config *corev1.PodDNSConfig) error {
+	switch policy {
+	case "", corev1.DNSClusterFirst, corev1.DNSClusterFirstWithHostNet, corev1.DNSDefault:
+	case corev1.DNSNone:
+		if config == nil || len(config.Nameservers) == 0 {
+			return fmt.Errorf("spec.dnsConfig.nameservers is required when spec.dnsPolicy is None")
+		}
+	default:
+		return fmt.Errorf("unsupported spec.dnsPolicy: %s", policy)
+	}
+	if config == nil {
+		return nil
+	}
+	if len(config.Nameservers) > 3 {
+		return fmt.Errorf("spec.dnsConfig.nameservers supports at most 3 entries")
+	}
+	if len(config.Searches) > 32 {
+		return fmt.Errorf("spec.dnsConfig.searches supports at most 32 entries")
+	}
+	for _, option := range config.Options {
+		if option.Name == "" {
+			return fmt.Errorf("spec.dnsConfig.options entries require a name")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package v1
+
+import corev1 "k8s.io/api/core/v1"
+
+// RestrictedVolumeSource reports whether an extra volume uses a source other
+// than emptyDir, downwardAPI or inline CSI. The other sources can reach data
+// that already exists in the cluster, such as Secrets, claims or the node
+// filesystem, so they are reserved for admins.
+func RestrictedVolumeSource(volume corev1.Volume) bool {
+	source := volume.VolumeSource
+	source.EmptyDir = nil
+	source.DownwardAPI = nil
+	source.CSI = nil
+	return source != corev1.VolumeSource{}
+}
@@ -0,0 +1,22 @@
+package v1
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// imageReferencePattern mirrors the CRD pattern on spec.image so API callers
+// get the same answer before the object reaches the apiserver.
+var imageReferencePattern = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*(@sha256:[a-f0-9]{64}|:[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127})?$`)
+
+// ValidateImageReference checks that image is a valid container image
+// reference. Empty values are accepted so optional fields can share it.
+func ValidateImageReference(field, image string) error {
+	if image == "" {
+		return nil
+	}
+	if !imageReferencePattern.MatchString(image) {
+		return fmt.Errorf("%s is not a valid image reference: %s", field, image)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ValidateSeccompProfile checks a seccomp profile requested by a spritz spec.
+func ValidateSeccompProfile(profile *corev1.SeccompProfile) error {
+	if profile == nil {
+		return nil
+	}
+	localhost := ""
+	if profile.LocalhostProfile != nil {
+		localhost = strings.TrimSpace(*profile.LocalhostProfile)
+	}
+	switch profile.Type {
+	case corev1.SeccompProfileTypeRuntimeDefault, corev1.SeccompProfileTypeUnconfined:
+		if localhost != "" {
+			return fmt.Errorf("spec.seccompProfile.localhostProfile is only allowed when type is Localhost")
+		}
+	case corev1.SeccompProfileTypeLocalhost:
+		if localhost == "" {
+			return fmt.Errorf("spec.seccompProfile.localhostProfile is required when type is Localhost")
+		}
+	default:
+		return fmt.Errorf("unsupported spec.seccompProfile.type: %s", profile.Type)
+	}
+	return nil
+}
+
+// ValidateAppArmorProfile checks an AppArmor profile requested by a spritz spec.
+func ValidateAppArmorProfile(profile *corev1.AppArmorProfile) error {
+	if profile == nil {
+		return nil
+	}
+	localhost := ""
+	if profile.LocalhostProfile != nil {
+		localhost = strings.TrimSpace(*profile.LocalhostProfile)
+	}
+	switch profile.Type {
+	case corev1.AppArmorProfileTypeRuntimeDefault, corev1.AppArmorProfileTypeUnconfined:
+		if localhost != "" {
+			return fmt.Errorf("spec.appArmorProfile.localhostProfile is only allowed when type is Localhost")
+		}
+	case corev1.AppArmorProfileTypeLocalhost:
+		if localhost == "" {
+			return fmt.Errorf("spec.appArmorProfile.localhostProfile is required when type is Localhost")
+		}
+	default:
+		return fmt.Errorf("unsupported spec.appArmorProfile.type: %s", profile.Type)
+	}
+	return nil
+}
@@ -22,6 +22,10 @@ const (
 type SpritzSpec struct {
 	// +kubebuilder:validation:Pattern="^[a-z0-9]+((\\.|_|__|-+)[a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+((\\.|_|__|-+)[a-z0-9]+)*)*(@sha256:[a-f0-9]{64}|:[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127})?$"
 	Image string `json:"image"`
+	// ImagePullPolicy sets the pull policy of the workspace container. When
+	// unset it is IfNotPresent for digest-pinned images and Always otherwise.
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
 	// +kubebuilder:validation:MaxLength=63
 	// +kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
 	ServiceAccountName string               `json:"serviceAccountName,omitempty"`
@@ -31,6 +35,25 @@ type SpritzSpec struct {
 	Env                []corev1.EnvVar      `json:"env,omitempty"`
 	// SharedMounts configures per-spritz shared directories.
 	SharedMounts []sharedmounts.MountSpec `json:"sharedMounts,omitempty"`
+	// ExtraVolumes are added to the workspace pod as is, for sources the spec
+	// does not cover such as CSI ephemeral or downward API volumes. Sources
+	// other than emptyDir, downwardAPI and csi need the admin-only
+	// spritz.sh/extra-volumes-unrestricted annotation, and hostPath also needs
+	// the operator to allow it.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	ExtraVolumes []corev1.Volume `json:"extraVolumes,omitempty"`
+	// ExtraVolumeMounts mount ExtraVolumes into the workspace container. Paths
+	// must not overlap the operator-managed mounts.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	ExtraVolumeMounts []corev1.VolumeMount `json:"extraVolumeMounts,omitempty"`
+	// InitScript is a shell snippet run with `sh -ec` in an init container
+	// after repo checkout and before the workload starts. It runs once per pod
+	// start, so it must be idempotent. Use it for bootstrap such as downloading
+	// models or warming caches.
+	// +kubebuilder:validation:MaxLength=16384
+	InitScript string `json:"initScript,omitempty"`
 	// +kubebuilder:validation:Pattern="^([0-9]+h)?([0-9]+m)?([0-9]+s)?$"
 	TTL string `json:"ttl,omitempty"`
 	// +kubebuilder:validation:Pattern="^([0-9]+h)?([0-9]+m)?([0-9]+s)?$"
@@ -44,8 +67,44 @@ type SpritzSpec struct {
 	Annotations      map[string]string   `json:"annotations,omitempty"`
 	Features         *SpritzFeatures     `json:"features,omitempty"`
 	SSH              *SpritzSSH          `json:"ssh,omitempty"`
+	Terminal         *SpritzTerminal     `json:"terminal,omitempty"`
 	Ports            []SpritzPort        `json:"ports,omitempty"`
 	Ingress          *SpritzIngress      `json:"ingress,omitempty"`
+	// DNSPolicy overrides the workload pod DNS policy.
+	// +kubebuilder:validation:Enum=ClusterFirst;ClusterFirstWithHostNet;Default;None
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+	// DNSConfig adds nameservers, search domains, or resolver options to the workload pod.
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+	// TerminationGracePeriodSeconds gives the workload more time to shut down cleanly.
+	// +kubebuilder:validation:Minimum=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// TopologySpreadConstraints spread the workload pods across zones or
+	// nodes. A constraint without a labelSelector selects the pods of this
+	// spritz. Overrides the operator default.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// CABundleSecret mounts a PEM CA bundle into the workspace and repo init
+	// containers and points git, Node.js and OpenSSL at it.
+	CABundleSecret *SpritzCABundleSecret `json:"caBundleSecret,omitempty"`
+	// Proxy sets the egress proxy env of the workspace, repo init and shared
+	// mount syncer containers. Empty fields fall back to the operator default.
+	Proxy *SpritzProxy `json:"proxy,omitempty"`
+	// SecurityHardening tightens the security context of every container in
+	// the workspace pod.
+	SecurityHardening *SpritzSecurityHardening `json:"securityHardening,omitempty"`
+	// SeccompProfile is set on the pod security context. Overrides the
+	// operator default.
+	SeccompProfile *corev1.SeccompProfile `json:"seccompProfile,omitempty"`
+	// AppArmorProfile is set on the pod security context. Overrides the
+	// operator default.
+	AppArmorProfile *corev1.AppArmorProfile `json:"appArmorProfile,omitempty"`
+	// RevisionHistoryLimit is the number of old ReplicaSets the Deployment
+	// keeps. Overrides the operator default.
+	// +kubebuilder:validation:Minimum=0
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+	// ProgressDeadlineSeconds is how long a rollout may make no progress
+	// before the spritz reports an Error. Overrides the operator default.
+	// +kubebuilder:validation:Minimum=1
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
 }
 
 // SpritzRuntimePolicy stores deployment-resolved infrastructure policy profile references.
@@ -67,14 +126,41 @@ type SpritzRuntimePolicy struct {
 // SpritzRepo describes the repository to clone inside the workload.
 type SpritzRepo struct {
 	// +kubebuilder:validation:Format=uri
-	URL      string `json:"url"`
-	Dir      string `json:"dir,omitempty"`
-	Branch   string `json:"branch,omitempty"`
+	URL    string `json:"url"`
+	Dir    string `json:"dir,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	// Revision is a branch, tag, commit SHA, or review ref such as pull/123/head
+	// or merge-requests/45/head.
 	Revision string `json:"revision,omitempty"`
 	// +kubebuilder:validation:Minimum=1
 	Depth      int             `json:"depth,omitempty"`
 	Submodules bool            `json:"submodules,omitempty"`
 	Auth       *SpritzRepoAuth `json:"auth,omitempty"`
+	// PostClone lists shell commands run with `sh -c` inside the repo dir after checkout.
+	// +kubebuilder:validation:items:MinLength=1
+	PostClone []string `json:"postClone,omitempty"`
+	// SkipIfExists leaves an existing checkout untouched on restart instead of
+	// fetching and checking out the requested revision.
+	SkipIfExists bool `json:"skipIfExists,omitempty"`
+	// LFS resolves Git LFS pointers after checkout with `git lfs pull`. The init
+	// image must include git-lfs; without it the step is skipped with a warning.
+	LFS bool `json:"lfs,omitempty"`
+	// InitImage overrides the operator's repo init image for this repo, e.g. an
+	// image that bundles git-lfs. It must provide sh and git.
+	// +kubebuilder:validation:Pattern="^[a-z0-9]+((\\.|_|__|-+)[a-z0-9]+)*(:[0-9]+)?(/[a-z0-9]+((\\.|_|__|-+)[a-z0-9]+)*)*(@sha256:[a-f0-9]{64}|:[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127})?$"
+	InitImage string `json:"initImage,omitempty"`
+	// Worktrees are added with `git worktree add` after the primary checkout, so
+	// several branches can be checked out side by side from one clone.
+	Worktrees []SpritzRepoWorktree `json:"worktrees,omitempty"`
+}
+
+// SpritzRepoWorktree checks out a branch of the repo into its own directory.
+type SpritzRepoWorktree struct {
+	// +kubebuilder:validation:MinLength=1
+	Branch string `json:"branch"`
+	// Dir follows the same rules as repo.dir and must differ from it.
+	// +kubebuilder:validation:MinLength=1
+	Dir string `json:"dir"`
 }
 
 // SpritzRepoAuth describes how to authenticate git clone operations.
@@ -83,12 +169,38 @@ type SpritzRepoAuth struct {
 	SecretName string `json:"secretName"`
 	// NetrcKey points to a Secret key containing a full .netrc file.
 	NetrcKey string `json:"netrcKey,omitempty"`
+	// CredentialsKey points to a Secret key containing a git credential-store file.
+	CredentialsKey string `json:"credentialsKey,omitempty"`
 	// UsernameKey points to a Secret key containing the username to use.
 	UsernameKey string `json:"usernameKey,omitempty"`
 	// PasswordKey points to a Secret key containing the password/token to use.
 	PasswordKey string `json:"passwordKey,omitempty"`
 }
 
+// SpritzCABundleSecret references a Secret key holding PEM CA certificates.
+type SpritzCABundleSecret struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key defaults to ca.crt.
+	Key string `json:"key,omitempty"`
+}
+
+// SpritzProxy describes an HTTP egress proxy.
+type SpritzProxy struct {
+	HTTPProxy  string `json:"httpProxy,omitempty"`
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	// NoProxy is a comma-separated list of hosts, domains and CIDRs that
+	// bypass the proxy.
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// SpritzSecurityHardening holds opt-in container hardening settings.
+type SpritzSecurityHardening struct {
+	// ReadOnlyRootFilesystem mounts every container root filesystem read-only.
+	// /workspace, the home directory and /tmp stay writable.
+	ReadOnlyRootFilesystem bool `json:"readOnlyRootFilesystem,omitempty"`
+}
+
 // SpritzOwner identifies the creator of a spritz.
 type SpritzOwner struct {
 	// +kubebuilder:validation:MinLength=1
@@ -116,6 +228,10 @@ type SpritzAgentProfile struct {
 
 // SpritzFeatures toggles optional capabilities.
 type SpritzFeatures struct {
+	// Home mounts the default home volume at /home/dev. Disable it for images
+	// that manage their own home directory.
+	// +kubebuilder:default=true
+	Home *bool `json:"home,omitempty"`
 	// +kubebuilder:default=false
 	SSH *bool `json:"ssh,omitempty"`
 	// +kubebuilder:default=true
@@ -139,6 +255,18 @@ type SpritzSSH struct {
 	// +kubebuilder:validation:Maximum=65535
 	GatewayPort int32  `json:"gatewayPort,omitempty"`
 	User        string `json:"user,omitempty"`
+	// AdditionalPrincipals lists owner ids, besides the owner, that may mint
+	// SSH credentials for this spritz. Only admins may set it.
+	AdditionalPrincipals []string `json:"additionalPrincipals,omitempty"`
+}
+
+// SpritzTerminal configures the shell opened by the web terminal and SSH gateway.
+type SpritzTerminal struct {
+	// Command replaces the default terminal and SSH command for this spritz,
+	// for example ["sh", "-l"] or ["python3"].
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:MinLength=1
+	Command []string `json:"command,omitempty"`
 }
 
 // SpritzPort exposes a container port via a Service.
@@ -153,6 +281,9 @@ type SpritzPort struct {
 	ServicePort int32 `json:"servicePort,omitempty"`
 	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
 	Protocol corev1.Protocol `json:"protocol,omitempty"`
+	// TargetContainer names the pod container that listens on ContainerPort.
+	// Empty means the main spritz container.
+	TargetContainer string `json:"targetContainer,omitempty"`
 }
 
 // SpritzIngress configures optional HTTP routing.
@@ -174,7 +305,7 @@ type SpritzIngress struct {
 
 // SpritzStatus defines the observed state of Spritz.
 type SpritzStatus struct {
-	// +kubebuilder:validation:Enum=Provisioning;Ready;Expiring;Expired;Terminating;Error
+	// +kubebuilder:validation:Enum=Provisioning;Ready;Expiring;Expired;Terminating;Error;Paused
 	Phase string `json:"phase,omitempty"`
 	// +kubebuilder:validation:Format=uri
 	URL             string                    `json:"url,omitempty"`
@@ -188,7 +319,26 @@ type SpritzStatus struct {
 	ExpiresAt       *metav1.Time              `json:"expiresAt,omitempty"`
 	LifecycleReason string                    `json:"lifecycleReason,omitempty"`
 	ReadyAt         *metav1.Time              `json:"readyAt,omitempty"`
-	Conditions      []metav1.Condition        `json:"conditions,omitempty"`
+	// UnavailableSince is when a Ready spritz lost its last available
+	// replica. It is set while the ready debounce window is running.
+	UnavailableSince *metav1.Time `json:"unavailableSince,omitempty"`
+	// Repos reports the checkout each repo init container resolved, in spec order.
+	Repos []SpritzRepoStatus `json:"repos,omitempty"`
+	// PodNames lists the live workload pods, newest first.
+	PodNames []string `json:"podNames,omitempty"`
+	// ObservedGeneration is the metadata.generation the rest of the status
+	// reflects. Status written before it matches is about an older spec.
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// SpritzRepoStatus describes the revision a repo init container checked out.
+type SpritzRepoStatus struct {
+	Dir string `json:"dir,omitempty"`
+	// Branch is empty when the checkout is a detached HEAD.
+	Branch   string `json:"branch,omitempty"`
+	Commit   string `json:"commit,omitempty"`
+	Detached bool   `json:"detached,omitempty"`
 }
 
 // SpritzAgentProfileStatus stores the synced UI-facing profile for an instance.
@@ -464,28 +614,12 @@ func (in *SpritzSpec) DeepCopyInto(out *SpritzSpec) {
 	}
 	if in.Repo != nil {
 		out.Repo = &SpritzRepo{}
-		*out.Repo = *in.Repo
-		if in.Repo.Auth != nil {
-			out.Repo.Auth = &SpritzRepoAuth{}
-			*out.Repo.Auth = *in.Repo.Auth
-		}
+		in.Repo.DeepCopyInto(out.Repo)
 	}
 	if in.Repos != nil {
 		out.Repos = make([]SpritzRepo, len(in.Repos))
 		for i := range in.Repos {
-			repo := in.Repos[i]
-			out.Repos[i] = SpritzRepo{
-				URL:        repo.URL,
-				Dir:        repo.Dir,
-				Branch:     repo.Branch,
-				Revision:   repo.Revision,
-				Depth:      repo.Depth,
-				Submodules: repo.Submodules,
-			}
-			if repo.Auth != nil {
-				out.Repos[i].Auth = &SpritzRepoAuth{}
-				*out.Repos[i].Auth = *repo.Auth
-			}
+			in.Repos[i].DeepCopyInto(&out.Repos[i])
 		}
 	}
 	if in.Env != nil {
@@ -497,6 +631,23 @@ func (in *SpritzSpec) DeepCopyInto(out *SpritzSpec) {
 	if in.SharedMounts != nil {
 		out.SharedMounts = make([]sharedmounts.MountSpec, len(in.SharedMounts))
 		copy(out.SharedMounts, in.SharedMounts)
+		for i := range in.SharedMounts {
+			if in.SharedMounts[i].Excludes != nil {
+				out.SharedMounts[i].Excludes = append([]string(nil), in.SharedMounts[i].Excludes...)
+			}
+		}
+	}
+	if in.ExtraVolumes != nil {
+		out.ExtraVolumes = make([]corev1.Volume, len(in.ExtraVolumes))
+		for i := range in.ExtraVolumes {
+			in.ExtraVolumes[i].DeepCopyInto(&out.ExtraVolumes[i])
+		}
+	}
+	if in.ExtraVolumeMounts != nil {
+		out.ExtraVolumeMounts = make([]corev1.VolumeMount, len(in.ExtraVolumeMounts))
+		for i := range in.ExtraVolumeMounts {
+			in.ExtraVolumeMounts[i].DeepCopyInto(&out.ExtraVolumeMounts[i])
+		}
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.AgentRef != nil {
@@ -521,6 +672,10 @@ func (in *SpritzSpec) DeepCopyInto(out *SpritzSpec) {
 	}
 	if in.Features != nil {
 		out.Features = &SpritzFeatures{}
+		if in.Features.Home != nil {
+			home := *in.Features.Home
+			out.Features.Home = &home
+		}
 		if in.Features.SSH != nil {
 			ssh := *in.Features.SSH
 			out.Features.SSH = &ssh
@@ -533,11 +688,61 @@ func (in *SpritzSpec) DeepCopyInto(out *SpritzSpec) {
 	if in.SSH != nil {
 		out.SSH = &SpritzSSH{}
 		*out.SSH = *in.SSH
+		if in.SSH.AdditionalPrincipals != nil {
+			out.SSH.AdditionalPrincipals = make([]string, len(in.SSH.AdditionalPrincipals))
+			copy(out.SSH.AdditionalPrincipals, in.SSH.AdditionalPrincipals)
+		}
+	}
+	if in.Terminal != nil {
+		out.Terminal = &SpritzTerminal{}
+		if in.Terminal.Command != nil {
+			out.Terminal.Command = make([]string, len(in.Terminal.Command))
+			copy(out.Terminal.Command, in.Terminal.Command)
+		}
 	}
 	if in.Ports != nil {
 		out.Ports = make([]SpritzPort, len(in.Ports))
 		copy(out.Ports, in.Ports)
 	}
+	if in.DNSConfig != nil {
+		out.DNSConfig = in.DNSConfig.DeepCopy()
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		grace := *in.TerminationGracePeriodSeconds
+		out.TerminationGracePeriodSeconds = &grace
+	}
+	if in.TopologySpreadConstraints != nil {
+		out.TopologySpreadConstraints = make([]corev1.TopologySpreadConstraint, len(in.TopologySpreadConstraints))
+		for i := range in.TopologySpreadConstraints {
+			in.TopologySpreadConstraints[i].DeepCopyInto(&out.TopologySpreadConstraints[i])
+		}
+	}
+	if in.CABundleSecret != nil {
+		value := *in.CABundleSecret
+		out.CABundleSecret = &value
+	}
+	if in.Proxy != nil {
+		value := *in.Proxy
+		out.Proxy = &value
+	}
+	if in.SecurityHardening != nil {
+		value := *in.SecurityHardening
+		out.SecurityHardening = &value
+	}
+	if in.SeccompProfile != nil {
+		out.SeccompProfile = in.SeccompProfile.DeepCopy()
+	}
+	if in.AppArmorProfile != nil {
+		out.AppArmorProfile = in.AppArmorProfile.DeepCopy()
+	}
+	if in.RevisionHistoryLimit != nil {
+		value := *in.RevisionHistoryLimit
+		out.RevisionHistoryLimit = &value
+	}
+	if in.ProgressDeadlineSeconds != nil {
+		value := *in.ProgressDeadlineSeconds
+		out.ProgressDeadlineSeconds = &value
+	}
 	if in.Ingress != nil {
 		out.Ingress = &SpritzIngress{}
 		out.Ingress.Mode = in.Ingress.Mode
@@ -556,6 +761,22 @@ func (in *SpritzSpec) DeepCopyInto(out *SpritzSpec) {
 	}
 }
 
+func (in *SpritzRepo) DeepCopyInto(out *SpritzRepo) {
+	*out = *in
+	if in.Auth != nil {
+		out.Auth = &SpritzRepoAuth{}
+		*out.Auth = *in.Auth
+	}
+	if in.PostClone != nil {
+		out.PostClone = make([]string, len(in.PostClone))
+		copy(out.PostClone, in.PostClone)
+	}
+	if in.Worktrees != nil {
+		out.Worktrees = make([]SpritzRepoWorktree, len(in.Worktrees))
+		copy(out.Worktrees, in.Worktrees)
+	}
+}
+
 func (in *SpritzStatus) DeepCopyInto(out *SpritzStatus) {
 	*out = *in
 	if in.Profile != nil {
@@ -588,6 +809,16 @@ func (in *SpritzStatus) DeepCopyInto(out *SpritzStatus) {
 	if in.ReadyAt != nil {
 		out.ReadyAt = in.ReadyAt.DeepCopy()
 	}
+	if in.UnavailableSince != nil {
+		out.UnavailableSince = in.UnavailableSince.DeepCopy()
+	}
+	if in.Repos != nil {
+		out.Repos = make([]SpritzRepoStatus, len(in.Repos))
+		copy(out.Repos, in.Repos)
+	}
+	if in.PodNames != nil {
+		out.PodNames = append([]string(nil), in.PodNames...)
+	}
 	if in.Conditions != nil {
 		out.Conditions = make([]metav1.Condition, len(in.Conditions))
 		for i := range in.Conditions {
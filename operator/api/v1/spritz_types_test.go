@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -54,3 +55,79 @@ func TestSpritzStatusDeepCopyIntoCopiesLifecycleTimestamps(t *testing.T) {
 		t.Fatalf("expected original profile sync time to stay unchanged, got %#v", original.Profile.LastSyncedAt)
 	}
 }
+
+func TestSpritzSpecDeepCopyIntoCopiesDNSConfig(t *testing.T) {
+	original := &SpritzSpec{
+		DNSPolicy: corev1.DNSNone,
+		DNSConfig: &corev1.PodDNSConfig{
+			Nameservers: []string{"10.0.0.10"},
+			Searches:    []string{"svc.example.com"},
+		},
+	}
+
+	var copied SpritzSpec
+	original.DeepCopyInto(&copied)
+	copied.DNSConfig.Nameservers[0] = "10.0.0.11"
+
+	if original.DNSConfig.Nameservers[0] != "10.0.0.10" {
+		t.Fatalf("expected original nameservers to stay unchanged, got %#v", original.DNSConfig.Nameservers)
+	}
+}
+
+func TestValidateDNS(t *testing.T) {
+	cases := []struct {
+		name    string
+		policy  corev1.DNSPolicy
+		config  *corev1.PodDNSConfig
+		wantErr bool
+	}{
+		{"empty ok", "", nil, false},
+		{"cluster first ok", corev1.DNSClusterFirst, nil, false},
+		{"search domains ok", "", &corev1.PodDNSConfig{Searches: []string{"corp.example.com"}}, false},
+		{"none with nameserver ok", corev1.DNSNone, &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.10"}}, false},
+		{"none without config invalid", corev1.DNSNone, nil, true},
+		{"unknown policy invalid", corev1.DNSPolicy("Custom"), nil, true},
+		{"too many nameservers invalid", "", &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}}, true},
+		{"unnamed option invalid", "", &corev1.PodDNSConfig{Options: []corev1.PodDNSConfigOption{{}}}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateDNS(tc.policy, tc.config)
+			if tc.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateImageReference(t *testing.T) {
+	cases := []struct {
+		name    string
+		image   string
+		wantErr bool
+	}{
+		{"empty ok", "", false},
+		{"tag ok", "example.com/git-lfs:3.4", false},
+		{"registry port ok", "registry.example.com:5000/tools/git-lfs", false},
+		{"digest ok", "example.com/git-lfs@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", false},
+		{"uppercase invalid", "Example.com/git-lfs", true},
+		{"whitespace invalid", "example.com/git lfs", true},
+		{"scheme invalid", "https://example.com/git-lfs", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateImageReference("spec.repo.initImage", tc.image)
+			if tc.wantErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// templateTokenPattern matches `{token}` placeholders. Matches that touch
+// another brace or follow `$` are left alone so shell `${VAR}` and `{{ }}`
+// templates pass through unchanged.
+var templateTokenPattern = regexp.MustCompile(`\{([a-z][A-Za-z]*)\}`)
+
+// TemplateVars are the values spec templates can reference as {name},
+// {namespace} and {owner}.
+type TemplateVars struct {
+	Name      string
+	Namespace string
+	Owner     string
+}
+
+// TemplateVarsFor returns the template values of an existing spritz.
+func TemplateVarsFor(spritz *Spritz) TemplateVars {
+	return TemplateVars{Name: spritz.Name, Namespace: spritz.Namespace, Owner: spritz.Spec.Owner.ID}
+}
+
+// Lookup returns the value of token, or false when it is not a known token.
+func (v TemplateVars) Lookup(token string) (string, bool) {
+	switch token {
+	case "name":
+		return v.Name, true
+	case "namespace":
+		return v.Namespace, true
+	case "owner":
+		return v.Owner, true
+	}
+	return "", false
+}
+
+// hostVars returns vars with the owner reduced to a DNS label, since owner IDs
+// such as email addresses are not valid in a host name.
+func (v TemplateVars) hostVars() (TemplateVars, error) {
+	owner := sanitizeBindingNameToken(v.Owner)
+	if len(owner) > 63 {
+		owner = strings.TrimRight(owner[:63], "-")
+	}
+	if owner == "" && v.Owner != "" {
+		return v, fmt.Errorf("owner %q has no characters usable in a host name", v.Owner)
+	}
+	v.Owner = owner
+	return v, nil
+}
+
+// ExpandTemplate replaces the `{token}` placeholders in value that lookup
+// knows. It expands in a single pass, so substituted values are never
+// expanded again, and leaves any other token as written.
+func ExpandTemplate(value string, lookup func(token string) (string, bool)) string {
+	matches := templateTokenPattern.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return value
+	}
+	var out strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if start > 0 && (value[start-1] == '{' || value[start-1] == '$') {
+			continue
+		}
+		if end < len(value) && value[end] == '}' {
+			continue
+		}
+		replacement, ok := lookup(value[match[2]:match[3]])
+		if !ok {
+			continue
+		}
+		out.WriteString(value[last:start])
+		out.WriteString(replacement)
+		last = end
+	}
+	out.WriteString(value[last:])
+	return out.String()
+}
+
+// ExpandSpecTemplates expands the spec fields that accept templates: ingress
+// host and path, and literal env values. {owner} is reduced to a DNS label in
+// the host. The API create path, the binding controller and the defaulting
+// webhook all call it after ApplyIngressDefaults, so defaulted and explicit
+// templates expand the same way.
+func ExpandSpecTemplates(spec *SpritzSpec, vars TemplateVars) error {
+	if spec.Ingress != nil {
+		hostVars := vars
+		if strings.Contains(spec.Ingress.Host, "{owner}") {
+			var err error
+			if hostVars, err = vars.hostVars(); err != nil {
+				return fmt.Errorf("spec.ingress.host: %w", err)
+			}
+		}
+		spec.Ingress.Host = ExpandTemplate(spec.Ingress.Host, hostVars.Lookup)
+		spec.Ingress.Path = ExpandTemplate(spec.Ingress.Path, vars.Lookup)
+	}
+	for i := range spec.Env {
+		if spec.Env[i].ValueFrom != nil {
+			continue
+		}
+		spec.Env[i].Value = ExpandTemplate(spec.Env[i].Value, vars.Lookup)
+	}
+	return nil
+}
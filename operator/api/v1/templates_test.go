@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	vars := TemplateVars{Name: "tidy-otter", Namespace: "spritz-test", Owner: "user-{name}"}
+	cases := []struct {
+		value string
+		want  string
+	}{
+		{value: "{name}.{namespace}.example.com", want: "tidy-otter.spritz-test.example.com"},
+		{value: "/i/{name}", want: "/i/tidy-otter"},
+		// Substituted values are not expanded again.
+		{value: "{owner}", want: "user-{name}"},
+		{value: "${name} {{name}} {{ .Values.name }}", want: "${name} {{name}} {{ .Values.name }}"},
+		{value: `{"mode":"dev"} {NAME}`, want: `{"mode":"dev"} {NAME}`},
+		// Unknown tokens are left as written.
+		{value: "{name}-{foo}", want: "tidy-otter-{foo}"},
+	}
+	for _, tc := range cases {
+		if got := ExpandTemplate(tc.value, vars.Lookup); got != tc.want {
+			t.Fatalf("ExpandTemplate(%q) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestExpandSpecTemplatesSanitizesOwnerInHost(t *testing.T) {
+	spec := SpritzSpec{
+		Ingress: &SpritzIngress{Host: "{owner}-{name}.example.com", Path: "/u/{owner}"},
+	}
+	vars := TemplateVars{Name: "tidy-otter", Namespace: "spritz-test", Owner: "Dev.User@example.com"}
+	if err := ExpandSpecTemplates(&spec, vars); err != nil {
+		t.Fatalf("ExpandSpecTemplates returned error: %v", err)
+	}
+	if spec.Ingress.Host != "dev-user-example-com-tidy-otter.example.com" {
+		t.Fatalf("expected owner reduced to a DNS label in the host, got %q", spec.Ingress.Host)
+	}
+	if spec.Ingress.Path != "/u/Dev.User@example.com" {
+		t.Fatalf("expected path to keep the owner ID, got %q", spec.Ingress.Path)
+	}
+
+	spec = SpritzSpec{Ingress: &SpritzIngress{Host: "{owner}.example.com"}}
+	if err := ExpandSpecTemplates(&spec, TemplateVars{Owner: "@@"}); err == nil || !strings.Contains(err.Error(), "spec.ingress.host") {
+		t.Fatalf("expected an owner without DNS characters to be rejected, got %v", err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -30,6 +32,12 @@ func newControllerTestScheme(t *testing.T) *runtime.Scheme {
 	if err := corev1.AddToScheme(scheme); err != nil {
 		t.Fatalf("failed to register core scheme: %v", err)
 	}
+	if err := policyv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register policy scheme: %v", err)
+	}
+	if err := netv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register networking scheme: %v", err)
+	}
 	return scheme
 }
 
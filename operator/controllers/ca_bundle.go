@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+const (
+	caBundleVolumeName = "ca-bundle"
+	caBundleMountPath  = "/etc/spritz/ca"
+	caBundleFileName   = "ca.crt"
+	defaultCABundleKey = "ca.crt"
+)
+
+// caBundleFilePath is where the CA bundle is mounted in every container.
+const caBundleFilePath = caBundleMountPath + "/" + caBundleFileName
+
+func caBundleEnabled(spritz *spritzv1.Spritz) bool {
+	return spritz != nil && spritz.Spec.CABundleSecret != nil && strings.TrimSpace(spritz.Spec.CABundleSecret.Name) != ""
+}
+
+// caBundleVolume projects the configured Secret key to a fixed file name, so
+// the env vars do not depend on the key.
+func caBundleVolume(spritz *spritzv1.Spritz) corev1.Volume {
+	key := strings.TrimSpace(spritz.Spec.CABundleSecret.Key)
+	if key == "" {
+		key = defaultCABundleKey
+	}
+	return corev1.Volume{
+		Name: caBundleVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: strings.TrimSpace(spritz.Spec.CABundleSecret.Name),
+				Items:      []corev1.KeyToPath{{Key: key, Path: caBundleFileName}},
+			},
+		},
+	}
+}
+
+func caBundleVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{Name: caBundleVolumeName, MountPath: caBundleMountPath, ReadOnly: true}
+}
+
+// caBundleEnv points git, Node.js and OpenSSL based tools at the bundle.
+// GIT_SSL_CAINFO and SSL_CERT_FILE replace the system trust store, so the
+// bundle must also hold any public roots the workspace needs.
+func caBundleEnv() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "GIT_SSL_CAINFO", Value: caBundleFilePath},
+		{Name: "NODE_EXTRA_CA_CERTS", Value: caBundleFilePath},
+		{Name: "SSL_CERT_FILE", Value: caBundleFilePath},
+	}
+}
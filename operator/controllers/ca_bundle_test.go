@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func hasVolumeMount(mounts []corev1.VolumeMount, name, path string) bool {
+	for _, mount := range mounts {
+		if mount.Name == name && mount.MountPath == path {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReconcileDeploymentMountsCABundle(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.CABundleSecret = &spritzv1.SpritzCABundleSecret{Name: "corp-ca", Key: "bundle.pem"}
+	spritz.Spec.Repo = &spritzv1.SpritzRepo{URL: "https://git.example.com/example-org/app.git"}
+	deployment := reconcileTestDeployment(t, spritz)
+	podSpec := deployment.Spec.Template.Spec
+
+	var volume *corev1.Volume
+	for i := range podSpec.Volumes {
+		if podSpec.Volumes[i].Name == caBundleVolumeName {
+			volume = &podSpec.Volumes[i]
+		}
+	}
+	if volume == nil || volume.Secret == nil || volume.Secret.SecretName != "corp-ca" {
+		t.Fatalf("expected CA bundle secret volume, got %#v", podSpec.Volumes)
+	}
+	if len(volume.Secret.Items) != 1 || volume.Secret.Items[0].Key != "bundle.pem" || volume.Secret.Items[0].Path != caBundleFileName {
+		t.Fatalf("expected secret key projected to %s, got %#v", caBundleFileName, volume.Secret.Items)
+	}
+
+	main := podSpec.Containers[0]
+	if !hasVolumeMount(main.VolumeMounts, caBundleVolumeName, caBundleMountPath) {
+		t.Fatalf("expected CA bundle mount on main container, got %#v", main.VolumeMounts)
+	}
+	for _, name := range []string{"GIT_SSL_CAINFO", "NODE_EXTRA_CA_CERTS", "SSL_CERT_FILE"} {
+		if value, ok := envValue(main.Env, name); !ok || value != caBundleFilePath {
+			t.Fatalf("expected %s=%s on main container, got %q", name, caBundleFilePath, value)
+		}
+	}
+
+	if len(podSpec.InitContainers) == 0 {
+		t.Fatal("expected repo init container")
+	}
+	repoInit := podSpec.InitContainers[0]
+	if !hasVolumeMount(repoInit.VolumeMounts, caBundleVolumeName, caBundleMountPath) {
+		t.Fatalf("expected CA bundle mount on repo init container, got %#v", repoInit.VolumeMounts)
+	}
+	if value, ok := envValue(repoInit.Env, "GIT_SSL_CAINFO"); !ok || value != caBundleFilePath {
+		t.Fatalf("expected GIT_SSL_CAINFO on repo init container, got %q", value)
+	}
+}
+
+func TestReconcileDeploymentCABundleEnvCanBeOverridden(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.CABundleSecret = &spritzv1.SpritzCABundleSecret{Name: "corp-ca"}
+	spritz.Spec.Env = []corev1.EnvVar{{Name: "SSL_CERT_FILE", Value: "/etc/ssl/certs/ca-certificates.crt"}}
+	deployment := reconcileTestDeployment(t, spritz)
+
+	env := deployment.Spec.Template.Spec.Containers[0].Env
+	last := ""
+	for _, item := range env {
+		if item.Name == "SSL_CERT_FILE" {
+			last = item.Value
+		}
+	}
+	if last != "/etc/ssl/certs/ca-certificates.crt" {
+		t.Fatalf("expected spec.env to win over the CA bundle default, got %q", last)
+	}
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == caBundleVolumeName && volume.Secret.Items[0].Key != defaultCABundleKey {
+			t.Fatalf("expected default key %s, got %#v", defaultCABundleKey, volume.Secret.Items)
+		}
+	}
+}
+
+func TestReconcileDeploymentWithoutCABundle(t *testing.T) {
+	deployment := reconcileTestDeployment(t, newPodSpecTestSpritz())
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == caBundleVolumeName {
+			t.Fatal("expected no CA bundle volume")
+		}
+	}
+	if _, ok := envValue(deployment.Spec.Template.Spec.Containers[0].Env, "SSL_CERT_FILE"); ok {
+		t.Fatal("expected no SSL_CERT_FILE env")
+	}
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// defaultRevisionHistoryLimit keeps one ReplicaSet to roll back to besides
+// the current one. Kubernetes keeps ten, which piles up for workspaces that
+// roll often.
+const defaultRevisionHistoryLimit = int32(2)
+
+// deploymentRevisionHistoryLimit returns spec.revisionHistoryLimit or
+// SPRITZ_REVISION_HISTORY_LIMIT.
+func deploymentRevisionHistoryLimit(spritz *spritzv1.Spritz) (int32, error) {
+	if spritz.Spec.RevisionHistoryLimit != nil {
+		return *spritz.Spec.RevisionHistoryLimit, nil
+	}
+	raw := strings.TrimSpace(os.Getenv("SPRITZ_REVISION_HISTORY_LIMIT"))
+	if raw == "" {
+		return defaultRevisionHistoryLimit, nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid SPRITZ_REVISION_HISTORY_LIMIT: %q", raw)
+	}
+	return int32(value), nil
+}
+
+// deploymentProgressDeadlineSeconds returns spec.progressDeadlineSeconds or
+// SPRITZ_PROGRESS_DEADLINE_SECONDS. Nil keeps the Kubernetes default of 600.
+func deploymentProgressDeadlineSeconds(spritz *spritzv1.Spritz) (*int32, error) {
+	if spritz.Spec.ProgressDeadlineSeconds != nil {
+		value := *spritz.Spec.ProgressDeadlineSeconds
+		return &value, nil
+	}
+	raw := strings.TrimSpace(os.Getenv("SPRITZ_PROGRESS_DEADLINE_SECONDS"))
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || value < 1 {
+		return nil, fmt.Errorf("invalid SPRITZ_PROGRESS_DEADLINE_SECONDS: %q", raw)
+	}
+	deadline := int32(value)
+	return &deadline, nil
+}
+
+// rolloutProgressingCondition reports a rollout that passed its progress
+// deadline while an older pod is still available. The spritz stays Ready on
+// the old pod and this condition says the new spec is not rolling out.
+const rolloutProgressingCondition = "RolloutProgressing"
+
+// deploymentRolloutFailure reports a rollout the Deployment controller gave
+// up on: it could not create pods at all, for example because of a resource
+// quota, or the progress deadline passed with no pod available. A deadline
+// that passes while a pod is still available is reported by
+// setRolloutProgressingCondition instead.
+func deploymentRolloutFailure(deploy *appsv1.Deployment) (string, string, bool) {
+	for _, condition := range deploy.Status.Conditions {
+		switch {
+		case condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue:
+			return "ReplicaFailure", rolloutFailureMessage(condition.Message, "deployment cannot create pods"), true
+		case isProgressDeadlineExceeded(condition) && deploy.Status.AvailableReplicas == 0:
+			return "ProgressDeadlineExceeded", rolloutFailureMessage(condition.Message, "deployment rollout exceeded its progress deadline"), true
+		}
+	}
+	return "", "", false
+}
+
+// setRolloutProgressingCondition sets RolloutProgressing to False while the
+// rollout is past its progress deadline and an older pod is still available,
+// and removes it otherwise.
+func setRolloutProgressingCondition(conditions *[]metav1.Condition, generation int64, deploy *appsv1.Deployment) {
+	if deploy.Status.AvailableReplicas > 0 {
+		for _, condition := range deploy.Status.Conditions {
+			if !isProgressDeadlineExceeded(condition) {
+				continue
+			}
+			meta.SetStatusCondition(conditions, metav1.Condition{
+				Type:               rolloutProgressingCondition,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: generation,
+				Reason:             "ProgressDeadlineExceeded",
+				Message:            rolloutFailureMessage(condition.Message, "deployment rollout exceeded its progress deadline"),
+			})
+			return
+		}
+	}
+	meta.RemoveStatusCondition(conditions, rolloutProgressingCondition)
+}
+
+func isProgressDeadlineExceeded(condition appsv1.DeploymentCondition) bool {
+	return condition.Type == appsv1.DeploymentProgressing &&
+		condition.Status == corev1.ConditionFalse &&
+		condition.Reason == "ProgressDeadlineExceeded"
+}
+
+func rolloutFailureMessage(message, fallback string) string {
+	if message = strings.TrimSpace(message); message != "" {
+		return message
+	}
+	return fallback
+}
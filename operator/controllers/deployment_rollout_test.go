@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestReconcileDeploymentSetsRolloutLimits(t *testing.T) {
+	deployment := reconcileTestDeployment(t, newPodSpecTestSpritz())
+	if deployment.Spec.RevisionHistoryLimit == nil || *deployment.Spec.RevisionHistoryLimit != defaultRevisionHistoryLimit {
+		t.Fatalf("expected default revisionHistoryLimit %d, got %v", defaultRevisionHistoryLimit, deployment.Spec.RevisionHistoryLimit)
+	}
+
+	t.Setenv("SPRITZ_REVISION_HISTORY_LIMIT", "5")
+	t.Setenv("SPRITZ_PROGRESS_DEADLINE_SECONDS", "300")
+	deployment = reconcileTestDeployment(t, newPodSpecTestSpritz())
+	if *deployment.Spec.RevisionHistoryLimit != 5 {
+		t.Fatalf("expected operator revisionHistoryLimit 5, got %d", *deployment.Spec.RevisionHistoryLimit)
+	}
+	if deployment.Spec.ProgressDeadlineSeconds == nil || *deployment.Spec.ProgressDeadlineSeconds != 300 {
+		t.Fatalf("expected operator progressDeadlineSeconds 300, got %v", deployment.Spec.ProgressDeadlineSeconds)
+	}
+
+	spritz := newPodSpecTestSpritz()
+	history := int32(0)
+	deadline := int32(120)
+	spritz.Spec.RevisionHistoryLimit = &history
+	spritz.Spec.ProgressDeadlineSeconds = &deadline
+	deployment = reconcileTestDeployment(t, spritz)
+	if *deployment.Spec.RevisionHistoryLimit != 0 || *deployment.Spec.ProgressDeadlineSeconds != 120 {
+		t.Fatalf("expected spec rollout limits, got %d and %d", *deployment.Spec.RevisionHistoryLimit, *deployment.Spec.ProgressDeadlineSeconds)
+	}
+}
+
+func TestDeploymentRolloutSettingsRejectInvalidEnv(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	t.Setenv("SPRITZ_REVISION_HISTORY_LIMIT", "-1")
+	if _, err := deploymentRevisionHistoryLimit(spritz); err == nil {
+		t.Fatal("expected negative revision history limit to be rejected")
+	}
+	t.Setenv("SPRITZ_PROGRESS_DEADLINE_SECONDS", "0")
+	if _, err := deploymentProgressDeadlineSeconds(spritz); err == nil {
+		t.Fatal("expected zero progress deadline to be rejected")
+	}
+}
+
+func TestReconcileStatusReportsStuckRollout(t *testing.T) {
+	cases := []struct {
+		name       string
+		condition  appsv1.DeploymentCondition
+		available  int32
+		wantReason string
+	}{
+		{
+			name: "progress deadline exceeded",
+			condition: appsv1.DeploymentCondition{
+				Type:    appsv1.DeploymentProgressing,
+				Status:  corev1.ConditionFalse,
+				Reason:  "ProgressDeadlineExceeded",
+				Message: `ReplicaSet "tidy-otter-abc" has timed out progressing.`,
+			},
+			wantReason: "ProgressDeadlineExceeded",
+		},
+		{
+			name: "replica failure",
+			condition: appsv1.DeploymentCondition{
+				Type:    appsv1.DeploymentReplicaFailure,
+				Status:  corev1.ConditionTrue,
+				Reason:  "FailedCreate",
+				Message: "exceeded quota",
+			},
+			available:  1,
+			wantReason: "ReplicaFailure",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := newControllerTestScheme(t)
+			spritz := newPodSpecTestSpritz()
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: spritz.Name, Namespace: spritz.Namespace},
+				Status: appsv1.DeploymentStatus{
+					AvailableReplicas: tc.available,
+					Conditions:        []appsv1.DeploymentCondition{tc.condition},
+				},
+			}
+			k8sClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(&spritzv1.Spritz{}).
+				WithObjects(spritz, deployment).
+				Build()
+			reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+			if _, err := reconciler.reconcileStatus(context.Background(), spritz); err != nil {
+				t.Fatalf("reconcileStatus returned error: %v", err)
+			}
+			stored := &spritzv1.Spritz{}
+			if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: spritz.Namespace, Name: spritz.Name}, stored); err != nil {
+				t.Fatalf("failed to load updated spritz: %v", err)
+			}
+			if stored.Status.Phase != "Error" || !hasConditionReason(stored.Status.Conditions, tc.wantReason) {
+				t.Fatalf("expected Error with reason %s, got %q %#v", tc.wantReason, stored.Status.Phase, stored.Status.Conditions)
+			}
+			if stored.Status.Message != tc.condition.Message {
+				t.Fatalf("expected deployment message, got %q", stored.Status.Message)
+			}
+		})
+	}
+}
+
+func TestReconcileStatusKeepsReadyWhenRolloutStallsBehindAvailablePod(t *testing.T) {
+	scheme := newControllerTestScheme(t)
+	spritz := newPodSpecTestSpritz()
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: spritz.Name, Namespace: spritz.Namespace},
+		Status: appsv1.DeploymentStatus{
+			AvailableReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:    appsv1.DeploymentProgressing,
+				Status:  corev1.ConditionFalse,
+				Reason:  "ProgressDeadlineExceeded",
+				Message: `ReplicaSet "tidy-otter-def" has timed out progressing.`,
+			}},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&spritzv1.Spritz{}).
+		WithObjects(spritz, deployment).
+		Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	if _, err := reconciler.reconcileStatus(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcileStatus returned error: %v", err)
+	}
+	stored := &spritzv1.Spritz{}
+	if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: spritz.Namespace, Name: spritz.Name}, stored); err != nil {
+		t.Fatalf("failed to load updated spritz: %v", err)
+	}
+	if stored.Status.Phase != "Ready" {
+		t.Fatalf("expected spritz to stay Ready on the old pod, got %q", stored.Status.Phase)
+	}
+	condition := meta.FindStatusCondition(stored.Status.Conditions, rolloutProgressingCondition)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "ProgressDeadlineExceeded" {
+		t.Fatalf("expected stalled rollout condition, got %#v", stored.Status.Conditions)
+	}
+
+	deployment.Status.Conditions = nil
+	if err := k8sClient.Status().Update(context.Background(), deployment); err != nil {
+		t.Fatalf("failed to update deployment: %v", err)
+	}
+	if _, err := reconciler.reconcileStatus(context.Background(), stored); err != nil {
+		t.Fatalf("reconcileStatus returned error: %v", err)
+	}
+	if meta.FindStatusCondition(stored.Status.Conditions, rolloutProgressingCondition) != nil {
+		t.Fatalf("expected condition to be removed after recovery, got %#v", stored.Status.Conditions)
+	}
+}
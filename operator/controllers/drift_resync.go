@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// DriftResyncIntervalFromEnv reads SPRITZ_DRIFT_RESYNC_INTERVAL. Owned
+// resources are watched, but a resync also repairs objects whose events were
+// missed or whose owner reference was removed. Zero disables it.
+func DriftResyncIntervalFromEnv() time.Duration {
+	return parseDurationEnv("SPRITZ_DRIFT_RESYNC_INTERVAL", 0)
+}
+
+// driftResyncRequeue returns the periodic resync delay, or nil when disabled.
+func (r *SpritzReconciler) driftResyncRequeue() *time.Duration {
+	return durationPtr(r.DriftResyncInterval)
+}
+
+// logOwnedResourceDrift logs an update to an owned resource that was not
+// caused by a spec change. The Ready condition records the generation of the
+// last reconcile, so an update at the same generation means the live object
+// no longer matched the desired state. Operator config changes are reported
+// the same way.
+func logOwnedResourceDrift(ctx context.Context, spritz *spritzv1.Spritz, kind string, result controllerutil.OperationResult) {
+	if result != controllerutil.OperationResultUpdated {
+		return
+	}
+	ready := meta.FindStatusCondition(spritz.Status.Conditions, "Ready")
+	if ready == nil || ready.ObservedGeneration != spritz.Generation {
+		return
+	}
+	log.FromContext(ctx).Info(
+		"corrected drift in owned resource",
+		"kind", kind,
+		"name", spritz.Name,
+		"namespace", spritz.Namespace,
+	)
+}
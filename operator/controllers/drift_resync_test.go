@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestReconcileRequeuesForDriftResync(t *testing.T) {
+	scheme := newControllerTestScheme(t)
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register networking scheme: %v", err)
+	}
+	if err := gatewayv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register gateway scheme: %v", err)
+	}
+	spritz := newPodSpecTestSpritz()
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&spritzv1.Spritz{}).
+		WithObjects(spritz).
+		Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme, DriftResyncInterval: 10 * time.Minute}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: spritz.Namespace, Name: spritz.Name}}
+
+	// The first pass only adds the finalizer and owner labels.
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	result, err := reconciler.Reconcile(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > 10*time.Minute {
+		t.Fatalf("expected requeue within the drift resync interval, got %s", result.RequeueAfter)
+	}
+
+	deployment := &appsv1.Deployment{}
+	key := client.ObjectKey{Namespace: spritz.Namespace, Name: spritz.Name}
+	if err := k8sClient.Get(context.Background(), key, deployment); err != nil {
+		t.Fatalf("failed to load deployment: %v", err)
+	}
+	deployment.Spec.Template.Spec.Containers[0].Image = "example.com/other:latest"
+	if err := k8sClient.Update(context.Background(), deployment); err != nil {
+		t.Fatalf("failed to edit deployment: %v", err)
+	}
+
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := k8sClient.Get(context.Background(), key, deployment); err != nil {
+		t.Fatalf("failed to load deployment: %v", err)
+	}
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != spritz.Spec.Image {
+		t.Fatalf("expected drifted image to be restored, got %q", image)
+	}
+}
+
+func TestDriftResyncIntervalFromEnv(t *testing.T) {
+	if got := DriftResyncIntervalFromEnv(); got != 0 {
+		t.Fatalf("expected resync to be disabled by default, got %s", got)
+	}
+	t.Setenv("SPRITZ_DRIFT_RESYNC_INTERVAL", "15m")
+	if got := DriftResyncIntervalFromEnv(); got != 15*time.Minute {
+		t.Fatalf("expected 15m, got %s", got)
+	}
+	reconciler := &SpritzReconciler{}
+	if reconciler.driftResyncRequeue() != nil {
+		t.Fatal("expected no requeue when the interval is zero")
+	}
+}
+
+func TestLogOwnedResourceDriftOnlyLogsUpdatesAtObservedGeneration(t *testing.T) {
+	var lines []string
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})
+	ctx := log.IntoContext(context.Background(), logger)
+	spritz := newPodSpecTestSpritz()
+	spritz.Generation = 2
+	spritz.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, ObservedGeneration: 2}}
+
+	logOwnedResourceDrift(ctx, spritz, "Deployment", controllerutil.OperationResultNone)
+	if len(lines) != 0 {
+		t.Fatalf("expected no log for an unchanged resource, got %v", lines)
+	}
+	logOwnedResourceDrift(ctx, spritz, "Deployment", controllerutil.OperationResultUpdated)
+	if len(lines) != 1 || !strings.Contains(lines[0], "corrected drift") {
+		t.Fatalf("expected drift log, got %v", lines)
+	}
+
+	spritz.Generation = 3
+	logOwnedResourceDrift(ctx, spritz, "Deployment", controllerutil.OperationResultUpdated)
+	if len(lines) != 1 {
+		t.Fatalf("expected no drift log for a spec change, got %v", lines)
+	}
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// extraVolumeReservedPaths are owned by the operator in every workspace pod.
+// Repo checkouts live under /workspace and the home volume under /home.
+var extraVolumeReservedPaths = []string{"/workspace", "/home"}
+
+// extraVolumesUnrestrictedAnnotationKey lets admins use extra volume sources
+// beyond emptyDir, downwardAPI and csi. The API reserves it for admins.
+const extraVolumesUnrestrictedAnnotationKey = "spritz.sh/extra-volumes-unrestricted"
+
+func extraVolumesUnrestricted(spritz *spritzv1.Spritz) bool {
+	return strings.EqualFold(strings.TrimSpace(spritz.Annotations[extraVolumesUnrestrictedAnnotationKey]), "true")
+}
+
+// allowHostPathVolumes reports whether spec.extraVolumes may use hostPath.
+// hostPath exposes the node filesystem, so it stays off unless cluster admins
+// opt in with SPRITZ_ALLOW_HOSTPATH_VOLUMES.
+func allowHostPathVolumes() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("SPRITZ_ALLOW_HOSTPATH_VOLUMES")), "true")
+}
+
+// validateExtraVolumes checks spec.extraVolumes and spec.extraVolumeMounts
+// against the policy and the mounts every workspace pod has. Mounts that only
+// exist for some spritzes are checked again by appendExtraVolumes.
+func validateExtraVolumes(spritz *spritzv1.Spritz) error {
+	names := map[string]bool{}
+	for i, volume := range spritz.Spec.ExtraVolumes {
+		name := strings.TrimSpace(volume.Name)
+		if name == "" {
+			return fmt.Errorf("spec.extraVolumes[%d].name is required", i)
+		}
+		if name == "workspace" || name == "home" {
+			return fmt.Errorf("spec.extraVolumes[%d].name %q is reserved", i, name)
+		}
+		if names[name] {
+			return fmt.Errorf("spec.extraVolumes[%d].name %q is used more than once", i, name)
+		}
+		names[name] = true
+		if volume.HostPath != nil && !allowHostPathVolumes() {
+			return fmt.Errorf("spec.extraVolumes[%d] uses hostPath, which is not allowed by the operator", i)
+		}
+		if spritzv1.RestrictedVolumeSource(volume) && !extraVolumesUnrestricted(spritz) {
+			return fmt.Errorf("spec.extraVolumes[%d] must use emptyDir, downwardAPI or csi unless the %s annotation is set", i, extraVolumesUnrestrictedAnnotationKey)
+		}
+	}
+
+	reserved := append([]string{}, extraVolumeReservedPaths...)
+	for _, mount := range spritz.Spec.SharedMounts {
+		if mountPath := strings.TrimSpace(mount.MountPath); mountPath != "" {
+			reserved = append(reserved, path.Clean(mountPath))
+		}
+	}
+	var seen []string
+	for i, mount := range spritz.Spec.ExtraVolumeMounts {
+		if !names[strings.TrimSpace(mount.Name)] {
+			return fmt.Errorf("spec.extraVolumeMounts[%d].name must match an entry in spec.extraVolumes", i)
+		}
+		mountPath := strings.TrimSpace(mount.MountPath)
+		if !path.IsAbs(mountPath) || path.Clean(mountPath) == "/" {
+			return fmt.Errorf("spec.extraVolumeMounts[%d].mountPath must be an absolute path below /", i)
+		}
+		mountPath = path.Clean(mountPath)
+		for _, other := range reserved {
+			if pathHasPrefix(mountPath, other) || pathHasPrefix(other, mountPath) {
+				return fmt.Errorf("spec.extraVolumeMounts[%d].mountPath %s overlaps managed mount %s", i, mountPath, other)
+			}
+		}
+		for _, other := range seen {
+			if pathHasPrefix(mountPath, other) || pathHasPrefix(other, mountPath) {
+				return fmt.Errorf("spec.extraVolumeMounts[%d].mountPath %s overlaps %s", i, mountPath, other)
+			}
+		}
+		seen = append(seen, mountPath)
+	}
+	return nil
+}
+
+// appendExtraVolumes adds the spec's extra volumes and mounts after the
+// managed ones, rejecting any name or path the operator already uses.
+func appendExtraVolumes(spritz *spritzv1.Spritz, volumes []corev1.Volume, mounts []corev1.VolumeMount) ([]corev1.Volume, []corev1.VolumeMount, error) {
+	if len(spritz.Spec.ExtraVolumes) == 0 && len(spritz.Spec.ExtraVolumeMounts) == 0 {
+		return volumes, mounts, nil
+	}
+	if err := validateExtraVolumes(spritz); err != nil {
+		return nil, nil, err
+	}
+	for i, volume := range spritz.Spec.ExtraVolumes {
+		for _, existing := range volumes {
+			if existing.Name == strings.TrimSpace(volume.Name) {
+				return nil, nil, fmt.Errorf("spec.extraVolumes[%d].name %q is used by a managed volume", i, existing.Name)
+			}
+		}
+	}
+	for i, mount := range spritz.Spec.ExtraVolumeMounts {
+		mountPath := path.Clean(strings.TrimSpace(mount.MountPath))
+		for _, existing := range mounts {
+			other := path.Clean(existing.MountPath)
+			if pathHasPrefix(mountPath, other) || pathHasPrefix(other, mountPath) {
+				return nil, nil, fmt.Errorf("spec.extraVolumeMounts[%d].mountPath %s overlaps managed mount %s", i, mountPath, other)
+			}
+		}
+	}
+
+	volumes = append(volumes, spritz.Spec.ExtraVolumes...)
+	for _, mount := range spritz.Spec.ExtraVolumeMounts {
+		mount.MountPath = path.Clean(strings.TrimSpace(mount.MountPath))
+		mounts = append(mounts, mount)
+	}
+	return volumes, mounts, nil
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+	"spritz.sh/operator/sharedmounts"
+)
+
+func TestReconcileDeploymentAddsExtraVolumes(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.ExtraVolumes = []corev1.Volume{{
+		Name: "podinfo",
+		VolumeSource: corev1.VolumeSource{DownwardAPI: &corev1.DownwardAPIVolumeSource{
+			Items: []corev1.DownwardAPIVolumeFile{{Path: "labels", FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.labels"}}},
+		}},
+	}}
+	spritz.Spec.ExtraVolumeMounts = []corev1.VolumeMount{{Name: "podinfo", MountPath: "/etc/podinfo/", ReadOnly: true}}
+
+	deployment := reconcileTestDeployment(t, spritz)
+	podSpec := deployment.Spec.Template.Spec
+	found := false
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == "podinfo" && volume.DownwardAPI != nil {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected extra volume in pod spec, got %#v", podSpec.Volumes)
+	}
+	mounts := podSpec.Containers[0].VolumeMounts
+	last := mounts[len(mounts)-1]
+	if last.Name != "podinfo" || last.MountPath != "/etc/podinfo" || !last.ReadOnly {
+		t.Fatalf("expected extra mount after managed mounts, got %#v", mounts)
+	}
+}
+
+func TestValidateExtraVolumes(t *testing.T) {
+	hostPath := corev1.Volume{
+		Name:         "src",
+		VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/srv/src"}},
+	}
+	configMap := corev1.Volume{
+		Name: "settings",
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+		}},
+	}
+	cases := []struct {
+		name    string
+		volumes []corev1.Volume
+		mounts  []corev1.VolumeMount
+		shared  []sharedmounts.MountSpec
+		want    string
+	}{
+		{name: "reserved name", volumes: []corev1.Volume{{Name: "home"}}, want: "is reserved"},
+		{name: "duplicate name", volumes: []corev1.Volume{{Name: "data"}, {Name: "data"}}, want: "more than once"},
+		{name: "host path", volumes: []corev1.Volume{hostPath}, want: "hostPath"},
+		{name: "restricted source", volumes: []corev1.Volume{configMap}, want: "must use emptyDir, downwardAPI or csi"},
+		{name: "unknown volume", mounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}}, want: "must match"},
+		{name: "relative path", volumes: []corev1.Volume{{Name: "data"}}, mounts: []corev1.VolumeMount{{Name: "data", MountPath: "data"}}, want: "absolute"},
+		{name: "under workspace", volumes: []corev1.Volume{{Name: "data"}}, mounts: []corev1.VolumeMount{{Name: "data", MountPath: "/workspace/data"}}, want: "overlaps managed mount /workspace"},
+		{name: "above home", volumes: []corev1.Volume{{Name: "data"}}, mounts: []corev1.VolumeMount{{Name: "data", MountPath: "/home"}}, want: "overlaps managed mount /home"},
+		{
+			name:    "shared mount",
+			volumes: []corev1.Volume{{Name: "data"}},
+			mounts:  []corev1.VolumeMount{{Name: "data", MountPath: "/config/extra"}},
+			shared:  []sharedmounts.MountSpec{{Name: "config", MountPath: "/config"}},
+			want:    "overlaps managed mount /config",
+		},
+		{
+			name:    "overlapping extras",
+			volumes: []corev1.Volume{{Name: "data"}, {Name: "cache"}},
+			mounts:  []corev1.VolumeMount{{Name: "data", MountPath: "/data"}, {Name: "cache", MountPath: "/data/cache"}},
+			want:    "overlaps /data",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spritz := &spritzv1.Spritz{Spec: spritzv1.SpritzSpec{ExtraVolumes: tc.volumes, ExtraVolumeMounts: tc.mounts, SharedMounts: tc.shared}}
+			err := validateExtraVolumes(spritz)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+
+	allowed := &spritzv1.Spritz{Spec: spritzv1.SpritzSpec{ExtraVolumes: []corev1.Volume{
+		{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		{Name: "podinfo", VolumeSource: corev1.VolumeSource{DownwardAPI: &corev1.DownwardAPIVolumeSource{}}},
+		{Name: "models", VolumeSource: corev1.VolumeSource{CSI: &corev1.CSIVolumeSource{Driver: "csi.example.com"}}},
+	}}}
+	if err := validateExtraVolumes(allowed); err != nil {
+		t.Fatalf("expected emptyDir, downwardAPI and csi without the annotation, got %v", err)
+	}
+
+	t.Setenv("SPRITZ_ALLOW_HOSTPATH_VOLUMES", "true")
+	spritz := &spritzv1.Spritz{Spec: spritzv1.SpritzSpec{
+		ExtraVolumes:      []corev1.Volume{hostPath, configMap},
+		ExtraVolumeMounts: []corev1.VolumeMount{{Name: "src", MountPath: "/src"}},
+	}}
+	if err := validateExtraVolumes(spritz); err == nil || !strings.Contains(err.Error(), extraVolumesUnrestrictedAnnotationKey) {
+		t.Fatalf("expected hostPath to need the unrestricted annotation, got %v", err)
+	}
+	spritz.Annotations = map[string]string{extraVolumesUnrestrictedAnnotationKey: "true"}
+	if err := validateExtraVolumes(spritz); err != nil {
+		t.Fatalf("expected hostPath to be allowed by policy, got %v", err)
+	}
+}
+
+func TestReconcileStatusReportsInvalidExtraVolumes(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.ExtraVolumes = []corev1.Volume{{
+		Name:         "src",
+		VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/srv/src"}},
+	}}
+	scheme := newControllerTestScheme(t)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).WithStatusSubresource(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	if _, err := reconciler.reconcileStatus(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcileStatus returned error: %v", err)
+	}
+	if spritz.Status.Phase != "Error" || !strings.Contains(spritz.Status.Message, "hostPath") {
+		t.Fatalf("expected hostPath error in status, got %q: %q", spritz.Status.Phase, spritz.Status.Message)
+	}
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const defaultGPUResourceName = corev1.ResourceName("nvidia.com/gpu")
+
+// gpuScheduling holds the pod scheduling settings applied to workspaces that
+// request GPUs.
+type gpuScheduling struct {
+	resourceName corev1.ResourceName
+	nodeSelector map[string]string
+	tolerations  []corev1.Toleration
+}
+
+func loadGPUScheduling() (gpuScheduling, error) {
+	settings := gpuScheduling{resourceName: defaultGPUResourceName}
+	if name := strings.TrimSpace(os.Getenv("SPRITZ_GPU_RESOURCE_NAME")); name != "" {
+		settings.resourceName = corev1.ResourceName(name)
+	}
+	if raw := strings.TrimSpace(os.Getenv("SPRITZ_GPU_NODE_SELECTOR")); raw != "" {
+		selector, err := parseNodeSelector(raw)
+		if err != nil {
+			return gpuScheduling{}, fmt.Errorf("invalid SPRITZ_GPU_NODE_SELECTOR: %w", err)
+		}
+		settings.nodeSelector = selector
+	}
+	if raw := strings.TrimSpace(os.Getenv("SPRITZ_GPU_TOLERATIONS")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &settings.tolerations); err != nil {
+			return gpuScheduling{}, fmt.Errorf("invalid SPRITZ_GPU_TOLERATIONS: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+func (g gpuScheduling) configured() bool {
+	return len(g.nodeSelector) > 0 || len(g.tolerations) > 0
+}
+
+// requestsGPU reports whether the container resources ask for at least one GPU.
+func (g gpuScheduling) requestsGPU(resources corev1.ResourceRequirements) bool {
+	if quantity, ok := resources.Limits[g.resourceName]; ok && !quantity.IsZero() {
+		return true
+	}
+	if quantity, ok := resources.Requests[g.resourceName]; ok && !quantity.IsZero() {
+		return true
+	}
+	return false
+}
+
+// normalizeGPUResources validates a GPU request and fills in the limit when
+// only the request is set. Kubernetes requires extended resources to be whole
+// numbers with equal request and limit, and rejects a request without a limit.
+func (g gpuScheduling) normalizeGPUResources(resources corev1.ResourceRequirements) (corev1.ResourceRequirements, error) {
+	request, hasRequest := resources.Requests[g.resourceName]
+	limit, hasLimit := resources.Limits[g.resourceName]
+	if hasRequest && hasLimit && request.Cmp(limit) != 0 {
+		return resources, fmt.Errorf("resources: %s request %s must equal limit %s", g.resourceName, request.String(), limit.String())
+	}
+	quantity := limit
+	if !hasLimit {
+		quantity = request
+	}
+	if quantity.Sign() < 0 || quantity.MilliValue()%1000 != 0 {
+		return resources, fmt.Errorf("resources: %s must be a whole number, got %s", g.resourceName, quantity.String())
+	}
+	if hasLimit {
+		return resources, nil
+	}
+	normalized := *resources.DeepCopy()
+	if normalized.Limits == nil {
+		normalized.Limits = corev1.ResourceList{}
+	}
+	normalized.Limits[g.resourceName] = request.DeepCopy()
+	return normalized, nil
+}
+
+// applyGPUScheduling adds the GPU node selector and tolerations to the pod.
+// The GPU node selector wins over the operator default node selector on
+// conflicting keys.
+func (g gpuScheduling) applyGPUScheduling(podSpec *corev1.PodSpec) {
+	if len(g.nodeSelector) > 0 {
+		podSpec.NodeSelector = mergeMaps(podSpec.NodeSelector, g.nodeSelector)
+	}
+	for _, toleration := range g.tolerations {
+		podSpec.Tolerations = append(podSpec.Tolerations, *toleration.DeepCopy())
+	}
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileDeploymentAppliesGPUScheduling(t *testing.T) {
+	t.Setenv("SPRITZ_POD_NODE_SELECTOR", "pool=default,arch=amd64")
+	t.Setenv("SPRITZ_GPU_NODE_SELECTOR", "pool=gpu")
+	t.Setenv("SPRITZ_GPU_TOLERATIONS", `[{"key":"nvidia.com/gpu","operator":"Exists","effect":"NoSchedule"}]`)
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{defaultGPUResourceName: resource.MustParse("1")},
+	}
+	deployment := reconcileTestDeployment(t, spritz)
+
+	podSpec := deployment.Spec.Template.Spec
+	if podSpec.NodeSelector["pool"] != "gpu" || podSpec.NodeSelector["arch"] != "amd64" {
+		t.Fatalf("expected GPU node selector merged over the default, got %#v", podSpec.NodeSelector)
+	}
+	if len(podSpec.Tolerations) != 1 || podSpec.Tolerations[0].Key != "nvidia.com/gpu" {
+		t.Fatalf("expected GPU toleration, got %#v", podSpec.Tolerations)
+	}
+	limit := podSpec.Containers[0].Resources.Limits[defaultGPUResourceName]
+	if limit.Value() != 1 {
+		t.Fatalf("expected GPU limit defaulted from request, got %s", limit.String())
+	}
+	if _, ok := spritz.Spec.Resources.Limits[defaultGPUResourceName]; ok {
+		t.Fatal("expected spritz spec to be left unmodified")
+	}
+}
+
+func TestReconcileDeploymentSkipsGPUSchedulingWithoutGPURequest(t *testing.T) {
+	t.Setenv("SPRITZ_GPU_NODE_SELECTOR", "pool=gpu")
+	t.Setenv("SPRITZ_GPU_TOLERATIONS", `[{"key":"nvidia.com/gpu","operator":"Exists","effect":"NoSchedule"}]`)
+	deployment := reconcileTestDeployment(t, newPodSpecTestSpritz())
+
+	podSpec := deployment.Spec.Template.Spec
+	if len(podSpec.NodeSelector) != 0 || len(podSpec.Tolerations) != 0 {
+		t.Fatalf("expected no GPU scheduling, got selector %#v tolerations %#v", podSpec.NodeSelector, podSpec.Tolerations)
+	}
+}
+
+func TestReconcileDeploymentRejectsMismatchedGPURequest(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{defaultGPUResourceName: resource.MustParse("1")},
+		Limits:   corev1.ResourceList{defaultGPUResourceName: resource.MustParse("2")},
+	}
+	scheme := newControllerTestScheme(t)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	if err := reconciler.reconcileDeployment(context.Background(), spritz); err == nil {
+		t.Fatal("expected mismatched GPU request and limit to be rejected")
+	}
+}
+
+func TestNormalizeGPUResourcesRejectsFractions(t *testing.T) {
+	gpu := gpuScheduling{resourceName: defaultGPUResourceName}
+	resources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{defaultGPUResourceName: resource.MustParse("500m")},
+	}
+	if _, err := gpu.normalizeGPUResources(resources); err == nil {
+		t.Fatal("expected fractional GPU limit to be rejected")
+	}
+}
+
+func TestLoadGPUSchedulingCustomResourceName(t *testing.T) {
+	t.Setenv("SPRITZ_GPU_RESOURCE_NAME", "amd.com/gpu")
+	gpu, err := loadGPUScheduling()
+	if err != nil {
+		t.Fatalf("loadGPUScheduling returned error: %v", err)
+	}
+	resources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{"amd.com/gpu": resource.MustParse("1")},
+	}
+	if !gpu.requestsGPU(resources) {
+		t.Fatal("expected custom GPU resource to be detected")
+	}
+	if gpu.configured() {
+		t.Fatal("expected no GPU scheduling to be configured")
+	}
+
+	t.Setenv("SPRITZ_GPU_TOLERATIONS", "not-json")
+	if _, err := loadGPUScheduling(); err == nil {
+		t.Fatal("expected invalid tolerations to be rejected")
+	}
+}
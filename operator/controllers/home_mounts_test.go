@@ -141,3 +141,44 @@ func TestValidateRepoDir(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileDeploymentOmitsHomeWhenDisabled(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	homeDisabled := false
+	spritz.Spec.Features = &spritzv1.SpritzFeatures{Home: &homeDisabled}
+	spritz.Spec.Repo = &spritzv1.SpritzRepo{
+		URL:  "https://example.com/example-org/app.git",
+		Auth: &spritzv1.SpritzRepoAuth{SecretName: "git-auth", NetrcKey: "netrc"},
+	}
+
+	podSpec := reconcileTestDeployment(t, spritz).Spec.Template.Spec
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == "home" {
+			t.Fatal("expected no home volume")
+		}
+	}
+	containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, container := range containers {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == "home" || mount.MountPath == repoInitHomeDir {
+				t.Fatalf("expected no home mount in %s, got %#v", container.Name, mount)
+			}
+		}
+	}
+	if len(podSpec.InitContainers) == 0 {
+		t.Fatal("expected repo init container")
+	}
+	if home, _ := envValue(podSpec.InitContainers[0].Env, "HOME"); home != repoInitTempHomeDir {
+		t.Fatalf("expected repo init to write credentials to a scratch HOME, got %q", home)
+	}
+	if !hasVolumeMount(podSpec.InitContainers[0].VolumeMounts, repoInitTempHomeVolumeName, repoInitTempHomeDir) {
+		t.Fatalf("expected repo init to mount its own scratch HOME, got %#v", podSpec.InitContainers[0].VolumeMounts)
+	}
+	for _, container := range podSpec.Containers {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == repoInitTempHomeVolumeName {
+				t.Fatalf("expected %s not to mount the repo init HOME", container.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// imageDigestExemptAnnotationKey lets admins run a tag-only image while
+// SPRITZ_REQUIRE_IMAGE_DIGEST is on.
+const imageDigestExemptAnnotationKey = "spritz.sh/image-digest-exempt"
+
+func requireImageDigest() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("SPRITZ_REQUIRE_IMAGE_DIGEST")), "true")
+}
+
+// validateImagePinning enforces SPRITZ_REQUIRE_IMAGE_DIGEST. The CRD still
+// accepts tags; this is a cluster policy applied at reconcile time.
+func validateImagePinning(spritz *spritzv1.Spritz) error {
+	if !requireImageDigest() {
+		return nil
+	}
+	if strings.EqualFold(strings.TrimSpace(spritz.Annotations[imageDigestExemptAnnotationKey]), "true") {
+		return nil
+	}
+	if !strings.Contains(spritz.Spec.Image, "@sha256:") {
+		return fmt.Errorf("spec.image must be pinned by digest (image@sha256:...): %s", spritz.Spec.Image)
+	}
+	if spritz.Spec.Repo != nil && !initImagePinned(spritz.Spec.Repo.InitImage) {
+		return fmt.Errorf("spec.repo.initImage must be pinned by digest (image@sha256:...): %s", spritz.Spec.Repo.InitImage)
+	}
+	for i, repo := range spritz.Spec.Repos {
+		if !initImagePinned(repo.InitImage) {
+			return fmt.Errorf("spec.repos[%d].initImage must be pinned by digest (image@sha256:...): %s", i, repo.InitImage)
+		}
+	}
+	return nil
+}
+
+// initImagePinned reports whether a per-repo init image override satisfies the
+// digest policy. Unset overrides use the operator default and are not checked.
+func initImagePinned(image string) bool {
+	image = strings.TrimSpace(image)
+	return image == "" || strings.Contains(image, "@sha256:")
+}
+
+// imagePullPolicy returns spec.imagePullPolicy, or a default that refreshes
+// mutable tags on every start and reuses cached digest-pinned images.
+func imagePullPolicy(spritz *spritzv1.Spritz) corev1.PullPolicy {
+	if spritz.Spec.ImagePullPolicy != "" {
+		return spritz.Spec.ImagePullPolicy
+	}
+	if strings.Contains(spritz.Spec.Image, "@sha256:") {
+		return corev1.PullIfNotPresent
+	}
+	return corev1.PullAlways
+}
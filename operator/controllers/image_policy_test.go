@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestRequireImageDigestBlocksUnpinnedImages(t *testing.T) {
+	t.Setenv("SPRITZ_REQUIRE_IMAGE_DIGEST", "true")
+	scheme := newControllerTestScheme(t)
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register networking scheme: %v", err)
+	}
+	if err := gatewayv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register gateway scheme: %v", err)
+	}
+	spritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidy-otter", Namespace: "spritz-test"},
+		Spec: spritzv1.SpritzSpec{
+			Image: "example.com/spritz:latest",
+			Owner: spritzv1.SpritzOwner{ID: "user-1"},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&spritzv1.Spritz{}).
+		WithObjects(spritz).
+		Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	if err := reconciler.reconcileResources(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcileResources returned error: %v", err)
+	}
+	if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "spritz-test", Name: "tidy-otter"}, &appsv1.Deployment{}); !errors.IsNotFound(err) {
+		t.Fatalf("expected no deployment for an unpinned image, got %v", err)
+	}
+	if _, err := reconciler.reconcileStatus(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcileStatus returned error: %v", err)
+	}
+	stored := &spritzv1.Spritz{}
+	if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "spritz-test", Name: "tidy-otter"}, stored); err != nil {
+		t.Fatalf("get spritz: %v", err)
+	}
+	if stored.Status.Phase != "Error" {
+		t.Fatalf("expected Error phase, got %q", stored.Status.Phase)
+	}
+	if !hasConditionReason(stored.Status.Conditions, "ImageNotPinned") {
+		t.Fatalf("expected ImageNotPinned reason, got %#v", stored.Status.Conditions)
+	}
+}
+
+func TestValidateImagePinning(t *testing.T) {
+	spritz := &spritzv1.Spritz{Spec: spritzv1.SpritzSpec{Image: "example.com/spritz:latest"}}
+	if err := validateImagePinning(spritz); err != nil {
+		t.Fatalf("expected policy to be off by default, got %v", err)
+	}
+
+	t.Setenv("SPRITZ_REQUIRE_IMAGE_DIGEST", "true")
+	if err := validateImagePinning(spritz); err == nil {
+		t.Fatal("expected tag-only image to be rejected")
+	}
+	spritz.Spec.Image = "example.com/spritz:latest@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	if err := validateImagePinning(spritz); err != nil {
+		t.Fatalf("expected digest-pinned image to pass, got %v", err)
+	}
+	spritz.Spec.Repos = []spritzv1.SpritzRepo{
+		{URL: "https://example.com/example-org/app.git"},
+		{URL: "https://example.com/example-org/assets.git", InitImage: "example.com/git-lfs:latest"},
+	}
+	if err := validateImagePinning(spritz); err == nil || !strings.Contains(err.Error(), "spec.repos[1].initImage") {
+		t.Fatalf("expected tag-only repo init image to be rejected, got %v", err)
+	}
+	spritz.Spec.Repos[1].InitImage = "example.com/git-lfs@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	if err := validateImagePinning(spritz); err != nil {
+		t.Fatalf("expected digest-pinned repo init image to pass, got %v", err)
+	}
+	spritz.Spec.Image = "example.com/spritz:latest"
+	spritz.Annotations = map[string]string{imageDigestExemptAnnotationKey: "true"}
+	if err := validateImagePinning(spritz); err != nil {
+		t.Fatalf("expected exempt spritz to pass, got %v", err)
+	}
+}
+
+func hasConditionReason(conditions []metav1.Condition, reason string) bool {
+	for _, condition := range conditions {
+		if condition.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReconcileDeploymentSetsImagePullPolicy(t *testing.T) {
+	const digest = "@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	for _, tc := range []struct {
+		image  string
+		policy corev1.PullPolicy
+		want   corev1.PullPolicy
+	}{
+		{image: "example.com/spritz:1.2", want: corev1.PullAlways},
+		{image: "example.com/spritz" + digest, want: corev1.PullIfNotPresent},
+		{image: "example.com/spritz:1.2", policy: corev1.PullNever, want: corev1.PullNever},
+	} {
+		spritz := newPodSpecTestSpritz()
+		spritz.Spec.Image = tc.image
+		spritz.Spec.ImagePullPolicy = tc.policy
+		container := reconcileTestDeployment(t, spritz).Spec.Template.Spec.Containers[0]
+		if container.ImagePullPolicy != tc.want {
+			t.Fatalf("image %s policy %q: expected %s, got %s", tc.image, tc.policy, tc.want, container.ImagePullPolicy)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+const (
+	initScriptContainerName = "init-script"
+	initScriptSucceeded     = "InitScriptSucceeded"
+	// maxInitScriptBytes matches the CRD MaxLength so specs admitted before the
+	// CRD was updated are rejected the same way.
+	maxInitScriptBytes = 16384
+)
+
+func validateInitScript(script string) error {
+	if len(script) > maxInitScriptBytes {
+		return fmt.Errorf("initScript must be at most %d bytes, got %d", maxInitScriptBytes, len(script))
+	}
+	return nil
+}
+
+// initScriptImage returns SPRITZ_INIT_SCRIPT_IMAGE, falling back to the
+// workload image so the script sees the same tools as the shell.
+func initScriptImage(spritz *spritzv1.Spritz) string {
+	if value := strings.TrimSpace(os.Getenv("SPRITZ_INIT_SCRIPT_IMAGE")); value != "" {
+		return value
+	}
+	return spritz.Spec.Image
+}
+
+// buildInitScriptContainer runs spec.initScript with the workload's env and
+// mounts, once per pod start. It returns nil when no script is set. On failure the last lines of
+// its output become the termination message that status reporting reads.
+func buildInitScriptContainer(
+	spritz *spritzv1.Spritz,
+	env []corev1.EnvVar,
+	volumeMounts []corev1.VolumeMount,
+	resources corev1.ResourceRequirements,
+) (*corev1.Container, error) {
+	script := spritz.Spec.InitScript
+	if strings.TrimSpace(script) == "" {
+		return nil, nil
+	}
+	if err := validateInitScript(script); err != nil {
+		return nil, err
+	}
+	return &corev1.Container{
+		Name:                     initScriptContainerName,
+		Image:                    initScriptImage(spritz),
+		Command:                  []string{"/bin/sh", "-ec", script},
+		Env:                      append([]corev1.EnvVar{}, env...),
+		Resources:                resources,
+		VolumeMounts:             append([]corev1.VolumeMount{}, volumeMounts...),
+		WorkingDir:               "/workspace",
+		TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}, nil
+}
+
+// observeInitScript sets InitScriptSucceeded from the newest pod's init script
+// container. The condition is removed when the spec has no script and left
+// unchanged while no pod exists.
+func (r *SpritzReconciler) observeInitScript(
+	ctx context.Context,
+	spritz *spritzv1.Spritz,
+	deploy *appsv1.Deployment,
+) error {
+	if strings.TrimSpace(spritz.Spec.InitScript) == "" {
+		meta.RemoveStatusCondition(&spritz.Status.Conditions, initScriptSucceeded)
+		return nil
+	}
+	pod, err := r.newestSpritzPod(ctx, spritz, deploy)
+	if err != nil || pod == nil {
+		return err
+	}
+	setInitScriptCondition(&spritz.Status.Conditions, spritz.Generation, pod)
+	return nil
+}
+
+func setInitScriptCondition(conditions *[]metav1.Condition, generation int64, pod *corev1.Pod) {
+	condition := metav1.Condition{
+		Type:               initScriptSucceeded,
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: generation,
+		Reason:             "Pending",
+		Message:            "Init script has not finished.",
+	}
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name != initScriptContainerName {
+			continue
+		}
+		// A failed init container is restarted, so while the next attempt
+		// waits or runs the failure is only in the last termination state.
+		terminated := status.State.Terminated
+		if terminated == nil {
+			terminated = status.LastTerminationState.Terminated
+		}
+		switch {
+		case terminated == nil:
+		case terminated.ExitCode == 0:
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "Succeeded"
+			condition.Message = "Init script completed."
+		default:
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "Failed"
+			condition.Message = initScriptFailureMessage(terminated, status.RestartCount)
+		}
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
+
+func initScriptFailureMessage(terminated *corev1.ContainerStateTerminated, restarts int32) string {
+	message := fmt.Sprintf("Init script exited with code %d", terminated.ExitCode)
+	if restarts > 0 {
+		message += fmt.Sprintf(" after %d restarts", restarts)
+	}
+	if output := strings.TrimSpace(terminated.Message); output != "" {
+		message += ": " + output
+	}
+	return message
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestReconcileDeploymentRunsInitScriptAfterRepoInit(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.Repo = &spritzv1.SpritzRepo{URL: "https://example.com/example-org/app.git"}
+	spritz.Spec.Env = []corev1.EnvVar{{Name: "MODEL", Value: "small"}}
+	spritz.Spec.InitScript = "curl -fsSL https://example.com/model.bin -o ~/model.bin"
+
+	podSpec := reconcileTestDeployment(t, spritz).Spec.Template.Spec
+	if len(podSpec.InitContainers) < 2 {
+		t.Fatalf("expected repo init and init script containers, got %d", len(podSpec.InitContainers))
+	}
+	container := podSpec.InitContainers[len(podSpec.InitContainers)-1]
+	if container.Name != initScriptContainerName {
+		t.Fatalf("expected init script to run last, got %q", container.Name)
+	}
+	if container.Image != spritz.Spec.Image {
+		t.Fatalf("expected init script to default to the workload image, got %q", container.Image)
+	}
+	if got := container.Command; len(got) != 3 || got[2] != spritz.Spec.InitScript {
+		t.Fatalf("unexpected init script command %#v", got)
+	}
+	if container.TerminationMessagePolicy != corev1.TerminationMessageFallbackToLogsOnError {
+		t.Fatalf("expected log fallback termination messages, got %q", container.TerminationMessagePolicy)
+	}
+	mounts := map[string]bool{}
+	for _, mount := range container.VolumeMounts {
+		mounts[mount.Name] = true
+	}
+	if !mounts["workspace"] || !mounts["home"] {
+		t.Fatalf("expected workspace and home mounts, got %#v", container.VolumeMounts)
+	}
+	foundEnv := false
+	for _, env := range container.Env {
+		foundEnv = foundEnv || env.Name == "MODEL" && env.Value == "small"
+	}
+	if !foundEnv {
+		t.Fatal("expected init script to receive spec.env")
+	}
+}
+
+func TestInitScriptImageOverride(t *testing.T) {
+	t.Setenv("SPRITZ_INIT_SCRIPT_IMAGE", "example.com/bootstrap:1")
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.InitScript = "true"
+
+	container, err := buildInitScriptContainer(spritz, nil, nil, corev1.ResourceRequirements{})
+	if err != nil {
+		t.Fatalf("buildInitScriptContainer returned error: %v", err)
+	}
+	if container.Image != "example.com/bootstrap:1" {
+		t.Fatalf("expected configured image, got %q", container.Image)
+	}
+}
+
+func TestBuildInitScriptContainerRejectsOversizedScript(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.InitScript = strings.Repeat("x", maxInitScriptBytes+1)
+	if _, err := buildInitScriptContainer(spritz, nil, nil, corev1.ResourceRequirements{}); err == nil {
+		t.Fatal("expected oversized init script to be rejected")
+	}
+}
+
+func TestSetInitScriptConditionReportsFailureWhileRetrying(t *testing.T) {
+	pod := &corev1.Pod{Status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{{
+		Name:         initScriptContainerName,
+		RestartCount: 2,
+		State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ExitCode: 22,
+			Message:  "curl: (22) The requested URL returned error: 404",
+		}},
+	}}}}
+
+	var conditions []metav1.Condition
+	setInitScriptCondition(&conditions, 3, pod)
+	condition := meta.FindStatusCondition(conditions, initScriptSucceeded)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "Failed" {
+		t.Fatalf("expected failed init script condition, got %#v", condition)
+	}
+	if !strings.Contains(condition.Message, "code 22") || !strings.Contains(condition.Message, "404") {
+		t.Fatalf("expected exit code and output in message, got %q", condition.Message)
+	}
+
+	pod.Status.InitContainerStatuses[0].State = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}
+	setInitScriptCondition(&conditions, 3, pod)
+	if condition := meta.FindStatusCondition(conditions, initScriptSucceeded); condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected succeeded condition, got %#v", condition)
+	}
+}
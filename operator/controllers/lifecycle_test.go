@@ -76,3 +76,37 @@ func TestComputeSpritzLifecycleWindowReturnsNoReasonWithoutLifetimes(t *testing.
 		t.Fatalf("expected empty lifecycle reason, got %q", reason)
 	}
 }
+
+func TestCapLifecycleAtMaxLifetime(t *testing.T) {
+	createdAt := time.Date(2026, 3, 11, 9, 0, 0, 0, time.UTC)
+	spritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(createdAt)},
+	}
+	ceiling := createdAt.Add(720 * time.Hour)
+
+	maxExpiresAt, effectiveExpiresAt, reason := capLifecycleAtMaxLifetime(spritz, 720*time.Hour, nil, nil, "")
+	if reason != lifecycleReasonMaxLifetime {
+		t.Fatalf("expected max lifetime reason without a ttl, got %q", reason)
+	}
+	if maxExpiresAt == nil || !maxExpiresAt.Time.Equal(ceiling) || effectiveExpiresAt == nil || !effectiveExpiresAt.Time.Equal(ceiling) {
+		t.Fatalf("expected expiry at the ceiling, got max=%v effective=%v", maxExpiresAt, effectiveExpiresAt)
+	}
+
+	ttlExpiry := metav1.NewTime(createdAt.Add(8 * time.Hour))
+	maxExpiresAt, effectiveExpiresAt, reason = capLifecycleAtMaxLifetime(spritz, 720*time.Hour, &ttlExpiry, &ttlExpiry, spritzv1.LifecycleReasonTTL)
+	if reason != spritzv1.LifecycleReasonTTL || !maxExpiresAt.Time.Equal(ttlExpiry.Time) || !effectiveExpiresAt.Time.Equal(ttlExpiry.Time) {
+		t.Fatalf("expected a shorter ttl to win, got max=%v effective=%v reason=%q", maxExpiresAt, effectiveExpiresAt, reason)
+	}
+
+	longExpiry := metav1.NewTime(createdAt.Add(8760 * time.Hour))
+	_, effectiveExpiresAt, reason = capLifecycleAtMaxLifetime(spritz, 720*time.Hour, &longExpiry, &longExpiry, spritzv1.LifecycleReasonTTL)
+	if reason != lifecycleReasonMaxLifetime || !effectiveExpiresAt.Time.Equal(ceiling) {
+		t.Fatalf("expected a longer ttl to be capped, got effective=%v reason=%q", effectiveExpiresAt, reason)
+	}
+
+	spritz.Annotations = map[string]string{maxLifetimeExemptAnnotationKey: "true"}
+	_, effectiveExpiresAt, _ = capLifecycleAtMaxLifetime(spritz, 720*time.Hour, nil, nil, "")
+	if effectiveExpiresAt != nil {
+		t.Fatalf("expected exempt spritz to keep no expiry, got %v", effectiveExpiresAt)
+	}
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The operator records which label and annotation keys it applied to an owned
+// object, so a key removed from the spritz spec is also removed from the
+// object on the next reconcile. Keys set by other controllers or by hand are
+// never touched.
+const (
+	managedLabelsAnnotationKey         = "spritz.sh/managed-labels"
+	managedAnnotationsAnnotationKey    = "spritz.sh/managed-annotations"
+	managedPodAnnotationsAnnotationKey = "spritz.sh/managed-pod-annotations"
+)
+
+// applyManagedKeys merges desired into current and drops every key listed in
+// the previous record that is no longer desired.
+func applyManagedKeys(current map[string]string, desired map[string]string, previousRecord string) map[string]string {
+	out := mergeMaps(current, desired)
+	for _, key := range parseManagedKeys(previousRecord) {
+		if _, ok := desired[key]; !ok {
+			delete(out, key)
+		}
+	}
+	return out
+}
+
+// managedKeysRecord returns the sorted, comma-separated keys of desired.
+// Label and annotation keys cannot contain commas.
+func managedKeysRecord(desired map[string]string) string {
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func parseManagedKeys(record string) []string {
+	return parseCSV(record)
+}
+
+// setManagedKeysRecord stores the record of desired under recordKey, and
+// removes the record when nothing is managed.
+func setManagedKeysRecord(annotations map[string]string, recordKey string, desired map[string]string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if len(desired) == 0 {
+		delete(annotations, recordKey)
+		return annotations
+	}
+	annotations[recordKey] = managedKeysRecord(desired)
+	return annotations
+}
+
+// applyManagedMetadata reconciles the labels and annotations of an owned
+// object with prune semantics.
+func applyManagedMetadata(meta *metav1.ObjectMeta, labels map[string]string, annotations map[string]string) {
+	previousLabels := meta.Annotations[managedLabelsAnnotationKey]
+	previousAnnotations := meta.Annotations[managedAnnotationsAnnotationKey]
+	meta.Labels = applyManagedKeys(meta.Labels, labels, previousLabels)
+	meta.Annotations = applyManagedKeys(meta.Annotations, annotations, previousAnnotations)
+	meta.Annotations = setManagedKeysRecord(meta.Annotations, managedLabelsAnnotationKey, labels)
+	meta.Annotations = setManagedKeysRecord(meta.Annotations, managedAnnotationsAnnotationKey, annotations)
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileDeploymentPrunesRemovedSpecMetadata(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.Labels = map[string]string{"example.com/team": "data", "example.com/tier": "gold"}
+	spritz.Spec.Annotations = map[string]string{"example.com/note": "keep", "example.com/ticket": "EX-1"}
+	scheme := newControllerTestScheme(t)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+	ctx := context.Background()
+	key := client.ObjectKey{Name: spritz.Name, Namespace: spritz.Namespace}
+
+	if err := reconciler.reconcileDeployment(ctx, spritz); err != nil {
+		t.Fatalf("reconcileDeployment returned error: %v", err)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := k8sClient.Get(ctx, key, deployment); err != nil {
+		t.Fatalf("failed to load deployment: %v", err)
+	}
+	deployment.Labels["example.com/external"] = "true"
+	deployment.Annotations["example.com/external"] = "true"
+	deployment.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"] = "2026-10-17T00:00:00Z"
+	if err := k8sClient.Update(ctx, deployment); err != nil {
+		t.Fatalf("failed to update deployment: %v", err)
+	}
+
+	spritz.Spec.Labels = map[string]string{"example.com/team": "data"}
+	spritz.Spec.Annotations = map[string]string{"example.com/note": "keep"}
+	if err := reconciler.reconcileDeployment(ctx, spritz); err != nil {
+		t.Fatalf("reconcileDeployment returned error: %v", err)
+	}
+	deployment = &appsv1.Deployment{}
+	if err := k8sClient.Get(ctx, key, deployment); err != nil {
+		t.Fatalf("failed to load deployment: %v", err)
+	}
+
+	if _, ok := deployment.Labels["example.com/tier"]; ok {
+		t.Fatalf("expected removed label to be pruned, got %#v", deployment.Labels)
+	}
+	if deployment.Labels["example.com/team"] != "data" || deployment.Labels["example.com/external"] != "true" {
+		t.Fatalf("expected kept and foreign labels to remain, got %#v", deployment.Labels)
+	}
+	if _, ok := deployment.Annotations["example.com/ticket"]; ok {
+		t.Fatalf("expected removed annotation to be pruned, got %#v", deployment.Annotations)
+	}
+	if deployment.Annotations["example.com/external"] != "true" {
+		t.Fatalf("expected foreign annotation to remain, got %#v", deployment.Annotations)
+	}
+	template := deployment.Spec.Template.Annotations
+	if _, ok := template["example.com/ticket"]; ok {
+		t.Fatalf("expected removed pod annotation to be pruned, got %#v", template)
+	}
+	if template["example.com/note"] != "keep" || template["kubectl.kubernetes.io/restartedAt"] == "" {
+		t.Fatalf("expected kept and foreign pod annotations to remain, got %#v", template)
+	}
+}
+
+func TestReconcileServicePrunesRemovedSpecLabels(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.Labels = map[string]string{"example.com/tier": "gold"}
+	scheme := newControllerTestScheme(t)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+	ctx := context.Background()
+
+	if err := reconciler.reconcileService(ctx, spritz); err != nil {
+		t.Fatalf("reconcileService returned error: %v", err)
+	}
+	spritz.Spec.Labels = nil
+	if err := reconciler.reconcileService(ctx, spritz); err != nil {
+		t.Fatalf("reconcileService returned error: %v", err)
+	}
+	svc := &corev1.Service{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: spritz.Name, Namespace: spritz.Namespace}, svc); err != nil {
+		t.Fatalf("failed to load service: %v", err)
+	}
+	if _, ok := svc.Labels["example.com/tier"]; ok {
+		t.Fatalf("expected removed label to be pruned, got %#v", svc.Labels)
+	}
+}
+
+func TestApplyManagedMetadataWithoutRecordKeepsExistingKeys(t *testing.T) {
+	meta := metav1.ObjectMeta{Labels: map[string]string{"example.com/legacy": "true"}}
+	applyManagedMetadata(&meta, map[string]string{"example.com/team": "data"}, nil)
+
+	if meta.Labels["example.com/legacy"] != "true" || meta.Labels["example.com/team"] != "data" {
+		t.Fatalf("expected unrecorded keys to be kept, got %#v", meta.Labels)
+	}
+	if meta.Annotations[managedLabelsAnnotationKey] != "example.com/team" {
+		t.Fatalf("unexpected labels record %q", meta.Annotations[managedLabelsAnnotationKey])
+	}
+	if _, ok := meta.Annotations[managedAnnotationsAnnotationKey]; ok {
+		t.Fatal("expected no annotations record when no annotations are managed")
+	}
+}
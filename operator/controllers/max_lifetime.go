@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+const (
+	// maxLifetimeExemptAnnotationKey lets admins keep a spritz past
+	// SPRITZ_MAX_LIFETIME.
+	maxLifetimeExemptAnnotationKey = "spritz.sh/max-lifetime-exempt"
+	lifecycleReasonMaxLifetime     = "MaxLifetime"
+)
+
+// maxLifetime reads SPRITZ_MAX_LIFETIME, a cluster-wide cap on how long any
+// spritz may live. Zero disables the cap.
+func maxLifetime() time.Duration {
+	return parseDurationEnv("SPRITZ_MAX_LIFETIME", 0)
+}
+
+// capLifecycleAtMaxLifetime lowers the max and effective expiry to
+// CreationTimestamp + limit when that comes first, including for spritzes
+// without any TTL.
+func capLifecycleAtMaxLifetime(
+	spritz *spritzv1.Spritz,
+	limit time.Duration,
+	maxExpiresAt, effectiveExpiresAt *metav1.Time,
+	reason string,
+) (*metav1.Time, *metav1.Time, string) {
+	if limit <= 0 || strings.EqualFold(strings.TrimSpace(spritz.Annotations[maxLifetimeExemptAnnotationKey]), "true") {
+		return maxExpiresAt, effectiveExpiresAt, reason
+	}
+	ceiling := metav1.NewTime(spritz.CreationTimestamp.Add(limit))
+	if maxExpiresAt == nil || ceiling.Before(maxExpiresAt) {
+		maxExpiresAt = &ceiling
+	}
+	if effectiveExpiresAt == nil || ceiling.Before(effectiveExpiresAt) {
+		effectiveExpiresAt = &ceiling
+		reason = lifecycleReasonMaxLifetime
+	}
+	return maxExpiresAt, effectiveExpiresAt, reason
+}
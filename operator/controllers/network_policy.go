@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+const (
+	defaultNetworkPolicyAPIPodLabels      = "app.kubernetes.io/name=spritz-api"
+	defaultNetworkPolicyOperatorPodLabels = "app.kubernetes.io/name=spritz-operator"
+)
+
+// networkPolicyEnabled reports whether every spritz gets its own default-deny
+// NetworkPolicy (SPRITZ_CREATE_NETWORK_POLICY).
+func networkPolicyEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("SPRITZ_CREATE_NETWORK_POLICY")), "true")
+}
+
+// networkPolicySettings are the peers and egress rules a workspace pod is
+// allowed besides DNS.
+type networkPolicySettings struct {
+	api      netv1.NetworkPolicyPeer
+	operator netv1.NetworkPolicyPeer
+	gateways []netv1.NetworkPolicyPeer
+	egress   []netv1.NetworkPolicyEgressRule
+}
+
+// loadNetworkPolicySettings reads the API and operator pods (namespace plus
+// pod labels; an empty namespace means the spritz namespace), the namespaces
+// of ingress controllers or gateways that route to workspaces, and extra
+// egress rules as a JSON list of Kubernetes NetworkPolicyEgressRule.
+//
+// Gateway namespaces are required: an unset list would silently cut off
+// browser traffic to every workspace. "none" allows no gateway traffic.
+func loadNetworkPolicySettings() (networkPolicySettings, error) {
+	api, err := networkPolicyPeerFromEnv("SPRITZ_NETWORK_POLICY_API_NAMESPACE", "SPRITZ_NETWORK_POLICY_API_POD_LABELS", defaultNetworkPolicyAPIPodLabels)
+	if err != nil {
+		return networkPolicySettings{}, err
+	}
+	operator, err := networkPolicyPeerFromEnv("SPRITZ_NETWORK_POLICY_OPERATOR_NAMESPACE", "SPRITZ_NETWORK_POLICY_OPERATOR_POD_LABELS", defaultNetworkPolicyOperatorPodLabels)
+	if err != nil {
+		return networkPolicySettings{}, err
+	}
+	settings := networkPolicySettings{api: api, operator: operator}
+	gatewayNamespaces := strings.TrimSpace(os.Getenv("SPRITZ_NETWORK_POLICY_GATEWAY_NAMESPACES"))
+	if gatewayNamespaces == "" {
+		return networkPolicySettings{}, fmt.Errorf("SPRITZ_NETWORK_POLICY_GATEWAY_NAMESPACES is required; set it to none to block gateway traffic")
+	}
+	if !strings.EqualFold(gatewayNamespaces, "none") {
+		for _, namespace := range parseCSV(gatewayNamespaces) {
+			settings.gateways = append(settings.gateways, netv1.NetworkPolicyPeer{NamespaceSelector: namespaceNameSelector(namespace)})
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SPRITZ_NETWORK_POLICY_EGRESS")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &settings.egress); err != nil {
+			return networkPolicySettings{}, fmt.Errorf("invalid SPRITZ_NETWORK_POLICY_EGRESS: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+func networkPolicyPeerFromEnv(namespaceKey, labelsKey, defaultLabels string) (netv1.NetworkPolicyPeer, error) {
+	rawLabels := strings.TrimSpace(os.Getenv(labelsKey))
+	if rawLabels == "" {
+		rawLabels = defaultLabels
+	}
+	podLabels, err := parseNodeSelector(rawLabels)
+	if err != nil {
+		return netv1.NetworkPolicyPeer{}, fmt.Errorf("invalid %s: %w", labelsKey, err)
+	}
+	peer := netv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: podLabels}}
+	if namespace := strings.TrimSpace(os.Getenv(namespaceKey)); namespace != "" {
+		peer.NamespaceSelector = namespaceNameSelector(namespace)
+	}
+	return peer, nil
+}
+
+func namespaceNameSelector(namespace string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": namespace}}
+}
+
+// buildNetworkPolicySpec denies all traffic to and from the workspace pod
+// except:
+//   - ingress from the API (instance proxy, ACP, SSH) on any port
+//   - ingress from the operator on the ACP port
+//   - ingress from gateway namespaces on any port
+//   - egress to DNS and the API, plus the configured egress rules
+func buildNetworkPolicySpec(spritz *spritzv1.Spritz, settings networkPolicySettings) netv1.NetworkPolicySpec {
+	udp := corev1.ProtocolUDP
+	tcp := corev1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+	acpPort := intstr.FromInt(int(spritzv1.DefaultACPPort))
+
+	ingress := []netv1.NetworkPolicyIngressRule{
+		{From: []netv1.NetworkPolicyPeer{settings.api}},
+		{
+			From:  []netv1.NetworkPolicyPeer{settings.operator},
+			Ports: []netv1.NetworkPolicyPort{{Protocol: &tcp, Port: &acpPort}},
+		},
+	}
+	if len(settings.gateways) > 0 {
+		ingress = append(ingress, netv1.NetworkPolicyIngressRule{From: settings.gateways})
+	}
+
+	egress := []netv1.NetworkPolicyEgressRule{
+		{Ports: []netv1.NetworkPolicyPort{{Protocol: &udp, Port: &dnsPort}, {Protocol: &tcp, Port: &dnsPort}}},
+		{To: []netv1.NetworkPolicyPeer{settings.api}},
+	}
+	for i := range settings.egress {
+		egress = append(egress, *settings.egress[i].DeepCopy())
+	}
+
+	return netv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{MatchLabels: deploymentSelectorLabels(spritz)},
+		PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeIngress, netv1.PolicyTypeEgress},
+		Ingress:     ingress,
+		Egress:      egress,
+	}
+}
+
+func (r *SpritzReconciler) reconcileNetworkPolicy(ctx context.Context, spritz *spritzv1.Spritz) error {
+	policy := &netv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: spritz.Name, Namespace: spritz.Namespace}}
+	if !networkPolicyEnabled() {
+		// Read from the cache so a disabled setting costs no API calls, and
+		// leave policies with the spritz's name that it does not control.
+		if err := r.Get(ctx, client.ObjectKeyFromObject(policy), policy); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(policy, spritz) {
+			return nil
+		}
+		if err := r.Delete(ctx, policy); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	settings, err := loadNetworkPolicySettings()
+	if err != nil {
+		return err
+	}
+
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, policy, func() error {
+		if err := controllerutil.SetControllerReference(spritz, policy, r.Scheme); err != nil {
+			return err
+		}
+
+		labels := baseLabels(spritz)
+		annotations := baseAnnotations(spritz)
+		applyManagedMetadata(&policy.ObjectMeta, mergeMaps(labels, spritz.Spec.Labels), mergeMaps(spritz.Spec.Annotations, annotations))
+		policy.Spec = buildNetworkPolicySpec(spritz, settings)
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+	logOwnedResourceDrift(ctx, spritz, "NetworkPolicy", result)
+	return nil
+}
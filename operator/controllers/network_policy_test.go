@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	netv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileNetworkPolicyFollowsOperatorSetting(t *testing.T) {
+	scheme := newControllerTestScheme(t)
+	spritz := newPodSpecTestSpritz()
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+	key := client.ObjectKey{Name: spritz.Name, Namespace: spritz.Namespace}
+
+	t.Setenv("SPRITZ_CREATE_NETWORK_POLICY", "true")
+	t.Setenv("SPRITZ_NETWORK_POLICY_API_NAMESPACE", "spritz-system")
+	t.Setenv("SPRITZ_NETWORK_POLICY_GATEWAY_NAMESPACES", "ingress-nginx")
+	t.Setenv("SPRITZ_NETWORK_POLICY_EGRESS", `[{"to":[{"ipBlock":{"cidr":"0.0.0.0/0","except":["10.0.0.0/8"]}}],"ports":[{"protocol":"TCP","port":443}]}]`)
+	if err := reconciler.reconcileNetworkPolicy(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcileNetworkPolicy returned error: %v", err)
+	}
+	policy := &netv1.NetworkPolicy{}
+	if err := k8sClient.Get(context.Background(), key, policy); err != nil {
+		t.Fatalf("failed to load network policy: %v", err)
+	}
+	if policy.Spec.PodSelector.MatchLabels["spritz.sh/name"] != spritz.Name {
+		t.Fatalf("unexpected pod selector: %#v", policy.Spec.PodSelector)
+	}
+	if len(policy.Spec.PolicyTypes) != 2 {
+		t.Fatalf("expected ingress and egress to be denied by default, got %v", policy.Spec.PolicyTypes)
+	}
+	if len(policy.OwnerReferences) != 1 || policy.OwnerReferences[0].Name != spritz.Name {
+		t.Fatalf("expected network policy to be owned by the spritz, got %#v", policy.OwnerReferences)
+	}
+
+	api := policy.Spec.Ingress[0].From[0]
+	if api.NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"] != "spritz-system" || api.PodSelector.MatchLabels["app.kubernetes.io/name"] != "spritz-api" {
+		t.Fatalf("unexpected API peer: %#v", api)
+	}
+	operator := policy.Spec.Ingress[1]
+	if operator.From[0].NamespaceSelector != nil || len(operator.Ports) != 1 || operator.Ports[0].Port.IntValue() != 2529 {
+		t.Fatalf("expected same-namespace operator peer on the ACP port, got %#v", operator)
+	}
+	if gateway := policy.Spec.Ingress[2].From[0]; gateway.NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"] != "ingress-nginx" {
+		t.Fatalf("unexpected gateway peer: %#v", gateway)
+	}
+	if len(policy.Spec.Egress) != 3 {
+		t.Fatalf("expected DNS, API and one configured egress rule, got %#v", policy.Spec.Egress)
+	}
+	if dns := policy.Spec.Egress[0]; len(dns.To) != 0 || dns.Ports[0].Port.IntValue() != 53 {
+		t.Fatalf("unexpected DNS egress rule: %#v", dns)
+	}
+	if extra := policy.Spec.Egress[2]; extra.To[0].IPBlock == nil || extra.To[0].IPBlock.CIDR != "0.0.0.0/0" {
+		t.Fatalf("unexpected configured egress rule: %#v", extra)
+	}
+
+	t.Setenv("SPRITZ_CREATE_NETWORK_POLICY", "false")
+	if err := reconciler.reconcileNetworkPolicy(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcileNetworkPolicy returned error: %v", err)
+	}
+	if err := k8sClient.Get(context.Background(), key, &netv1.NetworkPolicy{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected network policy to be deleted when disabled, got %v", err)
+	}
+}
+
+func TestReconcileNetworkPolicyKeepsUnownedPolicyWhenDisabled(t *testing.T) {
+	scheme := newControllerTestScheme(t)
+	spritz := newPodSpecTestSpritz()
+	unowned := &netv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: spritz.Name, Namespace: spritz.Namespace}}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz, unowned).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	t.Setenv("SPRITZ_CREATE_NETWORK_POLICY", "false")
+	if err := reconciler.reconcileNetworkPolicy(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcileNetworkPolicy returned error: %v", err)
+	}
+	if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(unowned), &netv1.NetworkPolicy{}); err != nil {
+		t.Fatalf("expected policy not controlled by the spritz to be kept, got %v", err)
+	}
+}
+
+func TestLoadNetworkPolicySettingsRequiresGatewayNamespaces(t *testing.T) {
+	t.Setenv("SPRITZ_NETWORK_POLICY_GATEWAY_NAMESPACES", "")
+	if _, err := loadNetworkPolicySettings(); err == nil {
+		t.Fatal("expected an error when gateway namespaces are unset")
+	}
+	t.Setenv("SPRITZ_NETWORK_POLICY_GATEWAY_NAMESPACES", "none")
+	settings, err := loadNetworkPolicySettings()
+	if err != nil {
+		t.Fatalf("expected none to be accepted, got %v", err)
+	}
+	if len(settings.gateways) != 0 {
+		t.Fatalf("expected no gateway peers for none, got %#v", settings.gateways)
+	}
+}
+
+func TestLoadNetworkPolicySettingsRejectsInvalidEgress(t *testing.T) {
+	t.Setenv("SPRITZ_NETWORK_POLICY_GATEWAY_NAMESPACES", "ingress-nginx")
+	t.Setenv("SPRITZ_NETWORK_POLICY_EGRESS", `{"to":[]}`)
+	if _, err := loadNetworkPolicySettings(); err == nil {
+		t.Fatal("expected an error for egress rules that are not a JSON list")
+	}
+}
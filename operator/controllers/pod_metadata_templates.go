@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// podMetadataTemplates are extra pod template labels and annotations, such as
+// cost-allocation keys, derived from the spritz owner and name.
+type podMetadataTemplates struct {
+	labels      map[string]string
+	annotations map[string]string
+}
+
+func loadPodMetadataTemplates() (podMetadataTemplates, error) {
+	labels, err := parsePodMetadataTemplate("SPRITZ_POD_LABEL_TEMPLATE", true)
+	if err != nil {
+		return podMetadataTemplates{}, err
+	}
+	annotations, err := parsePodMetadataTemplate("SPRITZ_POD_ANNOTATION_TEMPLATE", false)
+	if err != nil {
+		return podMetadataTemplates{}, err
+	}
+	return podMetadataTemplates{labels: labels, annotations: annotations}, nil
+}
+
+// parsePodMetadataTemplate reads a JSON object of key to value template from
+// key. JSON keeps values free to contain commas and `=`. Unknown tokens are
+// rejected so a typo fails reconciliation instead of producing a literal
+// value.
+func parsePodMetadataTemplate(key string, label bool) (map[string]string, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return nil, nil
+	}
+	var entries map[string]string
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	out := map[string]string{}
+	for name, value := range entries {
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if name == "" || value == "" {
+			return nil, fmt.Errorf("invalid %s entry %q: key and value are required", key, name)
+		}
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %s key %q: %s", key, name, strings.Join(errs, "; "))
+		}
+		if label && strings.HasPrefix(name, "spritz.sh/") {
+			return nil, fmt.Errorf("invalid %s key %q: spritz.sh/ labels are reserved", key, name)
+		}
+		var unknown string
+		spritzv1.ExpandTemplate(value, func(token string) (string, bool) {
+			if _, ok := podMetadataLookup(spritzv1.TemplateVars{}, "")(token); !ok && unknown == "" {
+				unknown = token
+			}
+			return "", false
+		})
+		if unknown != "" {
+			return nil, fmt.Errorf("invalid %s entry %q: unknown token {%s}", key, name, unknown)
+		}
+		out[name] = value
+	}
+	return out, nil
+}
+
+func (t podMetadataTemplates) podLabels(spritz *spritzv1.Spritz) map[string]string {
+	out := map[string]string{}
+	for key, template := range t.labels {
+		value, ok := expandPodMetadataTemplate(template, spritz)
+		if value = sanitizeLabelValue(value); ok && value != "" {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+func (t podMetadataTemplates) podAnnotations(spritz *spritzv1.Spritz) map[string]string {
+	out := map[string]string{}
+	for key, template := range t.annotations {
+		if value, ok := expandPodMetadataTemplate(template, spritz); ok && value != "" {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// podMetadataLookup resolves the spec template tokens plus {team}.
+func podMetadataLookup(vars spritzv1.TemplateVars, team string) func(string) (string, bool) {
+	return func(token string) (string, bool) {
+		if token == "team" {
+			return team, true
+		}
+		return vars.Lookup(token)
+	}
+}
+
+// expandPodMetadataTemplate fills in the template tokens. It reports false
+// when a token has no value, for example {team} on an owner without a team,
+// so the key is skipped rather than set to a partial value.
+func expandPodMetadataTemplate(template string, spritz *spritzv1.Spritz) (string, bool) {
+	vars := spritzv1.TemplateVarsFor(spritz)
+	vars.Owner = strings.TrimSpace(vars.Owner)
+	lookup := podMetadataLookup(vars, strings.TrimSpace(spritz.Spec.Owner.Team))
+	complete := true
+	expanded := spritzv1.ExpandTemplate(template, func(token string) (string, bool) {
+		value, ok := lookup(token)
+		if ok && value == "" {
+			complete = false
+		}
+		return value, ok
+	})
+	return expanded, complete
+}
+
+// sanitizeLabelValue maps an expanded value, such as an email owner id, onto
+// the label value charset.
+func sanitizeLabelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(value, "-")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	return strings.Trim(value, "-_.")
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReconcileDeploymentAppliesPodMetadataTemplates(t *testing.T) {
+	t.Setenv("SPRITZ_POD_LABEL_TEMPLATE", `{"cost.example.com/owner":"{owner}","cost.example.com/team":"{team}","spritz":"{name}"}`)
+	t.Setenv("SPRITZ_POD_ANNOTATION_TEMPLATE", `{"cost.example.com/owner-id":"{owner}","cost.example.com/center":"eng-{team}","cost.example.com/tags":"owner={owner},workspace={name}"}`)
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.Owner.ID = "user@example.com"
+	spritz.Spec.Owner.Team = ""
+	spritz.Spec.Labels = map[string]string{"spritz": "from-spec"}
+
+	template := reconcileTestDeployment(t, spritz).Spec.Template
+	if got := template.Labels["cost.example.com/owner"]; got != "user-example.com" {
+		t.Fatalf("expected sanitized owner label, got %q", got)
+	}
+	if _, ok := template.Labels["cost.example.com/team"]; ok {
+		t.Fatal("expected label with an empty token to be skipped")
+	}
+	if got := template.Labels["spritz"]; got != "from-spec" {
+		t.Fatalf("expected spec labels to win over templates, got %q", got)
+	}
+	if got := template.Annotations["cost.example.com/owner-id"]; got != "user@example.com" {
+		t.Fatalf("expected raw owner annotation, got %q", got)
+	}
+	if _, ok := template.Annotations["cost.example.com/center"]; ok {
+		t.Fatal("expected annotation with an empty token to be skipped")
+	}
+	if got, want := template.Annotations["cost.example.com/tags"], "owner=user@example.com,workspace="+spritz.Name; got != want {
+		t.Fatalf("expected annotation value with commas %q, got %q", want, got)
+	}
+}
+
+func TestParsePodMetadataTemplateRejectsInvalidEntries(t *testing.T) {
+	for raw, want := range map[string]string{
+		`{"cost.example.com/owner":"{user}"}`: "unknown token {user}",
+		`{"spritz.sh/owner":"{owner}"}`:       "reserved",
+		`{"cost.example.com/owner":""}`:       "required",
+		`{"bad key":"{owner}"}`:               "invalid",
+		`cost.example.com/owner={owner}`:      "invalid SPRITZ_POD_LABEL_TEMPLATE",
+	} {
+		t.Setenv("SPRITZ_POD_LABEL_TEMPLATE", raw)
+		if _, err := loadPodMetadataTemplates(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q to fail with %q, got %v", raw, want, err)
+		}
+	}
+}
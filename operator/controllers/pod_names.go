@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// observePodNames returns the names of the deployment's live pods, newest
+// first, so clients can open logs or a terminal on the first entry.
+func (r *SpritzReconciler) observePodNames(
+	ctx context.Context,
+	spritz *spritzv1.Spritz,
+	deploy *appsv1.Deployment,
+) ([]string, error) {
+	pods, err := r.liveSpritzPods(ctx, spritz, deploy)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		if !pods[i].CreationTimestamp.Equal(&pods[j].CreationTimestamp) {
+			return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp)
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names, nil
+}
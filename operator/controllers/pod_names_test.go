@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestObservePodNamesListsLivePodsNewestFirst(t *testing.T) {
+	scheme := newControllerTestScheme(t)
+	spritz := newPodSpecTestSpritz()
+	created := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	deleting := metav1.NewTime(created.Add(time.Hour))
+	newPod := func(name string, age time.Duration, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         spritz.Namespace,
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(created.Add(-age)),
+		}}
+	}
+	old := newPod("tidy-otter-old", time.Hour, deploymentSelectorLabels(spritz))
+	current := newPod("tidy-otter-new", time.Minute, deploymentSelectorLabels(spritz))
+	terminating := newPod("tidy-otter-gone", 0, deploymentSelectorLabels(spritz))
+	terminating.DeletionTimestamp = &deleting
+	terminating.Finalizers = []string{"example.com/hold"}
+	other := newPod("other-spritz", 0, map[string]string{"spritz.sh/name": "other"})
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz, old, current, terminating, other).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	names, err := reconciler.observePodNames(context.Background(), spritz, &appsv1.Deployment{})
+	if err != nil {
+		t.Fatalf("observePodNames returned error: %v", err)
+	}
+	if want := []string{"tidy-otter-new", "tidy-otter-old"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+}
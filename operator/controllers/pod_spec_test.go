@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func newPodSpecTestSpritz() *spritzv1.Spritz {
+	return &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidy-otter", Namespace: "spritz-test"},
+		Spec: spritzv1.SpritzSpec{
+			Image: "example.com/openclaw:latest",
+			Owner: spritzv1.SpritzOwner{ID: "user-1"},
+		},
+	}
+}
+
+func reconcileTestDeployment(t *testing.T, spritz *spritzv1.Spritz) *appsv1.Deployment {
+	t.Helper()
+	scheme := newControllerTestScheme(t)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	if err := reconciler.reconcileDeployment(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcileDeployment returned error: %v", err)
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := k8sClient.Get(
+		context.Background(),
+		client.ObjectKey{Name: spritz.Name, Namespace: spritz.Namespace},
+		deployment,
+	); err != nil {
+		t.Fatalf("failed to load deployment: %v", err)
+	}
+	return deployment
+}
+
+func TestReconcileDeploymentAppliesDNSSettings(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.DNSPolicy = corev1.DNSNone
+	spritz.Spec.DNSConfig = &corev1.PodDNSConfig{
+		Nameservers: []string{"10.0.0.10"},
+		Searches:    []string{"corp.example.com"},
+	}
+
+	deployment := reconcileTestDeployment(t, spritz)
+	podSpec := deployment.Spec.Template.Spec
+	if podSpec.DNSPolicy != corev1.DNSNone {
+		t.Fatalf("expected dnsPolicy None, got %q", podSpec.DNSPolicy)
+	}
+	if podSpec.DNSConfig == nil || len(podSpec.DNSConfig.Searches) != 1 || podSpec.DNSConfig.Searches[0] != "corp.example.com" {
+		t.Fatalf("unexpected dnsConfig: %#v", podSpec.DNSConfig)
+	}
+}
+
+func TestReconcileDeploymentAppliesTerminationGracePeriod(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	grace := int64(600)
+	spritz.Spec.TerminationGracePeriodSeconds = &grace
+
+	deployment := reconcileTestDeployment(t, spritz)
+	got := deployment.Spec.Template.Spec.TerminationGracePeriodSeconds
+	if got == nil || *got != 600 {
+		t.Fatalf("expected terminationGracePeriodSeconds 600, got %v", got)
+	}
+}
+
+func TestReconcilePodDisruptionBudgetFollowsOperatorSetting(t *testing.T) {
+	scheme := newControllerTestScheme(t)
+	spritz := newPodSpecTestSpritz()
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+	key := client.ObjectKey{Name: spritz.Name, Namespace: spritz.Namespace}
+
+	t.Setenv("SPRITZ_CREATE_PDB", "true")
+	if err := reconciler.reconcilePodDisruptionBudget(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcilePodDisruptionBudget returned error: %v", err)
+	}
+	pdb := &policyv1.PodDisruptionBudget{}
+	if err := k8sClient.Get(context.Background(), key, pdb); err != nil {
+		t.Fatalf("failed to load pdb: %v", err)
+	}
+	if pdb.Spec.MinAvailable == nil || pdb.Spec.MinAvailable.IntValue() != 1 {
+		t.Fatalf("expected minAvailable 1, got %#v", pdb.Spec.MinAvailable)
+	}
+	if pdb.Spec.Selector == nil || pdb.Spec.Selector.MatchLabels["spritz.sh/name"] != spritz.Name {
+		t.Fatalf("unexpected pdb selector: %#v", pdb.Spec.Selector)
+	}
+	if len(pdb.OwnerReferences) != 1 || pdb.OwnerReferences[0].Name != spritz.Name {
+		t.Fatalf("expected pdb to be owned by the spritz, got %#v", pdb.OwnerReferences)
+	}
+
+	t.Setenv("SPRITZ_CREATE_PDB", "false")
+	if err := reconciler.reconcilePodDisruptionBudget(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcilePodDisruptionBudget returned error: %v", err)
+	}
+	if err := k8sClient.Get(context.Background(), key, &policyv1.PodDisruptionBudget{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected pdb to be deleted when disabled, got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"os"
+	"strings"
+)
+
+// podStatusEnabled gates every status field the operator reads from workload
+// pods: status.repos, status.podNames, and the SharedMountsSynced and
+// InitScriptSucceeded conditions. It is opt-in because reading pods makes the
+// operator cache all pods in the watched namespaces.
+func podStatusEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("SPRITZ_POD_STATUS_ENABLED")), "true")
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// portTargetsMainContainer reports whether a spec port is served by the main
+// spritz container rather than a sidecar.
+func portTargetsMainContainer(port spritzv1.SpritzPort) bool {
+	target := strings.TrimSpace(port.TargetContainer)
+	return target == "" || target == spritzContainerName
+}
+
+// applyPortTargetContainers declares spec ports with a targetContainer on that
+// container. The Service targets ports by number, so the Service and Ingress
+// backends reach the sidecar once the port is declared on it. Native sidecars
+// run as init containers with restartPolicy Always and are matched too.
+func applyPortTargetContainers(spritz *spritzv1.Spritz, podSpec *corev1.PodSpec) error {
+	for i, port := range spritz.Spec.Ports {
+		if portTargetsMainContainer(port) {
+			continue
+		}
+		container := findPortTargetContainer(podSpec, strings.TrimSpace(port.TargetContainer))
+		if container == nil {
+			return fmt.Errorf("spec.ports[%d].targetContainer %q does not match a container in the workspace pod", i, port.TargetContainer)
+		}
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		container.Ports = append(container.Ports, corev1.ContainerPort{
+			Name:          port.Name,
+			ContainerPort: port.ContainerPort,
+			Protocol:      protocol,
+		})
+	}
+	return nil
+}
+
+func findPortTargetContainer(podSpec *corev1.PodSpec, name string) *corev1.Container {
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == name {
+			return &podSpec.Containers[i]
+		}
+	}
+	for i := range podSpec.InitContainers {
+		container := &podSpec.InitContainers[i]
+		if container.Name == name && container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			return container
+		}
+	}
+	return nil
+}
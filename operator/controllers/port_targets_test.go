@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestApplyPortTargetContainersDeclaresSidecarPort(t *testing.T) {
+	spritz := &spritzv1.Spritz{}
+	spritz.Spec.Ports = []spritzv1.SpritzPort{
+		{Name: "http", ContainerPort: 8080, ServicePort: 80, TargetContainer: "web-proxy"},
+		{Name: "metrics", ContainerPort: 9090},
+	}
+	restartAlways := corev1.ContainerRestartPolicyAlways
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{{Name: spritzContainerName, Ports: containerPorts(spritz)}},
+		InitContainers: []corev1.Container{
+			{Name: "web-proxy", RestartPolicy: &restartAlways},
+		},
+	}
+
+	if err := applyPortTargetContainers(spritz, &podSpec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, port := range podSpec.Containers[0].Ports {
+		if port.Name == "http" {
+			t.Fatalf("expected http port to move off the main container, got %#v", podSpec.Containers[0].Ports)
+		}
+	}
+	sidecarPorts := podSpec.InitContainers[0].Ports
+	if len(sidecarPorts) != 1 || sidecarPorts[0].Name != "http" || sidecarPorts[0].ContainerPort != 8080 || sidecarPorts[0].Protocol != corev1.ProtocolTCP {
+		t.Fatalf("unexpected sidecar ports %#v", sidecarPorts)
+	}
+	if name := httpPortName(spritz); name != "http" {
+		t.Fatalf("expected ingress backend to keep using the http service port, got %q", name)
+	}
+	for _, port := range servicePorts(spritz) {
+		if port.Name == "http" && (port.Port != 80 || port.TargetPort.IntValue() != 8080) {
+			t.Fatalf("unexpected http service port %#v", port)
+		}
+	}
+}
+
+func TestApplyPortTargetContainersRejectsUnknownContainer(t *testing.T) {
+	spritz := &spritzv1.Spritz{}
+	spritz.Spec.Ports = []spritzv1.SpritzPort{{Name: "http", ContainerPort: 8080, TargetContainer: "missing"}}
+	podSpec := corev1.PodSpec{
+		Containers:     []corev1.Container{{Name: spritzContainerName}},
+		InitContainers: []corev1.Container{{Name: "missing"}},
+	}
+
+	if err := applyPortTargetContainers(spritz, &podSpec); err == nil {
+		t.Fatal("expected an error when targetContainer is not a running container")
+	}
+}
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+const (
+	// prepullImagesKey holds the in-use images, one reference per line, sorted.
+	prepullImagesKey = "images"
+	// prepullImagesLabelKey marks the ConfigMap so pre-pullers can select it.
+	prepullImagesLabelKey = "spritz.sh/prepull-images"
+)
+
+// PrepullImages maintains a ConfigMap listing every image that live spritzes
+// run, so an external pre-puller (typically a DaemonSet) can warm nodes before
+// the next workspace lands on them. The operator only publishes the list; it
+// never pulls images itself.
+type PrepullImages struct {
+	Namespace string
+	Name      string
+
+	mu      sync.Mutex
+	written bool
+	last    string
+}
+
+// NewPrepullImagesFromEnv reads SPRITZ_PREPULL_CONFIGMAP and
+// SPRITZ_PREPULL_NAMESPACE. It returns nil unless both are set.
+func NewPrepullImagesFromEnv() *PrepullImages {
+	name := strings.TrimSpace(os.Getenv("SPRITZ_PREPULL_CONFIGMAP"))
+	namespace := strings.TrimSpace(os.Getenv("SPRITZ_PREPULL_NAMESPACE"))
+	if name == "" || namespace == "" {
+		return nil
+	}
+	return &PrepullImages{Namespace: namespace, Name: name}
+}
+
+func (p *PrepullImages) enabled() bool {
+	return p != nil && p.Name != "" && p.Namespace != ""
+}
+
+// spritzImages returns the images a spritz's pod pulls: the workspace image,
+// the init script image, and the repo init image of every repo.
+func spritzImages(spritz *spritzv1.Spritz) []string {
+	images := []string{spritz.Spec.Image}
+	if strings.TrimSpace(spritz.Spec.InitScript) != "" {
+		images = append(images, initScriptImage(spritz))
+	}
+	for _, repo := range repoEntries(spritz) {
+		if strings.TrimSpace(repo.URL) == "" {
+			continue
+		}
+		images = append(images, repoInitImageFor(&repo))
+	}
+	return images
+}
+
+// prepullImagesValue renders the sorted, de-duplicated images of spritzes
+// that are not being deleted.
+func prepullImagesValue(spritzes []spritzv1.Spritz) string {
+	seen := map[string]bool{}
+	var images []string
+	for i := range spritzes {
+		if !spritzes[i].DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, image := range spritzImages(&spritzes[i]) {
+			image = strings.TrimSpace(image)
+			if image == "" || seen[image] {
+				continue
+			}
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+	if len(images) == 0 {
+		return ""
+	}
+	sort.Strings(images)
+	return strings.Join(images, "\n") + "\n"
+}
+
+// syncPrepullImages rewrites the pre-pull ConfigMap from the cached spritz
+// list. It skips the write when the list has not changed since the last one.
+func (r *SpritzReconciler) syncPrepullImages(ctx context.Context) error {
+	if !r.PrepullImages.enabled() {
+		return nil
+	}
+	var spritzes spritzv1.SpritzList
+	if err := r.List(ctx, &spritzes); err != nil {
+		return err
+	}
+	value := prepullImagesValue(spritzes.Items)
+
+	p := r.PrepullImages
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.written && p.last == value {
+		return nil
+	}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: p.Name, Namespace: p.Namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		if configMap.Labels == nil {
+			configMap.Labels = map[string]string{}
+		}
+		configMap.Labels[prepullImagesLabelKey] = "true"
+		configMap.Data = map[string]string{prepullImagesKey: value}
+		return nil
+	}); err != nil {
+		return err
+	}
+	p.written = true
+	p.last = value
+	return nil
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestPrepullImagesValueListsImagesInUse(t *testing.T) {
+	t.Setenv("SPRITZ_GIT_INIT_IMAGE", "example.com/git-init:1.0")
+	now := metav1.Now()
+	spritzes := []spritzv1.Spritz{
+		{Spec: spritzv1.SpritzSpec{
+			Image: "example.com/openclaw:2",
+			Repos: []spritzv1.SpritzRepo{
+				{URL: "https://example.com/acme/app.git"},
+				{URL: "https://example.com/acme/assets.git", InitImage: "example.com/git-lfs:2.0"},
+			},
+		}},
+		{Spec: spritzv1.SpritzSpec{Image: "example.com/openclaw:1", InitScript: "true"}},
+		{Spec: spritzv1.SpritzSpec{Image: "example.com/openclaw:2"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now, Finalizers: []string{spritzFinalizer}},
+			Spec:       spritzv1.SpritzSpec{Image: "example.com/retired:1"},
+		},
+	}
+
+	want := "example.com/git-init:1.0\nexample.com/git-lfs:2.0\nexample.com/openclaw:1\nexample.com/openclaw:2\n"
+	if got := prepullImagesValue(spritzes); got != want {
+		t.Fatalf("unexpected image list:\n%s\nwant:\n%s", got, want)
+	}
+	if got := prepullImagesValue(nil); got != "" {
+		t.Fatalf("expected empty list without spritzes, got %q", got)
+	}
+}
+
+func TestSyncPrepullImagesWritesConfigMap(t *testing.T) {
+	scheme := newControllerTestScheme(t)
+	spritz := newPodSpecTestSpritz()
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{
+		Client:        k8sClient,
+		Scheme:        scheme,
+		PrepullImages: &PrepullImages{Namespace: "spritz-system", Name: "spritz-prepull-images"},
+	}
+
+	if err := reconciler.syncPrepullImages(context.Background()); err != nil {
+		t.Fatalf("syncPrepullImages returned error: %v", err)
+	}
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: "spritz-system", Name: "spritz-prepull-images"}
+	if err := k8sClient.Get(context.Background(), key, configMap); err != nil {
+		t.Fatalf("failed to load configmap: %v", err)
+	}
+	if configMap.Data[prepullImagesKey] != spritz.Spec.Image+"\n" {
+		t.Fatalf("unexpected images %q", configMap.Data[prepullImagesKey])
+	}
+	if configMap.Labels[prepullImagesLabelKey] != "true" {
+		t.Fatalf("expected pre-pull label, got %#v", configMap.Labels)
+	}
+
+	if err := k8sClient.Delete(context.Background(), spritz); err != nil {
+		t.Fatalf("failed to delete spritz: %v", err)
+	}
+	if err := reconciler.syncPrepullImages(context.Background()); err != nil {
+		t.Fatalf("syncPrepullImages returned error: %v", err)
+	}
+	if err := k8sClient.Get(context.Background(), key, configMap); err != nil {
+		t.Fatalf("failed to load configmap: %v", err)
+	}
+	if configMap.Data[prepullImagesKey] != "" {
+		t.Fatalf("expected deleted spritz's image to be dropped, got %q", configMap.Data[prepullImagesKey])
+	}
+}
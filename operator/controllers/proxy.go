@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// proxySettings is the effective egress proxy for a spritz.
+type proxySettings struct {
+	httpProxy  string
+	httpsProxy string
+	noProxy    string
+}
+
+// resolveProxySettings starts from the operator defaults and lets each
+// non-empty spec.proxy field override its default.
+func resolveProxySettings(spritz *spritzv1.Spritz) proxySettings {
+	settings := proxySettings{
+		httpProxy:  strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_HTTP_PROXY")),
+		httpsProxy: strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_HTTPS_PROXY")),
+		noProxy:    strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_NO_PROXY")),
+	}
+	if spritz == nil || spritz.Spec.Proxy == nil {
+		return settings
+	}
+	if value := strings.TrimSpace(spritz.Spec.Proxy.HTTPProxy); value != "" {
+		settings.httpProxy = value
+	}
+	if value := strings.TrimSpace(spritz.Spec.Proxy.HTTPSProxy); value != "" {
+		settings.httpsProxy = value
+	}
+	if value := strings.TrimSpace(spritz.Spec.Proxy.NoProxy); value != "" {
+		settings.noProxy = value
+	}
+	return settings
+}
+
+func (p proxySettings) enabled() bool {
+	return p.httpProxy != "" || p.httpsProxy != ""
+}
+
+// env returns both the upper and lower case spellings, since curl only reads
+// http_proxy in lower case and most other tools prefer upper case.
+func (p proxySettings) env() []corev1.EnvVar {
+	if !p.enabled() {
+		return nil
+	}
+	env := []corev1.EnvVar{}
+	appendPair := func(name, value string) {
+		if value == "" {
+			return
+		}
+		env = append(env,
+			corev1.EnvVar{Name: name, Value: value},
+			corev1.EnvVar{Name: strings.ToLower(name), Value: value},
+		)
+	}
+	appendPair("HTTP_PROXY", p.httpProxy)
+	appendPair("HTTPS_PROXY", p.httpsProxy)
+	appendPair("NO_PROXY", p.noProxy)
+	return env
+}
+
+// withNoProxyURL adds the host of rawURL to NO_PROXY, so in-cluster calls to
+// that URL skip the proxy.
+func (p proxySettings) withNoProxyURL(rawURL string) proxySettings {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Hostname() == "" {
+		return p
+	}
+	host := parsed.Hostname()
+	for _, entry := range parseCSV(p.noProxy) {
+		if entry == host {
+			return p
+		}
+	}
+	if p.noProxy == "" {
+		p.noProxy = host
+	} else {
+		p.noProxy = p.noProxy + "," + host
+	}
+	return p
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"testing"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+	"spritz.sh/operator/sharedmounts"
+)
+
+func TestReconcileDeploymentInjectsProxyEnv(t *testing.T) {
+	t.Setenv("SPRITZ_DEFAULT_HTTP_PROXY", "http://proxy.example.com:3128")
+	t.Setenv("SPRITZ_DEFAULT_HTTPS_PROXY", "http://proxy.example.com:3128")
+	t.Setenv("SPRITZ_DEFAULT_NO_PROXY", ".svc,.cluster.local")
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.Proxy = &spritzv1.SpritzProxy{HTTPSProxy: "http://team-proxy.example.com:3128"}
+	spritz.Spec.Repo = &spritzv1.SpritzRepo{URL: "https://git.example.com/example-org/app.git"}
+	deployment := reconcileTestDeployment(t, spritz)
+	podSpec := deployment.Spec.Template.Spec
+
+	if len(podSpec.InitContainers) == 0 {
+		t.Fatal("expected repo init container")
+	}
+	for _, container := range append(podSpec.Containers[:1:1], podSpec.InitContainers[0]) {
+		expected := map[string]string{
+			"HTTP_PROXY":  "http://proxy.example.com:3128",
+			"http_proxy":  "http://proxy.example.com:3128",
+			"HTTPS_PROXY": "http://team-proxy.example.com:3128",
+			"https_proxy": "http://team-proxy.example.com:3128",
+			"NO_PROXY":    ".svc,.cluster.local",
+			"no_proxy":    ".svc,.cluster.local",
+		}
+		for name, want := range expected {
+			if got, ok := envValue(container.Env, name); !ok || got != want {
+				t.Fatalf("expected %s=%s on %s, got %q", name, want, container.Name, got)
+			}
+		}
+	}
+}
+
+func TestReconcileDeploymentWithoutProxy(t *testing.T) {
+	deployment := reconcileTestDeployment(t, newPodSpecTestSpritz())
+	if _, ok := envValue(deployment.Spec.Template.Spec.Containers[0].Env, "HTTPS_PROXY"); ok {
+		t.Fatal("expected no proxy env without proxy settings")
+	}
+}
+
+func TestBuildSharedMountRuntimeSyncerBypassesProxyForAPI(t *testing.T) {
+	t.Setenv("SPRITZ_DEFAULT_HTTPS_PROXY", "http://proxy.example.com:3128")
+	t.Setenv("SPRITZ_DEFAULT_NO_PROXY", "localhost")
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Owner: spritzv1.SpritzOwner{ID: "owner-1"},
+			SharedMounts: []sharedmounts.MountSpec{
+				sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "config", MountPath: "/home/dev/.config"}),
+			},
+		},
+	}
+	settings := sharedMountsSettings{
+		enabled:       true,
+		apiURL:        "http://spritz-api.svc.cluster.local:8080/api",
+		ownerTokenKey: "owner-signing-key",
+		syncerImage:   "spritz-api:latest",
+	}
+
+	runtime, err := buildSharedMountRuntime(spritz, settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, container := range append(runtime.initContainers[:1:1], runtime.sidecarContainers[0]) {
+		if got, _ := envValue(container.Env, "HTTPS_PROXY"); got != "http://proxy.example.com:3128" {
+			t.Fatalf("expected HTTPS_PROXY on %s, got %q", container.Name, got)
+		}
+		if got, _ := envValue(container.Env, "NO_PROXY"); got != "localhost,spritz-api.svc.cluster.local" {
+			t.Fatalf("expected API host in NO_PROXY on %s, got %q", container.Name, got)
+		}
+	}
+}
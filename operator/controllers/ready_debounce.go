@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// readyDebounce is how long a Ready spritz may have no available replica
+// before it leaves the Ready phase. Zero disables the debounce.
+func readyDebounce() time.Duration {
+	value := strings.TrimSpace(os.Getenv("SPRITZ_READY_DEBOUNCE"))
+	if value == "" {
+		return 0
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		return 0
+	}
+	return window
+}
+
+// holdReadyDuringDebounce reports whether an unavailable spritz should stay
+// Ready, and how long until the window ends. It tracks the start of the
+// outage in status.unavailableSince and clears it once the spritz either
+// recovers or leaves the Ready phase.
+func holdReadyDuringDebounce(spritz *spritzv1.Spritz, available bool, now time.Time, window time.Duration) (bool, time.Duration) {
+	if available || window <= 0 || spritz.Status.Phase != "Ready" {
+		spritz.Status.UnavailableSince = nil
+		return false, 0
+	}
+	if spritz.Status.UnavailableSince == nil {
+		since := metav1.NewTime(now)
+		spritz.Status.UnavailableSince = &since
+	}
+	remaining := window - now.Sub(spritz.Status.UnavailableSince.Time)
+	if remaining <= 0 {
+		spritz.Status.UnavailableSince = nil
+		return false, 0
+	}
+	return true, remaining
+}
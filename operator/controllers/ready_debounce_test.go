@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func reconcileReadyDebounceStatus(t *testing.T, spritz *spritzv1.Spritz) (*spritzv1.Spritz, *time.Duration) {
+	t.Helper()
+	scheme := newControllerTestScheme(t)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: spritz.Name, Namespace: spritz.Namespace},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 0},
+	}
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&spritzv1.Spritz{}).
+		WithObjects(spritz, deployment).
+		Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	requeue, err := reconciler.reconcileStatus(context.Background(), spritz)
+	if err != nil {
+		t.Fatalf("reconcileStatus returned error: %v", err)
+	}
+	stored := &spritzv1.Spritz{}
+	if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: spritz.Namespace, Name: spritz.Name}, stored); err != nil {
+		t.Fatalf("failed to load updated spritz: %v", err)
+	}
+	return stored, requeue
+}
+
+func TestReconcileStatusHoldsReadyDuringDebounce(t *testing.T) {
+	t.Setenv("SPRITZ_READY_DEBOUNCE", "2m")
+	spritz := newPodSpecTestSpritz()
+	spritz.Status.Phase = "Ready"
+
+	stored, requeue := reconcileReadyDebounceStatus(t, spritz)
+	if stored.Status.Phase != "Ready" {
+		t.Fatalf("expected Ready phase during debounce, got %q", stored.Status.Phase)
+	}
+	if stored.Status.UnavailableSince == nil {
+		t.Fatal("expected unavailableSince to be recorded")
+	}
+	if requeue == nil || *requeue <= 0 || *requeue > 2*time.Minute {
+		t.Fatalf("expected requeue within the debounce window, got %v", requeue)
+	}
+}
+
+func TestReconcileStatusLeavesReadyAfterDebounce(t *testing.T) {
+	t.Setenv("SPRITZ_READY_DEBOUNCE", "2m")
+	spritz := newPodSpecTestSpritz()
+	spritz.Status.Phase = "Ready"
+	since := metav1.NewTime(time.Now().Add(-3 * time.Minute))
+	spritz.Status.UnavailableSince = &since
+
+	stored, _ := reconcileReadyDebounceStatus(t, spritz)
+	if stored.Status.Phase != "Provisioning" {
+		t.Fatalf("expected Provisioning phase after debounce, got %q", stored.Status.Phase)
+	}
+	if stored.Status.UnavailableSince != nil {
+		t.Fatalf("expected unavailableSince to be cleared, got %v", stored.Status.UnavailableSince)
+	}
+}
+
+func TestReconcileStatusWithoutDebounceLeavesReadyImmediately(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Status.Phase = "Ready"
+
+	stored, _ := reconcileReadyDebounceStatus(t, spritz)
+	if stored.Status.Phase != "Provisioning" {
+		t.Fatalf("expected Provisioning phase, got %q", stored.Status.Phase)
+	}
+}
+
+func TestHoldReadyDuringDebounceOnlyAppliesToReadySpritz(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Status.Phase = "Provisioning"
+	if held, _ := holdReadyDuringDebounce(spritz, false, time.Now(), time.Minute); held {
+		t.Fatal("expected a provisioning spritz not to be held Ready")
+	}
+
+	spritz.Status.Phase = "Ready"
+	since := metav1.NewTime(time.Now())
+	spritz.Status.UnavailableSince = &since
+	if held, _ := holdReadyDuringDebounce(spritz, true, time.Now(), time.Minute); held {
+		t.Fatal("expected an available spritz not to be held")
+	}
+	if spritz.Status.UnavailableSince != nil {
+		t.Fatal("expected recovery to clear unavailableSince")
+	}
+}
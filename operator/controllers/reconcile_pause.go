@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+const (
+	pausedAnnotationKey = "spritz.sh/paused"
+	pausedPhase         = "Paused"
+)
+
+// spritzPaused reports whether the spritz.sh/paused annotation freezes the
+// spritz, so its live resources can be inspected or edited by hand without
+// the controller reverting them.
+func spritzPaused(spritz *spritzv1.Spritz) bool {
+	return strings.EqualFold(strings.TrimSpace(spritz.Annotations[pausedAnnotationKey]), "true")
+}
+
+// reconcilePaused records the Paused phase and leaves every owned resource
+// alone until the annotation is removed. Expiry still applies: once the TTL or
+// idle TTL and the grace period have passed, the spritz is deleted, so pausing
+// cannot keep a workspace alive past its lifetime. It returns when to check
+// again.
+func (r *SpritzReconciler) reconcilePaused(ctx context.Context, spritz *spritzv1.Spritz) (*time.Duration, error) {
+	logger := log.FromContext(ctx)
+	var requeue *time.Duration
+	if deleteAt, ok := pausedDeleteAt(spritz); ok {
+		remaining := time.Until(deleteAt)
+		if remaining <= 0 {
+			logger.Info("deleting expired paused spritz", "name", spritz.Name, "namespace", spritz.Namespace)
+			return nil, r.Delete(ctx, spritz)
+		}
+		requeue = &remaining
+	}
+	if spritz.Status.Phase == pausedPhase && spritz.Status.ObservedGeneration == spritz.Generation {
+		return requeue, nil
+	}
+	logger.Info("spritz reconciliation paused", "name", spritz.Name, "namespace", spritz.Namespace)
+	return requeue, r.setStatus(
+		ctx,
+		spritz,
+		pausedPhase,
+		"",
+		buildSSHInfo(spritz),
+		"Paused",
+		"reconciliation paused by the "+pausedAnnotationKey+" annotation",
+		deepCopyACPStatus(spritz.Status.ACP),
+	)
+}
+
+// pausedDeleteAt returns when a paused spritz is due for deletion: its
+// effective expiry, capped at SPRITZ_MAX_LIFETIME, plus the TTL grace period. An invalid TTL is reported once
+// the spritz is resumed, so it does not expire the spritz here.
+func pausedDeleteAt(spritz *spritzv1.Spritz) (time.Time, bool) {
+	_, maxExpiresAt, expiresAt, reason, err := spritzv1.LifecycleExpiryTimes(spritz)
+	if err != nil {
+		return time.Time{}, false
+	}
+	_, expiresAt, _ = capLifecycleAtMaxLifetime(spritz, maxLifetime(), maxExpiresAt, expiresAt, reason)
+	if expiresAt == nil {
+		return time.Time{}, false
+	}
+	return expiresAt.Add(ttlGracePeriod()), true
+}
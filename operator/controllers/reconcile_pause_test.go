@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestReconcileLeavesResourcesAloneWhilePaused(t *testing.T) {
+	scheme := newControllerTestScheme(t)
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register networking scheme: %v", err)
+	}
+	if err := gatewayv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to register gateway scheme: %v", err)
+	}
+	spritz := newPodSpecTestSpritz()
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&spritzv1.Spritz{}).
+		WithObjects(spritz).
+		Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: spritz.Namespace, Name: spritz.Name}}
+	key := client.ObjectKey{Namespace: spritz.Namespace, Name: spritz.Name}
+	for i := 0; i < 2; i++ {
+		if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("Reconcile returned error: %v", err)
+		}
+	}
+
+	current := &spritzv1.Spritz{}
+	if err := k8sClient.Get(context.Background(), key, current); err != nil {
+		t.Fatalf("failed to load spritz: %v", err)
+	}
+	metav1.SetMetaDataAnnotation(&current.ObjectMeta, pausedAnnotationKey, "true")
+	if err := k8sClient.Update(context.Background(), current); err != nil {
+		t.Fatalf("failed to pause spritz: %v", err)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := k8sClient.Get(context.Background(), key, deployment); err != nil {
+		t.Fatalf("failed to load deployment: %v", err)
+	}
+	deployment.Spec.Template.Spec.Containers[0].Image = "example.com/debug:latest"
+	if err := k8sClient.Update(context.Background(), deployment); err != nil {
+		t.Fatalf("failed to edit deployment: %v", err)
+	}
+
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := k8sClient.Get(context.Background(), key, deployment); err != nil {
+		t.Fatalf("failed to load deployment: %v", err)
+	}
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != "example.com/debug:latest" {
+		t.Fatalf("expected manual edit to survive while paused, got %q", image)
+	}
+	if err := k8sClient.Get(context.Background(), key, current); err != nil {
+		t.Fatalf("failed to load spritz: %v", err)
+	}
+	if current.Status.Phase != pausedPhase {
+		t.Fatalf("expected phase %q, got %q", pausedPhase, current.Status.Phase)
+	}
+
+	delete(current.Annotations, pausedAnnotationKey)
+	if err := k8sClient.Update(context.Background(), current); err != nil {
+		t.Fatalf("failed to resume spritz: %v", err)
+	}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if err := k8sClient.Get(context.Background(), key, deployment); err != nil {
+		t.Fatalf("failed to load deployment: %v", err)
+	}
+	if image := deployment.Spec.Template.Spec.Containers[0].Image; image != spritz.Spec.Image {
+		t.Fatalf("expected image to be restored after resuming, got %q", image)
+	}
+}
+
+func TestReconcilePausedStillEnforcesExpiry(t *testing.T) {
+	t.Setenv("SPRITZ_TTL_GRACE_PERIOD", "5m")
+	scheme := newControllerTestScheme(t)
+	expired := newPodSpecTestSpritz()
+	expired.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	expired.Spec.TTL = "1h"
+	metav1.SetMetaDataAnnotation(&expired.ObjectMeta, pausedAnnotationKey, "true")
+	live := newPodSpecTestSpritz()
+	live.Name = "quiet-harbor"
+	live.CreationTimestamp = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	live.Spec.TTL = "3h"
+	metav1.SetMetaDataAnnotation(&live.ObjectMeta, pausedAnnotationKey, "true")
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&spritzv1.Spritz{}).
+		WithObjects(expired, live).
+		Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	if _, err := reconciler.reconcilePaused(context.Background(), expired); err != nil {
+		t.Fatalf("reconcilePaused returned error: %v", err)
+	}
+	err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(expired), &spritzv1.Spritz{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected expired paused spritz to be deleted, got %v", err)
+	}
+
+	requeue, err := reconciler.reconcilePaused(context.Background(), live)
+	if err != nil {
+		t.Fatalf("reconcilePaused returned error: %v", err)
+	}
+	if requeue == nil || *requeue <= time.Hour || *requeue > time.Hour+5*time.Minute {
+		t.Fatalf("expected requeue at expiry plus grace, got %v", requeue)
+	}
+	current := &spritzv1.Spritz{}
+	if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(live), current); err != nil {
+		t.Fatalf("failed to load spritz: %v", err)
+	}
+	if current.Status.Phase != pausedPhase {
+		t.Fatalf("expected phase %q, got %q", pausedPhase, current.Status.Phase)
+	}
+}
+
+func TestPausedDeleteAtAppliesMaxLifetime(t *testing.T) {
+	t.Setenv("SPRITZ_MAX_LIFETIME", "720h")
+	t.Setenv("SPRITZ_TTL_GRACE_PERIOD", "5m")
+	createdAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	spritz := newPodSpecTestSpritz()
+	spritz.CreationTimestamp = metav1.NewTime(createdAt)
+	metav1.SetMetaDataAnnotation(&spritz.ObjectMeta, pausedAnnotationKey, "true")
+
+	deleteAt, ok := pausedDeleteAt(spritz)
+	if !ok || !deleteAt.Equal(createdAt.Add(720*time.Hour+5*time.Minute)) {
+		t.Fatalf("expected deletion at the ceiling plus grace, got %v (%v)", deleteAt, ok)
+	}
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconcileTimeoutFromEnvDefaultsToThirtySeconds(t *testing.T) {
+	t.Setenv("SPRITZ_RECONCILE_TIMEOUT", "")
+	if got := ReconcileTimeoutFromEnv(); got != 30*time.Second {
+		t.Fatalf("expected 30s default, got %s", got)
+	}
+	if got := (&SpritzReconciler{}).reconcileTimeout(); got != 30*time.Second {
+		t.Fatalf("expected unset reconciler timeout to default to 30s, got %s", got)
+	}
+}
+
+func TestReconcileTimeoutFromEnvParsesOverride(t *testing.T) {
+	t.Setenv("SPRITZ_RECONCILE_TIMEOUT", "2m")
+	if got := ReconcileTimeoutFromEnv(); got != 2*time.Minute {
+		t.Fatalf("expected 2m, got %s", got)
+	}
+	t.Setenv("SPRITZ_RECONCILE_TIMEOUT", "soon")
+	if got := ReconcileTimeoutFromEnv(); got != 30*time.Second {
+		t.Fatalf("expected invalid value to fall back to 30s, got %s", got)
+	}
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const repoAuthVolumePrefix = "repo-auth-"
+
+// repoAuthLiveEnabled reports whether repo auth secrets are also mounted into
+// the workspace container so rotated tokens reach git without a pod restart.
+func repoAuthLiveEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("SPRITZ_REPO_AUTH_LIVE_ENABLED")), "true")
+}
+
+// repoAuthMountPathFor returns where the auth secret of repo index is mounted.
+// Each secret gets its own path, named after the first repo that uses it, so
+// the workspace container can mount all of them at the same paths the init
+// containers linked to.
+func repoAuthMountPathFor(index int) string {
+	if index == 0 {
+		return repoAuthMountPath
+	}
+	return fmt.Sprintf("%s-%d", repoAuthMountPath, index)
+}
+
+// repoAuthLiveMounts returns the repo auth secret mounts of the repo init
+// containers, for reuse in the workspace container. Secret volumes mounted
+// without subPath are updated in place by the kubelet.
+func repoAuthLiveMounts(initContainers []corev1.Container) []corev1.VolumeMount {
+	var mounts []corev1.VolumeMount
+	seen := map[string]bool{}
+	for _, container := range initContainers {
+		for _, mount := range container.VolumeMounts {
+			if strings.HasPrefix(mount.Name, repoAuthVolumePrefix) && !seen[mount.Name] {
+				seen[mount.Name] = true
+				mounts = append(mounts, mount)
+			}
+		}
+	}
+	return mounts
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+const (
+	repoCacheVolumeName            = "repo-cache"
+	defaultRepoCacheRefreshMinutes = 10
+)
+
+// repoCacheSettings configures the optional shared git mirror cache that repo
+// init containers clone from with --reference.
+type repoCacheSettings struct {
+	path                 string
+	claimName            string
+	refreshMinutes       int
+	includeAuthenticated bool
+}
+
+func (s repoCacheSettings) enabled() bool {
+	return s.path != ""
+}
+
+// appliesTo reports whether the cache should be used for a repo. Authenticated
+// repos are excluded by default because every init container that mounts the
+// cache, including its post-clone hooks, can read every mirror in it.
+func (s repoCacheSettings) appliesTo(repo *spritzv1.SpritzRepo) bool {
+	if !s.enabled() || repo == nil {
+		return false
+	}
+	if repo.Auth != nil && !s.includeAuthenticated {
+		return false
+	}
+	return true
+}
+
+func loadRepoCacheSettings() (repoCacheSettings, error) {
+	cachePath := strings.TrimSpace(os.Getenv("SPRITZ_REPO_CACHE_PATH"))
+	if cachePath == "" {
+		return repoCacheSettings{}, nil
+	}
+	if !path.IsAbs(cachePath) {
+		return repoCacheSettings{}, fmt.Errorf("SPRITZ_REPO_CACHE_PATH must be an absolute path")
+	}
+	claimName := strings.TrimSpace(os.Getenv("SPRITZ_REPO_CACHE_CLAIM_NAME"))
+	if claimName == "" {
+		return repoCacheSettings{}, fmt.Errorf("SPRITZ_REPO_CACHE_CLAIM_NAME is required when SPRITZ_REPO_CACHE_PATH is set")
+	}
+	refreshMinutes := defaultRepoCacheRefreshMinutes
+	if raw := strings.TrimSpace(os.Getenv("SPRITZ_REPO_CACHE_REFRESH_INTERVAL")); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval < 0 {
+			return repoCacheSettings{}, fmt.Errorf("invalid SPRITZ_REPO_CACHE_REFRESH_INTERVAL: %s", raw)
+		}
+		refreshMinutes = int(interval.Round(time.Minute) / time.Minute)
+	}
+	return repoCacheSettings{
+		path:                 path.Clean(cachePath),
+		claimName:            claimName,
+		refreshMinutes:       refreshMinutes,
+		includeAuthenticated: strings.EqualFold(strings.TrimSpace(os.Getenv("SPRITZ_REPO_CACHE_INCLUDE_AUTHENTICATED")), "true"),
+	}, nil
+}
+
+func repoCacheVolume(settings repoCacheSettings) corev1.Volume {
+	return corev1.Volume{
+		Name: repoCacheVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: settings.claimName},
+		},
+	}
+}
+
+func repoCacheEnv(settings repoCacheSettings) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "SPRITZ_REPO_CACHE_PATH", Value: settings.path},
+		{Name: "SPRITZ_REPO_CACHE_REFRESH_MINUTES", Value: fmt.Sprintf("%d", settings.refreshMinutes)},
+	}
+}
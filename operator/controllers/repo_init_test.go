@@ -0,0 +1,482 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func envValue(env []corev1.EnvVar, name string) (string, bool) {
+	for _, item := range env {
+		if item.Name == name {
+			return item.Value, true
+		}
+	}
+	return "", false
+}
+
+func TestBuildRepoInitContainerPlumbsPostCloneHooks(t *testing.T) {
+	spritz := &spritzv1.Spritz{}
+	repo := &spritzv1.SpritzRepo{
+		URL:       "https://example.com/acme/repo.git",
+		PostClone: []string{"make setup", "npm ci"},
+	}
+
+	container, _, err := buildRepoInitContainerForRepo(spritz, repo, "/workspace/repo", false, buildHomeMounts(), 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, _ := envValue(container.Env, "SPRITZ_REPO_POST_CLONE_COUNT"); value != "2" {
+		t.Fatalf("expected post-clone count 2, got %q", value)
+	}
+	if value, _ := envValue(container.Env, "SPRITZ_REPO_POST_CLONE_0"); value != "make setup" {
+		t.Fatalf("unexpected first post-clone hook %q", value)
+	}
+	if value, _ := envValue(container.Env, "SPRITZ_REPO_POST_CLONE_1"); value != "npm ci" {
+		t.Fatalf("unexpected second post-clone hook %q", value)
+	}
+}
+
+func TestBuildRepoInitContainerOmitsPostCloneEnvWithoutHooks(t *testing.T) {
+	spritz := &spritzv1.Spritz{}
+	repo := &spritzv1.SpritzRepo{URL: "https://example.com/acme/repo.git"}
+
+	container, _, err := buildRepoInitContainerForRepo(spritz, repo, "/workspace/repo", false, buildHomeMounts(), 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := envValue(container.Env, "SPRITZ_REPO_POST_CLONE_COUNT"); ok {
+		t.Fatal("expected no post-clone env without hooks")
+	}
+}
+
+func TestBuildRepoInitContainerRejectsEmptyPostCloneHook(t *testing.T) {
+	spritz := &spritzv1.Spritz{}
+	repo := &spritzv1.SpritzRepo{
+		URL:       "https://example.com/acme/repo.git",
+		PostClone: []string{"make setup", "  "},
+	}
+
+	if _, _, err := buildRepoInitContainerForRepo(spritz, repo, "/workspace/repo", false, buildHomeMounts(), 0, 0); err == nil {
+		t.Fatal("expected error for empty post-clone hook")
+	}
+}
+
+func TestBuildRepoInitContainerPlumbsSkipIfExists(t *testing.T) {
+	spritz := &spritzv1.Spritz{}
+	repo := &spritzv1.SpritzRepo{URL: "https://example.com/acme/repo.git", SkipIfExists: true}
+
+	container, _, err := buildRepoInitContainerForRepo(spritz, repo, "/workspace/repo", false, buildHomeMounts(), 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, _ := envValue(container.Env, "SPRITZ_REPO_SKIP_IF_EXISTS"); value != "true" {
+		t.Fatalf("expected skip-if-exists env, got %q", value)
+	}
+}
+
+func TestBuildRepoInitContainersUsesPerRepoInitImage(t *testing.T) {
+	t.Setenv("SPRITZ_GIT_INIT_IMAGE", "example.com/git-init:1.0")
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Repos: []spritzv1.SpritzRepo{
+				{URL: "https://example.com/acme/app.git"},
+				{URL: "https://example.com/acme/assets.git", InitImage: "example.com/git-lfs:2.0"},
+			},
+		},
+	}
+
+	containers, _, err := buildRepoInitContainers(spritz, repoEntries(spritz), buildHomeMounts())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if containers[0].Image != "example.com/git-init:1.0" {
+		t.Fatalf("expected global init image for repo without override, got %q", containers[0].Image)
+	}
+	if containers[1].Image != "example.com/git-lfs:2.0" {
+		t.Fatalf("expected per-repo init image, got %q", containers[1].Image)
+	}
+}
+
+func TestRepoInitScriptSkipIfExistsLeavesCheckoutUntouched(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	repoDir := filepath.Join(root, "repo")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoDir, "-c", "user.name=Example", "-c", "user.email=user@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	if err := os.MkdirAll(repoDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q", "-b", "main")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	if err := os.WriteFile(filepath.Join(repoDir, "notes.txt"), []byte("work in progress"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	statusFile := filepath.Join(root, "status")
+	cmd := exec.Command("/bin/sh", "-ec", repoInitScript)
+	cmd.Env = append(os.Environ(),
+		"HOME="+root,
+		// An unreachable origin proves nothing is fetched.
+		"SPRITZ_REPO_URL=file:///nonexistent/repo.git",
+		"SPRITZ_REPO_DIR="+repoDir,
+		"SPRITZ_REPO_REVISION=does-not-exist",
+		"SPRITZ_REPO_SKIP_IF_EXISTS=true",
+		"SPRITZ_REPO_STATUS_FILE="+statusFile,
+		"SPRITZ_REPO_POST_CLONE_COUNT=1",
+		"SPRITZ_REPO_POST_CLONE_0=rm notes.txt",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("repo init script failed: %v: %s", err, out)
+	}
+	if data, err := os.ReadFile(filepath.Join(repoDir, "notes.txt")); err != nil || string(data) != "work in progress" {
+		t.Fatalf("expected local edits to survive, got %q (%v)", data, err)
+	}
+	status, err := os.ReadFile(statusFile)
+	if err != nil || !strings.Contains(string(status), "branch=main") {
+		t.Fatalf("expected repo status to be reported, got %q (%v)", status, err)
+	}
+}
+
+func TestBuildRepoInitContainerPlumbsLFS(t *testing.T) {
+	spritz := &spritzv1.Spritz{}
+	repo := &spritzv1.SpritzRepo{URL: "https://example.com/acme/assets.git", LFS: true}
+
+	container, _, err := buildRepoInitContainerForRepo(spritz, repo, "/workspace/assets", false, buildHomeMounts(), 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, _ := envValue(container.Env, "SPRITZ_REPO_LFS"); value != "true" {
+		t.Fatalf("expected lfs env, got %q", value)
+	}
+	if value, _ := envValue(container.Env, "GIT_LFS_SKIP_SMUDGE"); value != "1" {
+		t.Fatalf("expected smudge to be skipped during checkout, got %q", value)
+	}
+
+	container, _, err = buildRepoInitContainerForRepo(spritz, &spritzv1.SpritzRepo{URL: repo.URL}, "/workspace/assets", false, buildHomeMounts(), 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := envValue(container.Env, "GIT_LFS_SKIP_SMUDGE"); ok {
+		t.Fatal("expected no lfs env without spec.repo.lfs")
+	}
+}
+
+func TestRepoInitScriptLFSWarnsWithoutGitLFS(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	if err := exec.Command("git", "lfs", "version").Run(); err == nil {
+		t.Skip("git-lfs is installed")
+	}
+	root := t.TempDir()
+	originDir := filepath.Join(root, "origin")
+	if err := os.MkdirAll(originDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=Example", "-c", "user.email=user@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", originDir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	cmd := exec.Command("/bin/sh", "-ec", repoInitScript)
+	cmd.Env = append(os.Environ(),
+		"HOME="+root,
+		"SPRITZ_REPO_URL="+originDir,
+		"SPRITZ_REPO_DIR="+filepath.Join(root, "repo"),
+		"SPRITZ_REPO_LFS=true",
+		"GIT_LFS_SKIP_SMUDGE=1",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("repo init script failed: %v: %s", err, out)
+	}
+	if !strings.Contains(string(out), "git-lfs is not available") {
+		t.Fatalf("expected missing git-lfs warning, got %s", out)
+	}
+}
+
+func TestBuildRepoInitContainerPlumbsWorktrees(t *testing.T) {
+	spritz := &spritzv1.Spritz{}
+	repo := &spritzv1.SpritzRepo{
+		URL: "https://example.com/acme/app.git",
+		Worktrees: []spritzv1.SpritzRepoWorktree{
+			{Branch: "release", Dir: "app-release"},
+			{Branch: "feature/login", Dir: "/workspace/app-login"},
+		},
+	}
+
+	container, _, err := buildRepoInitContainerForRepo(spritz, repo, "/workspace/app", false, buildHomeMounts(), 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"SPRITZ_REPO_WORKTREE_COUNT":    "2",
+		"SPRITZ_REPO_WORKTREE_0_BRANCH": "release",
+		"SPRITZ_REPO_WORKTREE_0_DIR":    "/workspace/app-release",
+		"SPRITZ_REPO_WORKTREE_1_BRANCH": "feature/login",
+		"SPRITZ_REPO_WORKTREE_1_DIR":    "/workspace/app-login",
+	}
+	for name, want := range expected {
+		if value, _ := envValue(container.Env, name); value != want {
+			t.Fatalf("expected %s=%q, got %q", name, want, value)
+		}
+	}
+}
+
+func TestValidateRepoWorktrees(t *testing.T) {
+	cases := []struct {
+		name      string
+		worktrees []spritzv1.SpritzRepoWorktree
+		wantErr   string
+	}{
+		{name: "valid", worktrees: []spritzv1.SpritzRepoWorktree{{Branch: "release", Dir: "app-release"}}},
+		{name: "missing branch", worktrees: []spritzv1.SpritzRepoWorktree{{Dir: "app-release"}}, wantErr: "branch is required"},
+		{name: "outside workspace", worktrees: []spritzv1.SpritzRepoWorktree{{Branch: "release", Dir: "/tmp/release"}}, wantErr: "must be under /workspace"},
+		{name: "inside repo dir", worktrees: []spritzv1.SpritzRepoWorktree{{Branch: "release", Dir: "app/release"}}, wantErr: "must not overlap repo.dir"},
+		{name: "duplicate", worktrees: []spritzv1.SpritzRepoWorktree{{Branch: "a", Dir: "app-x"}, {Branch: "b", Dir: "/workspace/app-x"}}, wantErr: "used more than once"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateRepoWorktrees(tc.worktrees, "/workspace/app")
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRepoInitScriptAddsWorktrees(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	originDir := filepath.Join(root, "origin")
+	if err := os.MkdirAll(originDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=Example", "-c", "user.email=user@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+		{"branch", "release"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", originDir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	worktreeDir := filepath.Join(root, "release")
+	env := append(os.Environ(),
+		"HOME="+root,
+		"SPRITZ_REPO_URL=file://"+originDir,
+		"SPRITZ_REPO_DIR="+filepath.Join(root, "repo"),
+		"SPRITZ_REPO_BRANCH=main",
+		"SPRITZ_REPO_DEPTH=1",
+		"SPRITZ_REPO_WORKTREE_COUNT=1",
+		"SPRITZ_REPO_WORKTREE_0_BRANCH=release",
+		"SPRITZ_REPO_WORKTREE_0_DIR="+worktreeDir,
+	)
+	runInit := func() {
+		t.Helper()
+		cmd := exec.Command("/bin/sh", "-ec", repoInitScript)
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("repo init script failed: %v: %s", err, out)
+		}
+	}
+	runInit()
+	out, err := exec.Command("git", "-C", worktreeDir, "symbolic-ref", "--short", "HEAD").CombinedOutput()
+	if err != nil || strings.TrimSpace(string(out)) != "release" {
+		t.Fatalf("expected release worktree, got %q (%v)", out, err)
+	}
+
+	// A restart leaves the existing worktree in place.
+	runInit()
+}
+
+func TestBuildRepoInitContainerUsesCredentialStoreKey(t *testing.T) {
+	spritz := &spritzv1.Spritz{}
+	repo := &spritzv1.SpritzRepo{
+		URL: "https://example.com/acme/repo.git",
+		Auth: &spritzv1.SpritzRepoAuth{
+			SecretName:     "spritz-repo-auth-example",
+			CredentialsKey: "git-credentials",
+		},
+	}
+
+	container, volume, err := buildRepoInitContainerForRepo(spritz, repo, "/workspace/repo", false, buildHomeMounts(), 0, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if volume == nil || volume.Secret == nil || volume.Secret.SecretName != "spritz-repo-auth-example" {
+		t.Fatalf("expected repo auth secret volume, got %#v", volume)
+	}
+	if value, _ := envValue(container.Env, "SPRITZ_REPO_AUTH_CREDENTIALS_PATH"); value != repoAuthMountPath+"/git-credentials" {
+		t.Fatalf("unexpected credentials path %q", value)
+	}
+	if _, ok := envValue(container.Env, "SPRITZ_REPO_AUTH_NETRC_PATH"); ok {
+		t.Fatal("expected no default netrc path when a credentials key is set")
+	}
+}
+
+func TestBuildRepoInitContainersMountsRepoCache(t *testing.T) {
+	t.Setenv("SPRITZ_REPO_CACHE_PATH", "/var/cache/spritz-repos")
+	t.Setenv("SPRITZ_REPO_CACHE_CLAIM_NAME", "spritz-repo-cache")
+	t.Setenv("SPRITZ_REPO_CACHE_REFRESH_INTERVAL", "30m")
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Repos: []spritzv1.SpritzRepo{
+				{URL: "https://example.com/acme/public.git"},
+				{
+					URL:  "https://example.com/acme/private.git",
+					Auth: &spritzv1.SpritzRepoAuth{SecretName: "repo-auth"},
+				},
+			},
+		},
+	}
+
+	containers, volumes, err := buildRepoInitContainers(spritz, repoEntries(spritz), buildHomeMounts())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("expected 2 repo init containers, got %d", len(containers))
+	}
+	if value, _ := envValue(containers[0].Env, "SPRITZ_REPO_CACHE_PATH"); value != "/var/cache/spritz-repos" {
+		t.Fatalf("expected cache path on public repo, got %q", value)
+	}
+	if value, _ := envValue(containers[0].Env, "SPRITZ_REPO_CACHE_REFRESH_MINUTES"); value != "30" {
+		t.Fatalf("expected cache refresh minutes 30, got %q", value)
+	}
+	if _, ok := envValue(containers[1].Env, "SPRITZ_REPO_CACHE_PATH"); ok {
+		t.Fatal("expected authenticated repo to skip the shared cache by default")
+	}
+
+	cacheVolumes := 0
+	for _, volume := range volumes {
+		if volume.Name != repoCacheVolumeName {
+			continue
+		}
+		cacheVolumes++
+		if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != "spritz-repo-cache" {
+			t.Fatalf("unexpected cache volume source: %#v", volume.VolumeSource)
+		}
+	}
+	if cacheVolumes != 1 {
+		t.Fatalf("expected one repo cache volume, got %d", cacheVolumes)
+	}
+}
+
+func TestLoadRepoCacheSettingsRequiresClaimName(t *testing.T) {
+	t.Setenv("SPRITZ_REPO_CACHE_PATH", "/var/cache/spritz-repos")
+	t.Setenv("SPRITZ_REPO_CACHE_CLAIM_NAME", "")
+
+	if _, err := loadRepoCacheSettings(); err == nil {
+		t.Fatal("expected error when cache claim name is missing")
+	}
+}
+
+func TestRepoRevisionFetchRef(t *testing.T) {
+	cases := []struct {
+		revision string
+		want     string
+	}{
+		{"pull/123/head", "refs/pull/123/head"},
+		{"refs/pull/123/merge", "refs/pull/123/merge"},
+		{"merge-requests/45/head", "refs/merge-requests/45/head"},
+		{"refs/merge-requests/45/head", "refs/merge-requests/45/head"},
+		{" pull/7/head ", "refs/pull/7/head"},
+		{"main", ""},
+		{"3f2c1ab", ""},
+		{"refs/heads/main", ""},
+		{"pull/abc/head", ""},
+		{"pull/123/head/extra", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.revision, func(t *testing.T) {
+			if got := repoRevisionFetchRef(tc.revision); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseRepoStatusMessage(t *testing.T) {
+	status := parseRepoStatusMessage("branch=main\ncommit=abc123\n")
+	if status.Branch != "main" || status.Commit != "abc123" || status.Detached {
+		t.Fatalf("unexpected branch status: %#v", status)
+	}
+
+	status = parseRepoStatusMessage("branch=\ncommit=def456\n")
+	if status.Branch != "" || status.Commit != "def456" || !status.Detached {
+		t.Fatalf("unexpected detached status: %#v", status)
+	}
+}
+
+func TestObserveRepoStatusesReadsInitContainerTerminationMessages(t *testing.T) {
+	scheme := newControllerTestScheme(t)
+	spritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidy-otter", Namespace: "spritz-test"},
+		Spec: spritzv1.SpritzSpec{
+			Repo: &spritzv1.SpritzRepo{URL: "https://example.com/acme/repo.git", Revision: "3f2c1ab"},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tidy-otter-abc",
+			Namespace: "spritz-test",
+			Labels:    deploymentSelectorLabels(spritz),
+		},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: repoInitContainerName(0),
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Message: "branch=\ncommit=3f2c1ab\n"},
+					},
+				},
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz, pod).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	statuses, err := reconciler.observeRepoStatuses(context.Background(), spritz, &appsv1.Deployment{})
+	if err != nil {
+		t.Fatalf("observeRepoStatuses returned error: %v", err)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("expected 1 repo status, got %#v", statuses)
+	}
+	if statuses[0].Dir != "/workspace/repo" || statuses[0].Commit != "3f2c1ab" || !statuses[0].Detached {
+		t.Fatalf("unexpected repo status: %#v", statuses[0])
+	}
+}
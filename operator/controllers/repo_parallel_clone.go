@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	repoParallelInitContainerName       = "repo-init-parallel"
+	defaultRepoParallelCloneConcurrency = 4
+)
+
+// repoParallelInitScript runs repoInitScript once per repo, spread across
+// SPRITZ_REPO_PARALLEL_CONCURRENCY workers. Each job's env is passed as
+// SPRITZ_REPO_JOB_<i>_<NAME> and re-exported under its plain name.
+//
+// Jobs with credentials run with a private $HOME so one repo's .netrc cannot
+// be swapped under another repo's clone. Once every job succeeds their auth
+// setup is replayed in repo order against the real $HOME, which leaves it as
+// a sequential run would.
+const repoParallelInitScript = `
+set -eu
+
+jobs_dir="$(mktemp -d)"
+real_home="$HOME"
+
+load_job_env() {
+  eval "job_vars=\${SPRITZ_REPO_JOB_${1}_VARS}"
+  for job_var in $job_vars; do
+    eval "export $job_var=\"\${SPRITZ_REPO_JOB_${1}_${job_var}}\""
+  done
+}
+
+job_has_auth() {
+  [ -n "${SPRITZ_REPO_AUTH_NETRC_PATH:-}" ] || [ -n "${SPRITZ_REPO_AUTH_CREDENTIALS_PATH:-}" ] || [ -n "${SPRITZ_REPO_AUTH_USERNAME:-}" ]
+}
+
+run_job() {
+  if (
+    load_job_env "$1"
+    if job_has_auth; then
+      export HOME="$jobs_dir/home-$1"
+      mkdir -p "$HOME"
+    fi
+    export SPRITZ_REPO_STATUS_FILE="$jobs_dir/status-$1"
+    /bin/sh -ec "$SPRITZ_REPO_INIT_SCRIPT"
+  ) > "$jobs_dir/log-$1" 2>&1; then
+    echo 0 > "$jobs_dir/exit-$1"
+  else
+    echo 1 > "$jobs_dir/exit-$1"
+  fi
+}
+
+worker() {
+  position=0
+  for job in $SPRITZ_REPO_JOBS; do
+    if [ $((position % SPRITZ_REPO_PARALLEL_CONCURRENCY)) -eq "$1" ]; then
+      run_job "$job"
+    fi
+    position=$((position + 1))
+  done
+}
+
+worker_index=0
+while [ "$worker_index" -lt "$SPRITZ_REPO_PARALLEL_CONCURRENCY" ]; do
+  worker "$worker_index" &
+  worker_index=$((worker_index + 1))
+done
+wait
+
+failed=0
+for job in $SPRITZ_REPO_JOBS; do
+  sed "s/^/[repo $job] /" "$jobs_dir/log-$job" 2>/dev/null || true
+  if [ "$(cat "$jobs_dir/exit-$job" 2>/dev/null || echo 1)" != "0" ]; then
+    echo "repo $job failed" >&2
+    failed=1
+  fi
+done
+if [ "$failed" -ne 0 ]; then
+  exit 1
+fi
+
+for job in $SPRITZ_REPO_JOBS; do
+  (
+    load_job_env "$job"
+    if job_has_auth; then
+      export HOME="$real_home"
+      /bin/sh -ec "$SPRITZ_REPO_AUTH_SETUP_SCRIPT"
+    fi
+  )
+done
+
+if [ -n "${SPRITZ_REPO_STATUS_FILE:-}" ]; then
+  for job in $SPRITZ_REPO_JOBS; do
+    printf 'repo=%s\n' "$job"
+    cat "$jobs_dir/status-$job" 2>/dev/null || true
+  done > "$SPRITZ_REPO_STATUS_FILE" || true
+fi
+`
+
+func repoParallelCloneEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("SPRITZ_REPO_PARALLEL_CLONE")), "true")
+}
+
+func repoParallelCloneConcurrency() int {
+	if value := parseIntEnv("SPRITZ_REPO_PARALLEL_CLONE_CONCURRENCY", defaultRepoParallelCloneConcurrency); value > 0 {
+		return value
+	}
+	return defaultRepoParallelCloneConcurrency
+}
+
+// parallelizeRepoInitContainers folds the repo init containers that use the
+// default init image into one container that clones them in parallel. Repos
+// with their own initImage keep a dedicated container, since one container
+// can only run one image. Repos whose directory contains or sits inside
+// another repo's directory also keep a dedicated container, so the outer repo
+// is cloned before the inner one as in a sequential run. indexes holds the
+// repo index of each container.
+func parallelizeRepoInitContainers(containers []corev1.Container, indexes []int) []corev1.Container {
+	defaultImage := repoInitImage()
+	nested := nestedRepoInitContainers(containers)
+	var merged []int
+	for i, container := range containers {
+		if container.Image == defaultImage && !nested[i] {
+			merged = append(merged, i)
+		}
+	}
+	if len(merged) < 2 {
+		return containers
+	}
+
+	concurrency := repoParallelCloneConcurrency()
+	if concurrency > len(merged) {
+		concurrency = len(merged)
+	}
+	home := repoInitHomeDir
+	for _, env := range containers[merged[0]].Env {
+		if env.Name == "HOME" {
+			home = env.Value
+		}
+	}
+	jobs := make([]string, 0, len(merged))
+	parallel := corev1.Container{
+		Name:                   repoParallelInitContainerName,
+		Image:                  defaultImage,
+		Command:                []string{"/bin/sh", "-ec", repoParallelInitScript},
+		TerminationMessagePath: corev1.TerminationMessagePathDefault,
+	}
+	var jobEnv []corev1.EnvVar
+	for _, i := range merged {
+		job := strconv.Itoa(indexes[i])
+		jobs = append(jobs, job)
+		names := make([]string, 0, len(containers[i].Env))
+		for _, env := range containers[i].Env {
+			names = append(names, env.Name)
+			env.Name = repoParallelJobEnvName(job, env.Name)
+			jobEnv = append(jobEnv, env)
+		}
+		sort.Strings(names)
+		jobEnv = append(jobEnv, corev1.EnvVar{Name: repoParallelJobEnvName(job, "VARS"), Value: strings.Join(names, " ")})
+		parallel.VolumeMounts = appendUniqueMounts(parallel.VolumeMounts, containers[i].VolumeMounts...)
+	}
+	parallel.Env = append([]corev1.EnvVar{
+		{Name: "HOME", Value: home},
+		{Name: "SPRITZ_REPO_JOBS", Value: strings.Join(jobs, " ")},
+		{Name: "SPRITZ_REPO_PARALLEL_CONCURRENCY", Value: strconv.Itoa(concurrency)},
+		{Name: "SPRITZ_REPO_STATUS_FILE", Value: corev1.TerminationMessagePathDefault},
+		{Name: "SPRITZ_REPO_INIT_SCRIPT", Value: repoInitScript},
+		{Name: "SPRITZ_REPO_AUTH_SETUP_SCRIPT", Value: repoAuthSetupScript},
+	}, jobEnv...)
+
+	result := make([]corev1.Container, 0, len(containers)-len(merged)+1)
+	for i, container := range containers {
+		switch {
+		case i == merged[0]:
+			result = append(result, parallel)
+		case container.Image != defaultImage || nested[i]:
+			result = append(result, container)
+		}
+	}
+	return result
+}
+
+// nestedRepoInitContainers marks the containers whose SPRITZ_REPO_DIR is
+// equal to, inside, or a parent of another container's.
+func nestedRepoInitContainers(containers []corev1.Container) map[int]bool {
+	dirs := make([]string, len(containers))
+	for i, container := range containers {
+		for _, env := range container.Env {
+			if env.Name == "SPRITZ_REPO_DIR" {
+				dirs[i] = path.Clean(env.Value)
+			}
+		}
+	}
+	nested := map[int]bool{}
+	for i := range dirs {
+		for j := i + 1; j < len(dirs); j++ {
+			if dirs[i] == "" || dirs[j] == "" {
+				continue
+			}
+			if repoDirsOverlap(dirs[i], dirs[j]) {
+				nested[i] = true
+				nested[j] = true
+			}
+		}
+	}
+	return nested
+}
+
+func repoDirsOverlap(a, b string) bool {
+	if a == b {
+		return true
+	}
+	return strings.HasPrefix(a, strings.TrimSuffix(b, "/")+"/") || strings.HasPrefix(b, strings.TrimSuffix(a, "/")+"/")
+}
+
+func repoParallelJobEnvName(job, name string) string {
+	return fmt.Sprintf("SPRITZ_REPO_JOB_%s_%s", job, name)
+}
+
+// parseParallelRepoStatusMessage splits the termination message of the
+// parallel repo init container into per-repo messages keyed by repo index.
+func parseParallelRepoStatusMessage(message string) map[int]string {
+	messages := map[int]string{}
+	current := -1
+	for _, line := range strings.Split(message, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "repo="); ok {
+			index, err := strconv.Atoi(value)
+			if err != nil {
+				current = -1
+				continue
+			}
+			current = index
+			messages[current] = ""
+			continue
+		}
+		if current >= 0 {
+			messages[current] += line + "\n"
+		}
+	}
+	return messages
+}
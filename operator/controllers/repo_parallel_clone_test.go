@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestBuildRepoInitContainersMergesReposWhenParallelCloneEnabled(t *testing.T) {
+	t.Setenv("SPRITZ_GIT_INIT_IMAGE", "example.com/git-init:1.0")
+	t.Setenv("SPRITZ_REPO_PARALLEL_CLONE", "true")
+	t.Setenv("SPRITZ_REPO_PARALLEL_CLONE_CONCURRENCY", "2")
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Repos: []spritzv1.SpritzRepo{
+				{URL: "https://example.com/acme/app.git"},
+				{URL: "https://example.com/acme/assets.git", InitImage: "example.com/git-lfs:2.0"},
+				{URL: "https://example.com/acme/api.git"},
+				{URL: "https://example.com/acme/web.git"},
+			},
+		},
+	}
+
+	containers, _, err := buildRepoInitContainers(spritz, repoEntries(spritz), buildHomeMounts())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("expected parallel container plus custom image container, got %d", len(containers))
+	}
+	parallel := containers[0]
+	if parallel.Name != repoParallelInitContainerName || parallel.Image != "example.com/git-init:1.0" {
+		t.Fatalf("unexpected parallel container %q (%s)", parallel.Name, parallel.Image)
+	}
+	if containers[1].Name != repoInitContainerName(1) || containers[1].Image != "example.com/git-lfs:2.0" {
+		t.Fatalf("expected repo with initImage to keep its own container, got %q (%s)", containers[1].Name, containers[1].Image)
+	}
+	if value, _ := envValue(parallel.Env, "SPRITZ_REPO_JOBS"); value != "0 2 3" {
+		t.Fatalf("unexpected jobs %q", value)
+	}
+	if value, _ := envValue(parallel.Env, "SPRITZ_REPO_PARALLEL_CONCURRENCY"); value != "2" {
+		t.Fatalf("unexpected concurrency %q", value)
+	}
+	if value, _ := envValue(parallel.Env, "SPRITZ_REPO_JOB_2_SPRITZ_REPO_URL"); value != "https://example.com/acme/api.git" {
+		t.Fatalf("expected per-job repo url, got %q", value)
+	}
+}
+
+func TestBuildRepoInitContainersKeepsNestedReposSequential(t *testing.T) {
+	t.Setenv("SPRITZ_GIT_INIT_IMAGE", "example.com/git-init:1.0")
+	t.Setenv("SPRITZ_REPO_PARALLEL_CLONE", "true")
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Repos: []spritzv1.SpritzRepo{
+				{URL: "https://example.com/acme/api.git", Dir: "/workspace/api"},
+				{URL: "https://example.com/acme/app.git", Dir: "/workspace/app"},
+				{URL: "https://example.com/acme/web.git", Dir: "/workspace/web"},
+				{URL: "https://example.com/acme/lib.git", Dir: "/workspace/app/lib"},
+				{URL: "https://example.com/acme/application.git", Dir: "/workspace/application"},
+			},
+		},
+	}
+
+	containers, _, err := buildRepoInitContainers(spritz, repoEntries(spritz), buildHomeMounts())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := make([]string, 0, len(containers))
+	for _, container := range containers {
+		names = append(names, container.Name)
+	}
+	want := []string{repoParallelInitContainerName, repoInitContainerName(1), repoInitContainerName(3)}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected nested repos to keep ordered containers %v, got %v", want, names)
+	}
+	if value, _ := envValue(containers[0].Env, "SPRITZ_REPO_JOBS"); value != "0 2 4" {
+		t.Fatalf("expected only non-nested repos in the parallel container, got %q", value)
+	}
+}
+
+func TestBuildRepoInitContainersKeepsSingleRepoSequential(t *testing.T) {
+	t.Setenv("SPRITZ_REPO_PARALLEL_CLONE", "true")
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Repos: []spritzv1.SpritzRepo{{URL: "https://example.com/acme/app.git"}},
+		},
+	}
+
+	containers, _, err := buildRepoInitContainers(spritz, repoEntries(spritz), buildHomeMounts())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(containers) != 1 || containers[0].Name != repoInitContainerName(0) {
+		t.Fatalf("expected the regular repo init container, got %#v", containers)
+	}
+}
+
+func TestRepoParallelInitScriptClonesEveryRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("SPRITZ_REPO_PARALLEL_CLONE", "true")
+	root := t.TempDir()
+	var repos []spritzv1.SpritzRepo
+	for _, name := range []string{"app", "api", "web"} {
+		originDir := filepath.Join(root, "origin", name)
+		if err := os.MkdirAll(originDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{
+			{"init", "-q", "-b", "main"},
+			{"-c", "user.name=Example", "-c", "user.email=user@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+		} {
+			if out, err := exec.Command("git", append([]string{"-C", originDir}, args...)...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v: %s", args, err, out)
+			}
+		}
+		repos = append(repos, spritzv1.SpritzRepo{URL: originDir, Dir: filepath.Join(root, "workspace", name)})
+	}
+	spritz := &spritzv1.Spritz{Spec: spritzv1.SpritzSpec{Repos: repos}}
+
+	containers, _, err := buildRepoInitContainers(spritz, repoEntries(spritz), buildHomeMounts())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(containers) != 1 {
+		t.Fatalf("expected one parallel container, got %d", len(containers))
+	}
+	statusFile := filepath.Join(root, "status")
+	env := os.Environ()
+	for _, item := range containers[0].Env {
+		switch item.Name {
+		case "HOME":
+			item.Value = root
+		case "SPRITZ_REPO_STATUS_FILE":
+			item.Value = statusFile
+		}
+		env = append(env, item.Name+"="+item.Value)
+	}
+	cmd := exec.Command(containers[0].Command[0], containers[0].Command[1:]...)
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("parallel repo init script failed: %v: %s", err, out)
+	}
+
+	for _, repo := range repos {
+		if _, err := os.Stat(filepath.Join(repo.Dir, ".git")); err != nil {
+			t.Fatalf("expected %s to be cloned: %v", repo.URL, err)
+		}
+	}
+	data, err := os.ReadFile(statusFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := parseParallelRepoStatusMessage(string(data))
+	for i := range repos {
+		if status := parseRepoStatusMessage(messages[i]); status.Branch != "main" || status.Commit == "" {
+			t.Fatalf("unexpected status for repo %d: %#v", i, status)
+		}
+	}
+}
+
+func TestParseParallelRepoStatusMessage(t *testing.T) {
+	messages := parseParallelRepoStatusMessage("repo=0\nbranch=main\ncommit=abc123\nrepo=2\nbranch=\ncommit=def456\n")
+	if len(messages) != 2 {
+		t.Fatalf("expected two repos, got %#v", messages)
+	}
+	if status := parseRepoStatusMessage(messages[0]); status.Branch != "main" || status.Commit != "abc123" {
+		t.Fatalf("unexpected status for repo 0: %#v", status)
+	}
+	if status := parseRepoStatusMessage(messages[2]); !status.Detached || status.Commit != "def456" {
+		t.Fatalf("unexpected status for repo 2: %#v", status)
+	}
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func repoInitContainerName(index int) string {
+	return fmt.Sprintf("repo-init-%d", index)
+}
+
+// observeRepoStatuses reads the checkout each repo init container reported via
+// its termination message. It returns nil when no pod has finished repo init yet.
+func (r *SpritzReconciler) observeRepoStatuses(
+	ctx context.Context,
+	spritz *spritzv1.Spritz,
+	deploy *appsv1.Deployment,
+) ([]spritzv1.SpritzRepoStatus, error) {
+	repos := repoEntries(spritz)
+	if len(repos) == 0 {
+		return []spritzv1.SpritzRepoStatus{}, nil
+	}
+	newest, err := r.newestSpritzPod(ctx, spritz, deploy)
+	if err != nil || newest == nil {
+		return nil, err
+	}
+
+	messages := map[string]string{}
+	for _, status := range newest.Status.InitContainerStatuses {
+		if status.State.Terminated == nil || status.State.Terminated.ExitCode != 0 {
+			continue
+		}
+		if status.Name == repoParallelInitContainerName {
+			for index, message := range parseParallelRepoStatusMessage(status.State.Terminated.Message) {
+				messages[repoInitContainerName(index)] = message
+			}
+			continue
+		}
+		messages[status.Name] = status.State.Terminated.Message
+	}
+
+	var statuses []spritzv1.SpritzRepoStatus
+	for i, repo := range repos {
+		if strings.TrimSpace(repo.URL) == "" {
+			continue
+		}
+		message, ok := messages[repoInitContainerName(i)]
+		if !ok {
+			return nil, nil
+		}
+		status := parseRepoStatusMessage(message)
+		status.Dir = repoDirFor(repo, i, len(repos))
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// newestSpritzPod returns the most recently created live pod of the
+// deployment, or nil when there is none.
+func (r *SpritzReconciler) newestSpritzPod(
+	ctx context.Context,
+	spritz *spritzv1.Spritz,
+	deploy *appsv1.Deployment,
+) (*corev1.Pod, error) {
+	pods, err := r.liveSpritzPods(ctx, spritz, deploy)
+	if err != nil {
+		return nil, err
+	}
+
+	var newest *corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if newest == nil || newest.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			newest = pod
+		}
+	}
+	return newest, nil
+}
+
+// liveSpritzPods lists the deployment's pods that are not being deleted.
+func (r *SpritzReconciler) liveSpritzPods(
+	ctx context.Context,
+	spritz *spritzv1.Spritz,
+	deploy *appsv1.Deployment,
+) ([]corev1.Pod, error) {
+	selector := deploymentSelectorLabels(spritz)
+	if deploy.Spec.Selector != nil && len(deploy.Spec.Selector.MatchLabels) > 0 {
+		selector = deploy.Spec.Selector.MatchLabels
+	}
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(spritz.Namespace), client.MatchingLabels(selector)); err != nil {
+		return nil, err
+	}
+
+	live := make([]corev1.Pod, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil {
+			live = append(live, pod)
+		}
+	}
+	return live, nil
+}
+
+// parseRepoStatusMessage parses the `key=value` lines written by repoInitScript.
+func parseRepoStatusMessage(message string) spritzv1.SpritzRepoStatus {
+	status := spritzv1.SpritzRepoStatus{}
+	for _, line := range strings.Split(message, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "branch":
+			status.Branch = value
+		case "commit":
+			status.Commit = value
+		}
+	}
+	status.Detached = status.Commit != "" && status.Branch == ""
+	return status
+}
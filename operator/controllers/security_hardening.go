@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+const (
+	tmpVolumeName = "tmp"
+	tmpMountPath  = "/tmp"
+)
+
+var defaultTmpSizeLimit = resource.MustParse("1Gi")
+
+func readOnlyRootFilesystemEnabled(spritz *spritzv1.Spritz) bool {
+	return spritz != nil && spritz.Spec.SecurityHardening != nil && spritz.Spec.SecurityHardening.ReadOnlyRootFilesystem
+}
+
+// applyReadOnlyRootFilesystem marks the root filesystem of every container
+// read-only and gives each one a writable /tmp. It runs on the finished pod
+// spec so repo init, init script and shared mount syncer containers are
+// covered as well; they already write only to mounted volumes and /tmp.
+func applyReadOnlyRootFilesystem(podSpec *corev1.PodSpec) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: tmpVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: emptyDirSizeLimit("SPRITZ_TMP_SIZE_LIMIT", defaultTmpSizeLimit)},
+		},
+	})
+	for i := range podSpec.InitContainers {
+		hardenContainerRootFilesystem(&podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		hardenContainerRootFilesystem(&podSpec.Containers[i])
+	}
+}
+
+func hardenContainerRootFilesystem(container *corev1.Container) {
+	if container.SecurityContext == nil {
+		container.SecurityContext = &corev1.SecurityContext{}
+	}
+	readOnly := true
+	container.SecurityContext.ReadOnlyRootFilesystem = &readOnly
+	for _, mount := range container.VolumeMounts {
+		if path.Clean(mount.MountPath) == tmpMountPath {
+			return
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: tmpVolumeName, MountPath: tmpMountPath})
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+	"spritz.sh/operator/sharedmounts"
+)
+
+func TestReconcileDeploymentAppliesReadOnlyRootFilesystem(t *testing.T) {
+	t.Setenv("SPRITZ_SHARED_MOUNTS_API_URL", "http://spritz-api.svc.cluster.local:8080")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_TOKEN_SECRET_NAME", "spritz-shared-mounts-internal-token")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_SYNCER_IMAGE", "spritz-api:latest")
+	t.Setenv("SPRITZ_TMP_SIZE_LIMIT", "256Mi")
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.SecurityHardening = &spritzv1.SpritzSecurityHardening{ReadOnlyRootFilesystem: true}
+	spritz.Spec.Repo = &spritzv1.SpritzRepo{URL: "https://git.example.com/example-org/app.git"}
+	spritz.Spec.InitScript = "echo ready"
+	spritz.Spec.SharedMounts = []sharedmounts.MountSpec{
+		sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "config", MountPath: "/home/dev/.config"}),
+	}
+	deployment := reconcileTestDeployment(t, spritz)
+	podSpec := deployment.Spec.Template.Spec
+
+	var tmpVolume *corev1.Volume
+	for i := range podSpec.Volumes {
+		if podSpec.Volumes[i].Name == tmpVolumeName {
+			tmpVolume = &podSpec.Volumes[i]
+		}
+	}
+	if tmpVolume == nil || tmpVolume.EmptyDir == nil || tmpVolume.EmptyDir.SizeLimit.String() != "256Mi" {
+		t.Fatalf("expected 256Mi /tmp emptyDir, got %#v", tmpVolume)
+	}
+
+	containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	names := map[string]bool{}
+	for _, container := range containers {
+		names[container.Name] = true
+		if container.SecurityContext == nil || container.SecurityContext.ReadOnlyRootFilesystem == nil || !*container.SecurityContext.ReadOnlyRootFilesystem {
+			t.Fatalf("expected read-only root filesystem on %s", container.Name)
+		}
+		if !hasVolumeMount(container.VolumeMounts, tmpVolumeName, tmpMountPath) {
+			t.Fatalf("expected writable /tmp on %s, got %#v", container.Name, container.VolumeMounts)
+		}
+	}
+	for _, name := range []string{spritzContainerName, repoInitContainerName(0), initScriptContainerName} {
+		if !names[name] {
+			t.Fatalf("expected container %s in pod, got %v", name, names)
+		}
+	}
+	if len(containers) < 5 {
+		t.Fatalf("expected shared mount syncer containers to be hardened too, got %v", names)
+	}
+	if !hasVolumeMount(podSpec.Containers[0].VolumeMounts, "workspace", "/workspace") ||
+		!hasVolumeMount(podSpec.Containers[0].VolumeMounts, "home", repoInitHomeDir) {
+		t.Fatalf("expected workspace and home to stay writable, got %#v", podSpec.Containers[0].VolumeMounts)
+	}
+}
+
+func TestReconcileDeploymentKeepsWritableRootByDefault(t *testing.T) {
+	deployment := reconcileTestDeployment(t, newPodSpecTestSpritz())
+	podSpec := deployment.Spec.Template.Spec
+	if podSpec.Containers[0].SecurityContext != nil {
+		t.Fatalf("expected no container security context, got %#v", podSpec.Containers[0].SecurityContext)
+	}
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == tmpVolumeName {
+			t.Fatal("expected no /tmp volume without hardening")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// parseSecurityProfileEnv splits an operator default such as "RuntimeDefault"
+// or "Localhost:profiles/workspace.json" into its type and localhost profile.
+func parseSecurityProfileEnv(raw string) (string, *string) {
+	profileType, localhost, found := strings.Cut(strings.TrimSpace(raw), ":")
+	profileType = strings.TrimSpace(profileType)
+	if !found {
+		return profileType, nil
+	}
+	localhost = strings.TrimSpace(localhost)
+	return profileType, &localhost
+}
+
+func loadDefaultSeccompProfile() (*corev1.SeccompProfile, error) {
+	raw := strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_SECCOMP_PROFILE"))
+	if raw == "" {
+		return nil, nil
+	}
+	profileType, localhost := parseSecurityProfileEnv(raw)
+	profile := &corev1.SeccompProfile{Type: corev1.SeccompProfileType(profileType), LocalhostProfile: localhost}
+	if err := spritzv1.ValidateSeccompProfile(profile); err != nil {
+		return nil, fmt.Errorf("invalid SPRITZ_DEFAULT_SECCOMP_PROFILE: %w", err)
+	}
+	return profile, nil
+}
+
+func loadDefaultAppArmorProfile() (*corev1.AppArmorProfile, error) {
+	raw := strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_APPARMOR_PROFILE"))
+	if raw == "" {
+		return nil, nil
+	}
+	profileType, localhost := parseSecurityProfileEnv(raw)
+	profile := &corev1.AppArmorProfile{Type: corev1.AppArmorProfileType(profileType), LocalhostProfile: localhost}
+	if err := spritzv1.ValidateAppArmorProfile(profile); err != nil {
+		return nil, fmt.Errorf("invalid SPRITZ_DEFAULT_APPARMOR_PROFILE: %w", err)
+	}
+	return profile, nil
+}
+
+// validateSecurityProfiles checks the profiles set on the spritz spec.
+func validateSecurityProfiles(spritz *spritzv1.Spritz) error {
+	if err := spritzv1.ValidateSeccompProfile(spritz.Spec.SeccompProfile); err != nil {
+		return err
+	}
+	return spritzv1.ValidateAppArmorProfile(spritz.Spec.AppArmorProfile)
+}
+
+// applyPodSecurityProfiles sets the seccomp and AppArmor profiles on the pod
+// security context, so every container inherits them. The spec wins over the
+// operator defaults.
+func applyPodSecurityProfiles(podSpec *corev1.PodSpec, spritz *spritzv1.Spritz) error {
+	if err := validateSecurityProfiles(spritz); err != nil {
+		return err
+	}
+	seccomp := spritz.Spec.SeccompProfile.DeepCopy()
+	if seccomp == nil {
+		defaultProfile, err := loadDefaultSeccompProfile()
+		if err != nil {
+			return err
+		}
+		seccomp = defaultProfile
+	}
+	appArmor := spritz.Spec.AppArmorProfile.DeepCopy()
+	if appArmor == nil {
+		defaultProfile, err := loadDefaultAppArmorProfile()
+		if err != nil {
+			return err
+		}
+		appArmor = defaultProfile
+	}
+	if seccomp == nil && appArmor == nil {
+		return nil
+	}
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	podSpec.SecurityContext.SeccompProfile = seccomp
+	podSpec.SecurityContext.AppArmorProfile = appArmor
+	return nil
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileDeploymentAppliesDefaultSecurityProfiles(t *testing.T) {
+	t.Setenv("SPRITZ_DEFAULT_SECCOMP_PROFILE", "RuntimeDefault")
+	t.Setenv("SPRITZ_DEFAULT_APPARMOR_PROFILE", "Localhost:spritz-workspace")
+	deployment := reconcileTestDeployment(t, newPodSpecTestSpritz())
+
+	securityContext := deployment.Spec.Template.Spec.SecurityContext
+	if securityContext == nil || securityContext.SeccompProfile == nil || securityContext.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Fatalf("expected RuntimeDefault seccomp profile, got %#v", securityContext)
+	}
+	appArmor := securityContext.AppArmorProfile
+	if appArmor == nil || appArmor.Type != corev1.AppArmorProfileTypeLocalhost || appArmor.LocalhostProfile == nil || *appArmor.LocalhostProfile != "spritz-workspace" {
+		t.Fatalf("expected localhost AppArmor profile, got %#v", appArmor)
+	}
+}
+
+func TestReconcileDeploymentSpecSeccompProfileOverridesDefault(t *testing.T) {
+	t.Setenv("SPRITZ_DEFAULT_SECCOMP_PROFILE", "RuntimeDefault")
+	localhost := "profiles/workspace.json"
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: &localhost}
+	deployment := reconcileTestDeployment(t, spritz)
+
+	profile := deployment.Spec.Template.Spec.SecurityContext.SeccompProfile
+	if profile == nil || profile.Type != corev1.SeccompProfileTypeLocalhost || *profile.LocalhostProfile != localhost {
+		t.Fatalf("expected spec seccomp profile, got %#v", profile)
+	}
+	if deployment.Spec.Template.Spec.SecurityContext.AppArmorProfile != nil {
+		t.Fatal("expected no AppArmor profile without a default")
+	}
+}
+
+func TestReconcileDeploymentRejectsInvalidSecurityProfiles(t *testing.T) {
+	t.Setenv("SPRITZ_DEFAULT_SECCOMP_PROFILE", "Localhost")
+	spritz := newPodSpecTestSpritz()
+	scheme := newControllerTestScheme(t)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	if err := reconciler.reconcileDeployment(context.Background(), spritz); err == nil {
+		t.Fatal("expected Localhost default without a profile path to be rejected")
+	}
+
+	t.Setenv("SPRITZ_DEFAULT_SECCOMP_PROFILE", "")
+	spritz.Spec.AppArmorProfile = &corev1.AppArmorProfile{Type: "Strict"}
+	if err := reconciler.reconcileDeployment(context.Background(), spritz); err == nil {
+		t.Fatal("expected unknown AppArmor profile type to be rejected")
+	}
+}
+
+func TestReconcileDeploymentLeavesSecurityContextUnsetWithoutProfiles(t *testing.T) {
+	deployment := reconcileTestDeployment(t, newPodSpecTestSpritz())
+	if deployment.Spec.Template.Spec.SecurityContext != nil {
+		t.Fatalf("expected no pod security context, got %#v", deployment.Spec.Template.Spec.SecurityContext)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"slices"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -22,16 +23,35 @@ type sharedMountsSettings struct {
 	apiURL                string
 	tokenSecretName       string
 	tokenSecretKey        string
+	ownerTokenKey         string
 	syncerImage           string
 	syncerImagePullPolicy corev1.PullPolicy
+	// syncerImages lists extra images mounts may select with syncerImage, so a
+	// new syncer can be rolled out to some mounts before it becomes the default.
+	syncerImages []string
+	// nativeSidecars runs the syncer as an init container with restartPolicy
+	// Always (Kubernetes 1.29+), so it starts before and stops after the app.
+	nativeSidecars bool
+	// syncerTuning forwards optional publish timing overrides to the syncer.
+	syncerTuning []corev1.EnvVar
 }
 
 type sharedMountRuntime struct {
-	volumes          []corev1.Volume
-	volumeMounts     []corev1.VolumeMount
-	initContainer    *corev1.Container
-	sidecarContainer *corev1.Container
-	env              []corev1.EnvVar
+	volumes           []corev1.Volume
+	volumeMounts      []corev1.VolumeMount
+	initContainers    []corev1.Container
+	sidecarContainers []corev1.Container
+	env               []corev1.EnvVar
+}
+
+// sharedMountsReadyPort is where the first syncer sidecar serves /ready;
+// additional per-image syncers use the following free ports.
+const sharedMountsReadyPort = int32(8091)
+
+// sharedMountSyncerGroup is the set of mounts served by one syncer image.
+type sharedMountSyncerGroup struct {
+	image  string
+	mounts []sharedmounts.MountSpec
 }
 
 func loadSharedMountsSettings() (sharedMountsSettings, error) {
@@ -50,34 +70,64 @@ func loadSharedMountsSettings() (sharedMountsSettings, error) {
 	if tokenSecretKey == "" {
 		tokenSecretKey = "token"
 	}
+	ownerTokenKey := strings.TrimSpace(os.Getenv("SPRITZ_SHARED_MOUNTS_OWNER_TOKEN_KEY"))
 	apiURL = normalizeSharedMountsAPIURL(apiURL, spritzv1.SharedHostRouteModelFromEnv().APIPathPrefix)
 	syncerImage := strings.TrimSpace(os.Getenv("SPRITZ_SHARED_MOUNTS_SYNCER_IMAGE"))
-	enabled := apiURL != "" || tokenSecretName != "" || syncerImage != "" || len(mounts) > 0
+	enabled := apiURL != "" || tokenSecretName != "" || ownerTokenKey != "" || syncerImage != "" || len(mounts) > 0
 	if !enabled {
 		return sharedMountsSettings{enabled: false, mounts: mounts}, nil
 	}
 	if apiURL == "" {
 		return sharedMountsSettings{}, fmt.Errorf("SPRITZ_SHARED_MOUNTS_API_URL is required when shared mounts are enabled")
 	}
-	if tokenSecretName == "" {
-		return sharedMountsSettings{}, fmt.Errorf("SPRITZ_SHARED_MOUNTS_TOKEN_SECRET_NAME is required when shared mounts are enabled")
+	if tokenSecretName == "" && ownerTokenKey == "" {
+		return sharedMountsSettings{}, fmt.Errorf("SPRITZ_SHARED_MOUNTS_TOKEN_SECRET_NAME or SPRITZ_SHARED_MOUNTS_OWNER_TOKEN_KEY is required when shared mounts are enabled")
 	}
 	if syncerImage == "" {
 		return sharedMountsSettings{}, fmt.Errorf("SPRITZ_SHARED_MOUNTS_SYNCER_IMAGE is required when shared mounts are enabled")
 	}
+	if err := sharedmounts.ValidateSyncerImage(syncerImage); err != nil {
+		return sharedMountsSettings{}, fmt.Errorf("invalid SPRITZ_SHARED_MOUNTS_SYNCER_IMAGE: %w", err)
+	}
+	syncerImages := []string{}
+	for _, image := range strings.Split(os.Getenv("SPRITZ_SHARED_MOUNTS_SYNCER_IMAGES"), ",") {
+		image = strings.TrimSpace(image)
+		if image == "" {
+			continue
+		}
+		if err := sharedmounts.ValidateSyncerImage(image); err != nil {
+			return sharedMountsSettings{}, fmt.Errorf("invalid SPRITZ_SHARED_MOUNTS_SYNCER_IMAGES: %w", err)
+		}
+		syncerImages = append(syncerImages, image)
+	}
 	pullPolicy := corev1.PullIfNotPresent
 	if rawPolicy := strings.TrimSpace(os.Getenv("SPRITZ_SHARED_MOUNTS_SYNCER_IMAGE_PULL_POLICY")); rawPolicy != "" {
 		pullPolicy = corev1.PullPolicy(rawPolicy)
 	}
 
+	syncerTuning := []corev1.EnvVar{}
+	for _, name := range []string{
+		"SPRITZ_SHARED_MOUNTS_DEBOUNCE",
+		"SPRITZ_SHARED_MOUNTS_MAX_PUBLISH_INTERVAL",
+		"SPRITZ_SHARED_MOUNTS_ATOMIC_GROUP",
+	} {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			syncerTuning = append(syncerTuning, corev1.EnvVar{Name: name, Value: value})
+		}
+	}
+
 	return sharedMountsSettings{
 		enabled:               true,
 		mounts:                mounts,
 		apiURL:                apiURL,
 		tokenSecretName:       tokenSecretName,
 		tokenSecretKey:        tokenSecretKey,
+		ownerTokenKey:         ownerTokenKey,
 		syncerImage:           syncerImage,
 		syncerImagePullPolicy: pullPolicy,
+		syncerImages:          syncerImages,
+		nativeSidecars:        parseBoolEnv("SPRITZ_USE_NATIVE_SIDECARS", false),
+		syncerTuning:          syncerTuning,
 	}, nil
 }
 
@@ -150,57 +200,168 @@ func buildSharedMountRuntime(spritz *spritzv1.Spritz, settings sharedMountsSetti
 		})
 		env = append(env, corev1.EnvVar{
 			Name:  sharedMountEnvKey(mount.Name),
-			Value: path.Join(mount.MountPath, "live"),
+			Value: path.Clean(mount.MountPath),
 		})
 	}
 
-	syncerEnv := []corev1.EnvVar{
-		{Name: "SPRITZ_SHARED_MOUNTS", Value: mustJSON(runtimeMounts)},
-		{Name: "SPRITZ_SHARED_MOUNTS_API_URL", Value: settings.apiURL},
-		{
-			Name: "SPRITZ_SHARED_MOUNTS_TOKEN",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{Name: settings.tokenSecretName},
-					Key:                  settings.tokenSecretKey,
-				},
-			},
-		},
-		{Name: "SPRITZ_OWNER_ID", Value: spritz.Spec.Owner.ID},
+	groups, err := groupSharedMountsBySyncerImage(runtimeMounts, settings)
+	if err != nil {
+		return sharedMountRuntime{}, err
 	}
 
 	syncerResources := defaultSharedMountSyncerResources()
+	readyPorts := sharedMountsReadyPorts(spritz, len(groups))
+	syncerProxyEnv := resolveProxySettings(spritz).withNoProxyURL(settings.apiURL).env()
+	initContainers := []corev1.Container{}
+	sidecarContainers := []corev1.Container{}
+	for i, group := range groups {
+		readyPort := readyPorts[i]
+		syncerEnv := []corev1.EnvVar{
+			{Name: "SPRITZ_SHARED_MOUNTS", Value: mustJSON(group.mounts)},
+			{Name: "SPRITZ_SHARED_MOUNTS_API_URL", Value: settings.apiURL},
+			sharedMountsTokenEnv(spritz, settings),
+			{Name: "SPRITZ_OWNER_ID", Value: spritz.Spec.Owner.ID},
+		}
+		syncerEnv = append(syncerEnv, settings.syncerTuning...)
+		syncerEnv = append(syncerEnv, syncerProxyEnv...)
+		for _, mount := range group.mounts {
+			env = append(env, corev1.EnvVar{
+				Name:  sharedMountReadyEnvKey(mount.Name),
+				Value: fmt.Sprintf("http://127.0.0.1:%d/ready?mount=%s", readyPort, url.QueryEscape(mount.Name)),
+			})
+		}
 
-	initContainer := corev1.Container{
-		Name:            "shared-mounts-init",
-		Image:           settings.syncerImage,
-		ImagePullPolicy: settings.syncerImagePullPolicy,
-		Command:         []string{"/usr/local/bin/spritz-shared-syncer"},
-		Args:            []string{"--mode=init"},
-		Env:             syncerEnv,
-		Resources:       syncerResources,
-		VolumeMounts:    sharedMountVolumeMounts(runtimeMounts),
-	}
-	sidecarContainer := corev1.Container{
-		Name:            "shared-mounts-syncer",
-		Image:           settings.syncerImage,
-		ImagePullPolicy: settings.syncerImagePullPolicy,
-		Command:         []string{"/usr/local/bin/spritz-shared-syncer"},
-		Args:            []string{"--mode=sidecar"},
-		Env:             syncerEnv,
-		Resources:       syncerResources,
-		VolumeMounts:    sharedMountVolumeMounts(runtimeMounts),
+		initContainers = append(initContainers, corev1.Container{
+			Name:            sharedMountSyncerContainerName("shared-mounts-init", i),
+			Image:           group.image,
+			ImagePullPolicy: settings.syncerImagePullPolicy,
+			Command:         []string{"/usr/local/bin/spritz-shared-syncer"},
+			Args:            []string{"--mode=init"},
+			Env:             syncerEnv,
+			Resources:       syncerResources,
+			VolumeMounts:    sharedMountVolumeMounts(group.mounts),
+		})
+		sidecarContainers = append(sidecarContainers, corev1.Container{
+			Name:            sharedMountSyncerContainerName("shared-mounts-syncer", i),
+			Image:           group.image,
+			ImagePullPolicy: settings.syncerImagePullPolicy,
+			Command:         []string{"/usr/local/bin/spritz-shared-syncer"},
+			Args:            []string{"--mode=sidecar"},
+			Env: append(slices.Clone(syncerEnv), corev1.EnvVar{
+				Name:  "SPRITZ_SHARED_MOUNTS_READY_ADDR",
+				Value: fmt.Sprintf(":%d", readyPort),
+			}),
+			Resources:    syncerResources,
+			VolumeMounts: sharedMountVolumeMounts(group.mounts),
+			ReadinessProbe: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{Path: "/ready", Port: intstrFromInt(readyPort)},
+				},
+				PeriodSeconds:    2,
+				TimeoutSeconds:   2,
+				FailureThreshold: 3,
+			},
+		})
+	}
+
+	if settings.nativeSidecars {
+		// Native sidecars start after the one-shot init syncers have populated the
+		// mounts, and are stopped only after the app containers exit.
+		restartAlways := corev1.ContainerRestartPolicyAlways
+		for i := range sidecarContainers {
+			sidecarContainers[i].RestartPolicy = &restartAlways
+		}
+		initContainers = append(initContainers, sidecarContainers...)
+		sidecarContainers = nil
 	}
 
 	return sharedMountRuntime{
-		volumes:          volumes,
-		volumeMounts:     mounts,
-		initContainer:    &initContainer,
-		sidecarContainer: &sidecarContainer,
-		env:              env,
+		volumes:           volumes,
+		volumeMounts:      mounts,
+		initContainers:    initContainers,
+		sidecarContainers: sidecarContainers,
+		env:               env,
 	}, nil
 }
 
+// sharedMountsReadyPorts picks count ports for the syncer sidecars' /ready
+// servers, starting at sharedMountsReadyPort. Containers in a pod share one
+// network namespace, so ports declared for the workload are skipped.
+func sharedMountsReadyPorts(spritz *spritzv1.Spritz, count int) []int32 {
+	taken := map[int32]bool{}
+	for _, port := range containerPorts(spritz) {
+		taken[port.ContainerPort] = true
+	}
+	for _, port := range spritz.Spec.Ports {
+		taken[port.ContainerPort] = true
+	}
+	if shouldExposeACP(spritz) {
+		taken[spritzv1.DefaultACPPort] = true
+	}
+	ports := make([]int32, 0, count)
+	for port := sharedMountsReadyPort; len(ports) < count; port++ {
+		if !taken[port] {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// groupSharedMountsBySyncerImage splits mounts into one group per syncer
+// image, keeping the default image first. Mounts may only select the default
+// image or one listed in settings.syncerImages.
+func groupSharedMountsBySyncerImage(mounts []sharedmounts.MountSpec, settings sharedMountsSettings) ([]sharedMountSyncerGroup, error) {
+	groups := []sharedMountSyncerGroup{{image: settings.syncerImage}}
+	for _, mount := range mounts {
+		image := mount.SyncerImage
+		if image == "" {
+			image = settings.syncerImage
+		}
+		if image != settings.syncerImage && !slices.Contains(settings.syncerImages, image) {
+			return nil, fmt.Errorf("shared mount %s requests syncer image %s, which is not allowed", mount.Name, image)
+		}
+		index := slices.IndexFunc(groups, func(group sharedMountSyncerGroup) bool { return group.image == image })
+		if index < 0 {
+			groups = append(groups, sharedMountSyncerGroup{image: image})
+			index = len(groups) - 1
+		}
+		groups[index].mounts = append(groups[index].mounts, mount)
+	}
+	if len(groups[0].mounts) == 0 {
+		groups = groups[1:]
+	}
+	return groups, nil
+}
+
+// sharedMountSyncerContainerName keeps the historical container names for the
+// first syncer and numbers any additional per-image syncers.
+func sharedMountSyncerContainerName(base string, index int) string {
+	if index == 0 {
+		return base
+	}
+	return fmt.Sprintf("%s-%d", base, index)
+}
+
+// sharedMountsTokenEnv gives the syncer a token that only works for the
+// workspace owner when an owner token key is configured, read from the
+// per-spritz Secret kept by reconcileSharedMountsOwnerToken, and the shared
+// internal token secret otherwise.
+func sharedMountsTokenEnv(spritz *spritzv1.Spritz, settings sharedMountsSettings) corev1.EnvVar {
+	secretName, secretKey := settings.tokenSecretName, settings.tokenSecretKey
+	if settings.ownerTokenKey != "" {
+		secretName, secretKey = sharedMountsOwnerTokenSecretName(spritz), sharedMountsOwnerTokenKey
+	}
+	return corev1.EnvVar{
+		Name: "SPRITZ_SHARED_MOUNTS_TOKEN",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  secretKey,
+			},
+		},
+	}
+}
+
 func sharedMountVolumeMounts(mounts []sharedmounts.MountSpec) []corev1.VolumeMount {
 	volumeMounts := []corev1.VolumeMount{}
 	for _, mount := range mounts {
@@ -225,17 +386,13 @@ func sharedMountVolumeName(name string) string {
 }
 
 func sharedMountEnvKey(name string) string {
-	trimmed := strings.TrimSpace(name)
-	upper := strings.ToUpper(trimmed)
-	var b strings.Builder
-	for _, r := range upper {
-		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
-			b.WriteRune(r)
-		} else {
-			b.WriteRune('_')
-		}
-	}
-	return fmt.Sprintf("SPRITZ_SHARED_MOUNT_%s_PATH", b.String())
+	return fmt.Sprintf("SPRITZ_SHARED_MOUNT_%s_PATH", sharedmounts.EnvName(name))
+}
+
+// sharedMountReadyEnvKey names the env var that gives the app a mount's
+// readiness URL, next to its SPRITZ_SHARED_MOUNT_<NAME>_PATH variable.
+func sharedMountReadyEnvKey(name string) string {
+	return strings.TrimSuffix(sharedMountEnvKey(name), "_PATH") + "_READY_URL"
 }
 
 func mustJSON(value any) string {
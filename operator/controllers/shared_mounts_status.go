@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+const sharedMountsSyncedCondition = "SharedMountsSynced"
+
+// observeSharedMountsSynced sets SharedMountsSynced from the readiness of the
+// newest pod's syncer sidecars. The sidecars' /ready probe fails until every
+// mount has completed its initial sync, so a not-ready syncer is a mount that
+// is not in place yet. Later sync failures are listed in the /ready body
+// rather than failing the probe. The condition is removed when the pod runs
+// no syncer sidecars and left unchanged while no pod exists.
+func (r *SpritzReconciler) observeSharedMountsSynced(
+	ctx context.Context,
+	spritz *spritzv1.Spritz,
+	deploy *appsv1.Deployment,
+) error {
+	pod, err := r.newestSpritzPod(ctx, spritz, deploy)
+	if err != nil || pod == nil {
+		return err
+	}
+	setSharedMountsSyncedCondition(&spritz.Status.Conditions, spritz.Generation, pod)
+	return nil
+}
+
+func setSharedMountsSyncedCondition(conditions *[]metav1.Condition, generation int64, pod *corev1.Pod) {
+	var syncers, notReady []string
+	statuses := append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if !strings.HasPrefix(status.Name, "shared-mounts-syncer") {
+			continue
+		}
+		syncers = append(syncers, status.Name)
+		if !status.Ready {
+			notReady = append(notReady, status.Name)
+		}
+	}
+	if len(syncers) == 0 {
+		meta.RemoveStatusCondition(conditions, sharedMountsSyncedCondition)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               sharedMountsSyncedCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "Synced",
+		Message:            "Shared mounts are synced.",
+	}
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "NotSynced"
+		condition.Message = fmt.Sprintf(
+			"Shared mount syncer %s has not completed its initial sync; its /ready endpoint lists each mount and its last error.",
+			strings.Join(notReady, ", "),
+		)
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
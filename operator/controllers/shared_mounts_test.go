@@ -1,8 +1,13 @@
 package controllers
 
 import (
+	"strings"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	spritzv1 "spritz.sh/operator/api/v1"
 	"spritz.sh/operator/sharedmounts"
 )
@@ -53,10 +58,10 @@ func TestBuildSharedMountRuntimeSkipsConfiguredSharedMountsWhenNothingRequestsTh
 	if len(runtime.env) != 0 {
 		t.Fatalf("expected no shared mount env vars, got %d", len(runtime.env))
 	}
-	if runtime.initContainer != nil {
+	if len(runtime.initContainers) != 0 {
 		t.Fatal("expected no shared mount init container")
 	}
-	if runtime.sidecarContainer != nil {
+	if len(runtime.sidecarContainers) != 0 {
 		t.Fatal("expected no shared mount sidecar container")
 	}
 }
@@ -95,7 +100,322 @@ func TestBuildSharedMountRuntimeHonorsExplicitSharedMountRequest(t *testing.T) {
 	if len(runtime.volumes) != 1 {
 		t.Fatalf("expected 1 shared mount volume, got %d", len(runtime.volumes))
 	}
-	if runtime.initContainer == nil || runtime.sidecarContainer == nil {
+	if len(runtime.initContainers) != 1 || len(runtime.sidecarContainers) != 1 {
 		t.Fatal("expected explicit shared mount request to wire shared mount sync containers")
 	}
 }
+
+func TestBuildSharedMountRuntimeUsesOwnerScopedToken(t *testing.T) {
+	spritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidy-otter", Namespace: "spritz-test"},
+		Spec: spritzv1.SpritzSpec{
+			Owner: spritzv1.SpritzOwner{ID: "owner-1"},
+			SharedMounts: []sharedmounts.MountSpec{
+				sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "config", MountPath: "/home/dev/.config"}),
+			},
+		},
+	}
+	settings := sharedMountsSettings{
+		enabled:       true,
+		apiURL:        "http://spritz-api.svc.cluster.local:8080/api",
+		ownerTokenKey: "owner-signing-key",
+		syncerImage:   "spritz-api:latest",
+	}
+
+	runtime, err := buildSharedMountRuntime(spritz, settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, container := range []corev1.Container{runtime.initContainers[0], runtime.sidecarContainers[0]} {
+		var token *corev1.EnvVar
+		for i := range container.Env {
+			if container.Env[i].Name == "SPRITZ_SHARED_MOUNTS_TOKEN" {
+				token = &container.Env[i]
+			}
+		}
+		if token == nil || token.Value != "" || token.ValueFrom == nil || token.ValueFrom.SecretKeyRef == nil {
+			t.Fatalf("expected %s to read the owner token from a secret, got %#v", container.Name, token)
+		}
+		ref := token.ValueFrom.SecretKeyRef
+		if ref.Name != "tidy-otter-shared-mounts-token" || ref.Key != sharedMountsOwnerTokenKey {
+			t.Fatalf("expected %s token from the per-spritz secret, got %#v", container.Name, ref)
+		}
+	}
+}
+
+func TestLoadSharedMountsSettingsForwardsSyncerPublishTiming(t *testing.T) {
+	t.Setenv("SPRITZ_SHARED_MOUNTS", `[{"name":"config","mountPath":"/home/dev/.config","scope":"owner"}]`)
+	t.Setenv("SPRITZ_SHARED_MOUNTS_API_URL", "http://spritz-api.svc.cluster.local:8080")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_TOKEN_SECRET_NAME", "spritz-shared-mounts-internal-token")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_SYNCER_IMAGE", "spritz-api:latest")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_MAX_PUBLISH_INTERVAL", "30s")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_ATOMIC_GROUP", "true")
+
+	settings, err := loadSharedMountsSettings()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Owner: spritzv1.SpritzOwner{ID: "owner-1"},
+			SharedMounts: []sharedmounts.MountSpec{
+				sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "config", MountPath: "/home/dev/.config"}),
+			},
+		},
+	}
+	runtime, err := buildSharedMountRuntime(spritz, settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := false
+	for _, env := range runtime.sidecarContainers[0].Env {
+		if env.Name == "SPRITZ_SHARED_MOUNTS_DEBOUNCE" {
+			t.Fatal("expected unset debounce to be left to the syncer default")
+		}
+		if env.Name == "SPRITZ_SHARED_MOUNTS_MAX_PUBLISH_INTERVAL" && env.Value == "30s" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected syncer to receive SPRITZ_SHARED_MOUNTS_MAX_PUBLISH_INTERVAL")
+	}
+	if value, _ := envValue(runtime.sidecarContainers[0].Env, "SPRITZ_SHARED_MOUNTS_ATOMIC_GROUP"); value != "true" {
+		t.Fatalf("expected syncer to receive SPRITZ_SHARED_MOUNTS_ATOMIC_GROUP, got %q", value)
+	}
+}
+
+func TestBuildSharedMountRuntimeSplitsSyncersByImage(t *testing.T) {
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Owner: spritzv1.SpritzOwner{ID: "owner-1"},
+			SharedMounts: []sharedmounts.MountSpec{
+				{Name: "config", MountPath: "/home/dev/.config"},
+				{Name: "cache", MountPath: "/home/dev/.cache", SyncerImage: "example.com/spritz-api:canary"},
+			},
+		},
+	}
+	settings := sharedMountsSettings{
+		enabled:         true,
+		apiURL:          "http://spritz-api.svc.cluster.local:8080/api",
+		tokenSecretName: "spritz-shared-mounts-internal-token",
+		tokenSecretKey:  "token",
+		syncerImage:     "example.com/spritz-api:stable",
+		syncerImages:    []string{"example.com/spritz-api:canary"},
+	}
+
+	runtime, err := buildSharedMountRuntime(spritz, settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runtime.initContainers) != 2 || len(runtime.sidecarContainers) != 2 {
+		t.Fatalf("expected one syncer per image, got %d init and %d sidecar containers", len(runtime.initContainers), len(runtime.sidecarContainers))
+	}
+	stable, canary := runtime.sidecarContainers[0], runtime.sidecarContainers[1]
+	if stable.Name != "shared-mounts-syncer" || stable.Image != "example.com/spritz-api:stable" {
+		t.Fatalf("expected default syncer first, got %s (%s)", stable.Name, stable.Image)
+	}
+	if canary.Name != "shared-mounts-syncer-1" || canary.Image != "example.com/spritz-api:canary" {
+		t.Fatalf("expected canary syncer second, got %s (%s)", canary.Name, canary.Image)
+	}
+	if len(canary.VolumeMounts) != 1 || canary.VolumeMounts[0].MountPath != "/home/dev/.cache" {
+		t.Fatalf("expected canary syncer to mount only its mount, got %#v", canary.VolumeMounts)
+	}
+
+	spritz.Spec.SharedMounts[1].SyncerImage = "example.com/other:latest"
+	if _, err := buildSharedMountRuntime(spritz, settings); err == nil {
+		t.Fatal("expected an unlisted syncer image to be rejected")
+	}
+}
+
+func TestBuildSharedMountRuntimeUsesNativeSidecars(t *testing.T) {
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Owner: spritzv1.SpritzOwner{ID: "owner-1"},
+			SharedMounts: []sharedmounts.MountSpec{
+				sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "config", MountPath: "/home/dev/.config"}),
+			},
+		},
+	}
+	settings := sharedMountsSettings{
+		enabled:         true,
+		apiURL:          "http://spritz-api.svc.cluster.local:8080/api",
+		tokenSecretName: "spritz-shared-mounts-internal-token",
+		tokenSecretKey:  "token",
+		syncerImage:     "spritz-api:latest",
+		nativeSidecars:  true,
+	}
+
+	runtime, err := buildSharedMountRuntime(spritz, settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runtime.sidecarContainers) != 0 {
+		t.Fatalf("expected no classic sidecars, got %d", len(runtime.sidecarContainers))
+	}
+	if len(runtime.initContainers) != 2 {
+		t.Fatalf("expected init syncer followed by native sidecar, got %d init containers", len(runtime.initContainers))
+	}
+	if runtime.initContainers[0].Name != "shared-mounts-init" || runtime.initContainers[0].RestartPolicy != nil {
+		t.Fatalf("expected one-shot init syncer first, got %#v", runtime.initContainers[0])
+	}
+	sidecar := runtime.initContainers[1]
+	if sidecar.Name != "shared-mounts-syncer" || sidecar.RestartPolicy == nil || *sidecar.RestartPolicy != corev1.ContainerRestartPolicyAlways {
+		t.Fatalf("expected native sidecar with restartPolicy Always, got %#v", sidecar)
+	}
+}
+
+func TestBuildSharedMountRuntimeExposesSyncerReadiness(t *testing.T) {
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Owner: spritzv1.SpritzOwner{ID: "owner-1"},
+			SharedMounts: []sharedmounts.MountSpec{
+				sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "config", MountPath: "/home/dev/.config"}),
+			},
+		},
+	}
+	settings := sharedMountsSettings{
+		enabled:         true,
+		apiURL:          "http://spritz-api.svc.cluster.local:8080/api",
+		tokenSecretName: "spritz-shared-mounts-internal-token",
+		tokenSecretKey:  "token",
+		syncerImage:     "spritz-api:latest",
+	}
+
+	runtime, err := buildSharedMountRuntime(spritz, settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sidecar := runtime.sidecarContainers[0]
+	if value, _ := envValue(sidecar.Env, "SPRITZ_SHARED_MOUNTS_READY_ADDR"); value != ":8091" {
+		t.Fatalf("expected sidecar readiness address, got %q", value)
+	}
+	if sidecar.ReadinessProbe == nil || sidecar.ReadinessProbe.HTTPGet == nil || sidecar.ReadinessProbe.HTTPGet.Path != "/ready" {
+		t.Fatalf("expected sidecar readiness probe, got %#v", sidecar.ReadinessProbe)
+	}
+	if _, ok := envValue(runtime.initContainers[0].Env, "SPRITZ_SHARED_MOUNTS_READY_ADDR"); ok {
+		t.Fatal("expected the one-shot init syncer not to serve readiness")
+	}
+	if value, _ := envValue(runtime.env, "SPRITZ_SHARED_MOUNT_CONFIG_READY_URL"); value != "http://127.0.0.1:8091/ready?mount=config" {
+		t.Fatalf("expected app readiness URL, got %q", value)
+	}
+}
+
+func TestBuildSharedMountRuntimeSkipsWorkloadPortsForReadiness(t *testing.T) {
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Owner: spritzv1.SpritzOwner{ID: "owner-1"},
+			Ports: []spritzv1.SpritzPort{
+				{Name: "admin", ContainerPort: 8091},
+				{Name: "metrics", ContainerPort: 8092},
+			},
+			SharedMounts: []sharedmounts.MountSpec{
+				sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "config", MountPath: "/home/dev/.config"}),
+				sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "cache", MountPath: "/home/dev/.cache", SyncerImage: "example.com/spritz-api:canary"}),
+			},
+		},
+	}
+	settings := sharedMountsSettings{
+		enabled:         true,
+		apiURL:          "http://spritz-api.svc.cluster.local:8080/api",
+		tokenSecretName: "spritz-shared-mounts-internal-token",
+		tokenSecretKey:  "token",
+		syncerImage:     "example.com/spritz-api:latest",
+		syncerImages:    []string{"example.com/spritz-api:canary"},
+	}
+
+	runtime, err := buildSharedMountRuntime(spritz, settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, want := range []string{":8093", ":8094"} {
+		if value, _ := envValue(runtime.sidecarContainers[i].Env, "SPRITZ_SHARED_MOUNTS_READY_ADDR"); value != want {
+			t.Fatalf("expected syncer %d to skip workload ports and use %s, got %q", i, want, value)
+		}
+	}
+	if port := runtime.sidecarContainers[0].ReadinessProbe.HTTPGet.Port.IntValue(); port != 8093 {
+		t.Fatalf("expected readiness probe on 8093, got %d", port)
+	}
+}
+
+func TestBuildSharedMountRuntimePointsPathEnvAtMountRoot(t *testing.T) {
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Owner: spritzv1.SpritzOwner{ID: "owner-1"},
+			SharedMounts: []sharedmounts.MountSpec{
+				sharedmounts.NormalizeMount(sharedmounts.MountSpec{Name: "config", MountPath: "/home/dev/.config/"}),
+			},
+		},
+	}
+	settings := sharedMountsSettings{
+		enabled:         true,
+		apiURL:          "http://spritz-api.svc.cluster.local:8080/api",
+		tokenSecretName: "spritz-shared-mounts-internal-token",
+		tokenSecretKey:  "token",
+		syncerImage:     "spritz-api:latest",
+	}
+
+	runtime, err := buildSharedMountRuntime(spritz, settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, _ := envValue(runtime.env, "SPRITZ_SHARED_MOUNT_CONFIG_PATH"); value != "/home/dev/.config" {
+		t.Fatalf("expected path env to point at the flat mount root, got %q", value)
+	}
+}
+
+func TestBuildSharedMountRuntimeRejectsEnvNameCollisions(t *testing.T) {
+	spritz := &spritzv1.Spritz{
+		Spec: spritzv1.SpritzSpec{
+			Owner: spritzv1.SpritzOwner{ID: "owner-1"},
+			SharedMounts: []sharedmounts.MountSpec{
+				{Name: "my-data", MountPath: "/home/dev/one"},
+				{Name: "my.data", MountPath: "/home/dev/two"},
+			},
+		},
+	}
+	settings := sharedMountsSettings{
+		enabled:         true,
+		apiURL:          "http://spritz-api.svc.cluster.local:8080/api",
+		tokenSecretName: "spritz-shared-mounts-internal-token",
+		tokenSecretKey:  "token",
+		syncerImage:     "spritz-api:latest",
+	}
+
+	if _, err := buildSharedMountRuntime(spritz, settings); err == nil {
+		t.Fatal("expected mounts with colliding env names to be rejected")
+	}
+}
+
+func TestSetSharedMountsSyncedConditionFromSyncerReadiness(t *testing.T) {
+	pod := &corev1.Pod{Status: corev1.PodStatus{
+		InitContainerStatuses: []corev1.ContainerStatus{
+			{Name: "shared-mounts-init", Ready: false},
+			{Name: "shared-mounts-syncer", Ready: true},
+		},
+		ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "spritz", Ready: true},
+			{Name: "shared-mounts-syncer-1", Ready: false},
+		},
+	}}
+	var conditions []metav1.Condition
+	setSharedMountsSyncedCondition(&conditions, 3, pod)
+	condition := meta.FindStatusCondition(conditions, sharedMountsSyncedCondition)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "NotSynced" {
+		t.Fatalf("expected NotSynced condition, got %#v", condition)
+	}
+	if !strings.Contains(condition.Message, "shared-mounts-syncer-1") || strings.Contains(condition.Message, "shared-mounts-syncer,") {
+		t.Fatalf("expected message to name only the failing syncer, got %q", condition.Message)
+	}
+
+	pod.Status.ContainerStatuses[1].Ready = true
+	setSharedMountsSyncedCondition(&conditions, 3, pod)
+	condition = meta.FindStatusCondition(conditions, sharedMountsSyncedCondition)
+	if condition == nil || condition.Status != metav1.ConditionTrue || condition.ObservedGeneration != 3 {
+		t.Fatalf("expected Synced condition, got %#v", condition)
+	}
+
+	setSharedMountsSyncedCondition(&conditions, 3, &corev1.Pod{})
+	if meta.FindStatusCondition(conditions, sharedMountsSyncedCondition) != nil {
+		t.Fatal("expected condition to be removed without syncer sidecars")
+	}
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+	"spritz.sh/operator/sharedmounts"
+)
+
+const (
+	// sharedMountsOwnerTokenKey is the key of the owner token in the per-spritz
+	// Secret.
+	sharedMountsOwnerTokenKey = "token"
+	// sharedMountsTokenChecksumAnnotationKey rolls the pod when the owner token
+	// changes, since env read from a Secret is only resolved at container start.
+	sharedMountsTokenChecksumAnnotationKey = "spritz.sh/shared-mounts-token-checksum"
+)
+
+func sharedMountsOwnerTokenSecretName(spritz *spritzv1.Spritz) string {
+	return spritz.Name + "-shared-mounts-token"
+}
+
+// usesSharedMountsOwnerToken reports whether the syncers of spritz read an
+// owner-scoped token from the per-spritz Secret.
+func usesSharedMountsOwnerToken(spritz *spritzv1.Spritz, settings sharedMountsSettings) bool {
+	return settings.ownerTokenKey != "" &&
+		spritz.Spec.Owner.ID != "" &&
+		len(resolveSharedMounts(spritz.Spec.SharedMounts, settings.mounts)) > 0
+}
+
+// reconcileSharedMountsOwnerToken keeps the owner-scoped syncer token in a
+// Secret owned by the spritz, so it is not readable from the Deployment or its
+// ReplicaSets. It returns a checksum of the token for the pod template, or ""
+// when the spritz does not use an owner token.
+func (r *SpritzReconciler) reconcileSharedMountsOwnerToken(ctx context.Context, spritz *spritzv1.Spritz, settings sharedMountsSettings) (string, error) {
+	if !usesSharedMountsOwnerToken(spritz, settings) {
+		return "", nil
+	}
+	token := sharedmounts.OwnerToken(settings.ownerTokenKey, spritz.Spec.Owner.ID)
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: sharedMountsOwnerTokenSecretName(spritz), Namespace: spritz.Namespace}}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if err := controllerutil.SetControllerReference(spritz, secret, r.Scheme); err != nil {
+			return err
+		}
+		secret.Labels = mergeMaps(secret.Labels, baseLabels(spritz))
+		secret.Type = corev1.SecretTypeOpaque
+		secret.Data = map[string][]byte{sharedMountsOwnerTokenKey: []byte(token)}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	logOwnedResourceDrift(ctx, spritz, "Secret", result)
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:]), nil
+}
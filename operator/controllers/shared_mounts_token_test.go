@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"spritz.sh/operator/sharedmounts"
+)
+
+func TestReconcileDeploymentKeepsOwnerTokenInSecret(t *testing.T) {
+	t.Setenv("SPRITZ_SHARED_MOUNTS", `[{"name":"config","mountPath":"/home/dev/.config","scope":"owner"}]`)
+	t.Setenv("SPRITZ_SHARED_MOUNTS_API_URL", "http://spritz-api.svc.cluster.local:8080")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_OWNER_TOKEN_KEY", "owner-signing-key")
+	t.Setenv("SPRITZ_SHARED_MOUNTS_SYNCER_IMAGE", "spritz-api:latest")
+
+	spritz := newPodSpecTestSpritz()
+	scheme := newControllerTestScheme(t)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+	if err := reconciler.reconcileDeployment(context.Background(), spritz); err != nil {
+		t.Fatalf("reconcileDeployment returned error: %v", err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := k8sClient.Get(context.Background(), client.ObjectKey{Name: "tidy-otter-shared-mounts-token", Namespace: spritz.Namespace}, secret); err != nil {
+		t.Fatalf("expected owner token secret: %v", err)
+	}
+	if !metav1.IsControlledBy(secret, spritz) {
+		t.Fatal("expected owner token secret to be controlled by the spritz")
+	}
+	if !sharedmounts.VerifyOwnerToken("owner-signing-key", "user-1", string(secret.Data[sharedMountsOwnerTokenKey])) {
+		t.Fatal("expected secret token to be scoped to user-1")
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := k8sClient.Get(context.Background(), client.ObjectKey{Name: spritz.Name, Namespace: spritz.Namespace}, deployment); err != nil {
+		t.Fatalf("failed to load deployment: %v", err)
+	}
+	if deployment.Spec.Template.Annotations[sharedMountsTokenChecksumAnnotationKey] == "" {
+		t.Fatal("expected token checksum on the pod template")
+	}
+	podSpec := deployment.Spec.Template.Spec
+	for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+		for _, env := range container.Env {
+			if env.Name == "SPRITZ_SHARED_MOUNTS_TOKEN" && env.Value != "" {
+				t.Fatalf("expected no inline token on %s", container.Name)
+			}
+		}
+	}
+}
+
+func TestReconcileSharedMountsOwnerTokenSkipsSpritzWithoutMounts(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	scheme := newControllerTestScheme(t)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	checksum, err := reconciler.reconcileSharedMountsOwnerToken(context.Background(), spritz, sharedMountsSettings{enabled: true, ownerTokenKey: "owner-signing-key"})
+	if err != nil || checksum != "" {
+		t.Fatalf("expected no token without shared mounts, got %q %v", checksum, err)
+	}
+	secrets := &corev1.SecretList{}
+	if err := k8sClient.List(context.Background(), secrets); err != nil {
+		t.Fatalf("list secrets: %v", err)
+	}
+	if len(secrets.Items) != 0 {
+		t.Fatalf("expected no secret, got %d", len(secrets.Items))
+	}
+}
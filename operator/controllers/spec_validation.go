@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"errors"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// ValidateSpritzSpec runs the spec checks that would otherwise leave a spritz
+// in the Error phase. It returns the status reason for the first failing
+// check, so the reconciler and the admission webhook report the same problem.
+// Checks that depend on operator settings (image pinning, hostPath) use the
+// environment of the calling process.
+func ValidateSpritzSpec(spritz *spritzv1.Spritz) (string, error) {
+	if spritz.Spec.Ingress != nil && ingressMode(spritz) == "gateway" {
+		if spritz.Spec.Ingress.Host == "" {
+			return "InvalidIngress", errors.New("ingress.host is required when ingress.mode=gateway")
+		}
+		if spritz.Spec.Ingress.GatewayName == "" {
+			return "InvalidIngress", errors.New("ingress.gatewayName is required when ingress.mode=gateway")
+		}
+	}
+	if err := spritzv1.ValidateDNS(spritz.Spec.DNSPolicy, spritz.Spec.DNSConfig); err != nil {
+		return "InvalidDNSConfig", err
+	}
+	repos := repoEntries(spritz)
+	for i, repo := range repos {
+		if err := validateRepoDir(repo.Dir); err != nil {
+			return "InvalidRepoDir", err
+		}
+		if err := validateRepoPostClone(repo.PostClone); err != nil {
+			return "InvalidRepoPostClone", err
+		}
+		if err := validateRepoWorktrees(repo.Worktrees, repoDirFor(repo, i, len(repos))); err != nil {
+			return "InvalidRepoWorktrees", err
+		}
+	}
+	if err := validateInitScript(spritz.Spec.InitScript); err != nil {
+		return "InvalidInitScript", err
+	}
+	if err := validateSecurityProfiles(spritz); err != nil {
+		return "InvalidSecurityProfile", err
+	}
+	if err := validateImagePinning(spritz); err != nil {
+		return "ImageNotPinned", err
+	}
+	if err := validateExtraVolumes(spritz); err != nil {
+		return "InvalidExtraVolumes", err
+	}
+	if _, _, _, _, err := spritzv1.LifecycleExpiryTimes(spritz); err != nil {
+		if err.Error() == "invalid idle ttl format" {
+			return "InvalidIdleTTL", err
+		}
+		return "InvalidTTL", err
+	}
+	return "", nil
+}
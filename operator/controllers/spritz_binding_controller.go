@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -18,6 +17,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	spritzv1 "spritz.sh/operator/api/v1"
+	"spritz.sh/operator/tracing"
 )
 
 const spritzBindingFinalizer = "spritz.sh/binding-finalizer"
@@ -26,37 +26,10 @@ const (
 	bindingTargetRevisionAnnotationKey = "spritz.sh/target-revision"
 )
 
-type bindingIngressDefaults struct {
-	Mode               string
-	HostTemplate       string
-	Path               string
-	ClassName          string
-	GatewayName        string
-	GatewayNamespace   string
-	GatewaySectionName string
-}
-
-func NewBindingIngressDefaultsFromEnv() bindingIngressDefaults {
-	return bindingIngressDefaults{
-		Mode:               strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_MODE")),
-		HostTemplate:       strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_HOST_TEMPLATE")),
-		Path:               strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_PATH")),
-		ClassName:          strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_CLASS_NAME")),
-		GatewayName:        strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_GATEWAY_NAME")),
-		GatewayNamespace:   strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_GATEWAY_NAMESPACE")),
-		GatewaySectionName: strings.TrimSpace(os.Getenv("SPRITZ_DEFAULT_INGRESS_GATEWAY_SECTION_NAME")),
-	}
-}
-
-func (d bindingIngressDefaults) enabled() bool {
-	return d.Mode != "" || d.HostTemplate != "" || d.Path != "" || d.ClassName != "" ||
-		d.GatewayName != "" || d.GatewayNamespace != "" || d.GatewaySectionName != ""
-}
-
 type SpritzBindingReconciler struct {
 	client.Client
 	Scheme          *runtime.Scheme
-	IngressDefaults bindingIngressDefaults
+	IngressDefaults spritzv1.IngressDefaults
 }
 
 type bindingRequeueError struct {
@@ -70,7 +43,9 @@ func (e *bindingRequeueError) Error() string {
 	return e.cause.Error()
 }
 
-func (r *SpritzBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *SpritzBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := startReconcileSpan(ctx, "SpritzBinding", req)
+	defer func() { tracing.End(span, err) }()
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 	logger := log.FromContext(ctx)
@@ -434,7 +409,11 @@ func (r *SpritzBindingReconciler) desiredRuntimeSpec(
 ) (spritzv1.SpritzSpec, error) {
 	var spec spritzv1.SpritzSpec
 	binding.Spec.Template.Spec.DeepCopyInto(&spec)
-	applyBindingIngressDefaults(&spec, name, binding.Namespace, r.IngressDefaults)
+	spritzv1.ApplyIngressDefaults(&spec, r.IngressDefaults)
+	vars := spritzv1.TemplateVars{Name: name, Namespace: binding.Namespace, Owner: spec.Owner.ID}
+	if err := spritzv1.ExpandSpecTemplates(&spec, vars); err != nil {
+		return spritzv1.SpritzSpec{}, err
+	}
 	if spec.Ingress != nil && strings.EqualFold(spec.Ingress.Mode, "gateway") && strings.TrimSpace(spec.Ingress.Host) == "" {
 		return spritzv1.SpritzSpec{}, fmt.Errorf("spec.ingress.host is required when spec.ingress.mode=gateway")
 	}
@@ -693,46 +672,6 @@ func bindingRuntimeName(binding *spritzv1.SpritzBinding, sequence int64) string
 	)
 }
 
-func applyBindingIngressDefaults(spec *spritzv1.SpritzSpec, name, namespace string, defaults bindingIngressDefaults) {
-	if spec == nil || !defaults.enabled() {
-		return
-	}
-	if spec.Ingress == nil && bindingIsWebDisabled(spec) {
-		return
-	}
-	if spec.Ingress == nil {
-		spec.Ingress = &spritzv1.SpritzIngress{}
-	}
-	if spec.Ingress.Mode == "" && defaults.Mode != "" {
-		spec.Ingress.Mode = defaults.Mode
-	}
-	if spec.Ingress.Host == "" && defaults.HostTemplate != "" {
-		spec.Ingress.Host = strings.NewReplacer("{name}", name, "{namespace}", namespace).Replace(defaults.HostTemplate)
-	}
-	if spec.Ingress.Path == "" && defaults.Path != "" {
-		spec.Ingress.Path = strings.NewReplacer("{name}", name, "{namespace}", namespace).Replace(defaults.Path)
-	}
-	if spec.Ingress.ClassName == "" && defaults.ClassName != "" {
-		spec.Ingress.ClassName = defaults.ClassName
-	}
-	if spec.Ingress.GatewayName == "" && defaults.GatewayName != "" {
-		spec.Ingress.GatewayName = defaults.GatewayName
-	}
-	if spec.Ingress.GatewayNamespace == "" && defaults.GatewayNamespace != "" {
-		spec.Ingress.GatewayNamespace = defaults.GatewayNamespace
-	}
-	if spec.Ingress.GatewaySectionName == "" && defaults.GatewaySectionName != "" {
-		spec.Ingress.GatewaySectionName = defaults.GatewaySectionName
-	}
-}
-
-func bindingIsWebDisabled(spec *spritzv1.SpritzSpec) bool {
-	if spec == nil || spec.Features == nil || spec.Features.Web == nil {
-		return false
-	}
-	return !*spec.Features.Web
-}
-
 func cloneStringMap(value map[string]string) map[string]string {
 	if len(value) == 0 {
 		return nil
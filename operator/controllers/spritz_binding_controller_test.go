@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -532,3 +533,21 @@ func TestReconcileBindingReplacesOutdatedCandidateWhenTemplateSpecChangesMidRoll
 		t.Fatalf("expected stale candidate to be replaced, got %#v", storedBinding.Status)
 	}
 }
+
+func TestDesiredRuntimeSpecExpandsTemplates(t *testing.T) {
+	binding := newBindingTestBinding()
+	binding.Spec.Template.Spec.Env = []corev1.EnvVar{{Name: "WORKSPACE_URL", Value: "https://{name}.example.com/{owner}"}}
+	reconciler, _ := newBindingReconcilerForTest(t, binding)
+	reconciler.IngressDefaults = spritzv1.IngressDefaults{Mode: "gateway", HostTemplate: "{name}.example.com", GatewayName: "public"}
+
+	spec, err := reconciler.desiredRuntimeSpec(binding, "zeno-1")
+	if err != nil {
+		t.Fatalf("desiredRuntimeSpec returned error: %v", err)
+	}
+	if spec.Ingress == nil || spec.Ingress.Host != "zeno-1.example.com" {
+		t.Fatalf("expected expanded ingress host, got %#v", spec.Ingress)
+	}
+	if got := spec.Env[0].Value; got != "https://zeno-1.example.com/user-1" {
+		t.Fatalf("expected expanded env value, got %q", got)
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"regexp"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -26,6 +28,7 @@ import (
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	spritzv1 "spritz.sh/operator/api/v1"
+	"spritz.sh/operator/tracing"
 )
 
 const (
@@ -45,6 +48,8 @@ const (
 	defaultRepoInitImage                      = "alpine/git:2.45.2"
 	repoAuthMountPath                         = "/var/run/spritz/repo-auth"
 	repoInitHomeDir                           = "/home/dev"
+	repoInitTempHomeDir                       = "/tmp/spritz-repo-home"
+	repoInitTempHomeVolumeName                = "repo-init-home"
 	repoInitGroupID                     int64 = 65532
 	lifecycleNotifiedPhaseAnnotationKey       = "spritz.sh/lifecycle-notified-phase"
 )
@@ -59,6 +64,30 @@ type SpritzReconciler struct {
 	Scheme                 *runtime.Scheme
 	ACP                    ACPProbeConfig
 	LifecycleNotifications LifecycleNotificationConfig
+	// ReconcileTimeout bounds a single reconcile. Zero uses
+	// defaultReconcileTimeout.
+	ReconcileTimeout time.Duration
+	// DriftResyncInterval requeues every spritz after this long so owned
+	// resources are re-asserted without a watch event. Zero disables it.
+	DriftResyncInterval time.Duration
+	// PrepullImages publishes the images in use for an external pre-puller.
+	// Nil disables it.
+	PrepullImages *PrepullImages
+}
+
+const defaultReconcileTimeout = 30 * time.Second
+
+// ReconcileTimeoutFromEnv reads SPRITZ_RECONCILE_TIMEOUT. Clusters with slow
+// volume provisioning may need more than the default.
+func ReconcileTimeoutFromEnv() time.Duration {
+	return parseDurationEnv("SPRITZ_RECONCILE_TIMEOUT", defaultReconcileTimeout)
+}
+
+func (r *SpritzReconciler) reconcileTimeout() time.Duration {
+	if r.ReconcileTimeout > 0 {
+		return r.ReconcileTimeout
+	}
+	return defaultReconcileTimeout
 }
 
 type repoEntry struct {
@@ -162,23 +191,124 @@ func validateRepoDir(repoDir string) error {
 	return nil
 }
 
-func (r *SpritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+var repoReviewRefPattern = regexp.MustCompile(`^(?:refs/)?((?:pull|merge-requests)/[0-9]+/(?:head|merge))$`)
+
+// repoRevisionFetchRef resolves pull-request and merge-request revisions such as
+// `pull/123/head` or `refs/merge-requests/45/head` to the full ref that must be
+// fetched explicitly, since hosts do not advertise them to regular clones.
+func repoRevisionFetchRef(revision string) string {
+	match := repoReviewRefPattern.FindStringSubmatch(strings.TrimSpace(revision))
+	if match == nil {
+		return ""
+	}
+	return "refs/" + match[1]
+}
+
+func validateRepoPostClone(commands []string) error {
+	for i, command := range commands {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("repo.postClone[%d] must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// repoWorktreeDirFor resolves a worktree dir the same way repoDirFor resolves
+// an explicit repo.dir.
+func repoWorktreeDirFor(worktree spritzv1.SpritzRepoWorktree) string {
+	dir := worktree.Dir
+	if !strings.HasPrefix(dir, "/") {
+		dir = path.Join("/workspace", dir)
+	}
+	return path.Clean(dir)
+}
+
+func validateRepoWorktrees(worktrees []spritzv1.SpritzRepoWorktree, repoDir string) error {
+	seen := map[string]bool{}
+	for i, worktree := range worktrees {
+		if strings.TrimSpace(worktree.Branch) == "" {
+			return fmt.Errorf("repo.worktrees[%d].branch is required", i)
+		}
+		if strings.TrimSpace(worktree.Dir) == "" {
+			return fmt.Errorf("repo.worktrees[%d].dir is required", i)
+		}
+		if err := validateRepoDir(worktree.Dir); err != nil {
+			return fmt.Errorf("repo.worktrees[%d].dir: %w", i, err)
+		}
+		dir := repoWorktreeDirFor(worktree)
+		if pathHasPrefix(dir, repoDir) || pathHasPrefix(repoDir, dir) {
+			return fmt.Errorf("repo.worktrees[%d].dir must not overlap repo.dir", i)
+		}
+		if seen[dir] {
+			return fmt.Errorf("repo.worktrees[%d].dir is used more than once", i)
+		}
+		seen[dir] = true
+	}
+	return nil
+}
+
+// repoWorktreeEnv encodes worktrees as indexed env vars, like repoPostCloneEnv.
+func repoWorktreeEnv(worktrees []spritzv1.SpritzRepoWorktree) []corev1.EnvVar {
+	if len(worktrees) == 0 {
+		return nil
+	}
+	env := []corev1.EnvVar{{Name: "SPRITZ_REPO_WORKTREE_COUNT", Value: fmt.Sprintf("%d", len(worktrees))}}
+	for i, worktree := range worktrees {
+		env = append(env,
+			corev1.EnvVar{Name: fmt.Sprintf("SPRITZ_REPO_WORKTREE_%d_BRANCH", i), Value: worktree.Branch},
+			corev1.EnvVar{Name: fmt.Sprintf("SPRITZ_REPO_WORKTREE_%d_DIR", i), Value: repoWorktreeDirFor(worktree)},
+		)
+	}
+	return env
+}
+
+// repoPostCloneEnv encodes post-clone hooks as indexed env vars so each
+// command survives intact, including embedded newlines and quotes.
+func repoPostCloneEnv(commands []string) []corev1.EnvVar {
+	if len(commands) == 0 {
+		return nil
+	}
+	env := []corev1.EnvVar{{Name: "SPRITZ_REPO_POST_CLONE_COUNT", Value: fmt.Sprintf("%d", len(commands))}}
+	for i, command := range commands {
+		env = append(env, corev1.EnvVar{Name: fmt.Sprintf("SPRITZ_REPO_POST_CLONE_%d", i), Value: command})
+	}
+	return env
+}
+
+func (r *SpritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	ctx, span := startReconcileSpan(ctx, "Spritz", req)
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := context.WithTimeout(ctx, r.reconcileTimeout())
 	defer cancel()
 	logger := log.FromContext(ctx)
 
 	var spritz spritzv1.Spritz
 	if err := r.Get(ctx, req.NamespacedName, &spritz); err != nil {
 		if errors.IsNotFound(err) {
+			// A deleted spritz may have been the last user of an image.
+			if err := r.syncPrepullImages(ctx); err != nil {
+				logger.Error(err, "failed to update pre-pull image list")
+			}
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
+	if err := r.syncPrepullImages(ctx); err != nil {
+		logger.Error(err, "failed to update pre-pull image list")
+	}
 
 	if done, err := r.reconcileLifecycle(ctx, &spritz); done || err != nil {
 		return ctrl.Result{}, err
 	}
 
+	if spritzPaused(&spritz) {
+		requeueAfter, err := r.reconcilePaused(ctx, &spritz)
+		if err != nil || requeueAfter == nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: *requeueAfter}, nil
+	}
+
 	if err := r.reconcileResources(ctx, &spritz); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -187,6 +317,7 @@ func (r *SpritzReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	requeueAfter = minDurationPtr(requeueAfter, r.driftResyncRequeue())
 	if requeueAfter != nil {
 		return ctrl.Result{RequeueAfter: *requeueAfter}, nil
 	}
@@ -265,8 +396,12 @@ func reconcileSpritzMetadata(spritz *spritzv1.Spritz) bool {
 }
 
 func (r *SpritzReconciler) reconcileResources(ctx context.Context, spritz *spritzv1.Spritz) error {
-	if err := r.reconcileDeployment(ctx, spritz); err != nil {
-		return err
+	// An unpinned image or invalid extra volume is reported by reconcileStatus;
+	// the deployment is left as is so the change never rolls out.
+	if validateImagePinning(spritz) == nil && validateExtraVolumes(spritz) == nil {
+		if err := r.reconcileDeployment(ctx, spritz); err != nil {
+			return err
+		}
 	}
 	if err := r.reconcileService(ctx, spritz); err != nil {
 		return err
@@ -277,6 +412,12 @@ func (r *SpritzReconciler) reconcileResources(ctx context.Context, spritz *sprit
 	if err := r.reconcileGatewayRoute(ctx, spritz); err != nil {
 		return err
 	}
+	if err := r.reconcilePodDisruptionBudget(ctx, spritz); err != nil {
+		return err
+	}
+	if err := r.reconcileNetworkPolicy(ctx, spritz); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -294,23 +435,50 @@ func (r *SpritzReconciler) reconcileDeployment(ctx context.Context, spritz *spri
 	homeSizeLimit := emptyDirSizeLimit("SPRITZ_HOME_SIZE_LIMIT", defaultHomeSizeLimit)
 
 	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: spritz.Name, Namespace: spritz.Namespace}}
+	sharedMountsSettings, err := loadSharedMountsSettings()
+	if err != nil {
+		return err
+	}
+	sharedMountsTokenChecksum, err := r.reconcileSharedMountsOwnerToken(ctx, spritz, sharedMountsSettings)
+	if err != nil {
+		return err
+	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, deploy, func() error {
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, deploy, func() error {
 		if err := controllerutil.SetControllerReference(spritz, deploy, r.Scheme); err != nil {
 			return err
 		}
 
 		selectorLabels := stableWorkloadSelectorLabels(deploy.Spec.Selector, spritz)
-		deploy.Labels = mergeMaps(labels, spritz.Spec.Labels)
-		deploy.Annotations = mergeMaps(deploy.Annotations, spritz.Spec.Annotations)
-		deploy.Annotations = mergeMaps(deploy.Annotations, annotations)
+		previousPodAnnotations := deploy.Annotations[managedPodAnnotationsAnnotationKey]
+		applyManagedMetadata(&deploy.ObjectMeta, mergeMaps(labels, spritz.Spec.Labels), mergeMaps(spritz.Spec.Annotations, annotations))
 		deploy.Spec.Selector = &metav1.LabelSelector{MatchLabels: selectorLabels}
+		revisionHistoryLimit, err := deploymentRevisionHistoryLimit(spritz)
+		if err != nil {
+			return err
+		}
+		deploy.Spec.RevisionHistoryLimit = &revisionHistoryLimit
+		progressDeadline, err := deploymentProgressDeadlineSeconds(spritz)
+		if err != nil {
+			return err
+		}
+		if progressDeadline != nil {
+			deploy.Spec.ProgressDeadlineSeconds = progressDeadline
+		}
+		podMetadata, err := loadPodMetadataTemplates()
+		if err != nil {
+			return err
+		}
 		deploy.Spec.Template.Labels = mergeMaps(
-			mergeMaps(spritz.Spec.Labels, labels),
+			mergeMaps(mergeMaps(podMetadata.podLabels(spritz), spritz.Spec.Labels), labels),
 			selectorLabels,
 		)
-		deploy.Spec.Template.Annotations = mergeMaps(deploy.Spec.Template.Annotations, spritz.Spec.Annotations)
-		deploy.Spec.Template.Annotations = mergeMaps(deploy.Spec.Template.Annotations, annotations)
+		podAnnotations := mergeMaps(mergeMaps(podMetadata.podAnnotations(spritz), spritz.Spec.Annotations), annotations)
+		if sharedMountsTokenChecksum != "" {
+			podAnnotations = mergeMaps(podAnnotations, map[string]string{sharedMountsTokenChecksumAnnotationKey: sharedMountsTokenChecksum})
+		}
+		deploy.Spec.Template.Annotations = applyManagedKeys(deploy.Spec.Template.Annotations, podAnnotations, previousPodAnnotations)
+		deploy.Annotations = setManagedKeysRecord(deploy.Annotations, managedPodAnnotationsAnnotationKey, podAnnotations)
 
 		repos := repoEntries(spritz)
 		for _, repo := range repos {
@@ -324,6 +492,9 @@ func (r *SpritzReconciler) reconcileDeployment(ctx context.Context, spritz *spri
 				continue
 			}
 			repoDirs = append(repoDirs, repoDirFor(repo, i, len(repos)))
+			for _, worktree := range repo.Worktrees {
+				repoDirs = append(repoDirs, repoWorktreeDirFor(worktree))
+			}
 		}
 
 		env := []corev1.EnvVar{}
@@ -352,18 +523,29 @@ func (r *SpritzReconciler) reconcileDeployment(ctx context.Context, spritz *spri
 				env = append(env, corev1.EnvVar{Name: "SPRITZ_REPO_SUBMODULES", Value: "true"})
 			}
 		}
+		if caBundleEnabled(spritz) {
+			env = append(env, caBundleEnv()...)
+		}
+		env = append(env, resolveProxySettings(spritz).env()...)
 		env = append(env, spritz.Spec.Env...)
 
 		ports := containerPorts(spritz)
-		sharedMountsSettings, err := loadSharedMountsSettings()
+		nodeSelector, err := loadPodNodeSelector()
 		if err != nil {
 			return err
 		}
-		nodeSelector, err := loadPodNodeSelector()
+		defaultTopologySpread, err := loadPodTopologySpreadConstraints()
 		if err != nil {
 			return err
 		}
-		homeMounts := buildHomeMounts()
+		topologySpread := topologySpreadConstraintsFor(spritz, defaultTopologySpread, selectorLabels)
+		if err := validateTopologySpreadConstraints(topologySpread, deploy.Spec.Template.Labels); err != nil {
+			return err
+		}
+		var homeMounts []corev1.VolumeMount
+		if isHomeEnabled(spritz) {
+			homeMounts = buildHomeMounts()
+		}
 		sharedMountRuntime, err := buildSharedMountRuntime(spritz, sharedMountsSettings)
 		if err != nil {
 			return err
@@ -379,13 +561,19 @@ func (r *SpritzReconciler) reconcileDeployment(ctx context.Context, spritz *spri
 
 		volumes := []corev1.Volume{
 			{Name: "workspace", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: workspaceSizeLimit}}},
-			{Name: "home", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: homeSizeLimit}}},
+		}
+		if isHomeEnabled(spritz) {
+			volumes = append(volumes, corev1.Volume{Name: "home", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: homeSizeLimit}}})
 		}
 		if len(repoAuthVolumes) > 0 {
 			volumes = append(volumes, repoAuthVolumes...)
 		}
 
 		volumeMounts := append([]corev1.VolumeMount{{Name: "workspace", MountPath: "/workspace"}}, homeMounts...)
+		if caBundleEnabled(spritz) {
+			volumes = append(volumes, caBundleVolume(spritz))
+			volumeMounts = append(volumeMounts, caBundleVolumeMount())
+		}
 		if len(sharedMountRuntime.volumes) > 0 {
 			volumes = append(volumes, sharedMountRuntime.volumes...)
 		}
@@ -396,19 +584,45 @@ func (r *SpritzReconciler) reconcileDeployment(ctx context.Context, spritz *spri
 			env = append(env, sharedMountRuntime.env...)
 		}
 		volumeMounts = appendRepoDirMounts(volumeMounts, repoDirs, repoMountRoots)
+		if repoAuthLiveEnabled() {
+			volumeMounts = append(volumeMounts, repoAuthLiveMounts(repoInitContainers)...)
+		}
+		volumes, volumeMounts, err = appendExtraVolumes(spritz, volumes, volumeMounts)
+		if err != nil {
+			return err
+		}
 		spritzResources := spritz.Spec.Resources
 		if isEmptyResourceRequirements(spritzResources) {
 			spritzResources = defaultSpritzContainerResources()
 		}
+		gpu, err := loadGPUScheduling()
+		if err != nil {
+			return err
+		}
+		requestsGPU := gpu.requestsGPU(spritzResources)
+		if requestsGPU {
+			spritzResources, err = gpu.normalizeGPUResources(spritzResources)
+			if err != nil {
+				return err
+			}
+			if !gpu.configured() {
+				log.FromContext(ctx).Info(
+					"spritz requests GPUs but no GPU scheduling is configured; set SPRITZ_GPU_NODE_SELECTOR or SPRITZ_GPU_TOLERATIONS",
+					"spritz", spritz.Name,
+					"resource", string(gpu.resourceName),
+				)
+			}
+		}
 		podSpec := corev1.PodSpec{
 			Containers: []corev1.Container{
 				{
-					Name:         spritzContainerName,
-					Image:        spritz.Spec.Image,
-					Env:          env,
-					Resources:    spritzResources,
-					Ports:        ports,
-					VolumeMounts: volumeMounts,
+					Name:            spritzContainerName,
+					Image:           spritz.Spec.Image,
+					ImagePullPolicy: imagePullPolicy(spritz),
+					Env:             env,
+					Resources:       spritzResources,
+					Ports:           ports,
+					VolumeMounts:    volumeMounts,
 				},
 			},
 			Volumes: volumes,
@@ -441,28 +655,63 @@ func (r *SpritzReconciler) reconcileDeployment(ctx context.Context, spritz *spri
 				FailureThreshold:    3,
 			}
 		}
+		initScriptContainer, err := buildInitScriptContainer(spritz, env, volumeMounts, spritzResources)
+		if err != nil {
+			return err
+		}
 		podSpec.SecurityContext = buildPodSecurityContext(len(sharedMountRuntime.volumeMounts) > 0, len(repoInitContainers) > 0)
-		initContainers := []corev1.Container{}
-		if sharedMountRuntime.initContainer != nil {
-			initContainers = append(initContainers, *sharedMountRuntime.initContainer)
+		if err := applyPodSecurityProfiles(&podSpec, spritz); err != nil {
+			return err
 		}
+		initContainers := []corev1.Container{}
+		initContainers = append(initContainers, sharedMountRuntime.initContainers...)
 		if len(repoInitContainers) > 0 {
 			initContainers = append(initContainers, repoInitContainers...)
 		}
+		if initScriptContainer != nil {
+			initContainers = append(initContainers, *initScriptContainer)
+		}
 		if len(initContainers) > 0 {
 			podSpec.InitContainers = initContainers
 		}
-		if sharedMountRuntime.sidecarContainer != nil {
-			podSpec.Containers = append(podSpec.Containers, *sharedMountRuntime.sidecarContainer)
+		podSpec.Containers = append(podSpec.Containers, sharedMountRuntime.sidecarContainers...)
+		if err := applyPortTargetContainers(spritz, &podSpec); err != nil {
+			return err
 		}
 		if len(nodeSelector) > 0 {
 			podSpec.NodeSelector = nodeSelector
 		}
+		if requestsGPU {
+			gpu.applyGPUScheduling(&podSpec)
+		}
+		if len(topologySpread) > 0 {
+			podSpec.TopologySpreadConstraints = topologySpread
+		}
+		if err := spritzv1.ValidateDNS(spritz.Spec.DNSPolicy, spritz.Spec.DNSConfig); err != nil {
+			return err
+		}
+		if spritz.Spec.DNSPolicy != "" {
+			podSpec.DNSPolicy = spritz.Spec.DNSPolicy
+		}
+		if spritz.Spec.DNSConfig != nil {
+			podSpec.DNSConfig = spritz.Spec.DNSConfig.DeepCopy()
+		}
+		if spritz.Spec.TerminationGracePeriodSeconds != nil {
+			grace := *spritz.Spec.TerminationGracePeriodSeconds
+			podSpec.TerminationGracePeriodSeconds = &grace
+		}
+		if readOnlyRootFilesystemEnabled(spritz) {
+			applyReadOnlyRootFilesystem(&podSpec)
+		}
 		deploy.Spec.Template.Spec = podSpec
 		return nil
 	})
 
-	return err
+	if err != nil {
+		return err
+	}
+	logOwnedResourceDrift(ctx, spritz, "Deployment", result)
+	return nil
 }
 
 func (r *SpritzReconciler) reconcileService(ctx context.Context, spritz *spritzv1.Spritz) error {
@@ -476,23 +725,25 @@ func (r *SpritzReconciler) reconcileService(ctx context.Context, spritz *spritzv
 
 	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: spritz.Name, Namespace: spritz.Namespace}}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
 		if err := controllerutil.SetControllerReference(spritz, svc, r.Scheme); err != nil {
 			return err
 		}
 
 		labels := baseLabels(spritz)
 		annotations := baseAnnotations(spritz)
-		svc.Labels = mergeMaps(labels, spritz.Spec.Labels)
 		svc.Spec.Selector = deploymentSelectorLabels(spritz)
-		svc.Annotations = mergeMaps(svc.Annotations, spritz.Spec.Annotations)
-		svc.Annotations = mergeMaps(svc.Annotations, annotations)
+		applyManagedMetadata(&svc.ObjectMeta, mergeMaps(labels, spritz.Spec.Labels), mergeMaps(spritz.Spec.Annotations, annotations))
 
 		svc.Spec.Ports = servicePorts(spritz)
 		return nil
 	})
 
-	return err
+	if err != nil {
+		return err
+	}
+	logOwnedResourceDrift(ctx, spritz, "Service", result)
+	return nil
 }
 
 func (r *SpritzReconciler) reconcileIngress(ctx context.Context, spritz *spritzv1.Spritz) error {
@@ -506,17 +757,18 @@ func (r *SpritzReconciler) reconcileIngress(ctx context.Context, spritz *spritzv
 
 	ing := &netv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: spritz.Name, Namespace: spritz.Namespace}}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, ing, func() error {
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, ing, func() error {
 		if err := controllerutil.SetControllerReference(spritz, ing, r.Scheme); err != nil {
 			return err
 		}
 
 		labels := baseLabels(spritz)
 		annotations := baseAnnotations(spritz)
-		ing.Labels = mergeMaps(labels, spritz.Spec.Labels)
-		ing.Annotations = mergeMaps(ing.Annotations, spritz.Spec.Annotations)
-		ing.Annotations = mergeMaps(ing.Annotations, spritz.Spec.Ingress.Annotations)
-		ing.Annotations = mergeMaps(ing.Annotations, annotations)
+		applyManagedMetadata(
+			&ing.ObjectMeta,
+			mergeMaps(labels, spritz.Spec.Labels),
+			mergeMaps(mergeMaps(spritz.Spec.Annotations, spritz.Spec.Ingress.Annotations), annotations),
+		)
 
 		if spritz.Spec.Ingress.ClassName != "" {
 			ing.Spec.IngressClassName = &spritz.Spec.Ingress.ClassName
@@ -552,7 +804,11 @@ func (r *SpritzReconciler) reconcileIngress(ctx context.Context, spritz *spritzv
 		return nil
 	})
 
-	return err
+	if err != nil {
+		return err
+	}
+	logOwnedResourceDrift(ctx, spritz, "Ingress", result)
+	return nil
 }
 
 func (r *SpritzReconciler) reconcileGatewayRoute(ctx context.Context, spritz *spritzv1.Spritz) error {
@@ -571,17 +827,18 @@ func (r *SpritzReconciler) reconcileGatewayRoute(ctx context.Context, spritz *sp
 	}
 	route := &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: spritz.Name, Namespace: spritz.Namespace}}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, route, func() error {
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, route, func() error {
 		if err := controllerutil.SetControllerReference(spritz, route, r.Scheme); err != nil {
 			return err
 		}
 
 		labels := baseLabels(spritz)
 		annotations := baseAnnotations(spritz)
-		route.Labels = mergeMaps(labels, spritz.Spec.Labels)
-		route.Annotations = mergeMaps(route.Annotations, spritz.Spec.Annotations)
-		route.Annotations = mergeMaps(route.Annotations, spritz.Spec.Ingress.Annotations)
-		route.Annotations = mergeMaps(route.Annotations, annotations)
+		applyManagedMetadata(
+			&route.ObjectMeta,
+			mergeMaps(labels, spritz.Spec.Labels),
+			mergeMaps(mergeMaps(spritz.Spec.Annotations, spritz.Spec.Ingress.Annotations), annotations),
+		)
 
 		path := spritz.Spec.Ingress.Path
 		if path == "" {
@@ -643,8 +900,41 @@ func (r *SpritzReconciler) reconcileGatewayRoute(ctx context.Context, spritz *sp
 
 	if err != nil {
 		logger.Error(err, "failed to reconcile HTTPRoute", "name", spritz.Name, "namespace", spritz.Namespace)
+		return err
+	}
+	logOwnedResourceDrift(ctx, spritz, "HTTPRoute", result)
+	return nil
+}
+
+func (r *SpritzReconciler) reconcilePodDisruptionBudget(ctx context.Context, spritz *spritzv1.Spritz) error {
+	pdb := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: spritz.Name, Namespace: spritz.Namespace}}
+	if !podDisruptionBudgetEnabled() {
+		if err := r.Delete(ctx, pdb); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, pdb, func() error {
+		if err := controllerutil.SetControllerReference(spritz, pdb, r.Scheme); err != nil {
+			return err
+		}
+
+		labels := baseLabels(spritz)
+		annotations := baseAnnotations(spritz)
+		applyManagedMetadata(&pdb.ObjectMeta, mergeMaps(labels, spritz.Spec.Labels), mergeMaps(spritz.Spec.Annotations, annotations))
+
+		minAvailable := intstr.FromInt(1)
+		pdb.Spec.MinAvailable = &minAvailable
+		pdb.Spec.Selector = &metav1.LabelSelector{MatchLabels: deploymentSelectorLabels(spritz)}
+		return nil
+	})
+
+	if err != nil {
+		return err
 	}
-	return err
+	logOwnedResourceDrift(ctx, spritz, "PodDisruptionBudget", result)
+	return nil
 }
 
 func (r *SpritzReconciler) reconcileStatus(ctx context.Context, spritz *spritzv1.Spritz) (*time.Duration, error) {
@@ -652,30 +942,16 @@ func (r *SpritzReconciler) reconcileStatus(ctx context.Context, spritz *spritzv1
 	now := time.Now()
 	sshInfo := buildSSHInfo(spritz)
 
-	if spritz.Spec.Ingress != nil && ingressMode(spritz) == "gateway" {
-		if spritz.Spec.Ingress.Host == "" {
-			return nil, r.setStatus(ctx, spritz, "Error", "", sshInfo, "InvalidIngress", "ingress.host is required when ingress.mode=gateway", deepCopyACPStatus(spritz.Status.ACP))
-		}
-		if spritz.Spec.Ingress.GatewayName == "" {
-			return nil, r.setStatus(ctx, spritz, "Error", "", sshInfo, "InvalidIngress", "ingress.gatewayName is required when ingress.mode=gateway", deepCopyACPStatus(spritz.Status.ACP))
-		}
-	}
-	for _, repo := range repoEntries(spritz) {
-		if err := validateRepoDir(repo.Dir); err != nil {
-			return nil, r.setStatus(ctx, spritz, "Error", "", sshInfo, "InvalidRepoDir", err.Error(), deepCopyACPStatus(spritz.Status.ACP))
-		}
+	if reason, err := ValidateSpritzSpec(spritz); err != nil {
+		return nil, r.setStatus(ctx, spritz, "Error", "", sshInfo, reason, err.Error(), deepCopyACPStatus(spritz.Status.ACP))
 	}
 
 	var statusRequeue *time.Duration
 	idleExpiresAt, maxExpiresAt, effectiveExpiresAt, lifecycleReason, err := spritzv1.LifecycleExpiryTimes(spritz)
 	if err != nil {
-		switch err.Error() {
-		case "invalid idle ttl format":
-			return nil, r.setStatus(ctx, spritz, "Error", "", sshInfo, "InvalidIdleTTL", err.Error(), deepCopyACPStatus(spritz.Status.ACP))
-		default:
-			return nil, r.setStatus(ctx, spritz, "Error", "", sshInfo, "InvalidTTL", err.Error(), deepCopyACPStatus(spritz.Status.ACP))
-		}
+		return nil, err
 	}
+	maxExpiresAt, effectiveExpiresAt, lifecycleReason = capLifecycleAtMaxLifetime(spritz, maxLifetime(), maxExpiresAt, effectiveExpiresAt, lifecycleReason)
 	spritz.Status.IdleExpiresAt = idleExpiresAt
 	spritz.Status.MaxExpiresAt = maxExpiresAt
 	spritz.Status.ExpiresAt = effectiveExpiresAt
@@ -714,6 +990,7 @@ func (r *SpritzReconciler) reconcileStatus(ctx context.Context, spritz *spritzv1
 	var deploy appsv1.Deployment
 	if err := r.Get(ctx, client.ObjectKey{Name: spritz.Name, Namespace: spritz.Namespace}, &deploy); err != nil {
 		if errors.IsNotFound(err) {
+			spritz.Status.PodNames = nil
 			acpStatus, _, acpErr := r.reconcileACPStatus(ctx, spritz, false)
 			if acpErr != nil {
 				logger.Error(acpErr, "failed to resolve ACP status while deployment is missing")
@@ -723,6 +1000,25 @@ func (r *SpritzReconciler) reconcileStatus(ctx context.Context, spritz *spritzv1
 		return nil, err
 	}
 
+	observePods := podStatusEnabled()
+	if observePods {
+		podNames, err := r.observePodNames(ctx, spritz, &deploy)
+		if err != nil {
+			logger.Error(err, "failed to observe pod names", "name", spritz.Name, "namespace", spritz.Namespace)
+		} else {
+			spritz.Status.PodNames = podNames
+		}
+	}
+
+	setRolloutProgressingCondition(&spritz.Status.Conditions, spritz.Generation, &deploy)
+	if reason, rolloutMessage, failed := deploymentRolloutFailure(&deploy); failed {
+		acpStatus, _, acpErr := r.reconcileACPStatus(ctx, spritz, false)
+		if acpErr != nil {
+			logger.Error(acpErr, "failed to resolve ACP status while rollout is stuck")
+		}
+		return nil, r.setStatus(ctx, spritz, "Error", "", sshInfo, reason, rolloutMessage, acpStatus)
+	}
+
 	ready := deploy.Status.AvailableReplicas > 0
 	phase := "Provisioning"
 	reason := "Provisioning"
@@ -732,6 +1028,34 @@ func (r *SpritzReconciler) reconcileStatus(ctx context.Context, spritz *spritzv1
 		reason = "Ready"
 		message = "spritz ready"
 	}
+	var debounceRequeue *time.Duration
+	if held, remaining := holdReadyDuringDebounce(spritz, ready, now, readyDebounce()); held {
+		phase = "Ready"
+		reason = "Ready"
+		message = "spritz ready; deployment temporarily unavailable"
+		debounceRequeue = durationPtr(remaining)
+	}
+
+	if observePods {
+		repoStatuses, err := r.observeRepoStatuses(ctx, spritz, &deploy)
+		if err != nil {
+			logger.Error(err, "failed to observe repo checkout status", "name", spritz.Name, "namespace", spritz.Namespace)
+		} else if repoStatuses != nil {
+			spritz.Status.Repos = repoStatuses
+		}
+	}
+
+	if observePods {
+		if err := r.observeSharedMountsSynced(ctx, spritz, &deploy); err != nil {
+			logger.Error(err, "failed to observe shared mount sync status", "name", spritz.Name, "namespace", spritz.Namespace)
+		}
+	}
+
+	if observePods {
+		if err := r.observeInitScript(ctx, spritz, &deploy); err != nil {
+			logger.Error(err, "failed to observe init script status", "name", spritz.Name, "namespace", spritz.Namespace)
+		}
+	}
 
 	acpStatus, acpRequeue, acpErr := r.reconcileACPStatus(ctx, spritz, ready)
 	if acpErr != nil {
@@ -741,7 +1065,7 @@ func (r *SpritzReconciler) reconcileStatus(ctx context.Context, spritz *spritzv1
 	if err := r.setStatus(ctx, spritz, phase, url, sshInfo, reason, message, acpStatus); err != nil {
 		return nil, err
 	}
-	return minDurationPtr(statusRequeue, acpRequeue), nil
+	return minDurationPtr(minDurationPtr(statusRequeue, acpRequeue), debounceRequeue), nil
 }
 
 func (r *SpritzReconciler) setStatus(ctx context.Context, spritz *spritzv1.Spritz, phase, url string, sshInfo *spritzv1.SpritzSSHInfo, reason, message string, acpStatus *spritzv1.SpritzACPStatus) error {
@@ -760,6 +1084,7 @@ func (r *SpritzReconciler) setStatus(ctx context.Context, spritz *spritzv1.Sprit
 		LastTransitionTime: metav1.Now(),
 	})
 
+	spritz.Status.ObservedGeneration = spritz.Generation
 	spritz.Status.Phase = phase
 	spritz.Status.Message = message
 	if url != "" {
@@ -897,6 +1222,8 @@ func (r *SpritzReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.Service{}).
 		Owns(&netv1.Ingress{}).
 		Owns(&gatewayv1.HTTPRoute{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&netv1.NetworkPolicy{}).
 		Complete(r)
 }
 
@@ -982,6 +1309,10 @@ func ttlGracePeriod() time.Duration {
 	return grace
 }
 
+func podDisruptionBudgetEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv("SPRITZ_CREATE_PDB")), "true")
+}
+
 func loadPodNodeSelector() (map[string]string, error) {
 	raw := strings.TrimSpace(os.Getenv("SPRITZ_POD_NODE_SELECTOR"))
 	if raw == "" {
@@ -1109,24 +1440,42 @@ func buildHomeMounts() []corev1.VolumeMount {
 }
 
 type repoAuthConfig struct {
-	secretName  string
-	netrcKey    string
-	usernameKey string
-	passwordKey string
-	host        string
-}
-
-const repoInitScript = `
-set -eu
-
-mkdir -p "$SPRITZ_REPO_DIR"
-
+	secretName     string
+	netrcKey       string
+	credentialsKey string
+	usernameKey    string
+	passwordKey    string
+	host           string
+}
+
+// repoAuthSetupScript writes the repo's credentials into $HOME. It is part of
+// repoInitScript and is also replayed on its own by repoParallelInitScript.
+const repoAuthSetupScript = `
+# With SPRITZ_REPO_AUTH_LIVE the workspace container mounts the same secret at
+# the same path, so git reads the live file and sees rotated tokens.
 if [ -n "${SPRITZ_REPO_AUTH_NETRC_PATH:-}" ] && [ -f "$SPRITZ_REPO_AUTH_NETRC_PATH" ]; then
   mkdir -p "$HOME"
-  cp "$SPRITZ_REPO_AUTH_NETRC_PATH" "$HOME/.netrc"
-  chmod 0600 "$HOME/.netrc"
+  rm -f "$HOME/.netrc"
+  if [ "${SPRITZ_REPO_AUTH_LIVE:-false}" = "true" ]; then
+    ln -s "$SPRITZ_REPO_AUTH_NETRC_PATH" "$HOME/.netrc"
+  else
+    cp "$SPRITZ_REPO_AUTH_NETRC_PATH" "$HOME/.netrc"
+    chmod 0600 "$HOME/.netrc"
+  fi
+elif [ -n "${SPRITZ_REPO_AUTH_CREDENTIALS_PATH:-}" ] && [ -f "$SPRITZ_REPO_AUTH_CREDENTIALS_PATH" ]; then
+  mkdir -p "$HOME"
+  if [ "${SPRITZ_REPO_AUTH_LIVE:-false}" = "true" ]; then
+    # The mount is read-only, so only answer lookups from it.
+    git config --global credential.helper "!f() { if [ \"\$1\" = get ]; then git credential-store --file=$SPRITZ_REPO_AUTH_CREDENTIALS_PATH get; fi; }; f"
+  else
+    rm -f "$HOME/.git-credentials"
+    cp "$SPRITZ_REPO_AUTH_CREDENTIALS_PATH" "$HOME/.git-credentials"
+    chmod 0600 "$HOME/.git-credentials"
+    git config --global credential.helper "store --file=$HOME/.git-credentials"
+  fi
 elif [ -n "${SPRITZ_REPO_AUTH_USERNAME:-}" ] && [ -n "${SPRITZ_REPO_AUTH_PASSWORD:-}" ] && [ -n "${SPRITZ_REPO_AUTH_HOST:-}" ]; then
   mkdir -p "$HOME"
+  rm -f "$HOME/.netrc"
   cat > "$HOME/.netrc" <<EOF
 machine ${SPRITZ_REPO_AUTH_HOST}
   login ${SPRITZ_REPO_AUTH_USERNAME}
@@ -1134,7 +1483,13 @@ machine ${SPRITZ_REPO_AUTH_HOST}
 EOF
   chmod 0600 "$HOME/.netrc"
 fi
+`
+
+const repoInitScript = `
+set -eu
 
+mkdir -p "$SPRITZ_REPO_DIR"
+` + repoAuthSetupScript + `
 	fetch_cmd() {
   set -- git fetch --prune
   if [ -n "${SPRITZ_REPO_DEPTH:-}" ]; then
@@ -1144,11 +1499,46 @@ fi
   "$@"
 	}
 
+refresh_repo_cache() {
+  cache_key="$(printf '%s' "$SPRITZ_REPO_URL" | sha256sum | cut -c1-32)"
+  cache_mirror="$SPRITZ_REPO_CACHE_PATH/$cache_key.git"
+  cache_lock="$SPRITZ_REPO_CACHE_PATH/$cache_key.lock"
+  mkdir -p "$SPRITZ_REPO_CACHE_PATH"
+  # A lock left behind by a killed pod must not block refreshes forever.
+  find "$SPRITZ_REPO_CACHE_PATH" -maxdepth 1 -name "$cache_key.lock" -mmin +30 -exec rmdir {} \; 2>/dev/null || true
+  if mkdir "$cache_lock" 2>/dev/null; then
+    if [ ! -d "$cache_mirror" ]; then
+      echo "seeding repo cache mirror for $SPRITZ_REPO_URL"
+      rm -rf "$cache_mirror.tmp.$$"
+      if git clone --mirror "$SPRITZ_REPO_URL" "$cache_mirror.tmp.$$"; then
+        touch "$cache_mirror.tmp.$$/spritz-refreshed"
+        mv "$cache_mirror.tmp.$$" "$cache_mirror"
+      else
+        rm -rf "$cache_mirror.tmp.$$"
+      fi
+    elif [ ! -f "$cache_mirror/spritz-refreshed" ] || [ -n "$(find "$cache_mirror/spritz-refreshed" -mmin +"${SPRITZ_REPO_CACHE_REFRESH_MINUTES:-10}")" ]; then
+      echo "refreshing repo cache mirror for $SPRITZ_REPO_URL"
+      if git --git-dir "$cache_mirror" fetch --prune origin; then
+        touch "$cache_mirror/spritz-refreshed"
+      fi
+    fi
+    rmdir "$cache_lock" 2>/dev/null || true
+  else
+    echo "repo cache mirror is busy; cloning with the mirror as-is"
+  fi
+  if [ -d "$cache_mirror" ]; then
+    SPRITZ_REPO_CACHE_MIRROR="$cache_mirror"
+  fi
+}
+
 	clone_cmd() {
   set -- git clone
   if [ -n "${SPRITZ_REPO_DEPTH:-}" ]; then
     set -- "$@" --depth "${SPRITZ_REPO_DEPTH}"
   fi
+  if [ -n "${SPRITZ_REPO_CACHE_MIRROR:-}" ]; then
+    set -- "$@" --reference-if-able "${SPRITZ_REPO_CACHE_MIRROR}" --dissociate
+  fi
   if [ -n "${SPRITZ_REPO_BRANCH:-}" ]; then
     set -- "$@" --branch "${SPRITZ_REPO_BRANCH}"
   fi
@@ -1156,6 +1546,32 @@ fi
   "$@"
 	}
 
+report_repo_head() {
+  repo_head="$(git rev-parse HEAD)"
+  repo_branch="$(git symbolic-ref --quiet --short HEAD || true)"
+  if [ -n "$repo_branch" ]; then
+    echo "checked out branch $repo_branch at $repo_head"
+  else
+    echo "checked out detached HEAD at $repo_head"
+  fi
+  if [ -n "${SPRITZ_REPO_STATUS_FILE:-}" ]; then
+    printf 'branch=%s\ncommit=%s\n' "$repo_branch" "$repo_head" > "$SPRITZ_REPO_STATUS_FILE" || true
+  fi
+}
+
+# An existing checkout may hold uncommitted work on a persistent volume, so
+# skipIfExists leaves it exactly as it is: no fetch, checkout, or hooks.
+if [ "${SPRITZ_REPO_SKIP_IF_EXISTS:-false}" = "true" ] && [ -d "$SPRITZ_REPO_DIR/.git" ]; then
+  cd "$SPRITZ_REPO_DIR"
+  echo "repo already exists at $SPRITZ_REPO_DIR; skipping fetch"
+  report_repo_head
+  exit 0
+fi
+
+if [ -n "${SPRITZ_REPO_CACHE_PATH:-}" ] && [ ! -d "$SPRITZ_REPO_DIR/.git" ]; then
+  refresh_repo_cache
+fi
+
 if [ -d "$SPRITZ_REPO_DIR/.git" ]; then
   cd "$SPRITZ_REPO_DIR"
   git remote set-url origin "$SPRITZ_REPO_URL"
@@ -1165,7 +1581,14 @@ if [ -d "$SPRITZ_REPO_DIR/.git" ]; then
 	  cd "$SPRITZ_REPO_DIR"
 	fi
 
-if [ -n "${SPRITZ_REPO_REVISION:-}" ]; then
+if [ -n "${SPRITZ_REPO_FETCH_REF:-}" ]; then
+  set -- git fetch
+  if [ -n "${SPRITZ_REPO_DEPTH:-}" ]; then
+    set -- "$@" --depth "${SPRITZ_REPO_DEPTH}"
+  fi
+  "$@" origin "+${SPRITZ_REPO_FETCH_REF}:refs/spritz/revision"
+  git checkout --detach refs/spritz/revision
+elif [ -n "${SPRITZ_REPO_REVISION:-}" ]; then
   git checkout "$SPRITZ_REPO_REVISION" || (git fetch origin "$SPRITZ_REPO_REVISION" && git checkout "$SPRITZ_REPO_REVISION")
 fi
 
@@ -1173,9 +1596,70 @@ if [ "${SPRITZ_REPO_SUBMODULES:-false}" = "true" ]; then
   git submodule update --init --recursive
 fi
 
+# Checkout runs with GIT_LFS_SKIP_SMUDGE=1 so pointers are fetched in one batch
+# here instead of one request per file.
+if [ "${SPRITZ_REPO_LFS:-false}" = "true" ]; then
+  if git lfs version >/dev/null 2>&1; then
+    git lfs install --local
+    GIT_LFS_SKIP_SMUDGE=0 git lfs pull
+  else
+    echo "warning: git-lfs is not available in the repo init image; LFS pointers are left unresolved" >&2
+  fi
+fi
+
+# A worktree dir that already holds a checkout is left alone, like the primary
+# checkout with skipIfExists. Branches missing locally are fetched and tracked.
+worktree_index=0
+while [ "$worktree_index" -lt "${SPRITZ_REPO_WORKTREE_COUNT:-0}" ]; do
+  eval "worktree_branch=\${SPRITZ_REPO_WORKTREE_${worktree_index}_BRANCH}"
+  eval "worktree_dir=\${SPRITZ_REPO_WORKTREE_${worktree_index}_DIR}"
+  if [ -e "$worktree_dir/.git" ]; then
+    echo "worktree already exists at $worktree_dir; leaving it untouched"
+  else
+    git worktree prune
+    if git show-ref --verify --quiet "refs/heads/$worktree_branch"; then
+      git worktree add "$worktree_dir" "$worktree_branch"
+    else
+      # Single-branch clones (branch or depth set) do not track other branches.
+      worktree_refspec="+refs/heads/$worktree_branch:refs/remotes/origin/$worktree_branch"
+      if ! git config --get-all remote.origin.fetch | grep -qxF "$worktree_refspec" && ! git config --get-all remote.origin.fetch | grep -qxF '+refs/heads/*:refs/remotes/origin/*'; then
+        git config --add remote.origin.fetch "$worktree_refspec"
+      fi
+      set -- git fetch
+      if [ -n "${SPRITZ_REPO_DEPTH:-}" ]; then
+        set -- "$@" --depth "${SPRITZ_REPO_DEPTH}"
+      fi
+      "$@" origin "$worktree_refspec"
+      git worktree add --track -b "$worktree_branch" "$worktree_dir" "origin/$worktree_branch"
+    fi
+    echo "added worktree for $worktree_branch at $worktree_dir"
+  fi
+  worktree_index=$((worktree_index + 1))
+done
+
+report_repo_head
+
+hook_index=0
+while [ "$hook_index" -lt "${SPRITZ_REPO_POST_CLONE_COUNT:-0}" ]; do
+  eval "hook=\${SPRITZ_REPO_POST_CLONE_${hook_index}}"
+  echo "running post-clone hook $((hook_index + 1)): $hook"
+  if ! (cd "$SPRITZ_REPO_DIR" && sh -c "$hook"); then
+    echo "post-clone hook $((hook_index + 1)) failed" >&2
+    exit 1
+  fi
+  hook_index=$((hook_index + 1))
+done
+
 	if [ -n "${SPRITZ_REPO_GID:-}" ]; then
   chgrp -R "${SPRITZ_REPO_GID}" "$SPRITZ_REPO_DIR"
   chmod -R g+rwX "$SPRITZ_REPO_DIR"
+  worktree_index=0
+  while [ "$worktree_index" -lt "${SPRITZ_REPO_WORKTREE_COUNT:-0}" ]; do
+    eval "worktree_dir=\${SPRITZ_REPO_WORKTREE_${worktree_index}_DIR}"
+    chgrp -R "${SPRITZ_REPO_GID}" "$worktree_dir"
+    chmod -R g+rwX "$worktree_dir"
+    worktree_index=$((worktree_index + 1))
+  done
 fi
 `
 
@@ -1188,29 +1672,66 @@ func buildRepoInitContainers(
 		return nil, nil, nil
 	}
 
+	cacheSettings, err := loadRepoCacheSettings()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var containers []corev1.Container
+	var repoIndexes []int
 	var volumes []corev1.Volume
+	usesCache := false
+	// Repos that use the same auth secret share the volume and mount path of
+	// the first of them.
+	authIndexBySecret := map[string]int{}
 	for i, repo := range repos {
 		if strings.TrimSpace(repo.URL) == "" {
 			continue
 		}
 		repoDir := repoDirFor(repo, i, len(repos))
 		needsRepoDirMount := repoDirNeedsWorkspaceMount(repoDir, mountRoots)
-		container, authVolume, err := buildRepoInitContainerForRepo(spritz, &repo, repoDir, needsRepoDirMount, mountRoots, i)
+		authIndex := i
+		if repo.Auth != nil {
+			secretName := strings.TrimSpace(repo.Auth.SecretName)
+			if first, ok := authIndexBySecret[secretName]; ok {
+				authIndex = first
+			} else {
+				authIndexBySecret[secretName] = i
+			}
+		}
+		container, authVolume, err := buildRepoInitContainerForRepo(spritz, &repo, repoDir, needsRepoDirMount, mountRoots, i, authIndex)
 		if err != nil {
 			return nil, nil, err
 		}
 		if container != nil {
+			if cacheSettings.appliesTo(&repo) {
+				container.Env = append(container.Env, repoCacheEnv(cacheSettings)...)
+				container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: repoCacheVolumeName, MountPath: cacheSettings.path})
+				usesCache = true
+			}
 			containers = append(containers, *container)
+			repoIndexes = append(repoIndexes, i)
 		}
-		if authVolume != nil {
+		if authVolume != nil && authIndex == i {
 			volumes = append(volumes, *authVolume)
 		}
 	}
+	if usesCache {
+		volumes = append(volumes, repoCacheVolume(cacheSettings))
+	}
 
 	if len(containers) == 0 {
 		return nil, nil, nil
 	}
+	if !isHomeEnabled(spritz) {
+		volumes = append(volumes, corev1.Volume{
+			Name:         repoInitTempHomeVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+	if repoParallelCloneEnabled() {
+		containers = parallelizeRepoInitContainers(containers, repoIndexes)
+	}
 	return containers, volumes, nil
 }
 
@@ -1221,6 +1742,7 @@ func buildRepoInitContainerForRepo(
 	needsRepoDirMount bool,
 	mountRoots []corev1.VolumeMount,
 	index int,
+	authIndex int,
 ) (*corev1.Container, *corev1.Volume, error) {
 	if repo == nil || strings.TrimSpace(repo.URL) == "" {
 		return nil, nil, nil
@@ -1234,15 +1756,19 @@ func buildRepoInitContainerForRepo(
 	env := []corev1.EnvVar{
 		{Name: "SPRITZ_REPO_URL", Value: repo.URL},
 		{Name: "SPRITZ_REPO_DIR", Value: repoDir},
-		{Name: "HOME", Value: repoInitHomeDir},
+		{Name: "HOME", Value: repoInitHomeFor(spritz)},
 		{Name: "GIT_TERMINAL_PROMPT", Value: "0"},
 		{Name: "SPRITZ_REPO_GID", Value: fmt.Sprintf("%d", repoInitGroupID)},
+		{Name: "SPRITZ_REPO_STATUS_FILE", Value: corev1.TerminationMessagePathDefault},
 	}
 	if repo.Branch != "" {
 		env = append(env, corev1.EnvVar{Name: "SPRITZ_REPO_BRANCH", Value: repo.Branch})
 	}
 	if repo.Revision != "" {
 		env = append(env, corev1.EnvVar{Name: "SPRITZ_REPO_REVISION", Value: repo.Revision})
+		if fetchRef := repoRevisionFetchRef(repo.Revision); fetchRef != "" {
+			env = append(env, corev1.EnvVar{Name: "SPRITZ_REPO_FETCH_REF", Value: fetchRef})
+		}
 	}
 	if repo.Depth > 0 {
 		env = append(env, corev1.EnvVar{Name: "SPRITZ_REPO_DEPTH", Value: fmt.Sprintf("%d", repo.Depth)})
@@ -1250,29 +1776,71 @@ func buildRepoInitContainerForRepo(
 	if repo.Submodules {
 		env = append(env, corev1.EnvVar{Name: "SPRITZ_REPO_SUBMODULES", Value: "true"})
 	}
+	if repo.SkipIfExists {
+		env = append(env, corev1.EnvVar{Name: "SPRITZ_REPO_SKIP_IF_EXISTS", Value: "true"})
+	}
+	if repo.LFS {
+		env = append(env,
+			corev1.EnvVar{Name: "SPRITZ_REPO_LFS", Value: "true"},
+			corev1.EnvVar{Name: "GIT_LFS_SKIP_SMUDGE", Value: "1"},
+		)
+	}
+	if err := validateRepoPostClone(repo.PostClone); err != nil {
+		return nil, nil, err
+	}
+	env = append(env, repoPostCloneEnv(repo.PostClone)...)
+	if err := validateRepoWorktrees(repo.Worktrees, repoDir); err != nil {
+		return nil, nil, err
+	}
+	env = append(env, repoWorktreeEnv(repo.Worktrees)...)
+	if caBundleEnabled(spritz) {
+		env = append(env, corev1.EnvVar{Name: "GIT_SSL_CAINFO", Value: caBundleFilePath})
+	}
+	env = append(env, resolveProxySettings(spritz).env()...)
 
 	var authVolume *corev1.Volume
 	volumeMounts := []corev1.VolumeMount{
 		{Name: "workspace", MountPath: "/workspace"},
 	}
 	volumeMounts = appendUniqueMounts(volumeMounts, mountRoots...)
-	volumeMounts = ensureMount(volumeMounts, corev1.VolumeMount{Name: "home", MountPath: repoInitHomeDir})
+	if isHomeEnabled(spritz) {
+		volumeMounts = ensureMount(volumeMounts, corev1.VolumeMount{Name: "home", MountPath: repoInitHomeDir})
+	} else {
+		volumeMounts = ensureMount(volumeMounts, corev1.VolumeMount{Name: repoInitTempHomeVolumeName, MountPath: repoInitTempHomeDir})
+	}
 	volumeMounts = appendRepoDirMount(volumeMounts, repoDir, needsRepoDirMount)
+	for _, worktree := range repo.Worktrees {
+		worktreeDir := repoWorktreeDirFor(worktree)
+		volumeMounts = appendRepoDirMount(volumeMounts, worktreeDir, repoDirNeedsWorkspaceMount(worktreeDir, mountRoots))
+	}
+	if caBundleEnabled(spritz) {
+		volumeMounts = append(volumeMounts, caBundleVolumeMount())
+	}
 	if authConfig != nil {
-		authVolumeName := fmt.Sprintf("repo-auth-%d", index)
+		authVolumeName := fmt.Sprintf("%s%d", repoAuthVolumePrefix, authIndex)
+		authMountPath := repoAuthMountPathFor(authIndex)
 		authVolume = &corev1.Volume{
 			Name: authVolumeName,
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{SecretName: authConfig.secretName},
 			},
 		}
-		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: authVolumeName, MountPath: repoAuthMountPath, ReadOnly: true})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: authVolumeName, MountPath: authMountPath, ReadOnly: true})
 		if authConfig.netrcKey != "" {
 			env = append(env, corev1.EnvVar{
 				Name:  "SPRITZ_REPO_AUTH_NETRC_PATH",
-				Value: fmt.Sprintf("%s/%s", repoAuthMountPath, authConfig.netrcKey),
+				Value: fmt.Sprintf("%s/%s", authMountPath, authConfig.netrcKey),
 			})
 		}
+		if authConfig.credentialsKey != "" {
+			env = append(env, corev1.EnvVar{
+				Name:  "SPRITZ_REPO_AUTH_CREDENTIALS_PATH",
+				Value: fmt.Sprintf("%s/%s", authMountPath, authConfig.credentialsKey),
+			})
+		}
+		if repoAuthLiveEnabled() {
+			env = append(env, corev1.EnvVar{Name: "SPRITZ_REPO_AUTH_LIVE", Value: "true"})
+		}
 		if authConfig.usernameKey != "" && authConfig.passwordKey != "" {
 			env = append(env,
 				corev1.EnvVar{
@@ -1301,11 +1869,12 @@ func buildRepoInitContainerForRepo(
 	}
 
 	container := corev1.Container{
-		Name:         fmt.Sprintf("repo-init-%d", index),
-		Image:        repoInitImage(),
-		Command:      []string{"/bin/sh", "-ec", repoInitScript},
-		Env:          env,
-		VolumeMounts: volumeMounts,
+		Name:                   repoInitContainerName(index),
+		Image:                  repoInitImageFor(repo),
+		Command:                []string{"/bin/sh", "-ec", repoInitScript},
+		Env:                    env,
+		VolumeMounts:           volumeMounts,
+		TerminationMessagePath: corev1.TerminationMessagePathDefault,
 	}
 
 	return &container, authVolume, nil
@@ -1321,13 +1890,14 @@ func repoAuthConfigFromSpec(repo *spritzv1.SpritzRepo) (*repoAuthConfig, error)
 	}
 
 	cfg := &repoAuthConfig{
-		secretName:  repo.Auth.SecretName,
-		netrcKey:    repo.Auth.NetrcKey,
-		usernameKey: repo.Auth.UsernameKey,
-		passwordKey: repo.Auth.PasswordKey,
+		secretName:     repo.Auth.SecretName,
+		netrcKey:       repo.Auth.NetrcKey,
+		credentialsKey: repo.Auth.CredentialsKey,
+		usernameKey:    repo.Auth.UsernameKey,
+		passwordKey:    repo.Auth.PasswordKey,
 	}
 
-	if cfg.netrcKey == "" && cfg.usernameKey == "" && cfg.passwordKey == "" {
+	if cfg.netrcKey == "" && cfg.credentialsKey == "" && cfg.usernameKey == "" && cfg.passwordKey == "" {
 		cfg.netrcKey = "netrc"
 	}
 
@@ -1377,6 +1947,17 @@ func repoInitImage() string {
 	return defaultRepoInitImage
 }
 
+// repoInitImageFor returns the repo's own init image, falling back to the
+// operator-wide default.
+func repoInitImageFor(repo *spritzv1.SpritzRepo) string {
+	if repo != nil {
+		if value := strings.TrimSpace(repo.InitImage); value != "" {
+			return value
+		}
+	}
+	return repoInitImage()
+}
+
 func emptyDirSizeLimit(key string, fallback resource.Quantity) *resource.Quantity {
 	value := strings.TrimSpace(os.Getenv(key))
 	if value != "" {
@@ -1393,6 +1974,23 @@ func isWebEnabled(spritz *spritzv1.Spritz) bool {
 	return spritzv1.IsWebEnabled(spritz.Spec)
 }
 
+func isHomeEnabled(spritz *spritzv1.Spritz) bool {
+	if spritz.Spec.Features == nil || spritz.Spec.Features.Home == nil {
+		return true
+	}
+	return *spritz.Spec.Features.Home
+}
+
+// repoInitHomeFor returns the HOME of repo init containers. Without the home
+// volume, credentials are written to a scratch emptyDir that only the repo
+// init containers mount, so the workspace container cannot read them.
+func repoInitHomeFor(spritz *spritzv1.Spritz) string {
+	if isHomeEnabled(spritz) {
+		return repoInitHomeDir
+	}
+	return repoInitTempHomeDir
+}
+
 func isSSHEnabled(spritz *spritzv1.Spritz) bool {
 	if spritz.Spec.SSH != nil && spritz.Spec.SSH.Enabled {
 		return true
@@ -1475,6 +2073,9 @@ func containerPorts(spritz *spritzv1.Spritz) []corev1.ContainerPort {
 
 	ports := make([]corev1.ContainerPort, 0, len(spritz.Spec.Ports))
 	for _, port := range spritz.Spec.Ports {
+		if !portTargetsMainContainer(port) {
+			continue
+		}
 		protocol := port.Protocol
 		if protocol == "" {
 			protocol = corev1.ProtocolTCP
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// The webhook paths follow the controller-runtime convention for the
+// spritz.sh/v1 Spritz kind.
+const (
+	SpritzWebhookPath        = "/validate-spritz-sh-v1-spritz"
+	SpritzDefaultWebhookPath = "/mutate-spritz-sh-v1-spritz"
+)
+
+// WebhookConfig configures the operator's admission webhook server.
+type WebhookConfig struct {
+	Enabled bool
+	Port    int
+	CertDir string
+}
+
+// NewWebhookConfigFromEnv reads SPRITZ_OPERATOR_WEBHOOK_ENABLED,
+// SPRITZ_OPERATOR_WEBHOOK_PORT and SPRITZ_OPERATOR_WEBHOOK_CERT_DIR. A zero
+// port or empty cert dir keeps the controller-runtime defaults.
+func NewWebhookConfigFromEnv() WebhookConfig {
+	cfg := WebhookConfig{
+		Enabled: parseBoolEnv("SPRITZ_OPERATOR_WEBHOOK_ENABLED", false),
+		CertDir: strings.TrimSpace(os.Getenv("SPRITZ_OPERATOR_WEBHOOK_CERT_DIR")),
+	}
+	if raw := strings.TrimSpace(os.Getenv("SPRITZ_OPERATOR_WEBHOOK_PORT")); raw != "" {
+		if port, err := strconv.Atoi(raw); err == nil && port > 0 {
+			cfg.Port = port
+		}
+	}
+	return cfg
+}
+
+// SpritzValidator rejects specs that ValidateSpritzSpec would otherwise report
+// as an Error status after the object was persisted.
+type SpritzValidator struct{}
+
+// SetupSpritzWebhooksWithManager registers the defaulting and validating
+// webhooks for Spritz.
+func SetupSpritzWebhooksWithManager(mgr ctrl.Manager, defaulter *SpritzDefaulter, validator *SpritzValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&spritzv1.Spritz{}).
+		WithDefaulter(defaulter).
+		WithValidator(validator).
+		Complete()
+}
+
+// SpritzDefaulter applies the cluster SSH and ingress defaults at admission,
+// so Spritzes created with kubectl match the ones created through the API.
+type SpritzDefaulter struct {
+	SSH     spritzv1.SSHDefaults
+	Ingress spritzv1.IngressDefaults
+}
+
+// NewSpritzDefaulterFromEnv reads the same SPRITZ_DEFAULT_SSH_* and
+// SPRITZ_DEFAULT_INGRESS_* settings as the API.
+func NewSpritzDefaulterFromEnv() *SpritzDefaulter {
+	return &SpritzDefaulter{
+		SSH:     spritzv1.SSHDefaultsFromEnv(),
+		Ingress: spritzv1.IngressDefaultsFromEnv(),
+	}
+}
+
+// Default only runs on create, like the API. Applying defaults on update
+// would re-enable SSH or ingress that a user removed from an existing spec.
+func (d *SpritzDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	spritz, ok := obj.(*spritzv1.Spritz)
+	if !ok {
+		return fmt.Errorf("expected a Spritz, got %T", obj)
+	}
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.Operation != admissionv1.Create {
+		return nil
+	}
+	return applySpritzDefaults(spritz, d.SSH, d.Ingress)
+}
+
+// applySpritzDefaults applies the SSH and ingress defaults and expands the
+// spec templates the same way the API does. Templates need the final name,
+// so a generateName create gets its name here; the API server keeps a name
+// that admission has set.
+func applySpritzDefaults(spritz *spritzv1.Spritz, ssh spritzv1.SSHDefaults, ingress spritzv1.IngressDefaults) error {
+	spritzv1.ApplySSHDefaults(&spritz.Spec, ssh, spritz.Namespace)
+	if spritz.Name == "" && spritz.GenerateName != "" {
+		spritz.Name = generateSpritzName(spritz.GenerateName)
+	}
+	spritzv1.ApplyIngressDefaults(&spritz.Spec, ingress)
+	return spritzv1.ExpandSpecTemplates(&spritz.Spec, spritzv1.TemplateVarsFor(spritz))
+}
+
+// generateSpritzName appends a random suffix to prefix the way the API server
+// does for generateName.
+func generateSpritzName(prefix string) string {
+	const maxPrefixLength = validation.DNS1123LabelMaxLength - 5
+	if len(prefix) > maxPrefixLength {
+		prefix = prefix[:maxPrefixLength]
+	}
+	return prefix + utilrand.String(5)
+}
+
+func (v *SpritzValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	spritz, ok := obj.(*spritzv1.Spritz)
+	if !ok {
+		return nil, fmt.Errorf("expected a Spritz, got %T", obj)
+	}
+	return nil, validateSpritzAdmission(spritz)
+}
+
+// ValidateUpdate only checks changed specs. Metadata-only updates, such as
+// activity annotations or finalizer removal, must keep working for spritzes
+// that became invalid after an operator policy change.
+func (v *SpritzValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldSpritz, ok := oldObj.(*spritzv1.Spritz)
+	if !ok {
+		return nil, fmt.Errorf("expected a Spritz, got %T", oldObj)
+	}
+	spritz, ok := newObj.(*spritzv1.Spritz)
+	if !ok {
+		return nil, fmt.Errorf("expected a Spritz, got %T", newObj)
+	}
+	if spritz.DeletionTimestamp != nil || equality.Semantic.DeepEqual(oldSpritz.Spec, spritz.Spec) {
+		return nil, nil
+	}
+	return nil, validateSpritzAdmission(spritz)
+}
+
+func (v *SpritzValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateSpritzAdmission(spritz *spritzv1.Spritz) error {
+	if reason, err := ValidateSpritzSpec(spritz); err != nil {
+		return fmt.Errorf("%s: %w", reason, err)
+	}
+	return nil
+}
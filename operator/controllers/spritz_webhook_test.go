@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+func TestSpritzValidatorRejectsInvalidCreate(t *testing.T) {
+	validator := &SpritzValidator{}
+	spritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidy-otter", Namespace: "spritz-test"},
+		Spec: spritzv1.SpritzSpec{
+			Image:   "example.com/spritz:latest",
+			Ingress: &spritzv1.SpritzIngress{Mode: "gateway", Host: "tidy-otter.example.com"},
+		},
+	}
+
+	_, err := validator.ValidateCreate(context.Background(), spritz)
+	if err == nil || !strings.Contains(err.Error(), "InvalidIngress: ingress.gatewayName is required") {
+		t.Fatalf("expected gateway ingress to be rejected, got %v", err)
+	}
+
+	spritz.Spec.Ingress.GatewayName = "public"
+	if _, err := validator.ValidateCreate(context.Background(), spritz); err != nil {
+		t.Fatalf("expected valid spritz to be admitted, got %v", err)
+	}
+}
+
+func TestSpritzValidatorOnlyChecksChangedSpecsOnUpdate(t *testing.T) {
+	t.Setenv("SPRITZ_REQUIRE_IMAGE_DIGEST", "true")
+	validator := &SpritzValidator{}
+	oldSpritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidy-otter", Namespace: "spritz-test"},
+		Spec:       spritzv1.SpritzSpec{Image: "example.com/spritz:latest"},
+	}
+	annotated := oldSpritz.DeepCopy()
+	annotated.Annotations = map[string]string{"spritz.sh/last-activity": "2026-10-17T00:00:00Z"}
+
+	if _, err := validator.ValidateUpdate(context.Background(), oldSpritz, annotated); err != nil {
+		t.Fatalf("expected metadata-only update to be admitted, got %v", err)
+	}
+
+	changed := oldSpritz.DeepCopy()
+	changed.Spec.Image = "example.com/spritz:next"
+	_, err := validator.ValidateUpdate(context.Background(), oldSpritz, changed)
+	if err == nil || !strings.HasPrefix(err.Error(), "ImageNotPinned: ") {
+		t.Fatalf("expected unpinned image change to be rejected, got %v", err)
+	}
+}
+
+func TestSpritzDefaulterAppliesAPIDefaultsOnCreate(t *testing.T) {
+	defaulter := &SpritzDefaulter{
+		SSH:     spritzv1.SSHDefaults{Enabled: true, Mode: "gateway", GatewayService: "spritz-api", GatewayPort: 22, User: "spritz"},
+		Ingress: spritzv1.IngressDefaults{Mode: "gateway", HostTemplate: "{name}.example.com", GatewayName: "public"},
+	}
+	spritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidy-otter", Namespace: "spritz-test"},
+		Spec:       spritzv1.SpritzSpec{Image: "example.com/spritz:latest", Owner: spritzv1.SpritzOwner{ID: "user-1"}},
+	}
+	ctx := admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Create},
+	})
+
+	if err := defaulter.Default(ctx, spritz); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ssh := spritz.Spec.SSH
+	if ssh == nil || !ssh.Enabled || ssh.GatewayService != "spritz-api" || ssh.GatewayNamespace != "spritz-test" || ssh.User != "spritz" {
+		t.Fatalf("expected SSH defaults, got %#v", ssh)
+	}
+	ingress := spritz.Spec.Ingress
+	if ingress == nil || ingress.Host != "tidy-otter.example.com" || ingress.GatewayName != "public" {
+		t.Fatalf("expected expanded ingress defaults, got %#v", ingress)
+	}
+	if _, err := (&SpritzValidator{}).ValidateCreate(ctx, spritz); err != nil {
+		t.Fatalf("expected defaulted spritz to pass validation, got %v", err)
+	}
+}
+
+func TestSpritzDefaulterNamesGenerateNameCreates(t *testing.T) {
+	defaulter := &SpritzDefaulter{
+		Ingress: spritzv1.IngressDefaults{Mode: "gateway", HostTemplate: "{name}.example.com", GatewayName: "public"},
+	}
+	spritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "tidy-", Namespace: "spritz-test"},
+		Spec: spritzv1.SpritzSpec{
+			Image: "example.com/spritz:latest",
+			Owner: spritzv1.SpritzOwner{ID: "user-1"},
+			Env:   []corev1.EnvVar{{Name: "WORKSPACE_URL", Value: "https://{name}.example.com/{owner}"}},
+		},
+	}
+	ctx := admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Create},
+	})
+
+	if err := defaulter.Default(ctx, spritz); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(spritz.Name, "tidy-") || len(spritz.Name) != len("tidy-")+5 {
+		t.Fatalf("expected a generated name, got %q", spritz.Name)
+	}
+	if ingress := spritz.Spec.Ingress; ingress == nil || ingress.Host != spritz.Name+".example.com" {
+		t.Fatalf("expected ingress host for the generated name, got %#v", ingress)
+	}
+	if got, want := spritz.Spec.Env[0].Value, "https://"+spritz.Name+".example.com/user-1"; got != want {
+		t.Fatalf("expected env value %q, got %q", want, got)
+	}
+}
+
+func TestSpritzDefaulterSkipsUpdates(t *testing.T) {
+	defaulter := &SpritzDefaulter{SSH: spritzv1.SSHDefaults{Enabled: true, Mode: "gateway"}}
+	spritz := &spritzv1.Spritz{ObjectMeta: metav1.ObjectMeta{Name: "tidy-otter", Namespace: "spritz-test"}}
+	ctx := admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Update},
+	})
+
+	if err := defaulter.Default(ctx, spritz); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spritz.Spec.SSH != nil {
+		t.Fatalf("expected update to keep the spec as is, got %#v", spritz.Spec.SSH)
+	}
+}
@@ -206,3 +206,33 @@ func TestSetStatusRetriesLifecycleNotificationUntilRecorded(t *testing.T) {
 		t.Fatalf("expected successful retry to record notified phase, got %q", got)
 	}
 }
+
+func TestSetStatusRecordsObservedGeneration(t *testing.T) {
+	scheme := newControllerTestScheme(t)
+	spritz := &spritzv1.Spritz{
+		ObjectMeta: metav1.ObjectMeta{Name: "quiet-heron", Namespace: "spritz-test", Generation: 4},
+		Spec: spritzv1.SpritzSpec{
+			Image: "example.com/openclaw:latest",
+			Owner: spritzv1.SpritzOwner{ID: "user-1"},
+		},
+		Status: spritzv1.SpritzStatus{Phase: "Ready", ObservedGeneration: 3},
+	}
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&spritzv1.Spritz{}).
+		WithObjects(spritz).
+		Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	if err := reconciler.setStatus(context.Background(), spritz, "Provisioning", "", nil, "Provisioning", "waiting for deployment", nil); err != nil {
+		t.Fatalf("setStatus returned error: %v", err)
+	}
+
+	stored := &spritzv1.Spritz{}
+	if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(spritz), stored); err != nil {
+		t.Fatalf("failed to load updated spritz: %v", err)
+	}
+	if stored.Status.ObservedGeneration != stored.Generation {
+		t.Fatalf("expected observedGeneration %d, got %d", stored.Generation, stored.Status.ObservedGeneration)
+	}
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	spritzv1 "spritz.sh/operator/api/v1"
+)
+
+// loadPodTopologySpreadConstraints reads the operator default from
+// SPRITZ_POD_TOPOLOGY_SPREAD_CONSTRAINTS, a JSON list of Kubernetes topology
+// spread constraints.
+func loadPodTopologySpreadConstraints() ([]corev1.TopologySpreadConstraint, error) {
+	raw := strings.TrimSpace(os.Getenv("SPRITZ_POD_TOPOLOGY_SPREAD_CONSTRAINTS"))
+	if raw == "" {
+		return nil, nil
+	}
+	var constraints []corev1.TopologySpreadConstraint
+	if err := json.Unmarshal([]byte(raw), &constraints); err != nil {
+		return nil, fmt.Errorf("invalid SPRITZ_POD_TOPOLOGY_SPREAD_CONSTRAINTS: %w", err)
+	}
+	return constraints, nil
+}
+
+// topologySpreadConstraintsFor returns spec.topologySpreadConstraints, or the
+// operator default when the spec sets none. Constraints without a
+// labelSelector select the pods of this spritz through selectorLabels.
+func topologySpreadConstraintsFor(spritz *spritzv1.Spritz, defaults []corev1.TopologySpreadConstraint, selectorLabels map[string]string) []corev1.TopologySpreadConstraint {
+	source := spritz.Spec.TopologySpreadConstraints
+	if len(source) == 0 {
+		source = defaults
+	}
+	if len(source) == 0 {
+		return nil
+	}
+	constraints := make([]corev1.TopologySpreadConstraint, len(source))
+	for i := range source {
+		source[i].DeepCopyInto(&constraints[i])
+		if constraints[i].LabelSelector == nil {
+			constraints[i].LabelSelector = &metav1.LabelSelector{MatchLabels: mergeMaps(nil, selectorLabels)}
+		}
+	}
+	return constraints
+}
+
+// validateTopologySpreadConstraints rejects constraints that could never
+// count the workload pod itself: a labelSelector that does not match the pod
+// template labels, or matchLabelKeys that are missing from them. The
+// pod-template-hash label is added by the ReplicaSet and is always allowed.
+func validateTopologySpreadConstraints(constraints []corev1.TopologySpreadConstraint, podLabels map[string]string) error {
+	for i, constraint := range constraints {
+		selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+		if err != nil {
+			return fmt.Errorf("topologySpreadConstraints[%d].labelSelector: %w", i, err)
+		}
+		if !selector.Matches(labels.Set(podLabels)) {
+			return fmt.Errorf("topologySpreadConstraints[%d].labelSelector does not match the pod template labels", i)
+		}
+		for _, key := range constraint.MatchLabelKeys {
+			if key == appsv1.DefaultDeploymentUniqueLabelKey {
+				continue
+			}
+			if _, ok := podLabels[key]; !ok {
+				return fmt.Errorf("topologySpreadConstraints[%d].matchLabelKeys: label %q is not set on the pod template", i, key)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileDeploymentAppliesDefaultTopologySpread(t *testing.T) {
+	t.Setenv("SPRITZ_POD_TOPOLOGY_SPREAD_CONSTRAINTS", `[{"maxSkew":1,"topologyKey":"topology.kubernetes.io/zone","whenUnsatisfiable":"ScheduleAnyway"}]`)
+	deployment := reconcileTestDeployment(t, newPodSpecTestSpritz())
+
+	constraints := deployment.Spec.Template.Spec.TopologySpreadConstraints
+	if len(constraints) != 1 {
+		t.Fatalf("expected 1 topology spread constraint, got %#v", constraints)
+	}
+	if constraints[0].TopologyKey != "topology.kubernetes.io/zone" {
+		t.Fatalf("unexpected topology key %q", constraints[0].TopologyKey)
+	}
+	if constraints[0].LabelSelector == nil || len(constraints[0].LabelSelector.MatchLabels) == 0 {
+		t.Fatalf("expected default labelSelector to target the spritz pods, got %#v", constraints[0].LabelSelector)
+	}
+	for key, value := range constraints[0].LabelSelector.MatchLabels {
+		if deployment.Spec.Template.Labels[key] != value {
+			t.Fatalf("selector label %s=%s is not on the pod template", key, value)
+		}
+	}
+}
+
+func TestReconcileDeploymentSpecTopologySpreadOverridesDefault(t *testing.T) {
+	t.Setenv("SPRITZ_POD_TOPOLOGY_SPREAD_CONSTRAINTS", `[{"maxSkew":1,"topologyKey":"topology.kubernetes.io/zone","whenUnsatisfiable":"ScheduleAnyway"}]`)
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+		MaxSkew:           2,
+		TopologyKey:       "kubernetes.io/hostname",
+		WhenUnsatisfiable: corev1.DoNotSchedule,
+	}}
+	deployment := reconcileTestDeployment(t, spritz)
+
+	constraints := deployment.Spec.Template.Spec.TopologySpreadConstraints
+	if len(constraints) != 1 || constraints[0].TopologyKey != "kubernetes.io/hostname" || constraints[0].MaxSkew != 2 {
+		t.Fatalf("expected spec constraint to replace the default, got %#v", constraints)
+	}
+	if spritz.Spec.TopologySpreadConstraints[0].LabelSelector != nil {
+		t.Fatal("expected spec constraint to be left unmodified")
+	}
+}
+
+func TestReconcileDeploymentRejectsTopologySpreadForeignLabels(t *testing.T) {
+	spritz := newPodSpecTestSpritz()
+	spritz.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       "kubernetes.io/hostname",
+		WhenUnsatisfiable: corev1.DoNotSchedule,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+	}}
+	scheme := newControllerTestScheme(t)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(spritz).Build()
+	reconciler := &SpritzReconciler{Client: k8sClient, Scheme: scheme}
+
+	err := reconciler.reconcileDeployment(context.Background(), spritz)
+	if err == nil || !strings.Contains(err.Error(), "does not match the pod template labels") {
+		t.Fatalf("expected labelSelector mismatch error, got %v", err)
+	}
+}
+
+func TestValidateTopologySpreadConstraintsMatchLabelKeys(t *testing.T) {
+	podLabels := map[string]string{"spritz.sh/name": "tidy-otter"}
+	constraints := []corev1.TopologySpreadConstraint{{
+		TopologyKey:    "kubernetes.io/hostname",
+		LabelSelector:  &metav1.LabelSelector{MatchLabels: podLabels},
+		MatchLabelKeys: []string{"example.com/team"},
+	}}
+	if err := validateTopologySpreadConstraints(constraints, podLabels); err == nil {
+		t.Fatal("expected missing matchLabelKeys label to be rejected")
+	}
+	constraints[0].MatchLabelKeys = []string{"spritz.sh/name", "pod-template-hash"}
+	if err := validateTopologySpreadConstraints(constraints, podLabels); err != nil {
+		t.Fatalf("expected constraint to pass, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"spritz.sh/operator/tracing"
+)
+
+var tracer = tracing.Tracer("spritz.sh/operator/controllers")
+
+// startReconcileSpan opens the root span of one reconcile. Calls made with
+// the returned context, such as ACP probes, become its children.
+func startReconcileSpan(ctx context.Context, kind string, req ctrl.Request) (context.Context, trace.Span) {
+	return tracer.Start(ctx, kind+".Reconcile", trace.WithAttributes(
+		attribute.String("k8s.namespace.name", req.Namespace),
+		attribute.String("spritz.kind", kind),
+		attribute.String("spritz.name", req.Name),
+	))
+}
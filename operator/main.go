@@ -1,23 +1,29 @@
 package main
 
 import (
+	"context"
 	"os"
 	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	spritzv1 "spritz.sh/operator/api/v1"
 	"spritz.sh/operator/controllers"
+	"spritz.sh/operator/tracing"
 )
 
 func main() {
@@ -28,6 +34,7 @@ func main() {
 	utilruntime.Must(corev1.AddToScheme(scheme))
 	utilruntime.Must(appsv1.AddToScheme(scheme))
 	utilruntime.Must(netv1.AddToScheme(scheme))
+	utilruntime.Must(policyv1.AddToScheme(scheme))
 	utilruntime.Must(gatewayv1.AddToScheme(scheme))
 	utilruntime.Must(spritzv1.AddToScheme(scheme))
 
@@ -37,6 +44,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	ctx := ctrl.SetupSignalHandler()
+	shutdownTracing, err := tracing.Setup(ctx, "spritz-operator")
+	if err != nil {
+		logger.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	metricsAddr := envOrDefault("SPRITZ_OPERATOR_METRICS_ADDR", ":8080")
 	healthAddr := envOrDefault("SPRITZ_OPERATOR_HEALTH_ADDR", ":8081")
 	watchNamespaces := parseWatchNamespaces(os.Getenv("SPRITZ_OPERATOR_WATCH_NAMESPACES"))
@@ -49,13 +63,23 @@ func main() {
 		}
 	}
 
+	webhookConfig := controllers.NewWebhookConfigFromEnv()
+
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme: scheme,
 		Cache:  cacheOptions,
+		// The pre-pull ConfigMap and the shared mounts token Secrets are the
+		// only ConfigMaps and Secrets the operator touches, so read them
+		// directly instead of caching every one in the cluster.
+		Client: client.Options{Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}}}},
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
 		HealthProbeBindAddress: healthAddr,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    webhookConfig.Port,
+			CertDir: webhookConfig.CertDir,
+		}),
 	})
 	if err != nil {
 		logger.Error(err, "unable to start manager")
@@ -67,6 +91,9 @@ func main() {
 		Scheme:                 mgr.GetScheme(),
 		ACP:                    controllers.NewACPProbeConfigFromEnv(),
 		LifecycleNotifications: controllers.NewLifecycleNotificationConfigFromEnv(),
+		ReconcileTimeout:       controllers.ReconcileTimeoutFromEnv(),
+		DriftResyncInterval:    controllers.DriftResyncIntervalFromEnv(),
+		PrepullImages:          controllers.NewPrepullImagesFromEnv(),
 	}).SetupWithManager(mgr); err != nil {
 		logger.Error(err, "unable to create controller")
 		os.Exit(1)
@@ -74,16 +101,28 @@ func main() {
 	if err := (&controllers.SpritzBindingReconciler{
 		Client:          mgr.GetClient(),
 		Scheme:          mgr.GetScheme(),
-		IngressDefaults: controllers.NewBindingIngressDefaultsFromEnv(),
+		IngressDefaults: spritzv1.IngressDefaultsFromEnv(),
 	}).SetupWithManager(mgr); err != nil {
 		logger.Error(err, "unable to create binding controller")
 		os.Exit(1)
 	}
 
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if webhookConfig.Enabled {
+		if err := controllers.SetupSpritzWebhooksWithManager(mgr, controllers.NewSpritzDefaulterFromEnv(), &controllers.SpritzValidator{}); err != nil {
+			logger.Error(err, "unable to create spritz webhooks")
+			os.Exit(1)
+		}
+	}
+
+	if err := mgr.Start(ctx); err != nil {
 		logger.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		logger.Error(err, "failed to flush traces")
+	}
 }
 
 func envOrDefault(key, fallback string) string {
@@ -29,6 +29,12 @@ type MountSpec struct {
 	SyncMode       string `json:"syncMode,omitempty"`
 	PollSeconds    int    `json:"pollSeconds,omitempty"`
 	PublishSeconds int    `json:"publishSeconds,omitempty"`
+	// Excludes lists top-level entries (for example .git or .cache) that are never
+	// bundled, never trigger a publish, and are left in place when a revision is applied.
+	Excludes []string `json:"excludes,omitempty"`
+	// SyncerImage overrides the operator's syncer image for this mount. It must be
+	// the default image or one the operator allows in SPRITZ_SHARED_MOUNTS_SYNCER_IMAGES.
+	SyncerImage string `json:"syncerImage,omitempty"`
 }
 
 type LatestManifest struct {
@@ -83,6 +89,16 @@ func NormalizeMount(mount MountSpec) MountSpec {
 	}
 	mount.Mode = mode
 	mount.SyncMode = syncMode
+	mount.SyncerImage = strings.TrimSpace(mount.SyncerImage)
+	if len(mount.Excludes) > 0 {
+		excludes := make([]string, 0, len(mount.Excludes))
+		for _, exclude := range mount.Excludes {
+			if trimmed := strings.TrimSpace(exclude); trimmed != "" {
+				excludes = append(excludes, trimmed)
+			}
+		}
+		mount.Excludes = excludes
+	}
 	return mount
 }
 
@@ -97,6 +113,21 @@ func NormalizeMounts(mounts []MountSpec) []MountSpec {
 	return normalized
 }
 
+// EnvName turns a mount name into the env var fragment used for
+// SPRITZ_SHARED_MOUNT_<NAME>_PATH: uppercase, with other characters mapped to '_'.
+func EnvName(name string) string {
+	upper := strings.ToUpper(strings.TrimSpace(name))
+	var b strings.Builder
+	for _, r := range upper {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
 func ValidateName(name string) error {
 	trimmed := strings.TrimSpace(name)
 	if trimmed == "" {
@@ -111,6 +142,40 @@ func ValidateName(name string) error {
 	return nil
 }
 
+// ValidateExclude checks one MountSpec.Excludes entry. Excludes name a single
+// top-level entry under the mount root.
+func ValidateExclude(exclude string) error {
+	trimmed := strings.TrimSpace(exclude)
+	if trimmed == "" {
+		return fmt.Errorf("exclude must not be empty")
+	}
+	if trimmed == "." || trimmed == ".." {
+		return fmt.Errorf("exclude must not be '.' or '..'")
+	}
+	if strings.Contains(trimmed, "/") || strings.Contains(trimmed, "\\") {
+		return fmt.Errorf("exclude must name a top-level entry without slashes: %s", exclude)
+	}
+	return nil
+}
+
+// ValidateSyncerImage checks a syncer image reference. Images may be pinned by
+// digest, in which case the digest must be a full sha256 digest.
+func ValidateSyncerImage(image string) error {
+	if image == "" {
+		return nil
+	}
+	if strings.ContainsAny(image, " \t\n") {
+		return fmt.Errorf("syncer image must not contain whitespace: %q", image)
+	}
+	if _, digest, pinned := strings.Cut(image, "@"); pinned {
+		hexDigest, ok := strings.CutPrefix(digest, "sha256:")
+		if !ok || len(hexDigest) != 64 || strings.Trim(hexDigest, "0123456789abcdef") != "" {
+			return fmt.Errorf("syncer image digest must be sha256 with 64 hex characters: %s", image)
+		}
+	}
+	return nil
+}
+
 func ValidateRevision(revision string) error {
 	trimmed := strings.TrimSpace(revision)
 	if trimmed == "" {
@@ -154,6 +219,7 @@ func ValidateMounts(mounts []MountSpec) error {
 		return nil
 	}
 	seenNames := map[string]bool{}
+	envNames := map[string]string{}
 	paths := []string{}
 	seenPaths := map[string]bool{}
 	for _, mount := range mounts {
@@ -169,10 +235,23 @@ func ValidateMounts(mounts []MountSpec) error {
 		if err := ValidateMountPath(mount.MountPath); err != nil {
 			return err
 		}
+		for _, exclude := range mount.Excludes {
+			if err := ValidateExclude(exclude); err != nil {
+				return err
+			}
+		}
+		if err := ValidateSyncerImage(mount.SyncerImage); err != nil {
+			return err
+		}
 		if seenNames[mount.Name] {
 			return fmt.Errorf("duplicate shared mount name: %s", mount.Name)
 		}
 		seenNames[mount.Name] = true
+		envName := EnvName(mount.Name)
+		if other, ok := envNames[envName]; ok {
+			return fmt.Errorf("shared mount names %s and %s both map to env name %s", other, mount.Name, envName)
+		}
+		envNames[envName] = mount.Name
 		cleaned := strings.TrimRight(strings.TrimSpace(mount.MountPath), "/")
 		if seenPaths[cleaned] {
 			return fmt.Errorf("duplicate shared mount path: %s", cleaned)
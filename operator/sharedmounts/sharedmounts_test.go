@@ -1,6 +1,9 @@
 package sharedmounts
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestValidateMountsRejectsInvalidSyncMode(t *testing.T) {
 	mounts := []MountSpec{
@@ -54,3 +57,64 @@ func TestValidateMountsRejectsDuplicatePaths(t *testing.T) {
 		t.Fatal("expected error for duplicate mount paths")
 	}
 }
+
+func TestValidateMountsChecksExcludes(t *testing.T) {
+	valid := NormalizeMount(MountSpec{
+		Name:      "config",
+		MountPath: "/config",
+		Excludes:  []string{" .git ", "", ".cache"},
+	})
+	if len(valid.Excludes) != 2 || valid.Excludes[0] != ".git" {
+		t.Fatalf("expected excludes to be trimmed and blanks dropped, got %q", valid.Excludes)
+	}
+	if err := ValidateMounts([]MountSpec{valid}); err != nil {
+		t.Fatalf("expected top-level excludes to be valid, got error: %v", err)
+	}
+	for _, exclude := range []string{"..", "a/b"} {
+		mount := NormalizeMount(MountSpec{Name: "config", MountPath: "/config", Excludes: []string{exclude}})
+		if err := ValidateMounts([]MountSpec{mount}); err == nil {
+			t.Fatalf("expected exclude %q to be rejected", exclude)
+		}
+	}
+}
+
+func TestOwnerTokenIsScopedToOwner(t *testing.T) {
+	token := OwnerToken("signing-key", "user-a")
+	if !VerifyOwnerToken("signing-key", "user-a", token) {
+		t.Fatal("expected owner token to verify for its owner")
+	}
+	if VerifyOwnerToken("signing-key", "user-b", token) {
+		t.Fatal("expected owner token to be rejected for another owner")
+	}
+	if VerifyOwnerToken("other-key", "user-a", token) {
+		t.Fatal("expected owner token to be rejected with another key")
+	}
+	if VerifyOwnerToken("", "user-a", OwnerToken("", "user-a")) {
+		t.Fatal("expected empty key to never verify")
+	}
+}
+
+func TestValidateSyncerImage(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	for _, image := range []string{"", "example.com/spritz-api:latest", "example.com/spritz-api@" + digest} {
+		if err := ValidateSyncerImage(image); err != nil {
+			t.Fatalf("expected %q to be valid: %v", image, err)
+		}
+	}
+	for _, image := range []string{"example.com/spritz api", "example.com/spritz-api@sha256:abc", "example.com/spritz-api@md5:" + strings.Repeat("a", 64)} {
+		if err := ValidateSyncerImage(image); err == nil {
+			t.Fatalf("expected %q to be rejected", image)
+		}
+	}
+}
+
+func TestValidateMountsRejectsEnvNameCollisions(t *testing.T) {
+	mounts := NormalizeMounts([]MountSpec{
+		{Name: "my-data", MountPath: "/data/one"},
+		{Name: "my_data", MountPath: "/data/two"},
+	})
+	err := ValidateMounts(mounts)
+	if err == nil || !strings.Contains(err.Error(), "MY_DATA") {
+		t.Fatalf("expected env name collision error, got %v", err)
+	}
+}
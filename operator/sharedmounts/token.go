@@ -0,0 +1,26 @@
+package sharedmounts
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// OwnerToken derives the bearer token a shared mount syncer uses for one owner.
+// The API recomputes it from the same key, so a token minted for one owner
+// cannot read or write another owner's mounts.
+func OwnerToken(key, ownerID string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte("spritz-shared-mounts-owner:" + strings.TrimSpace(ownerID)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyOwnerToken reports whether token was derived from key for ownerID.
+func VerifyOwnerToken(key, ownerID, token string) bool {
+	if strings.TrimSpace(key) == "" || strings.TrimSpace(ownerID) == "" {
+		return false
+	}
+	expected := OwnerToken(key, ownerID)
+	return hmac.Equal([]byte(expected), []byte(strings.TrimSpace(token)))
+}